@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
+)
+
+// registerBackends adds the agent backends defined in config to the registry
+// and makes the selected one the default. An override (e.g. --backend flag)
+// takes precedence over agent.backend from config.
+func registerBackends(cfg *config.Config, override string) error {
+	for name, b := range cfg.Agent.Backends {
+		if err := agent.RegisterBackend(agent.Backend{
+			Name:    name,
+			Command: b.Command,
+			Args:    b.Args,
+			Env:     b.Env,
+			WorkDir: b.WorkDir,
+		}); err != nil {
+			return fmt.Errorf("invalid agent backend config: %w", err)
+		}
+	}
+
+	selected := cfg.Agent.Backend
+	if override != "" {
+		selected = override
+	}
+	if err := agent.SetDefaultBackend(selected); err != nil {
+		return fmt.Errorf("%w (configure it under agent.backends)", err)
+	}
+	return nil
+}
@@ -6,12 +6,16 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/mark3labs/iteratr/internal/agent"
 	"github.com/mark3labs/iteratr/internal/config"
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/nats"
 	"github.com/mark3labs/iteratr/internal/orchestrator"
 	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/spec"
+	"github.com/mark3labs/iteratr/internal/template"
 	"github.com/mark3labs/iteratr/internal/tui/wizard"
 	natsserver "github.com/nats-io/nats-server/v2/server"
 	"github.com/spf13/cobra"
@@ -19,15 +23,21 @@ import (
 
 var buildFlags struct {
 	name              string
-	spec              string
+	spec              []string
 	template          string
 	extraInstructions string
 	iterations        int
 	headless          bool
 	dataDir           string
 	model             string
+	backend           string
+	taskBranches      string
+	parallelism       int
 	reset             bool
 	autoCommit        bool
+	iterationTimeout  time.Duration
+	sessionTimeout    time.Duration
+	api               string
 }
 
 var buildCmd = &cobra.Command{
@@ -49,15 +59,21 @@ Global config: ~/.config/iteratr/iteratr.yml`,
 
 func init() {
 	buildCmd.Flags().StringVarP(&buildFlags.name, "name", "n", "", "Session name (default: spec filename stem)")
-	buildCmd.Flags().StringVarP(&buildFlags.spec, "spec", "s", "", "Spec file path (default: ./specs/SPEC.md)")
+	buildCmd.Flags().StringSliceVarP(&buildFlags.spec, "spec", "s", nil, "Spec file, directory, or glob; repeatable (default: specs from config, then ./specs/SPEC.md)")
 	buildCmd.Flags().StringVarP(&buildFlags.template, "template", "t", "", "Custom template file (overrides config file)")
 	buildCmd.Flags().StringVarP(&buildFlags.extraInstructions, "extra-instructions", "e", "", "Extra instructions for prompt")
 	buildCmd.Flags().IntVarP(&buildFlags.iterations, "iterations", "i", 0, "Max iterations, 0=infinite (overrides config file)")
 	buildCmd.Flags().BoolVar(&buildFlags.headless, "headless", false, "Run without TUI (overrides config file)")
 	buildCmd.Flags().StringVar(&buildFlags.dataDir, "data-dir", ".iteratr", "Data directory for NATS storage (overrides config file)")
 	buildCmd.Flags().StringVarP(&buildFlags.model, "model", "m", "", "Model to use (overrides config file, e.g., anthropic/claude-sonnet-4-5)")
+	buildCmd.Flags().StringVar(&buildFlags.backend, "backend", "", "Agent backend to launch (overrides config file, default: opencode)")
+	buildCmd.Flags().StringVar(&buildFlags.taskBranches, "task-branches", "", "Per-task git branches: off, branch, or worktree (overrides config file)")
+	buildCmd.Flags().IntVar(&buildFlags.parallelism, "parallelism", 1, "Ready tasks worked on at once, each in its own worktree (overrides config file)")
 	buildCmd.Flags().BoolVar(&buildFlags.reset, "reset", false, "Reset session data before starting (clears all NATS events for this session)")
 	buildCmd.Flags().BoolVar(&buildFlags.autoCommit, "auto-commit", true, "Auto-commit modified files after iteration (overrides config file)")
+	buildCmd.Flags().DurationVar(&buildFlags.iterationTimeout, "iteration-timeout", 0, "Max agent run time per iteration, e.g. 30m (overrides config file)")
+	buildCmd.Flags().DurationVar(&buildFlags.sessionTimeout, "session-timeout", 0, "Max wall-clock time for this run, e.g. 8h (overrides config file)")
+	buildCmd.Flags().StringVar(&buildFlags.api, "api", "", "Serve the control API on this address, e.g. 127.0.0.1:7420 (overrides config file)")
 }
 
 // setupWizardStore creates a temporary NATS connection and session store for the wizard.
@@ -156,12 +172,70 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	if !cmd.Flags().Changed("auto-commit") {
 		buildFlags.autoCommit = cfg.AutoCommit
 	}
+	if !cmd.Flags().Changed("iteration-timeout") {
+		buildFlags.iterationTimeout = cfg.IterationTimeout
+	}
+	if !cmd.Flags().Changed("session-timeout") {
+		buildFlags.sessionTimeout = cfg.SessionTimeout
+	}
 	if !cmd.Flags().Changed("data-dir") {
 		buildFlags.dataDir = cfg.DataDir
 	}
 	if !cmd.Flags().Changed("template") {
 		buildFlags.template = cfg.Template
 	}
+	if !cmd.Flags().Changed("spec") {
+		buildFlags.spec = cfg.Specs
+	}
+
+	// Register configured agent backends and select the one to launch
+	if err := registerBackends(cfg, buildFlags.backend); err != nil {
+		return err
+	}
+	permissions, err := loadPermissionPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("task-branches") {
+		cfg.TaskBranches.Mode = buildFlags.taskBranches
+	}
+	if err := cfg.TaskBranches.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Agent.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Commit.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Budget.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Prompt.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Notifications.Validate(); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("api") {
+		cfg.API.Addr = buildFlags.api
+	}
+	if err := cfg.API.Validate(); err != nil {
+		return err
+	}
+	cfg.IterationTimeout, cfg.SessionTimeout = buildFlags.iterationTimeout, buildFlags.sessionTimeout
+	if err := cfg.ValidateTimeouts(); err != nil {
+		return err
+	}
+	if err := cfg.ValidateSpecSections(); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("parallelism") {
+		cfg.Parallelism = buildFlags.parallelism
+	}
+	if err := cfg.ValidateParallelism(); err != nil {
+		return err
+	}
 
 	// Validate that model is set after applying config and CLI flags
 	// Model can come from config file, ENV var (ITERATR_MODEL), or CLI flag
@@ -175,7 +249,7 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	resumeMode := false
 
 	// Run wizard if no spec provided and not headless
-	if buildFlags.spec == "" && !buildFlags.headless {
+	if len(buildFlags.spec) == 0 && !buildFlags.headless {
 		logger.Info("No spec file provided, launching wizard...")
 
 		// Set up NATS for wizard session selector
@@ -202,7 +276,7 @@ func runBuild(cmd *cobra.Command, args []string) error {
 			logger.Info("Resuming existing session: %s (model: %s)", result.SessionName, buildFlags.model)
 		} else {
 			// New session mode: apply all wizard results to buildFlags
-			buildFlags.spec = result.SpecPath
+			buildFlags.spec = []string{result.SpecPath}
 			buildFlags.model = result.Model
 			buildFlags.name = result.SessionName
 			buildFlags.iterations = result.Iterations
@@ -239,32 +313,32 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Determine spec path
+	// Determine spec paths
 	// In resume mode, spec is optional (session already has tasks)
-	specPath := buildFlags.spec
-	if specPath == "" {
+	specPaths := buildFlags.spec
+	if len(specPaths) == 0 {
 		// Look for SPEC.md in specs/ directory
 		defaultSpec := "specs/SPEC.md"
 		if _, err := os.Stat(defaultSpec); err == nil {
-			specPath = defaultSpec
+			specPaths = []string{defaultSpec}
 		} else if !resumeMode {
 			// Require spec file for new sessions (not resume mode)
 			return fmt.Errorf("no spec file found, use --spec to specify path or run without --headless to use wizard")
 		}
 	}
 
-	// Check if spec file exists (only if a spec path was determined)
-	if specPath != "" {
-		if _, err := os.Stat(specPath); os.IsNotExist(err) {
-			return fmt.Errorf("spec file not found: %s", specPath)
+	// Check that every spec entry matches at least one file
+	if len(specPaths) > 0 {
+		if _, err := spec.Resolve(specPaths); err != nil {
+			return err
 		}
 	}
 
 	// Determine session name
 	sessionName := buildFlags.name
-	if sessionName == "" {
-		// Derive from spec filename
-		base := filepath.Base(specPath)
+	if sessionName == "" && len(specPaths) > 0 {
+		// Derive from the first spec entry
+		base := filepath.Base(strings.TrimRight(specPaths[0], "/"))
 		ext := filepath.Ext(base)
 		sessionName = strings.TrimSuffix(base, ext)
 
@@ -305,19 +379,44 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Catch template errors before the loop starts
+	if templatePath != "" {
+		if err := template.ValidateFile(templatePath, "."); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+
 	// Create orchestrator
 	orch, err := orchestrator.New(orchestrator.Config{
 		SessionName:       sessionName,
-		SpecPath:          specPath,
+		SpecPaths:         specPaths,
+		RelevantSpec:      cfg.SpecSections == config.SpecSectionsRelevant,
 		TemplatePath:      templatePath,
+		TemplateVars:      cfg.TemplateVars,
 		ExtraInstructions: buildFlags.extraInstructions,
 		Iterations:        buildFlags.iterations,
 		DataDir:           buildFlags.dataDir,
 		Headless:          buildFlags.headless,
 		Model:             buildFlags.model,
+		Backend:           agent.DefaultBackend(),
+		Permissions:       permissions,
+		TaskBranches:      cfg.TaskBranches,
 		Reset:             buildFlags.reset,
 		AutoCommit:        buildFlags.autoCommit,
 		CommitDataDir:     cfg.CommitDataDir,
+		Commit:            cfg.Commit,
+		Budget:            cfg.Budget,
+		Prompt:            cfg.Prompt,
+		Checklist:         cfg.Checklist,
+		Verify:            cfg.Verify,
+		Notifications:     cfg.Notifications,
+		API:               cfg.API,
+		Parallelism:       cfg.Parallelism,
+		IterationTimeout:  cfg.IterationTimeout,
+		SessionTimeout:    cfg.SessionTimeout,
+		StallTimeout:      cfg.StallTimeout,
+		TimeoutRetries:    cfg.TimeoutRetries,
+		MaxRestarts:       cfg.Agent.MaxRestarts,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create orchestrator: %w", err)
@@ -5,9 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"charm.land/lipgloss/v2"
 	"charm.land/lipgloss/v2/table"
+	"github.com/mark3labs/iteratr/internal/agent"
 	"github.com/mark3labs/iteratr/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -52,6 +54,30 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		{"iterations", strconv.Itoa(cfg.Iterations)},
 		{"headless", strconv.FormatBool(cfg.Headless)},
 		{"template", cfg.Template},
+		{"specs", strings.Join(cfg.Specs, ", ")},
+		{"spec_sections", cfg.SpecSections},
+		{"iteration_timeout", cfg.IterationTimeout.String()},
+		{"session_timeout", cfg.SessionTimeout.String()},
+		{"stall_timeout", cfg.StallTimeout.String()},
+		{"timeout_retries", strconv.Itoa(cfg.TimeoutRetries)},
+		{"parallelism", strconv.Itoa(cfg.Parallelism)},
+		{"agent.backend", agentBackendName(cfg)},
+		{"agent.max_restarts", strconv.Itoa(cfg.Agent.MaxRestarts)},
+		{"commit.mode", cfg.Commit.Mode},
+		{"task_branches.mode", cfg.TaskBranches.Mode},
+		{"budget.max_tokens", strconv.FormatInt(cfg.Budget.MaxTokens, 10)},
+		{"budget.max_cost", strconv.FormatFloat(cfg.Budget.MaxCost, 'f', -1, 64)},
+		{"budget.on_exceed", cfg.Budget.OnExceed},
+		{"prompt.max_tokens", strconv.Itoa(cfg.Prompt.MaxTokens)},
+		{"checklist.import", strconv.FormatBool(cfg.Checklist.Import)},
+		{"checklist.write_back", strconv.FormatBool(cfg.Checklist.WriteBack)},
+		{"verify.commands", strings.Join(cfg.Verify.Commands, ", ")},
+		{"verify.timeout", cfg.Verify.Timeout.String()},
+		{"notifications.sinks", notificationSinks(cfg)},
+		{"notifications.retries", strconv.Itoa(cfg.Notifications.Retries)},
+		{"notifications.timeout", cfg.Notifications.Timeout.String()},
+		{"api.addr", cfg.API.Addr},
+		{"api.token", secretValue(cfg.API.Token)},
 	}
 
 	configTable := table.New().
@@ -133,6 +159,25 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		{"ITERATR_ITERATIONS", "iterations"},
 		{"ITERATR_HEADLESS", "headless"},
 		{"ITERATR_TEMPLATE", "template"},
+		{"ITERATR_SPEC_SECTIONS", "spec_sections"},
+		{"ITERATR_ITERATION_TIMEOUT", "iteration_timeout"},
+		{"ITERATR_SESSION_TIMEOUT", "session_timeout"},
+		{"ITERATR_STALL_TIMEOUT", "stall_timeout"},
+		{"ITERATR_PARALLELISM", "parallelism"},
+		{"ITERATR_AGENT_BACKEND", "agent.backend"},
+		{"ITERATR_AGENT_MAX_RESTARTS", "agent.max_restarts"},
+		{"ITERATR_COMMIT_MODE", "commit.mode"},
+		{"ITERATR_TASK_BRANCHES_MODE", "task_branches.mode"},
+		{"ITERATR_BUDGET_MAX_TOKENS", "budget.max_tokens"},
+		{"ITERATR_BUDGET_MAX_COST", "budget.max_cost"},
+		{"ITERATR_BUDGET_ON_EXCEED", "budget.on_exceed"},
+		{"ITERATR_PROMPT_MAX_TOKENS", "prompt.max_tokens"},
+		{"ITERATR_CHECKLIST_IMPORT", "checklist.import"},
+		{"ITERATR_CHECKLIST_WRITE_BACK", "checklist.write_back"},
+		{"ITERATR_VERIFY_TIMEOUT", "verify.timeout"},
+		{"ITERATR_NOTIFICATIONS_RETRIES", "notifications.retries"},
+		{"ITERATR_NOTIFICATIONS_TIMEOUT", "notifications.timeout"},
+		{"ITERATR_API_ADDR", "api.addr"},
 	}
 
 	var envRows [][]string
@@ -176,3 +221,32 @@ func runConfig(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// agentBackendName returns the configured agent backend, falling back to the built-in default.
+func agentBackendName(cfg *config.Config) string {
+	if cfg.Agent.Backend == "" {
+		return agent.DefaultBackendName
+	}
+	return cfg.Agent.Backend
+}
+
+// notificationSinks lists the types of the configured notification sinks.
+func notificationSinks(cfg *config.Config) string {
+	var sinks []string
+	for _, s := range cfg.Notifications.Sinks {
+		if s.Type == config.SinkWebhook && s.Format != "" {
+			sinks = append(sinks, s.Type+" ("+s.Format+")")
+		} else {
+			sinks = append(sinks, s.Type)
+		}
+	}
+	return strings.Join(sinks, ", ")
+}
+
+// secretValue hides a secret, showing only whether it is set.
+func secretValue(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "(set)"
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"charm.land/lipgloss/v2"
 	"charm.land/lipgloss/v2/table"
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +22,7 @@ var doctorCmd = &cobra.Command{
 
 This command verifies that:
 - opencode is installed and in PATH
+- Each configured agent backend starts and completes the ACP handshake
 - The data directory is writable
 - Other environment requirements are met`,
 	RunE: runDoctor,
@@ -39,34 +45,73 @@ type checkResult struct {
 	details string
 }
 
+// backendProbeTimeout bounds the ACP handshake performed for each backend.
+const backendProbeTimeout = 15 * time.Second
+
+// checkBackend verifies that a backend's command exists and completes the ACP initialize handshake.
+func checkBackend(ctx context.Context, b agent.Backend) checkResult {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, err := exec.LookPath(b.Command); err != nil {
+		details := fmt.Sprintf("%s not found in PATH", b.Command)
+		if b.Name == agent.DefaultBackendName {
+			details = "Not found in PATH. Install: https://opencode.coder.com"
+		}
+		return checkResult{name: b.Name, status: "FAIL", details: details}
+	}
+
+	wd, _ := os.Getwd()
+	probeCtx, cancel := context.WithTimeout(ctx, backendProbeTimeout)
+	defer cancel()
+
+	info, err := agent.Probe(probeCtx, b, wd)
+	if err != nil {
+		return checkResult{name: b.Name, status: "FAIL", details: fmt.Sprintf("ACP handshake failed: %v", err)}
+	}
+	return checkResult{name: b.Name, status: "OK", details: fmt.Sprintf("%s %s (%s)", info.Name, info.Version, b)}
+}
+
 func runDoctor(cmd *cobra.Command, args []string) error {
 	var results []checkResult
 	allOk := true
 
-	// Check for opencode
-	if _, err := exec.LookPath("opencode"); err != nil {
+	// Register configured backends; an unknown selection is itself a failure
+	selected := agent.DefaultBackendName
+	if cfg, err := config.Load(); err != nil {
 		results = append(results, checkResult{
-			name:    "opencode",
+			name:    "config",
 			status:  "FAIL",
-			details: "Not found in PATH. Install: https://opencode.coder.com",
+			details: err.Error(),
 		})
 		allOk = false
 	} else {
-		out, err := exec.Command("opencode", "--version").CombinedOutput()
-		if err != nil {
-			results = append(results, checkResult{
-				name:    "opencode",
-				status:  "WARN",
-				details: "Found but can't get version",
-			})
-		} else {
-			version := strings.TrimSpace(string(out))
+		if cfg.Agent.Backend != "" {
+			selected = strings.ToLower(cfg.Agent.Backend)
+		}
+		if err := registerBackends(cfg, ""); err != nil {
 			results = append(results, checkResult{
-				name:    "opencode",
-				status:  "OK",
-				details: version,
+				name:    "agent." + selected,
+				status:  "FAIL",
+				details: err.Error(),
 			})
+			allOk = false
+		}
+	}
+
+	// Check each registered agent backend
+	for _, b := range agent.Backends() {
+		r := checkBackend(cmd.Context(), b)
+		if b.Name == selected {
+			r.name += " (selected)"
+		} else if r.status == "FAIL" {
+			// Unused backends only warn
+			r.status = "WARN"
+		}
+		if r.status == "FAIL" {
+			allOk = false
 		}
+		results = append(results, r)
 	}
 
 	// Build rows with status icons
@@ -10,6 +10,7 @@ import (
 
 var genTemplateFlags struct {
 	output string
+	check  string
 }
 
 var genTemplateCmd = &cobra.Command{
@@ -22,15 +23,29 @@ The generated template can be customized and then used with:
   - --template flag in build command
   - ITERATR_TEMPLATE environment variable
 
-Templates use {{variable}} syntax for substitution.`,
+Templates use Go text/template syntax. The original {{session}}, {{tasks}},
+... placeholders still work; {{.State}}, {{.Git}}, {{.Config}} and {{.Vars}}
+expose the full session state, repository status, run configuration and
+template_vars from iteratr.yml. Use --check to validate a customized template.`,
+	Example: `  iteratr gen-template -o .iteratr.template
+  iteratr gen-template --check .iteratr.template`,
 	RunE: runGenTemplate,
 }
 
 func init() {
 	genTemplateCmd.Flags().StringVarP(&genTemplateFlags.output, "output", "o", ".iteratr.template", "Output file")
+	genTemplateCmd.Flags().StringVar(&genTemplateFlags.check, "check", "", "Validate a template file instead of exporting")
 }
 
 func runGenTemplate(cmd *cobra.Command, args []string) error {
+	if genTemplateFlags.check != "" {
+		if err := template.ValidateFile(genTemplateFlags.check, "."); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		fmt.Printf("Template %s is valid\n", genTemplateFlags.check)
+		return nil
+	}
+
 	// Get default template content
 	content := template.DefaultTemplate
 
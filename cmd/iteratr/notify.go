@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"charm.land/lipgloss/v2"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage notification sinks",
+	Long: `Manage the notification sinks configured under "notifications" in iteratr.yml.
+
+Builds send notifications when the session completes, the agent adds a stuck
+note, an iteration fails or times out, or the budget is exceeded.`,
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test notification to every sink",
+	Long: `Send a test notification to every configured sink, whatever events it is
+subscribed to, retrying as a build would, and report which ones failed.`,
+	Example: `  iteratr notify test`,
+	Args:    cobra.NoArgs,
+	RunE:    runNotifyTest,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.Notifications.Validate(); err != nil {
+		return err
+	}
+	notifier := notify.New(cfg.Notifications)
+	if notifier == nil {
+		return fmt.Errorf("no notification sinks configured (see notifications.sinks in iteratr.yml)")
+	}
+
+	results := notifier.Send(context.Background(), notify.Event{
+		Type:    notify.EventTest,
+		Session: "test",
+		Title:   "Test notification",
+		Message: "Notifications from iteratr reach this sink.",
+	})
+
+	successStyle := lipgloss.NewStyle().Foreground(colorSuccess)
+	errorStyle := lipgloss.NewStyle().Foreground(colorError)
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Println(errorStyle.Render("✗ "+r.Sink) + ": " + r.Err.Error())
+		} else {
+			fmt.Println(successStyle.Render("✓ " + r.Sink))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sinks failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/permission"
+)
+
+// loadPermissionPolicy compiles the permissions section of the config.
+// Returns nil when no permissions are configured, which keeps the previous
+// behavior of auto-granting every tool call.
+func loadPermissionPolicy(cfg *config.Config) (*permission.Policy, error) {
+	p := cfg.Permissions
+	if p.Default == "" && p.Headless == "" && len(p.Rules) == 0 {
+		return nil, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	policy, err := permission.New(p, wd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid permissions config: %w", err)
+	}
+	return policy, nil
+}
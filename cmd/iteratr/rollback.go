@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+var rollbackFlags struct {
+	name      string
+	to        int
+	dataDir   string
+	keepFiles bool
+	yes       bool
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll a session back to an earlier iteration",
+	Long: `Roll a session back to the end of an earlier iteration.
+
+iteratr snapshots the working tree when each iteration starts. Rolling back to
+iteration N restores the snapshot taken when the iteration after N started and
+appends compensating events so tasks, notes, and iterations look as they did
+at that point. The data directory and git-ignored files are never touched.
+
+Stop any running build of the session first, or roll back from the TUI
+(ctrl+x h) instead.`,
+	Example: `  iteratr rollback --name my-session --to 3
+  iteratr rollback --name my-session --to 3 --keep-files --yes`,
+	RunE: runRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().StringVarP(&rollbackFlags.name, "name", "n", "", "Session name (required)")
+	rollbackCmd.Flags().IntVar(&rollbackFlags.to, "to", -1, "Iteration to roll back to (required)")
+	rollbackCmd.Flags().StringVar(&rollbackFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	rollbackCmd.Flags().BoolVar(&rollbackFlags.keepFiles, "keep-files", false, "Only rewind the session, leave the working tree alone")
+	rollbackCmd.Flags().BoolVarP(&rollbackFlags.yes, "yes", "y", false, "Skip the confirmation prompt")
+	_ = rollbackCmd.MarkFlagRequired("name")
+	_ = rollbackCmd.MarkFlagRequired("to")
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	if rollbackFlags.to < 0 {
+		return fmt.Errorf("--to must be an iteration number")
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	dataDir := resolveDataDir(rollbackFlags.dataDir)
+
+	ctx := context.Background()
+	store, running, cleanup, err := openStore(ctx, dataDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if running {
+		return fmt.Errorf("an iteratr build is running on %s; stop it first or roll back from the TUI (ctrl+x h)", dataDir)
+	}
+
+	state, err := store.LoadState(ctx, rollbackFlags.name)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	var discarded []string
+	for _, iter := range state.Iterations {
+		if iter.Number > rollbackFlags.to {
+			discarded = append(discarded, fmt.Sprintf("#%d", iter.Number))
+		}
+	}
+
+	if !rollbackFlags.yes {
+		fmt.Printf("Rolling back session '%s' to iteration #%d.\n", rollbackFlags.name, rollbackFlags.to)
+		if len(discarded) > 0 {
+			fmt.Printf("Discards iterations: %s\n", strings.Join(discarded, ", "))
+		}
+		if rollbackFlags.keepFiles {
+			fmt.Println("Files are kept as they are.")
+		} else {
+			fmt.Println("Uncommitted changes and commits made since then are discarded from the working tree.")
+		}
+		if !confirm("Continue?") {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	result, err := orchestrator.Rollback(ctx, store, orchestrator.RollbackOptions{
+		Session:   rollbackFlags.name,
+		WorkDir:   workDir,
+		DataDir:   dataDir,
+		To:        rollbackFlags.to,
+		KeepFiles: rollbackFlags.keepFiles,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolled back session '%s' to iteration #%d\n", rollbackFlags.name, rollbackFlags.to)
+	if result.Checkpoint != "" {
+		fmt.Printf("Restored files from %s\n", result.Checkpoint)
+	}
+	completed, remaining := 0, 0
+	for _, task := range result.State.Tasks {
+		if task.Status == "completed" {
+			completed++
+		} else if task.Status != "cancelled" {
+			remaining++
+		}
+	}
+	fmt.Printf("Tasks: %d completed, %d remaining\n", completed, remaining)
+	return nil
+}
@@ -0,0 +1,653 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/orchestrator"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var sessionFlags struct {
+	dataDir string
+	json    bool
+	yes     bool
+}
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Manage sessions",
+	Long:  `Manage the sessions stored in the data directory.`,
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List sessions",
+	Long:  `List all sessions with task progress, iteration count, model, and last activity.`,
+	Args:  cobra.NoArgs,
+	RunE:  runSessionList,
+}
+
+var sessionShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a session's tasks, notes, and iterations",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionShow,
+}
+
+var sessionDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a session",
+	Long: `Delete all events of a session and its rollback checkpoints.
+
+Consider 'iteratr session export' first; deleted sessions cannot be recovered.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionDelete,
+}
+
+var sessionRenameCmd = &cobra.Command{
+	Use:   "rename <name> <new-name>",
+	Short: "Rename a session",
+	Long: `Rename a session. Event subjects embed the session name, so all events are
+republished under the new name (keeping their IDs and timestamps) and the old
+ones are removed. Rollback checkpoints are moved as well.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSessionRename,
+}
+
+var sessionMarkCompleteCmd = &cobra.Command{
+	Use:   "complete <name>",
+	Short: "Mark a session as complete",
+	Long:  `Mark a session as complete. All tasks must be completed, blocked, or cancelled.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionMarkComplete,
+}
+
+var sessionReopenCmd = &cobra.Command{
+	Use:   "reopen <name>",
+	Short: "Reopen a completed session",
+	Long:  `Mark a completed session as not complete so 'iteratr build' continues it.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionReopen,
+}
+
+var sessionExportFlags struct {
+	name     string
+	output   string
+	spec     string
+	template string
+}
+
+var sessionExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a session to a portable archive",
+	Long: `Export all events of a session to a JSONL archive.
+
+The first line holds the archive header with the spec and template used by the
+session; each following line is one session event. The archive can be imported
+into another data directory with 'iteratr session import'.`,
+	Example: `  iteratr session export --name my-session
+  iteratr session export --name my-session -o my-session.jsonl --spec specs/auth.md`,
+	RunE: runSessionExport,
+}
+
+var sessionImportFlags struct {
+	name    string
+	force   bool
+	noFiles bool
+}
+
+var sessionImportCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import a session archive",
+	Long: `Import a session archive created by 'iteratr session export'.
+
+Events are replayed with their original IDs and timestamps, so task and note
+IDs and counters match the exported session. The bundled spec and template are
+written to their original paths unless a different file already exists there.`,
+	Example: `  iteratr session import my-session.jsonl
+  iteratr session import my-session.jsonl --name my-session-copy`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionImport,
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionListCmd)
+	sessionCmd.AddCommand(sessionShowCmd)
+	sessionCmd.AddCommand(sessionDeleteCmd)
+	sessionCmd.AddCommand(sessionRenameCmd)
+	sessionCmd.AddCommand(sessionMarkCompleteCmd)
+	sessionCmd.AddCommand(sessionReopenCmd)
+	sessionCmd.AddCommand(sessionExportCmd)
+	sessionCmd.AddCommand(sessionImportCmd)
+
+	sessionCmd.PersistentFlags().StringVar(&sessionFlags.dataDir, "data-dir", "", "Data directory (overrides config file, default: .iteratr)")
+	sessionListCmd.Flags().BoolVar(&sessionFlags.json, "json", false, "Output JSON")
+	sessionShowCmd.Flags().BoolVar(&sessionFlags.json, "json", false, "Output JSON")
+	sessionDeleteCmd.Flags().BoolVarP(&sessionFlags.yes, "yes", "y", false, "Skip the confirmation prompt")
+
+	sessionExportCmd.Flags().StringVarP(&sessionExportFlags.name, "name", "n", "", "Session name (required)")
+	sessionExportCmd.Flags().StringVarP(&sessionExportFlags.output, "output", "o", "", "Output file, - for stdout (default: <name>.iteratr.jsonl)")
+	sessionExportCmd.Flags().StringVarP(&sessionExportFlags.spec, "spec", "s", "", "Spec file to bundle (default: <spec_dir>/<name>.md or specs/SPEC.md if present)")
+	sessionExportCmd.Flags().StringVarP(&sessionExportFlags.template, "template", "t", "", "Template file to bundle (default: template from config)")
+	_ = sessionExportCmd.MarkFlagRequired("name")
+
+	sessionImportCmd.Flags().StringVarP(&sessionImportFlags.name, "name", "n", "", "Import under a different session name")
+	sessionImportCmd.Flags().BoolVar(&sessionImportFlags.force, "force", false, "Replace the session if it already exists")
+	sessionImportCmd.Flags().BoolVar(&sessionImportFlags.noFiles, "no-files", false, "Do not write the bundled spec and template")
+}
+
+func runSessionList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	store, _, cleanup, err := openStore(ctx, resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	infos, err := store.ListSessions(ctx)
+	if err != nil {
+		return err
+	}
+	if sessionFlags.json {
+		return printJSON(infos)
+	}
+	if len(infos) == 0 {
+		fmt.Println("No sessions found")
+		return nil
+	}
+
+	rows := make([][]string, len(infos))
+	for i, info := range infos {
+		rows[i] = []string{
+			info.Name,
+			sessionStatus(info.Complete),
+			fmt.Sprintf("%d/%d", info.TasksCompleted, info.TasksTotal),
+			fmt.Sprintf("%d", info.Iterations),
+			valueOr(info.Model, "-"),
+			formatActivity(info.LastActivity),
+		}
+	}
+	fmt.Println(sessionTable([]string{"Session", "Status", "Tasks", "Iterations", "Model", "Last Activity"}, rows, 1))
+	return nil
+}
+
+// sessionDetails is the JSON output of 'session show'.
+type sessionDetails struct {
+	session.SessionInfo
+	Tasks      []*session.Task      `json:"tasks"`
+	Notes      []*session.Note      `json:"notes"`
+	Iterations []*session.Iteration `json:"iterations"`
+}
+
+func runSessionShow(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	store, _, cleanup, err := openStore(ctx, resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	state, err := loadExistingSession(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+	tasks := make([]*session.Task, 0, len(state.Tasks))
+	for _, task := range state.Tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return taskNumber(tasks[i].ID) < taskNumber(tasks[j].ID) })
+
+	info := state.Info()
+	if sessionFlags.json {
+		return printJSON(sessionDetails{SessionInfo: info, Tasks: tasks, Notes: state.Notes, Iterations: state.Iterations})
+	}
+
+	label := lipgloss.NewStyle().Foreground(colorMuted).Width(15)
+	title := lipgloss.NewStyle().Foreground(colorPrimary).Bold(true)
+	fmt.Println(title.Render(info.Name))
+	for _, kv := range [][2]string{
+		{"Status", sessionStatus(info.Complete)},
+		{"Tasks", fmt.Sprintf("%d/%d completed", info.TasksCompleted, info.TasksTotal)},
+		{"Iterations", fmt.Sprintf("%d", info.Iterations)},
+		{"Notes", fmt.Sprintf("%d", len(state.Notes))},
+		{"Model", valueOr(info.Model, "-")},
+		{"Usage", formatUsage(info.Usage)},
+		{"Last activity", formatActivity(info.LastActivity)},
+	} {
+		fmt.Println(label.Render(kv[0]) + kv[1])
+	}
+
+	if len(tasks) > 0 {
+		rows := make([][]string, len(tasks))
+		for i, task := range tasks {
+			rows[i] = []string{task.ID, task.Status, fmt.Sprintf("P%d", task.Priority), task.Content}
+		}
+		fmt.Println()
+		fmt.Println(sessionTable([]string{"Task", "Status", "Priority", "Content"}, rows, 1))
+	}
+	if len(state.Iterations) > 0 {
+		rows := make([][]string, len(state.Iterations))
+		for i, iter := range state.Iterations {
+			status := "running"
+			if iter.Complete {
+				status = "complete"
+			}
+			if len(iter.Timeouts) > 0 {
+				status += " (timed out)"
+			}
+			switch {
+			case iter.Restarts == 1:
+				status += " (agent restarted)"
+			case iter.Restarts > 1:
+				status += fmt.Sprintf(" (agent restarted %d times)", iter.Restarts)
+			}
+			summary, _, _ := strings.Cut(iter.Summary, "\n")
+			rows[i] = []string{fmt.Sprintf("#%d", iter.Number), status, iter.StartedAt.Local().Format(time.DateTime), formatUsage(iter.Usage), summary}
+		}
+		fmt.Println()
+		fmt.Println(sessionTable([]string{"Iteration", "Status", "Started", "Usage", "Summary"}, rows, 1))
+	}
+	return nil
+}
+
+func runSessionDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	ctx := context.Background()
+	dataDir := resolveDataDir(sessionFlags.dataDir)
+	store, running, cleanup, err := openStore(ctx, dataDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if running {
+		return fmt.Errorf("an iteratr build is running on %s; stop it before deleting sessions", dataDir)
+	}
+
+	state, err := loadExistingSession(ctx, store, name)
+	if err != nil {
+		return err
+	}
+	if !sessionFlags.yes {
+		info := state.Info()
+		prompt := fmt.Sprintf("Delete session '%s' (%d tasks, %d iterations)? This cannot be undone.", name, info.TasksTotal, info.Iterations)
+		if !confirm(prompt) {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	if err := store.DeleteSession(ctx, name); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted session '%s'\n", name)
+	if wd, err := os.Getwd(); err == nil {
+		if n, err := orchestrator.DeleteCheckpoints(wd, name); err != nil {
+			fmt.Printf("Warning: failed to delete checkpoints: %v\n", err)
+		} else if n > 0 {
+			fmt.Printf("Deleted %d checkpoint(s)\n", n)
+		}
+	}
+	return nil
+}
+
+func runSessionRename(cmd *cobra.Command, args []string) error {
+	from, to := args[0], args[1]
+	ctx := context.Background()
+	dataDir := resolveDataDir(sessionFlags.dataDir)
+	store, running, cleanup, err := openStore(ctx, dataDir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if running {
+		return fmt.Errorf("an iteratr build is running on %s; stop it before renaming sessions", dataDir)
+	}
+
+	if err := store.RenameSession(ctx, from, to); err != nil {
+		return err
+	}
+	fmt.Printf("Renamed session '%s' to '%s'\n", from, to)
+	if wd, err := os.Getwd(); err == nil {
+		if n, err := orchestrator.RenameCheckpoints(wd, from, to); err != nil {
+			fmt.Printf("Warning: failed to move checkpoints: %v\n", err)
+		} else if n > 0 {
+			fmt.Printf("Moved %d checkpoint(s)\n", n)
+		}
+	}
+	return nil
+}
+
+func runSessionMarkComplete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	ctx := context.Background()
+	store, _, cleanup, err := openStore(ctx, resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	state, err := loadExistingSession(ctx, store, name)
+	if err != nil {
+		return err
+	}
+	if state.Complete {
+		fmt.Printf("Session '%s' is already complete\n", name)
+		return nil
+	}
+	if err := store.SessionComplete(ctx, name); err != nil {
+		return err
+	}
+	fmt.Printf("Session '%s' marked complete\n", name)
+	return nil
+}
+
+func runSessionReopen(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	ctx := context.Background()
+	store, _, cleanup, err := openStore(ctx, resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	state, err := loadExistingSession(ctx, store, name)
+	if err != nil {
+		return err
+	}
+	if !state.Complete {
+		fmt.Printf("Session '%s' is not complete\n", name)
+		return nil
+	}
+	if err := store.SessionRestart(ctx, name); err != nil {
+		return err
+	}
+	fmt.Printf("Session '%s' reopened\n", name)
+	return nil
+}
+
+// loadExistingSession loads a session's state, failing if it has no events.
+func loadExistingSession(ctx context.Context, store *session.Store, name string) (*session.State, error) {
+	events, err := store.Events(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: %q", session.ErrSessionNotFound, name)
+	}
+	return store.LoadState(ctx, name)
+}
+
+// sessionTable renders rows in the doctor table style; statusCol is colored
+// by sessionStatus value.
+func sessionTable(headers []string, rows [][]string, statusCol int) *table.Table {
+	return table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(colorBorder)).
+		Headers(headers...).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			style := lipgloss.NewStyle().Padding(0, 1)
+			if row == table.HeaderRow {
+				return style.Foreground(colorPrimary).Bold(true)
+			}
+			if col == statusCol {
+				switch rows[row][col] {
+				case "complete", "completed":
+					return style.Foreground(colorSuccess)
+				case "blocked", "cancelled":
+					return style.Foreground(colorError)
+				default:
+					return style.Foreground(colorWarning)
+				}
+			}
+			if col == 0 {
+				return style.Foreground(colorBase)
+			}
+			return style.Foreground(colorMuted)
+		})
+}
+
+// sessionStatus returns the display status of a session.
+func sessionStatus(complete bool) string {
+	if complete {
+		return "complete"
+	}
+	return "in progress"
+}
+
+// formatActivity formats a last-activity timestamp for display.
+func formatActivity(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+// taskNumber extracts N from a TAS-N ID for sorting.
+func taskNumber(id string) int {
+	var n int
+	_, _ = fmt.Sscanf(id, "TAS-%d", &n)
+	return n
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// formatUsage renders token and cost totals, or "-" when nothing was recorded.
+func formatUsage(u usage.Usage) string {
+	if u.IsZero() {
+		return "-"
+	}
+	return u.String()
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// confirm asks a yes/no question on stdin; anything but y/yes is a no.
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func runSessionExport(cmd *cobra.Command, args []string) error {
+	name := sessionExportFlags.name
+	files, err := archiveFiles(name, sessionExportFlags.spec, sessionExportFlags.template)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	store, _, cleanup, err := openStore(ctx, resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	output := sessionExportFlags.output
+	if output == "" {
+		output = name + ".iteratr.jsonl"
+	}
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	header, err := store.Export(ctx, w, name, files)
+	if err != nil {
+		if output != "-" {
+			_ = os.Remove(output)
+		}
+		return err
+	}
+	if output != "-" {
+		fmt.Printf("Exported session '%s' (%d events) to %s\n", name, header.Events, output)
+		for _, f := range files {
+			fmt.Printf("  %s: %s\n", f.Role, f.Path)
+		}
+	}
+	return nil
+}
+
+// archiveFiles reads the spec and template to bundle with an export.
+// Explicit paths must exist; defaults are skipped when missing.
+func archiveFiles(name, specPath, templatePath string) ([]session.ArchiveFile, error) {
+	cfg, _ := config.Load()
+
+	specCandidates := []string{specPath}
+	if specPath == "" {
+		specDir := "specs"
+		if cfg != nil && cfg.SpecDir != "" {
+			specDir = cfg.SpecDir
+		}
+		specCandidates = []string{filepath.Join(specDir, name+".md"), filepath.Join("specs", "SPEC.md")}
+	}
+	templateCandidates := []string{templatePath}
+	if templatePath == "" && cfg != nil {
+		templateCandidates = []string{cfg.Template}
+	}
+
+	var files []session.ArchiveFile
+	for _, c := range []struct {
+		role       string
+		candidates []string
+		explicit   bool
+	}{
+		{"spec", specCandidates, specPath != ""},
+		{"template", templateCandidates, templatePath != ""},
+	} {
+		for _, path := range c.candidates {
+			if path == "" {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				if c.explicit {
+					return nil, fmt.Errorf("failed to read %s: %w", c.role, err)
+				}
+				continue
+			}
+			files = append(files, session.ArchiveFile{Role: c.role, Path: archivePath(path), Content: string(data)})
+			break
+		}
+	}
+	return files, nil
+}
+
+// archivePath returns path relative to the working directory, or just its
+// base name if it lies outside of it.
+func archivePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	wd, _ := os.Getwd()
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil || !filepath.IsLocal(rel) {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func runSessionImport(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	header, events, err := session.ReadArchive(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+
+	name := header.Session
+	if sessionImportFlags.name != "" {
+		name = sessionImportFlags.name
+	}
+
+	ctx := context.Background()
+	store, _, cleanup, err := openStore(ctx, resolveDataDir(sessionFlags.dataDir))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if sessionImportFlags.force {
+		if err := store.ResetSession(ctx, name); err != nil {
+			return fmt.Errorf("failed to reset session '%s': %w", name, err)
+		}
+	}
+	if err := store.Import(ctx, name, events); err != nil {
+		if errors.Is(err, session.ErrSessionExists) {
+			return fmt.Errorf("%w; use --name to import under another name or --force to replace it", err)
+		}
+		return err
+	}
+
+	state, err := store.LoadState(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to load imported session: %w", err)
+	}
+	fmt.Printf("Imported session '%s' (%d events, %d tasks, %d notes, %d iterations)\n",
+		name, len(events), len(state.Tasks), len(state.Notes), len(state.Iterations))
+
+	if !sessionImportFlags.noFiles {
+		for _, file := range header.Files {
+			fmt.Printf("  %s: %s\n", file.Role, restoreArchiveFile(file))
+		}
+	}
+	return nil
+}
+
+// restoreArchiveFile writes a bundled file to its recorded path unless a
+// different file already exists there, and describes what happened.
+func restoreArchiveFile(file session.ArchiveFile) string {
+	path := filepath.FromSlash(file.Path)
+	if !filepath.IsLocal(path) {
+		return fmt.Sprintf("%s skipped (path outside the working directory)", file.Path)
+	}
+	if existing, err := os.ReadFile(path); err == nil {
+		if string(existing) == file.Content {
+			return fmt.Sprintf("%s (unchanged)", file.Path)
+		}
+		return fmt.Sprintf("%s skipped (a different file already exists)", file.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Sprintf("%s skipped (%v)", file.Path, err)
+	}
+	if err := os.WriteFile(path, []byte(file.Content), 0644); err != nil {
+		return fmt.Sprintf("%s skipped (%v)", file.Path, err)
+	}
+	return fmt.Sprintf("%s written", file.Path)
+}
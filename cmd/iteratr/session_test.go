@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestArchiveFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Chdir(tempDir)
+
+	if err := os.MkdirAll("specs", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("specs", "auth.md"), []byte("# Auth\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("finds spec by session name", func(t *testing.T) {
+		files, err := archiveFiles("auth", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files[0].Role != "spec" || files[0].Path != "specs/auth.md" || files[0].Content != "# Auth\n" {
+			t.Errorf("archiveFiles() = %+v, want specs/auth.md", files)
+		}
+	})
+
+	t.Run("missing defaults are skipped", func(t *testing.T) {
+		files, err := archiveFiles("other", "", "")
+		if err != nil || len(files) != 0 {
+			t.Errorf("archiveFiles() = %+v, %v; want nothing", files, err)
+		}
+	})
+
+	t.Run("missing explicit file is an error", func(t *testing.T) {
+		if _, err := archiveFiles("auth", "", "missing.template"); err == nil {
+			t.Error("expected error for missing template")
+		}
+	})
+}
+
+func TestRestoreArchiveFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	file := session.ArchiveFile{Role: "spec", Path: "specs/auth.md", Content: "# Auth\n"}
+	if got := restoreArchiveFile(file); !strings.HasSuffix(got, "written") {
+		t.Errorf("first restore = %q, want written", got)
+	}
+	if got := restoreArchiveFile(file); !strings.HasSuffix(got, "(unchanged)") {
+		t.Errorf("second restore = %q, want unchanged", got)
+	}
+
+	file.Content = "# Other\n"
+	if got := restoreArchiveFile(file); !strings.Contains(got, "skipped") {
+		t.Errorf("conflicting restore = %q, want skipped", got)
+	}
+	if data, _ := os.ReadFile(filepath.Join("specs", "auth.md")); string(data) != "# Auth\n" {
+		t.Errorf("existing file overwritten: %q", data)
+	}
+
+	if got := restoreArchiveFile(session.ArchiveFile{Path: "../escape.md", Content: "x"}); !strings.Contains(got, "outside") {
+		t.Errorf("escaping path = %q, want skipped", got)
+	}
+}
@@ -44,6 +44,11 @@ func runSpec(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("model not configured\n\nSet model via:\n  - iteratr setup (creates config file)\n  - ITERATR_MODEL environment variable")
 	}
 
+	// Register configured agent backends so the interview agent uses the selected one
+	if err := registerBackends(cfg, ""); err != nil {
+		return err
+	}
+
 	// Run the spec wizard
 	if err := specwizard.Run(cfg); err != nil {
 		return fmt.Errorf("spec wizard failed: %w", err)
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/session"
+	natsserver "github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// resolveDataDir returns flagValue if set, otherwise the configured data dir
+// (default: .iteratr).
+func resolveDataDir(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if cfg, err := config.Load(); err == nil && cfg.DataDir != "" {
+		return cfg.DataDir
+	}
+	return ".iteratr"
+}
+
+// openStore opens the session store in dataDir. It connects to the NATS server
+// of a running iteratr build if there is one (running = true), otherwise it
+// starts a temporary embedded server that the returned cleanup shuts down.
+func openStore(ctx context.Context, dataDir string) (store *session.Store, running bool, cleanup func(), err error) {
+	serverDir := filepath.Join(dataDir, "data")
+	if err := os.MkdirAll(serverDir, 0755); err != nil {
+		return nil, false, nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	var ns *natsserver.Server
+	nc := nats.TryConnectExisting(serverDir)
+	running = nc != nil
+	if nc == nil {
+		logger.Debug("Starting temporary NATS server in %s", serverDir)
+		ns, _, err = nats.StartEmbeddedNATS(serverDir)
+		if err != nil {
+			return nil, false, nil, fmt.Errorf("failed to start NATS: %w", err)
+		}
+		nc, err = nats.ConnectInProcess(ns)
+		if err != nil {
+			ns.Shutdown()
+			return nil, false, nil, fmt.Errorf("failed to connect to NATS: %w", err)
+		}
+	}
+
+	cleanup = func() { closeStore(nc, ns) }
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		cleanup()
+		return nil, false, nil, fmt.Errorf("failed to create JetStream: %w", err)
+	}
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		cleanup()
+		return nil, false, nil, fmt.Errorf("failed to setup stream: %w", err)
+	}
+
+	return session.NewStore(js, stream), running, cleanup, nil
+}
+
+// closeStore closes the NATS connection and, if we started it, the server.
+// Publishes are synchronous, so there is nothing to drain.
+func closeStore(nc *natsgo.Conn, ns *natsserver.Server) {
+	nc.Close()
+	if ns != nil {
+		ns.Shutdown()
+		ns.WaitForShutdown()
+	}
+}
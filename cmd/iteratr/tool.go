@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/mark3labs/iteratr/internal/config"
 	"github.com/mark3labs/iteratr/internal/nats"
 	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/verify"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/spf13/cobra"
 )
@@ -38,6 +40,7 @@ func init() {
 	toolCmd.AddCommand(taskDependsCmd)
 	toolCmd.AddCommand(taskListCmd)
 	toolCmd.AddCommand(taskNextCmd)
+	toolCmd.AddCommand(taskGraphCmd)
 	toolCmd.AddCommand(noteAddCmd)
 	toolCmd.AddCommand(noteListCmd)
 	toolCmd.AddCommand(iterationSummaryCmd)
@@ -114,10 +117,18 @@ var taskAddCmd = &cobra.Command{
 
 		content, _ := cmd.Flags().GetString("content")
 		status, _ := cmd.Flags().GetString("status")
+		description, _ := cmd.Flags().GetString("description")
+		criteria, _ := cmd.Flags().GetStringArray("criterion")
+		tags, _ := cmd.Flags().GetStringSlice("tags")
+		specRefs, _ := cmd.Flags().GetStringSlice("spec")
+		estimate, _ := cmd.Flags().GetString("estimate")
 
 		if content == "" {
 			return fmt.Errorf("content is required")
 		}
+		if err := checkInitialStatus(status); err != nil {
+			return err
+		}
 
 		store, cleanup, err := connectToSession()
 		if err != nil {
@@ -127,8 +138,13 @@ var taskAddCmd = &cobra.Command{
 
 		ctx := context.Background()
 		task, err := store.TaskAdd(ctx, toolFlags.name, session.TaskAddParams{
-			Content: content,
-			Status:  status,
+			Content:     content,
+			Status:      status,
+			Description: description,
+			Criteria:    criteria,
+			Tags:        tags,
+			SpecRefs:    specRefs,
+			Estimate:    estimate,
 		})
 		if err != nil {
 			return err
@@ -148,6 +164,11 @@ var taskAddCmd = &cobra.Command{
 func init() {
 	taskAddCmd.Flags().String("content", "", "Task content (required)")
 	taskAddCmd.Flags().String("status", "remaining", "Initial status")
+	taskAddCmd.Flags().String("description", "", "Multi-line details")
+	taskAddCmd.Flags().StringArray("criterion", nil, "Acceptance criterion (repeatable)")
+	taskAddCmd.Flags().StringSlice("tags", nil, "Comma-separated labels")
+	taskAddCmd.Flags().StringSlice("spec", nil, "Comma-separated spec sections, e.g. spec.md#Login")
+	taskAddCmd.Flags().String("estimate", "", "Size estimate, e.g. 2h")
 }
 
 // task-batch-add command
@@ -176,6 +197,11 @@ var taskBatchAddCmd = &cobra.Command{
 		if len(taskInputs) == 0 {
 			return fmt.Errorf("at least one task is required")
 		}
+		for _, input := range taskInputs {
+			if err := checkInitialStatus(input.Status); err != nil {
+				return err
+			}
+		}
 
 		store, cleanup, err := connectToSession()
 		if err != nil {
@@ -214,7 +240,7 @@ var taskBatchAddCmd = &cobra.Command{
 }
 
 func init() {
-	taskBatchAddCmd.Flags().String("tasks", "", `JSON array of tasks, e.g. [{"content":"Task 1"},{"content":"Task 2","status":"in_progress"}]`)
+	taskBatchAddCmd.Flags().String("tasks", "", `JSON array of tasks, e.g. [{"content":"Task 1","criteria":["Has tests"]},{"content":"Task 2","status":"in_progress"}]`)
 }
 
 // task-status command
@@ -243,6 +269,19 @@ var taskStatusCmd = &cobra.Command{
 		defer cleanup()
 
 		ctx := context.Background()
+		if status == "completed" {
+			cfg, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load config for verification: %w", err)
+			}
+			dir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			if err := verifyTask(ctx, store, toolFlags.name, id, cfg.Verify, dir); err != nil {
+				return err
+			}
+		}
 		err = store.TaskStatus(ctx, toolFlags.name, session.TaskStatusParams{
 			ID:     id,
 			Status: status,
@@ -261,6 +300,50 @@ func init() {
 	taskStatusCmd.Flags().String("status", "", "New status (required)")
 }
 
+// verifyTask runs the verification gate for a task being marked completed
+// in dir, recorded against the session's latest iteration. Returns an error
+// with the failing command's output if one fails.
+func verifyTask(ctx context.Context, store *session.Store, sessionName, id string, cfg config.VerifyConfig, dir string) error {
+	state, err := store.LoadState(ctx, sessionName)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	task, err := state.FindTask(id)
+	if err != nil {
+		return err
+	}
+	iteration := 0
+	if len(state.Iterations) > 0 {
+		iteration = state.Iterations[len(state.Iterations)-1].Number
+	}
+
+	result, err := verify.Gate(ctx, store, sessionName, task, cfg, dir, iteration)
+	if err != nil {
+		return fmt.Errorf("verification cancelled: %w", err)
+	}
+	if !result.Passed {
+		return fmt.Errorf("verification failed, %s was not marked completed\n$ %s\n%s\n\nFix the failure, then mark the task completed again",
+			task.ID, result.Failed, strings.TrimRight(result.Output, "\n"))
+	}
+	return nil
+}
+
+// checkInitialStatus rejects adding a task as completed while verification
+// commands are configured, since that would skip the verification gate.
+func checkInitialStatus(status string) error {
+	if status != "completed" {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config for verification: %w", err)
+	}
+	if cfg.Verify.Configured() {
+		return fmt.Errorf("tasks cannot be added as completed while verification commands are configured: add the task, then mark it completed with task-status")
+	}
+	return nil
+}
+
 // task-priority command
 var taskPriorityCmd = &cobra.Command{
 	Use:   "task-priority",
@@ -381,6 +464,9 @@ var taskListCmd = &cobra.Command{
 			lines = append(lines, fmt.Sprintf("%s:", statusLabel))
 			for _, t := range tasks {
 				lines = append(lines, fmt.Sprintf("  [%s] %s", t.ID, t.Content))
+				for _, detail := range t.DetailLines() {
+					lines = append(lines, "      "+detail)
+				}
 			}
 		}
 
@@ -521,6 +607,30 @@ var taskNextCmd = &cobra.Command{
 	},
 }
 
+// task-graph command
+var taskGraphCmd = &cobra.Command{
+	Use:   "task-graph",
+	Short: "Show the task dependency graph and critical path",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if toolFlags.name == "" {
+			return fmt.Errorf("session name is required (--name)")
+		}
+
+		store, cleanup, err := connectToSession()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		state, err := store.LoadState(context.Background(), toolFlags.name)
+		if err != nil {
+			return err
+		}
+		fmt.Println(state.FormatGraph())
+		return nil
+	},
+}
+
 // iteration-summary command
 var iterationSummaryCmd = &cobra.Command{
 	Use:   "iteration-summary",
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
 // TestToolDataDirResolution tests that tool commands correctly resolve data_dir
@@ -371,3 +376,61 @@ func TestToolPortFileResolution(t *testing.T) {
 		}
 	})
 }
+
+// TestVerifyTask tests that task-status runs the verification gate before a
+// task is marked completed, as the MCP task-update tool does
+func TestVerifyTask(t *testing.T) {
+	tmpDir := t.TempDir()
+	ns, port, err := nats.StartEmbeddedNATS(filepath.Join(tmpDir, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ns.Shutdown)
+	nc, err := nats.ConnectToPort(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := session.NewStore(js, stream)
+
+	if err := store.IterationStart(ctx, "demo", 1); err != nil {
+		t.Fatal(err)
+	}
+	task, err := store.TaskAdd(ctx, "demo", session.TaskAddParams{Content: "Add login", Tags: []string{"backend"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.VerifyConfig{
+		Commands: []string{"true"},
+		Tasks:    []config.VerifyRule{{Tag: "backend", Commands: []string{"test -f login.go || (echo missing login.go; exit 1)"}}},
+	}
+
+	err = verifyTask(ctx, store, "demo", task.ID, cfg, tmpDir)
+	if err == nil || !strings.Contains(err.Error(), "missing login.go") {
+		t.Errorf("verifyTask() error = %v, want the failing output", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "login.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyTask(ctx, store, "demo", task.ID, cfg, tmpDir); err != nil {
+		t.Errorf("verifyTask() error = %v, want nil once the commands pass", err)
+	}
+
+	state, err := store.LoadState(ctx, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := state.Tasks[task.ID].Verification; v == nil || !v.Passed || len(v.Commands) != 2 || v.Iteration != 1 {
+		t.Errorf("Verification = %+v, want the passing run recorded in iteration 1", v)
+	}
+}
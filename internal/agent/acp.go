@@ -6,11 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
+	"sync"
 	"sync/atomic"
 
 	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/usage"
 )
 
 // acpConn wraps stdin/stdout pipes for bidirectional JSON-RPC 2.0 communication.
@@ -19,7 +20,9 @@ type acpConn struct {
 	stdout  io.Reader
 	reader  *bufio.Reader
 	encoder *json.Encoder
+	writeMu sync.Mutex // Serializes writes (cancel notifications come from another goroutine)
 	reqID   atomic.Int32
+	costs   map[string]float64 // Last cumulative cost reported per ACP session
 }
 
 // newACPConn creates a new ACP connection wrapping the given pipes.
@@ -29,6 +32,7 @@ func newACPConn(stdin io.WriteCloser, stdout io.Reader) *acpConn {
 		stdout:  stdout,
 		reader:  bufio.NewReader(stdout),
 		encoder: json.NewEncoder(stdin),
+		costs:   make(map[string]float64),
 	}
 }
 
@@ -40,6 +44,8 @@ func (c *acpConn) sendResponse(id int, result any) error {
 		Result:  result,
 	}
 	logger.Debug("ACP response [%d]", id)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
 	if err := c.encoder.Encode(resp); err != nil {
 		return fmt.Errorf("failed to encode response: %w", err)
 	}
@@ -57,12 +63,31 @@ func (c *acpConn) sendRequest(method string, params any) (int, error) {
 	}
 
 	logger.Debug("ACP request [%d]: %s", id, method)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
 	if err := c.encoder.Encode(req); err != nil {
 		return 0, fmt.Errorf("failed to encode request: %w", err)
 	}
 	return id, nil
 }
 
+// sendNotification sends a JSON-RPC 2.0 notification (no response expected).
+func (c *acpConn) sendNotification(method string, params any) error {
+	notif := jsonRPCNotification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+
+	logger.Debug("ACP notification: %s", method)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.encoder.Encode(notif); err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	return nil
+}
+
 // readMessage reads one JSON-RPC message from stdout.
 // Returns nil if EOF is reached.
 func (c *acpConn) readMessage() (*jsonRPCResponse, error) {
@@ -90,7 +115,8 @@ func (c *acpConn) close() error {
 }
 
 // initialize sends the initialize request and validates the agent response.
-func (c *acpConn) initialize(ctx context.Context) error {
+// Returns the agent name and version reported by the agent.
+func (c *acpConn) initialize(ctx context.Context) (*AgentInfo, error) {
 	params := initializeParams{
 		ProtocolVersion: 1,
 		ClientCapabilities: clientCapabilities{
@@ -103,20 +129,20 @@ func (c *acpConn) initialize(ctx context.Context) error {
 
 	reqID, err := c.sendRequest("initialize", params)
 	if err != nil {
-		return fmt.Errorf("failed to send initialize request: %w", err)
+		return nil, fmt.Errorf("failed to send initialize request: %w", err)
 	}
 
 	// Read response
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 
 		resp, err := c.readMessage()
 		if err != nil {
-			return fmt.Errorf("failed to read initialize response: %w", err)
+			return nil, fmt.Errorf("failed to read initialize response: %w", err)
 		}
 
 		// Skip notifications
@@ -131,22 +157,22 @@ func (c *acpConn) initialize(ctx context.Context) error {
 
 		// Handle error response
 		if resp.Error != nil {
-			return fmt.Errorf("initialize failed: %s (code %d)", resp.Error.Message, resp.Error.Code)
+			return nil, fmt.Errorf("initialize failed: %s (code %d)", resp.Error.Message, resp.Error.Code)
 		}
 
 		// Parse result
 		var result initializeResult
 		if err := json.Unmarshal(resp.Result, &result); err != nil {
-			return fmt.Errorf("failed to parse initialize result: %w", err)
+			return nil, fmt.Errorf("failed to parse initialize result: %w", err)
 		}
 
 		// Validate agentInfo is present
 		if result.AgentInfo == nil {
-			return fmt.Errorf("initialize response missing agentInfo")
+			return nil, fmt.Errorf("initialize response missing agentInfo")
 		}
 
 		logger.Debug("ACP initialized: %s v%s", result.AgentInfo.Name, result.AgentInfo.Version)
-		return nil
+		return &AgentInfo{Name: result.AgentInfo.Name, Version: result.AgentInfo.Version}, nil
 	}
 }
 
@@ -294,44 +320,19 @@ type SessionLoader struct {
 	buffer []*jsonRPCResponse // Buffer for notifications received during LoadSession
 }
 
-// NewSessionLoader spawns an opencode acp subprocess and initializes it.
+// NewSessionLoader spawns the default agent backend subprocess and initializes it.
 // The subprocess is ready to load sessions via LoadAndStream.
 func NewSessionLoader(ctx context.Context, workDir string) (*SessionLoader, error) {
 	logger.Debug("Starting ACP subprocess for session loading")
 
-	// Create command - spawn opencode acp
-	cmd := exec.CommandContext(ctx, "opencode", "acp")
-	cmd.Dir = workDir
-	cmd.Env = os.Environ()
-	// Don't inherit stderr - it corrupts terminal state during TUI shutdown
-	// Subprocess errors are captured via the ACP protocol
-
-	// Setup stdin pipe
-	stdin, err := cmd.StdinPipe()
+	backend, err := LookupBackend("")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+		return nil, err
 	}
 
-	// Setup stdout pipe
-	stdout, err := cmd.StdoutPipe()
+	cmd, conn, _, err := startACP(ctx, backend, workDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
-	}
-
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start opencode: %w", err)
-	}
-
-	// Create acpConn from stdin/stdout pipes
-	conn := newACPConn(stdin, stdout)
-
-	// Initialize ACP protocol
-	if err := conn.initialize(ctx); err != nil {
-		_ = conn.close()
-		_ = cmd.Process.Kill()
-		_ = cmd.Wait()
-		return nil, fmt.Errorf("ACP initialize failed: %w", err)
+		return nil, err
 	}
 
 	logger.Debug("ACP subprocess ready for session loading")
@@ -599,10 +600,23 @@ func (c *acpConn) setModel(ctx context.Context, sessionID, modelID string) error
 	}
 }
 
+// promptCallbacks holds the callbacks invoked while a prompt is streaming.
+// Any callback may be nil.
+type promptCallbacks struct {
+	onText       func(string)
+	onToolCall   func(ToolCallEvent)
+	onThinking   func(string)
+	onFileChange func(FileChange)
+	onPermission PermissionHandler // nil = auto-grant every request
+	onActivity   func()            // Called for every message received from the agent
+}
+
 // prompt sends a prompt to the session and streams notifications via callbacks.
 // Accepts multiple text blocks which are sent as separate content blocks in the same request.
-// Returns the stop reason when the prompt completes or an error occurs.
-func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string, onText func(string), onToolCall func(ToolCallEvent), onThinking func(string), onFileChange func(FileChange)) (string, error) {
+// Returns the stop reason and the token usage of this prompt when it completes or an error occurs.
+func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string, cb promptCallbacks) (string, usage.Usage, error) {
+	onText, onToolCall, onThinking, onFileChange := cb.onText, cb.onToolCall, cb.onThinking, cb.onFileChange
+
 	// Build content blocks from texts
 	blocks := make([]contentBlock, 0, len(texts))
 	for _, text := range texts {
@@ -621,23 +635,28 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 
 	reqID, err := c.sendRequest("session/prompt", params)
 	if err != nil {
-		return "", fmt.Errorf("failed to send session/prompt request: %w", err)
+		return "", usage.Usage{}, fmt.Errorf("failed to send session/prompt request: %w", err)
 	}
 
 	// Track whether agent produced any output (to detect silent failures)
 	hadOutput := false
+	// Cost arrives through usage_update notifications, tokens with the prompt result
+	var used usage.Usage
 
 	// Read messages in loop until response with matching request ID arrives
 	for {
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", used, ctx.Err()
 		default:
 		}
 
 		resp, err := c.readMessage()
 		if err != nil {
-			return "", fmt.Errorf("failed to read prompt response: %w", err)
+			return "", used, fmt.Errorf("failed to read prompt response: %w", err)
+		}
+		if cb.onActivity != nil {
+			cb.onActivity()
 		}
 
 		// For notifications (id==nil, method=="session/update"): parse update params
@@ -739,6 +758,18 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 					onToolCall(event)
 				}
 
+			case "usage_update":
+				// usage_update: cost is cumulative for the ACP session, keep the delta for this prompt
+				var uu usageUpdate
+				if err := json.Unmarshal(updateParams.Update, &uu); err != nil {
+					logger.Warn("Failed to parse usage_update: %v", err)
+					continue
+				}
+				if uu.Cost != nil && uu.Cost.Amount > c.costs[sessionID] {
+					used.Cost += uu.Cost.Amount - c.costs[sessionID]
+					c.costs[sessionID] = uu.Cost.Amount
+				}
+
 			case "available_commands_update":
 				// available_commands_update: skip
 				continue
@@ -755,36 +786,15 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 			continue
 		}
 
-		// Handle server-initiated permission requests (auto-grant)
+		// Handle server-initiated permission requests
 		if resp.Method == "session/request_permission" {
 			var params permissionRequestParams
 			if err := json.Unmarshal(resp.Params, &params); err != nil {
 				logger.Warn("Failed to parse permission request: %v", err)
 				continue
 			}
-			// Find "always" option, fall back to first allow option
-			optionID := ""
-			for _, opt := range params.Options {
-				if opt.Kind == "allow_always" {
-					optionID = opt.OptionID
-					break
-				}
-			}
-			if optionID == "" {
-				for _, opt := range params.Options {
-					if opt.Kind == "allow_once" {
-						optionID = opt.OptionID
-						break
-					}
-				}
-			}
-			if optionID == "" && len(params.Options) > 0 {
-				optionID = params.Options[0].OptionID
-			}
-			logger.Debug("ACP auto-granting permission [%s] tool=%s option=%s", params.ToolCall.Title, params.ToolCall.ToolCallID, optionID)
-			if err := c.sendResponse(*resp.ID, permissionResponse{
-				Outcome: permissionOutcome{OptionID: optionID, Outcome: "selected"},
-			}); err != nil {
+			outcome := c.resolvePermission(ctx, params, cb.onPermission)
+			if err := c.sendResponse(*resp.ID, permissionResponse{Outcome: outcome}); err != nil {
 				logger.Warn("Failed to send permission response: %v", err)
 			}
 			continue
@@ -797,7 +807,7 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 
 		// Handle error response
 		if resp.Error != nil {
-			return "", fmt.Errorf("session/prompt failed: %s (code %d)", resp.Error.Message, resp.Error.Code)
+			return "", used, fmt.Errorf("session/prompt failed: %s (code %d)", resp.Error.Message, resp.Error.Code)
 		}
 
 		// Parse result to extract stop reason
@@ -805,7 +815,10 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 		if err := json.Unmarshal(resp.Result, &result); err != nil {
 			logger.Warn("Failed to parse prompt result: %v", err)
 			// Default to "end_turn" if parsing fails
-			return "end_turn", nil
+			return "end_turn", used, nil
+		}
+		if result.Usage != nil {
+			result.Usage.apply(&used)
 		}
 
 		// Return stop reason (e.g., "end_turn", "max_tokens", "cancelled", "refusal", "max_turn_requests")
@@ -818,14 +831,44 @@ func (c *acpConn) prompt(ctx context.Context, sessionID string, texts []string,
 		// This typically indicates credential errors or other API failures that don't return proper JSON-RPC errors
 		if stopReason == "end_turn" && !hadOutput {
 			logger.Warn("Agent returned end_turn without producing any output - possible credential or API error")
-			return "", fmt.Errorf("agent returned no output - this may indicate a credential error (e.g., API key restricted to specific use cases) or model availability issue")
+			return "", used, fmt.Errorf("agent returned no output - this may indicate a credential error (e.g., API key restricted to specific use cases) or model availability issue")
 		}
 
 		logger.Debug("ACP prompt completed with stop reason: %s", stopReason)
-		return stopReason, nil
+		return stopReason, used, nil
 	}
 }
 
+// cancel asks the agent to stop the in-flight prompt of a session.
+// The agent answers the pending session/prompt request with stop reason "cancelled".
+func (c *acpConn) cancel(sessionID string) error {
+	return c.sendNotification("session/cancel", cancelParams{SessionID: sessionID})
+}
+
+// resolvePermission answers a session/request_permission request.
+// Without a handler every request is auto-granted. With a handler, the decision is
+// mapped onto the offered options; a denial with no reject option cancels the request.
+func (c *acpConn) resolvePermission(ctx context.Context, params permissionRequestParams, handler PermissionHandler) permissionOutcome {
+	if handler == nil {
+		optionID := selectAutoGrantOption(params.Options)
+		logger.Debug("ACP auto-granting permission [%s] tool=%s option=%s", params.ToolCall.Title, params.ToolCall.ToolCallID, optionID)
+		return permissionOutcome{OptionID: optionID, Outcome: "selected"}
+	}
+
+	decision := handler(ctx, PermissionRequest{
+		ToolCallID: params.ToolCall.ToolCallID,
+		Title:      params.ToolCall.Title,
+		Kind:       params.ToolCall.Kind,
+		RawInput:   params.ToolCall.RawInput,
+	})
+	optionID := selectDecisionOption(params.Options, decision)
+	logger.Debug("ACP permission [%s] tool=%s allow=%v option=%s", params.ToolCall.Title, params.ToolCall.ToolCallID, decision.Allow, optionID)
+	if optionID == "" {
+		return permissionOutcome{Outcome: "cancelled"}
+	}
+	return permissionOutcome{OptionID: optionID, Outcome: "selected"}
+}
+
 // JSON-RPC 2.0 message envelope
 // extractSessionID parses rawOutput.metadata.sessionId from a completed tool call.
 func extractSessionID(rawOutput map[string]any) string {
@@ -951,6 +994,12 @@ type jsonRPCRequest struct {
 	Params  any    `json:"params"`
 }
 
+type jsonRPCNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
 type jsonRPCResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      *int            `json:"id,omitempty"`     // nil for notifications
@@ -1029,8 +1078,49 @@ type contentBlock struct {
 	Text string `json:"text"`
 }
 
+type cancelParams struct {
+	SessionID string `json:"sessionId"`
+}
+
 type promptResult struct {
-	StopReason string `json:"stopReason"`
+	StopReason string       `json:"stopReason"`
+	Usage      *promptUsage `json:"usage,omitempty"` // Token usage for the turn (optional)
+}
+
+// promptUsage is the token usage an agent may attach to a session/prompt result.
+type promptUsage struct {
+	InputTokens       int64 `json:"inputTokens"`
+	OutputTokens      int64 `json:"outputTokens"`
+	ThoughtTokens     int64 `json:"thoughtTokens"`
+	CachedReadTokens  int64 `json:"cachedReadTokens"`
+	CachedWriteTokens int64 `json:"cachedWriteTokens"`
+	TotalTokens       int64 `json:"totalTokens"`
+}
+
+// apply copies the token counts into u. Agents that only report a total
+// have it counted as input tokens.
+func (p *promptUsage) apply(u *usage.Usage) {
+	u.InputTokens = p.InputTokens
+	u.OutputTokens = p.OutputTokens
+	u.ReasoningTokens = p.ThoughtTokens
+	u.CacheReadTokens = p.CachedReadTokens
+	u.CacheWriteTokens = p.CachedWriteTokens
+	if u.Tokens() == 0 {
+		u.InputTokens = p.TotalTokens
+	}
+}
+
+// usage_update (context window and cumulative session cost)
+type usageUpdate struct {
+	SessionUpdate string     `json:"sessionUpdate"` // "usage_update"
+	Used          int64      `json:"used"`          // Tokens in the context window
+	Size          int64      `json:"size"`          // Context window size
+	Cost          *usageCost `json:"cost,omitempty"`
+}
+
+type usageCost struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
 }
 
 // SessionUpdate notification params
@@ -1129,7 +1219,7 @@ type permissionResponse struct {
 }
 
 type permissionOutcome struct {
-	OptionID string `json:"optionId"`
+	OptionID string `json:"optionId,omitempty"`
 	Outcome  string `json:"outcome"` // "selected" or "cancelled"
 }
 
@@ -1,7 +1,12 @@
 package agent
 
 import (
+	"context"
+	"math"
+	"strings"
 	"testing"
+
+	"github.com/mark3labs/iteratr/internal/usage"
 )
 
 func TestExtractDiffBlocks_OnlyProcessesCompletedEdits(t *testing.T) {
@@ -583,3 +588,40 @@ func TestBuildMcpServerSlice(t *testing.T) {
 		})
 	}
 }
+
+type discardCloser struct{}
+
+func (discardCloser) Write(p []byte) (int, error) { return len(p), nil }
+func (discardCloser) Close() error                { return nil }
+
+func TestPrompt_Usage(t *testing.T) {
+	lines := []string{
+		`{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"agent_message_chunk","content":{"type":"text","text":"hi"}}}}`,
+		`{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"usage_update","used":1200,"size":200000,"cost":{"amount":0.25,"currency":"USD"}}}}`,
+		`{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"usage_update","used":1500,"size":200000,"cost":{"amount":0.40,"currency":"USD"}}}}`,
+		`{"jsonrpc":"2.0","id":1,"result":{"stopReason":"end_turn","usage":{"inputTokens":1000,"outputTokens":200,"thoughtTokens":50,"cachedReadTokens":300,"totalTokens":1550}}}`,
+		// Second prompt on the same session: only the cost increase counts
+		`{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"agent_message_chunk","content":{"type":"text","text":"again"}}}}`,
+		`{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"usage_update","used":1800,"size":200000,"cost":{"amount":0.50,"currency":"USD"}}}}`,
+		`{"jsonrpc":"2.0","id":2,"result":{"stopReason":"end_turn","usage":{"totalTokens":400}}}`,
+	}
+	conn := newACPConn(discardCloser{}, strings.NewReader(strings.Join(lines, "\n")+"\n"))
+	ctx := context.Background()
+
+	stop, used, err := conn.prompt(ctx, "s1", []string{"go"}, promptCallbacks{})
+	if err != nil || stop != "end_turn" {
+		t.Fatalf("prompt() = %q, %v", stop, err)
+	}
+	want := usage.Usage{InputTokens: 1000, OutputTokens: 200, ReasoningTokens: 50, CacheReadTokens: 300, Cost: 0.40}
+	if used != want {
+		t.Errorf("first prompt usage = %+v, want %+v", used, want)
+	}
+
+	_, used, err = conn.prompt(ctx, "s1", []string{"again"}, promptCallbacks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used.InputTokens != 400 || math.Abs(used.Cost-0.10) > 1e-9 {
+		t.Errorf("second prompt usage = %+v, want 400 input tokens and $0.10", used)
+	}
+}
@@ -0,0 +1,197 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// DefaultBackendName is the name of the built-in opencode backend.
+const DefaultBackendName = "opencode"
+
+// Backend describes how to launch an ACP-speaking agent subprocess.
+type Backend struct {
+	Name    string   // Registry name (e.g., "opencode")
+	Command string   // Executable to run (looked up in PATH if not a path)
+	Args    []string // Arguments passed to the executable
+	Env     []string // Extra environment variables in KEY=VALUE form
+	WorkDir string   // Process working directory (relative to the runner work dir; empty = runner work dir)
+}
+
+// AgentInfo identifies the agent reported during the ACP initialize handshake.
+type AgentInfo struct {
+	Name    string
+	Version string
+}
+
+var (
+	backendsMu     sync.RWMutex
+	backends       = map[string]Backend{DefaultBackendName: builtinOpencode()}
+	defaultBackend = DefaultBackendName
+)
+
+// builtinOpencode returns the built-in backend that spawns `opencode acp`.
+func builtinOpencode() Backend {
+	return Backend{
+		Name:    DefaultBackendName,
+		Command: "opencode",
+		Args:    []string{"acp"},
+	}
+}
+
+// RegisterBackend adds or replaces a backend in the registry.
+// Names are case-insensitive and stored lowercase.
+func RegisterBackend(b Backend) error {
+	name := strings.ToLower(strings.TrimSpace(b.Name))
+	if name == "" {
+		return fmt.Errorf("backend name is required")
+	}
+	if strings.TrimSpace(b.Command) == "" {
+		return fmt.Errorf("backend %q: command is required", name)
+	}
+	for _, kv := range b.Env {
+		if !strings.Contains(kv, "=") {
+			return fmt.Errorf("backend %q: invalid env entry %q (expected KEY=VALUE)", name, kv)
+		}
+	}
+	b.Name = name
+
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = b
+	logger.Debug("Registered agent backend: %s (%s)", name, b.Command)
+	return nil
+}
+
+// LookupBackend returns the backend registered under name.
+// An empty name resolves to the current default backend.
+func LookupBackend(name string) (Backend, error) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	if name == "" {
+		name = defaultBackend
+	}
+	b, ok := backends[strings.ToLower(name)]
+	if !ok {
+		return Backend{}, fmt.Errorf("unknown agent backend: %s", name)
+	}
+	return b, nil
+}
+
+// SetDefaultBackend selects the backend used when a runner or session loader
+// doesn't name one explicitly. The backend must already be registered.
+func SetDefaultBackend(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultBackendName
+	}
+
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if _, ok := backends[name]; !ok {
+		return fmt.Errorf("unknown agent backend: %s", name)
+	}
+	defaultBackend = name
+	return nil
+}
+
+// DefaultBackend returns the name of the current default backend.
+func DefaultBackend() string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	return defaultBackend
+}
+
+// Backends returns all registered backends sorted by name.
+func Backends() []Backend {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	list := make([]Backend, 0, len(backends))
+	for _, b := range backends {
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// String returns the command line used to launch the backend.
+func (b Backend) String() string {
+	return strings.Join(append([]string{b.Command}, b.Args...), " ")
+}
+
+// command builds the subprocess command for the backend.
+func (b Backend) command(ctx context.Context, workDir string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, b.Command, b.Args...)
+	cmd.Dir = workDir
+	if b.WorkDir != "" {
+		if filepath.IsAbs(b.WorkDir) {
+			cmd.Dir = b.WorkDir
+		} else {
+			cmd.Dir = filepath.Join(workDir, b.WorkDir)
+		}
+	}
+	cmd.Env = append(os.Environ(), b.Env...)
+	return cmd
+}
+
+// startACP spawns the backend subprocess and performs the ACP initialize handshake.
+// On failure the subprocess is killed before returning.
+func startACP(ctx context.Context, b Backend, workDir string) (*exec.Cmd, *acpConn, *AgentInfo, error) {
+	cmd := b.command(ctx, workDir)
+	// Don't inherit stderr - it corrupts terminal state during TUI shutdown
+	// Subprocess errors are captured via the ACP protocol
+
+	// Setup stdin pipe
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+
+	// Setup stdout pipe
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	// Start the command
+	logger.Debug("Starting %s subprocess: %s", b.Name, b)
+	if err := cmd.Start(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to start %s: %w", b.Name, err)
+	}
+
+	// Create acpConn from stdin/stdout pipes
+	conn := newACPConn(stdin, stdout)
+
+	// Initialize ACP protocol (handshake only - sessions created separately)
+	info, err := conn.initialize(ctx)
+	if err != nil {
+		_ = conn.close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, nil, nil, fmt.Errorf("ACP initialize failed: %w", err)
+	}
+
+	return cmd, conn, info, nil
+}
+
+// Probe launches the backend, performs the ACP initialize handshake and shuts it down.
+// Used by `iteratr doctor` to validate configured backends.
+func Probe(ctx context.Context, b Backend, workDir string) (*AgentInfo, error) {
+	cmd, conn, info, err := startACP(ctx, b, workDir)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.close()
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	return info, nil
+}
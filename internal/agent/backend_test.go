@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFakeACP writes a shell script that answers the ACP initialize request.
+// It also dumps its environment and working directory to files for assertions.
+func writeFakeACP(t *testing.T, dir string) string {
+	t.Helper()
+	script := `#!/bin/sh
+pwd > "$FAKE_ACP_OUT/cwd"
+echo "$FAKE_ACP_FLAG" > "$FAKE_ACP_OUT/env"
+echo "$@" > "$FAKE_ACP_OUT/args"
+read line
+printf '{"jsonrpc":"2.0","id":1,"result":{"agentInfo":{"name":"fake-acp","version":"0.1.0"}}}\n'
+read line
+`
+	path := filepath.Join(dir, "fake-acp")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake ACP script: %v", err)
+	}
+	return path
+}
+
+// resetBackends restores the registry to its built-in state after a test.
+func resetBackends(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		backendsMu.Lock()
+		defer backendsMu.Unlock()
+		backends = map[string]Backend{DefaultBackendName: builtinOpencode()}
+		defaultBackend = DefaultBackendName
+	})
+}
+
+func TestLookupBackend_Default(t *testing.T) {
+	resetBackends(t)
+
+	b, err := LookupBackend("")
+	if err != nil {
+		t.Fatalf("LookupBackend(\"\") error = %v", err)
+	}
+	if b.Name != DefaultBackendName || b.Command != "opencode" {
+		t.Errorf("default backend = %+v, want opencode", b)
+	}
+	if b.String() != "opencode acp" {
+		t.Errorf("String() = %q, want %q", b.String(), "opencode acp")
+	}
+
+	if _, err := LookupBackend("missing"); err == nil {
+		t.Error("LookupBackend(missing) should fail")
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	resetBackends(t)
+
+	tests := []struct {
+		name    string
+		backend Backend
+		wantErr string
+	}{
+		{
+			name:    "missing name",
+			backend: Backend{Command: "agent"},
+			wantErr: "name is required",
+		},
+		{
+			name:    "missing command",
+			backend: Backend{Name: "custom"},
+			wantErr: "command is required",
+		},
+		{
+			name:    "invalid env",
+			backend: Backend{Name: "custom", Command: "agent", Env: []string{"NOVALUE"}},
+			wantErr: "invalid env entry",
+		},
+		{
+			name:    "valid",
+			backend: Backend{Name: "Custom", Command: "agent", Args: []string{"acp"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterBackend(tt.backend)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("RegisterBackend() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterBackend() error = %v", err)
+			}
+		})
+	}
+
+	// Names are case-insensitive
+	b, err := LookupBackend("CUSTOM")
+	if err != nil {
+		t.Fatalf("LookupBackend(CUSTOM) error = %v", err)
+	}
+	if b.Name != "custom" {
+		t.Errorf("registered name = %q, want custom", b.Name)
+	}
+
+	names := []string{}
+	for _, b := range Backends() {
+		names = append(names, b.Name)
+	}
+	if strings.Join(names, ",") != "custom,opencode" {
+		t.Errorf("Backends() = %v, want [custom opencode]", names)
+	}
+}
+
+func TestSetDefaultBackend(t *testing.T) {
+	resetBackends(t)
+
+	if err := SetDefaultBackend("nope"); err == nil {
+		t.Error("SetDefaultBackend(nope) should fail for unregistered backend")
+	}
+	if err := RegisterBackend(Backend{Name: "fake", Command: "fake-acp"}); err != nil {
+		t.Fatalf("RegisterBackend() error = %v", err)
+	}
+	if err := SetDefaultBackend("fake"); err != nil {
+		t.Fatalf("SetDefaultBackend(fake) error = %v", err)
+	}
+	if DefaultBackend() != "fake" {
+		t.Errorf("DefaultBackend() = %q, want fake", DefaultBackend())
+	}
+	b, _ := LookupBackend("")
+	if b.Name != "fake" {
+		t.Errorf("LookupBackend(\"\") = %q, want fake", b.Name)
+	}
+
+	// Empty resets to the built-in default
+	if err := SetDefaultBackend(""); err != nil {
+		t.Fatalf("SetDefaultBackend(\"\") error = %v", err)
+	}
+	if DefaultBackend() != DefaultBackendName {
+		t.Errorf("DefaultBackend() = %q, want %q", DefaultBackend(), DefaultBackendName)
+	}
+}
+
+func TestProbe_FakeBackend(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	sub := filepath.Join(dir, "sub")
+	for _, d := range []string{out, sub} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := Backend{
+		Name:    "fake",
+		Command: writeFakeACP(t, dir),
+		Args:    []string{"--mode", "ci"},
+		Env:     []string{"FAKE_ACP_OUT=" + out, "FAKE_ACP_FLAG=on"},
+		WorkDir: "sub",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	info, err := Probe(ctx, b, dir)
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if info.Name != "fake-acp" || info.Version != "0.1.0" {
+		t.Errorf("Probe() info = %+v, want fake-acp 0.1.0", info)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		return strings.TrimSpace(string(data))
+	}
+	if got := read("args"); got != "--mode ci" {
+		t.Errorf("args = %q, want %q", got, "--mode ci")
+	}
+	if got := read("env"); got != "on" {
+		t.Errorf("env FAKE_ACP_FLAG = %q, want on", got)
+	}
+	wantCwd, _ := filepath.EvalSymlinks(sub)
+	gotCwd, _ := filepath.EvalSymlinks(read("cwd"))
+	if gotCwd != wantCwd {
+		t.Errorf("cwd = %q, want %q", gotCwd, wantCwd)
+	}
+}
+
+func TestProbe_MissingCommand(t *testing.T) {
+	b := Backend{Name: "missing", Command: filepath.Join(t.TempDir(), "does-not-exist")}
+	if _, err := Probe(context.Background(), b, t.TempDir()); err == nil {
+		t.Error("Probe() should fail for a missing command")
+	}
+}
@@ -0,0 +1,62 @@
+package agent
+
+import "context"
+
+// PermissionRequest is a tool permission request received from the agent
+// via session/request_permission.
+type PermissionRequest struct {
+	ToolCallID string         // Tool call the permission applies to
+	Title      string         // Tool title (e.g., "bash")
+	Kind       string         // Tool kind (e.g., "execute", "edit")
+	RawInput   map[string]any // Tool input parameters
+}
+
+// PermissionDecision is the answer to a PermissionRequest.
+type PermissionDecision struct {
+	Allow bool
+}
+
+// PermissionHandler decides whether a tool call may proceed.
+// It may block (e.g. waiting for the user) until ctx is cancelled.
+type PermissionHandler func(ctx context.Context, req PermissionRequest) PermissionDecision
+
+// selectAutoGrantOption picks the option used when no permission handler is configured.
+// Prefers "allow_always", then "allow_once", then the first option.
+func selectAutoGrantOption(options []permissionOption) string {
+	if id := findOption(options, "allow_always"); id != "" {
+		return id
+	}
+	if id := findOption(options, "allow_once"); id != "" {
+		return id
+	}
+	if len(options) > 0 {
+		return options[0].OptionID
+	}
+	return ""
+}
+
+// selectDecisionOption maps a decision onto the offered options.
+// Once-only options are preferred so every future call is re-evaluated by the handler.
+// Returns an empty string if no option of the required kind exists.
+func selectDecisionOption(options []permissionOption, decision PermissionDecision) string {
+	if decision.Allow {
+		if id := findOption(options, "allow_once"); id != "" {
+			return id
+		}
+		return findOption(options, "allow_always")
+	}
+	if id := findOption(options, "reject_once"); id != "" {
+		return id
+	}
+	return findOption(options, "reject_always")
+}
+
+// findOption returns the ID of the first option with the given kind.
+func findOption(options []permissionOption, kind string) string {
+	for _, opt := range options {
+		if opt.Kind == kind {
+			return opt.OptionID
+		}
+	}
+	return ""
+}
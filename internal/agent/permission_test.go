@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"testing"
+)
+
+var testPermissionOptions = []permissionOption{
+	{OptionID: "always", Kind: "allow_always"},
+	{OptionID: "once", Kind: "allow_once"},
+	{OptionID: "reject", Kind: "reject_once"},
+	{OptionID: "reject-always", Kind: "reject_always"},
+}
+
+func TestSelectAutoGrantOption(t *testing.T) {
+	if got := selectAutoGrantOption(testPermissionOptions); got != "always" {
+		t.Errorf("selectAutoGrantOption() = %q, want always", got)
+	}
+	if got := selectAutoGrantOption([]permissionOption{{OptionID: "x", Kind: "other"}}); got != "x" {
+		t.Errorf("selectAutoGrantOption() = %q, want first option", got)
+	}
+	if got := selectAutoGrantOption(nil); got != "" {
+		t.Errorf("selectAutoGrantOption(nil) = %q, want empty", got)
+	}
+}
+
+func TestSelectDecisionOption(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []permissionOption
+		decision PermissionDecision
+		want     string
+	}{
+		{"allow prefers once", testPermissionOptions, PermissionDecision{Allow: true}, "once"},
+		{"allow falls back to always", testPermissionOptions[:1], PermissionDecision{Allow: true}, "always"},
+		{"deny prefers once", testPermissionOptions, PermissionDecision{Allow: false}, "reject"},
+		{"deny falls back to always", testPermissionOptions[3:], PermissionDecision{Allow: false}, "reject-always"},
+		{"deny without reject option", testPermissionOptions[:2], PermissionDecision{Allow: false}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectDecisionOption(tt.options, tt.decision); got != tt.want {
+				t.Errorf("selectDecisionOption() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePermission(t *testing.T) {
+	c := &acpConn{}
+	params := permissionRequestParams{
+		ToolCall: permissionTool{ToolCallID: "call-1", Title: "bash", Kind: "execute", RawInput: map[string]any{"command": "ls"}},
+		Options:  testPermissionOptions,
+	}
+
+	// No handler keeps the auto-grant behavior
+	if out := c.resolvePermission(context.Background(), params, nil); out.OptionID != "always" || out.Outcome != "selected" {
+		t.Errorf("auto-grant outcome = %+v", out)
+	}
+
+	var got PermissionRequest
+	deny := func(_ context.Context, req PermissionRequest) PermissionDecision {
+		got = req
+		return PermissionDecision{Allow: false}
+	}
+	if out := c.resolvePermission(context.Background(), params, deny); out.OptionID != "reject" || out.Outcome != "selected" {
+		t.Errorf("deny outcome = %+v", out)
+	}
+	if got.Title != "bash" || got.Kind != "execute" || got.RawInput["command"] != "ls" {
+		t.Errorf("handler received %+v", got)
+	}
+
+	// A denial with no reject option cancels the request
+	params.Options = testPermissionOptions[:2]
+	if out := c.resolvePermission(context.Background(), params, deny); out.Outcome != "cancelled" || out.OptionID != "" {
+		t.Errorf("cancelled outcome = %+v", out)
+	}
+}
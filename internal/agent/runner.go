@@ -2,17 +2,29 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	ierr "github.com/mark3labs/iteratr/internal/errors"
 	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/usage"
 )
 
-// Runner manages the execution of opencode run subprocess for each iteration.
+// cancelGrace is how long a cancelled prompt may take to stop before the
+// agent subprocess is killed.
+var cancelGrace = 10 * time.Second
+
+// Runner manages the execution of the agent backend subprocess for each iteration.
 type Runner struct {
+	backend      string
 	model        string
 	workDir      string
 	sessionName  string
@@ -23,15 +35,21 @@ type Runner struct {
 	onThinking   func(string)
 	onFinish     func(FinishEvent)
 	onFileChange func(FileChange)
+	onPermission PermissionHandler
 
 	// ACP subprocess (reused) and current session (created fresh per iteration)
-	conn      *acpConn
-	sessionID string // Current session ID (replaced each iteration for fresh context)
-	cmd       *exec.Cmd
+	conn       *acpConn
+	sessionID  string // Current session ID (replaced each iteration for fresh context)
+	sessionDir string // Working directory override for new sessions (e.g. a task worktree)
+	cmd        *exec.Cmd
+	startCtx   context.Context // Context the subprocess was started with (reused on restart)
+	dead       atomic.Bool     // Subprocess exited or was killed after ignoring a cancel; restarted on next iteration
+	activity   atomic.Int64    // Unix nanos of the last message received from the agent
 }
 
 // RunnerConfig holds configuration for creating a new Runner.
 type RunnerConfig struct {
+	Backend      string              // Agent backend name from the registry (default: current default backend)
 	Model        string              // LLM model to use (e.g., "anthropic/claude-sonnet-4-5")
 	WorkDir      string              // Working directory for agent
 	SessionName  string              // Session name
@@ -42,11 +60,13 @@ type RunnerConfig struct {
 	OnThinking   func(string)        // Callback for thinking/reasoning output
 	OnFinish     func(FinishEvent)   // Callback for iteration finish events
 	OnFileChange func(FileChange)    // Callback for file modifications
+	OnPermission PermissionHandler   // Decides tool permission requests (nil = auto-grant)
 }
 
 // NewRunner creates a new Runner instance.
 func NewRunner(cfg RunnerConfig) *Runner {
 	return &Runner{
+		backend:      cfg.Backend,
 		model:        cfg.Model,
 		workDir:      cfg.WorkDir,
 		sessionName:  cfg.SessionName,
@@ -57,7 +77,34 @@ func NewRunner(cfg RunnerConfig) *Runner {
 		onThinking:   cfg.OnThinking,
 		onFinish:     cfg.OnFinish,
 		onFileChange: cfg.OnFileChange,
+		onPermission: cfg.OnPermission,
+	}
+}
+
+// callbacks bundles the runner callbacks for acpConn.prompt.
+func (r *Runner) callbacks() promptCallbacks {
+	return promptCallbacks{
+		onText:       r.onText,
+		onToolCall:   r.onToolCall,
+		onThinking:   r.onThinking,
+		onFileChange: r.onFileChange,
+		onPermission: r.onPermission,
+		onActivity:   r.touch,
+	}
+}
+
+// touch records that the agent just sent a message.
+func (r *Runner) touch() {
+	r.activity.Store(time.Now().UnixNano())
+}
+
+// LastActivity returns when the agent last sent an ACP message, or when the
+// current prompt was sent if nothing has arrived since. Zero before the first prompt.
+func (r *Runner) LastActivity() time.Time {
+	if ns := r.activity.Load(); ns != 0 {
+		return time.Unix(0, ns)
 	}
+	return time.Time{}
 }
 
 // extractProvider parses provider name from model string.
@@ -75,56 +122,46 @@ func extractProvider(model string) string {
 	return ""
 }
 
-// Start spawns the opencode acp subprocess and initializes the ACP protocol.
+// Start spawns the agent backend subprocess and initializes the ACP protocol.
 // Sessions are created fresh per iteration for clean context.
 // Must be called before RunIteration.
 func (r *Runner) Start(ctx context.Context) error {
 	logger.Debug("Starting ACP subprocess")
 
-	// Create command - spawn opencode acp
-	cmd := exec.CommandContext(ctx, "opencode", "acp")
-	cmd.Dir = r.workDir
-	cmd.Env = os.Environ()
-	// Don't inherit stderr - it corrupts terminal state during TUI shutdown
-	// Subprocess errors are captured via the ACP protocol
-
-	// Setup stdin pipe
-	stdin, err := cmd.StdinPipe()
+	backend, err := LookupBackend(r.backend)
 	if err != nil {
-		return fmt.Errorf("failed to create stdin pipe: %w", err)
+		return err
 	}
 
-	// Setup stdout pipe
-	stdout, err := cmd.StdoutPipe()
+	cmd, conn, _, err := startACP(ctx, backend, r.workDir)
 	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
-	}
-
-	// Start the command
-	logger.Debug("Starting opencode subprocess")
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start opencode: %w", err)
-	}
-
-	// Create acpConn from stdin/stdout pipes
-	conn := newACPConn(stdin, stdout)
-
-	// Initialize ACP protocol (handshake only - sessions created per iteration)
-	if err := conn.initialize(ctx); err != nil {
-		_ = conn.close()
-		_ = cmd.Process.Kill()
-		_ = cmd.Wait()
-		return fmt.Errorf("ACP initialize failed: %w", err)
+		return err
 	}
 
 	// Store subprocess state (no session yet - created fresh per iteration)
 	r.conn = conn
 	r.cmd = cmd
+	r.startCtx = ctx
+	r.dead.Store(false)
 
 	logger.Debug("ACP subprocess ready")
 	return nil
 }
 
+// SetSessionDir sets the working directory for sessions created by later
+// RunIteration calls. An empty dir restores the runner's work dir.
+func (r *Runner) SetSessionDir(dir string) {
+	r.sessionDir = dir
+}
+
+// SessionDir returns the working directory used for new sessions.
+func (r *Runner) SessionDir() string {
+	if r.sessionDir != "" {
+		return r.sessionDir
+	}
+	return r.workDir
+}
+
 // RunIteration executes a single iteration with fresh context by creating a new ACP session.
 // Optional hookOutput is sent as a separate content block before the main prompt.
 // Start() must be called first to initialize the subprocess.
@@ -132,12 +169,18 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 	if r.conn == nil {
 		return fmt.Errorf("ACP subprocess not started - call Start() first")
 	}
+	if r.dead.Load() {
+		logger.Info("Restarting agent subprocess after it was terminated")
+		if err := r.Restart(); err != nil {
+			return ierr.NewTransientError("agent restart", err)
+		}
+	}
 
 	// Create fresh session for this iteration (clean context)
 	logger.Debug("Creating new ACP session for iteration")
-	sessID, err := r.conn.newSession(ctx, r.workDir, r.mcpServerURL)
+	sessID, err := r.conn.newSession(ctx, r.SessionDir(), r.mcpServerURL)
 	if err != nil {
-		return fmt.Errorf("ACP new session failed: %w", err)
+		return r.checkExit(ctx, fmt.Errorf("ACP new session failed: %w", err))
 	}
 	r.sessionID = sessID
 
@@ -145,7 +188,7 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 	if r.model != "" {
 		logger.Debug("Setting model: %s", r.model)
 		if err := r.conn.setModel(ctx, sessID, r.model); err != nil {
-			return fmt.Errorf("ACP set model failed: %w", err)
+			return r.checkExit(ctx, fmt.Errorf("ACP set model failed: %w", err))
 		}
 	}
 
@@ -160,27 +203,27 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 	texts = append(texts, prompt)
 
 	// Send prompt and stream notifications to callbacks
-	// Wire onText, onToolCall, onThinking, and onFileChange callbacks through to prompt()
 	startTime := time.Now()
-	stopReason, err := r.conn.prompt(ctx, r.sessionID, texts, r.onText, r.onToolCall, r.onThinking, r.onFileChange)
+	stopReason, used, err := r.prompt(ctx, texts)
 	duration := time.Since(startTime)
 
 	if err != nil {
 		// Prompt failed - determine if it was cancelled or error
 		if r.onFinish != nil {
-			finalStopReason := "error"
-			if ctx.Err() == context.Canceled {
-				finalStopReason = "cancelled"
+			finalStopReason, errMsg := "error", err.Error()
+			if ctx.Err() != nil {
+				finalStopReason, errMsg = "cancelled", context.Cause(ctx).Error()
 			}
 			r.onFinish(FinishEvent{
 				StopReason: finalStopReason,
-				Error:      err.Error(),
+				Error:      errMsg,
 				Duration:   duration,
 				Model:      r.model,
 				Provider:   extractProvider(r.model),
+				Usage:      used,
 			})
 		}
-		return fmt.Errorf("ACP prompt failed: %w", err)
+		return r.checkExit(ctx, fmt.Errorf("ACP prompt failed: %w", err))
 	}
 
 	// Prompt succeeded - call onFinish with the actual stop reason from ACP
@@ -190,10 +233,11 @@ func (r *Runner) RunIteration(ctx context.Context, prompt string, hookOutput str
 			Duration:   duration,
 			Model:      r.model,
 			Provider:   extractProvider(r.model),
+			Usage:      used,
 		})
 	}
 
-	logger.Debug("opencode iteration completed successfully")
+	logger.Debug("Agent iteration completed successfully")
 	return nil
 }
 
@@ -217,25 +261,26 @@ func (r *Runner) SendMessages(ctx context.Context, texts []string) error {
 
 	// Send prompt with all messages as separate content blocks
 	startTime := time.Now()
-	stopReason, err := r.conn.prompt(ctx, r.sessionID, texts, r.onText, r.onToolCall, r.onThinking, r.onFileChange)
+	stopReason, used, err := r.prompt(ctx, texts)
 	duration := time.Since(startTime)
 
 	if err != nil {
 		// Prompt failed - determine if it was cancelled or error
 		if r.onFinish != nil {
-			finalStopReason := "error"
-			if ctx.Err() == context.Canceled {
-				finalStopReason = "cancelled"
+			finalStopReason, errMsg := "error", err.Error()
+			if ctx.Err() != nil {
+				finalStopReason, errMsg = "cancelled", context.Cause(ctx).Error()
 			}
 			r.onFinish(FinishEvent{
 				StopReason: finalStopReason,
-				Error:      err.Error(),
+				Error:      errMsg,
 				Duration:   duration,
 				Model:      r.model,
 				Provider:   extractProvider(r.model),
+				Usage:      used,
 			})
 		}
-		return fmt.Errorf("ACP user message failed: %w", err)
+		return r.checkExit(ctx, fmt.Errorf("ACP user message failed: %w", err))
 	}
 
 	// Prompt succeeded - call onFinish with the actual stop reason from ACP
@@ -245,6 +290,7 @@ func (r *Runner) SendMessages(ctx context.Context, texts []string) error {
 			Duration:   duration,
 			Model:      r.model,
 			Provider:   extractProvider(r.model),
+			Usage:      used,
 		})
 	}
 
@@ -252,6 +298,77 @@ func (r *Runner) SendMessages(ctx context.Context, texts []string) error {
 	return nil
 }
 
+// prompt sends texts to the current session. Cancelling ctx is forwarded to the
+// agent as session/cancel; an agent that does not stop within cancelGrace is
+// killed so the read unblocks, and restarted by the next RunIteration.
+func (r *Runner) prompt(ctx context.Context, texts []string) (string, usage.Usage, error) {
+	conn, cmd, sessionID := r.conn, r.cmd, r.sessionID
+	r.touch()
+
+	// Wait for the watchdog on return so it never races with Stop()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	done := make(chan struct{})
+	defer close(done)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+		logger.Info("Cancelling agent prompt: %v", context.Cause(ctx))
+		if err := conn.cancel(sessionID); err != nil {
+			logger.Warn("Failed to send session/cancel: %v", err)
+		}
+		select {
+		case <-done:
+		case <-time.After(cancelGrace):
+			logger.Warn("Agent did not stop within %s of cancel, terminating subprocess", cancelGrace)
+			r.dead.Store(true)
+			if cmd != nil && cmd.Process != nil {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait() // Closes stdout even if a child process still holds it
+			}
+		}
+	}()
+
+	return conn.prompt(ctx, sessionID, texts, r.callbacks())
+}
+
+// checkExit marks the subprocess dead when err shows it exited or closed its
+// pipes, and wraps err as an *errors.TransientError so callers can restart
+// the agent and retry. Errors from a cancelled ctx are returned unchanged.
+func (r *Runner) checkExit(ctx context.Context, err error) error {
+	if ctx.Err() != nil || !isProcessExit(err) {
+		return err
+	}
+	logger.Warn("Agent subprocess exited unexpectedly: %v", err)
+	r.dead.Store(true)
+	return ierr.NewTransientError("agent", err)
+}
+
+// isProcessExit reports whether err comes from reading or writing the pipes
+// of an agent subprocess that has gone away.
+func isProcessExit(err error) bool {
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, os.ErrClosed)
+}
+
+// Restart stops the agent subprocess and starts a fresh one with the context
+// passed to Start, re-running the ACP initialize handshake.
+func (r *Runner) Restart() error {
+	startCtx := r.startCtx
+	if startCtx == nil {
+		return fmt.Errorf("ACP subprocess not started - call Start() first")
+	}
+	r.Stop()
+	return r.Start(startCtx)
+}
+
 // Stop terminates the ACP subprocess and cleans up resources.
 // Should be called when done with the runner (e.g., on orchestrator exit).
 func (r *Runner) Stop() {
@@ -261,7 +378,7 @@ func (r *Runner) Stop() {
 		r.conn = nil
 	}
 	if r.cmd != nil && r.cmd.Process != nil {
-		logger.Debug("Terminating opencode subprocess")
+		logger.Debug("Terminating agent subprocess")
 		_ = r.cmd.Process.Kill()
 		_ = r.cmd.Wait()
 		r.cmd = nil
@@ -1,6 +1,19 @@
 package agent
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+)
 
 func TestExtractProvider(t *testing.T) {
 	tests := []struct {
@@ -44,3 +57,151 @@ func TestExtractProvider(t *testing.T) {
 		})
 	}
 }
+
+// writeHangingACP writes a fake agent that opens a session, then ignores the
+// prompt and any cancel request. Each start is appended to $FAKE_ACP_OUT/starts.
+func writeHangingACP(t *testing.T, dir string) string {
+	t.Helper()
+	script := `#!/bin/sh
+echo start >> "$FAKE_ACP_OUT/starts"
+read line
+printf '{"jsonrpc":"2.0","id":1,"result":{"agentInfo":{"name":"hang","version":"0.1.0"}}}\n'
+read line
+printf '{"jsonrpc":"2.0","id":2,"result":{"sessionId":"s1"}}\n'
+read line
+printf '{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"agent_thought_chunk","content":{"type":"text","text":"thinking"}}}}\n'
+exec sleep 30
+`
+	path := filepath.Join(dir, "hang-acp")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake ACP script: %v", err)
+	}
+	return path
+}
+
+func TestRunner_CancelKillsUnresponsiveAgent(t *testing.T) {
+	resetBackends(t)
+	old := cancelGrace
+	cancelGrace = 100 * time.Millisecond
+	t.Cleanup(func() { cancelGrace = old })
+
+	dir := t.TempDir()
+	if err := RegisterBackend(Backend{
+		Name:    "hang",
+		Command: writeHangingACP(t, dir),
+		Env:     []string{"FAKE_ACP_OUT=" + dir},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var finish FinishEvent
+	r := NewRunner(RunnerConfig{Backend: "hang", WorkDir: dir, OnFinish: func(e FinishEvent) { finish = e }})
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(r.Stop)
+
+	errTimeout := errors.New("iteration timed out")
+	for attempt := 1; attempt <= 2; attempt++ {
+		ctx, cancel := context.WithTimeoutCause(context.Background(), 200*time.Millisecond, errTimeout)
+		start := time.Now()
+		err := r.RunIteration(ctx, "work", "")
+		cancel()
+		if err == nil {
+			t.Fatalf("attempt %d: RunIteration() should fail when cancelled", attempt)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("attempt %d: RunIteration() took %s to return after cancel", attempt, elapsed)
+		}
+		if finish.StopReason != "cancelled" || finish.Error != errTimeout.Error() {
+			t.Errorf("attempt %d: finish = %q/%q, want cancelled with the cancel cause", attempt, finish.StopReason, finish.Error)
+		}
+		if time.Since(r.LastActivity()) > 5*time.Second {
+			t.Errorf("attempt %d: LastActivity() = %v, want recent", attempt, r.LastActivity())
+		}
+	}
+
+	// The killed agent is restarted for the second iteration
+	data, err := os.ReadFile(filepath.Join(dir, "starts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if starts := strings.Count(string(data), "start"); starts != 2 {
+		t.Errorf("agent started %d times, want 2", starts)
+	}
+}
+
+// writeCrashingACP writes a fake agent that exits in the middle of the prompt
+// on its first start and completes the prompt on later starts. Each start is
+// appended to $FAKE_ACP_OUT/starts.
+func writeCrashingACP(t *testing.T, dir string) string {
+	t.Helper()
+	script := `#!/bin/sh
+echo start >> "$FAKE_ACP_OUT/starts"
+read line
+printf '{"jsonrpc":"2.0","id":1,"result":{"agentInfo":{"name":"crash","version":"0.1.0"}}}\n'
+read line
+printf '{"jsonrpc":"2.0","id":2,"result":{"sessionId":"s1"}}\n'
+read line
+if [ "$(wc -l < "$FAKE_ACP_OUT/starts")" -eq 1 ]; then
+	exit 1
+fi
+printf '{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"agent_message_chunk","content":{"type":"text","text":"done"}}}}\n'
+printf '{"jsonrpc":"2.0","id":3,"result":{"stopReason":"end_turn"}}\n'
+`
+	path := filepath.Join(dir, "crash-acp")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake ACP script: %v", err)
+	}
+	return path
+}
+
+func TestRunner_ProcessExitIsTransient(t *testing.T) {
+	resetBackends(t)
+	dir := t.TempDir()
+	if err := RegisterBackend(Backend{
+		Name:    "crash",
+		Command: writeCrashingACP(t, dir),
+		Env:     []string{"FAKE_ACP_OUT=" + dir},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRunner(RunnerConfig{Backend: "crash", WorkDir: dir})
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(r.Stop)
+
+	err := r.RunIteration(context.Background(), "work", "")
+	if !ierr.IsTransient(err) {
+		t.Fatalf("RunIteration() error = %v, want transient error", err)
+	}
+
+	if err := r.Restart(); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	if err := r.RunIteration(context.Background(), "work", ""); err != nil {
+		t.Fatalf("RunIteration() after restart error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "starts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if starts := strings.Count(string(data), "start"); starts != 2 {
+		t.Errorf("agent started %d times, want 2", starts)
+	}
+}
+
+func TestIsProcessExit(t *testing.T) {
+	for _, err := range []error{io.EOF, io.ErrUnexpectedEOF, syscall.EPIPE, os.ErrClosed, fmt.Errorf("read: %w", io.EOF)} {
+		if !isProcessExit(err) {
+			t.Errorf("isProcessExit(%v) = false, want true", err)
+		}
+	}
+	for _, err := range []error{errors.New("JSON-RPC error -32000"), context.Canceled} {
+		if isProcessExit(err) {
+			t.Errorf("isProcessExit(%v) = true, want false", err)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package agent
 
-import "time"
+import (
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/usage"
+)
 
 // FileDiff contains before/after file content from an edit tool call.
 type FileDiff struct {
@@ -40,4 +44,5 @@ type FinishEvent struct {
 	Duration   time.Duration // Time taken for the iteration
 	Model      string        // Model used (e.g., "anthropic/claude-sonnet-4-5")
 	Provider   string        // Provider extracted from model (e.g., "Anthropic")
+	Usage      usage.Usage   // Tokens and cost reported by the agent (zero if none)
 }
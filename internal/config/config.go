@@ -3,9 +3,13 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -23,6 +27,200 @@ type Config struct {
 	Template      string `mapstructure:"template" yaml:"template"`
 	SpecDir       string `mapstructure:"spec_dir" yaml:"spec_dir"`
 	CommitDataDir bool   `mapstructure:"commit_data_dir" yaml:"commit_data_dir"`
+
+	Specs        []string `mapstructure:"specs" yaml:"specs,omitempty"`                 // Spec files, directories, or globs (default: specs/SPEC.md)
+	SpecSections string   `mapstructure:"spec_sections" yaml:"spec_sections,omitempty"` // all or relevant (sections matching the current task)
+
+	IterationTimeout time.Duration `mapstructure:"iteration_timeout" yaml:"iteration_timeout,omitempty"` // Max agent run time per iteration (0 = none)
+	SessionTimeout   time.Duration `mapstructure:"session_timeout" yaml:"session_timeout,omitempty"`     // Max wall-clock time for a build run (0 = none)
+	StallTimeout     time.Duration `mapstructure:"stall_timeout" yaml:"stall_timeout,omitempty"`         // Cancel when the agent sends no updates for this long (0 = none)
+	TimeoutRetries   int           `mapstructure:"timeout_retries" yaml:"timeout_retries,omitempty"`     // Retries of a timed-out iteration before moving on
+
+	Parallelism int `mapstructure:"parallelism" yaml:"parallelism,omitempty"` // Ready tasks worked on at once, each in its own worktree (1 = sequential)
+
+	TemplateVars map[string]string `mapstructure:"template_vars" yaml:"template_vars,omitempty"` // User-defined variables available to prompt templates
+
+	Agent        AgentConfig        `mapstructure:"agent" yaml:"agent,omitempty"`
+	Permissions  PermissionsConfig  `mapstructure:"permissions" yaml:"permissions,omitempty"`
+	TaskBranches TaskBranchesConfig `mapstructure:"task_branches" yaml:"task_branches,omitempty"`
+	Commit       CommitConfig       `mapstructure:"commit" yaml:"commit,omitempty"`
+	Budget       BudgetConfig       `mapstructure:"budget" yaml:"budget,omitempty"`
+	Prompt       PromptConfig       `mapstructure:"prompt" yaml:"prompt,omitempty"`
+
+	Checklist     ChecklistConfig     `mapstructure:"checklist" yaml:"checklist,omitempty"`
+	Verify        VerifyConfig        `mapstructure:"verify" yaml:"verify,omitempty"`
+	Notifications NotificationsConfig `mapstructure:"notifications" yaml:"notifications,omitempty"`
+	API           APIConfig           `mapstructure:"api" yaml:"api,omitempty"`
+}
+
+// AgentConfig selects and defines the ACP agent backends iteratr can launch.
+type AgentConfig struct {
+	Backend     string                   `mapstructure:"backend" yaml:"backend,omitempty"`           // Selected backend (default: opencode)
+	Backends    map[string]BackendConfig `mapstructure:"backends" yaml:"backends,omitempty"`         // Custom backends keyed by name
+	MaxRestarts int                      `mapstructure:"max_restarts" yaml:"max_restarts,omitempty"` // Crash restarts allowed per session (0 = fail on first crash)
+}
+
+// PermissionsConfig controls how agent tool permission requests are answered.
+// Rules are evaluated in order; the first matching rule wins.
+type PermissionsConfig struct {
+	Default  string           `mapstructure:"default" yaml:"default,omitempty"`   // allow, deny, or ask (default: allow)
+	Headless string           `mapstructure:"headless" yaml:"headless,omitempty"` // How "ask" resolves without a TUI: prompt or deny (default: deny)
+	Rules    []PermissionRule `mapstructure:"rules" yaml:"rules,omitempty"`
+}
+
+// PermissionRule matches a tool call and assigns it an action.
+// Empty matchers match anything; all non-empty matchers must match.
+type PermissionRule struct {
+	Kind    string `mapstructure:"kind" yaml:"kind,omitempty"`       // Tool kind (execute, edit, read, ...)
+	Path    string `mapstructure:"path" yaml:"path,omitempty"`       // Glob matched against the tool's file path (supports **)
+	Command string `mapstructure:"command" yaml:"command,omitempty"` // Regex matched against the tool's shell command
+	Action  string `mapstructure:"action" yaml:"action"`             // allow, deny, or ask
+}
+
+// CommitConfig controls how auto-commit creates commits.
+type CommitConfig struct {
+	Mode     string   `mapstructure:"mode" yaml:"mode,omitempty"`         // iteratr (commit directly) or agent (ask the agent to commit)
+	Message  string   `mapstructure:"message" yaml:"message,omitempty"`   // Message template (iteratr mode)
+	Trailers []string `mapstructure:"trailers" yaml:"trailers,omitempty"` // "Key: value" trailer templates (iteratr mode)
+}
+
+// Commit modes.
+const (
+	CommitModeIteratr = "iteratr"
+	CommitModeAgent   = "agent"
+)
+
+// BudgetConfig limits the tokens and cost a session may consume.
+// Zero limits are disabled.
+type BudgetConfig struct {
+	MaxTokens   int64   `mapstructure:"max_tokens" yaml:"max_tokens,omitempty"`     // Total tokens across all iterations
+	MaxCost     float64 `mapstructure:"max_cost" yaml:"max_cost,omitempty"`         // Total cost in USD
+	OnExceed    string  `mapstructure:"on_exceed" yaml:"on_exceed,omitempty"`       // pause or stop (default: pause)
+	PricingFile string  `mapstructure:"pricing_file" yaml:"pricing_file,omitempty"` // YAML model pricing merged over the built-in table
+}
+
+// ChecklistConfig controls syncing spec checklist items ("- [ ] task") with
+// the task store.
+type ChecklistConfig struct {
+	Import    bool `mapstructure:"import" yaml:"import,omitempty"`         // Create tasks from checklist items instead of planning in iteration #0
+	WriteBack bool `mapstructure:"write_back" yaml:"write_back,omitempty"` // Tick "- [x]" in the spec when the matching task completes
+}
+
+// VerifyConfig sets the commands that must pass before a task can be marked
+// completed.
+type VerifyConfig struct {
+	Commands []string      `mapstructure:"commands" yaml:"commands,omitempty"` // Run for every task
+	Timeout  time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"`   // Per command (default: 10m)
+	Tasks    []VerifyRule  `mapstructure:"tasks" yaml:"tasks,omitempty"`       // Extra commands for matching tasks
+}
+
+// VerifyRule adds verification commands for tasks matching an ID glob or tag.
+// Empty matchers match anything; all non-empty matchers must match.
+type VerifyRule struct {
+	Match    string   `mapstructure:"match" yaml:"match,omitempty"` // Glob on the task ID, e.g. TAS-1*
+	Tag      string   `mapstructure:"tag" yaml:"tag,omitempty"`     // Task tag, e.g. frontend
+	Commands []string `mapstructure:"commands" yaml:"commands"`
+}
+
+// NotificationsConfig sends session events to webhooks, the desktop or email.
+type NotificationsConfig struct {
+	Sinks   []NotificationSink `mapstructure:"sinks" yaml:"sinks,omitempty"`
+	Retries int                `mapstructure:"retries" yaml:"retries,omitempty"` // Retries of a failed delivery (default: 3)
+	Timeout time.Duration      `mapstructure:"timeout" yaml:"timeout,omitempty"` // Per delivery attempt (default: 10s)
+}
+
+// NotificationSink is one destination for notifications. URL, header values
+// and Password may reference environment variables ($VAR or ${VAR}).
+type NotificationSink struct {
+	Type    string            `mapstructure:"type" yaml:"type"`                 // webhook, desktop, or email
+	Events  []string          `mapstructure:"events" yaml:"events,omitempty"`   // Events to send (default: all)
+	Format  string            `mapstructure:"format" yaml:"format,omitempty"`   // webhook: json, slack, or ntfy (default: json)
+	URL     string            `mapstructure:"url" yaml:"url,omitempty"`         // webhook: endpoint (ntfy: topic URL)
+	Headers map[string]string `mapstructure:"headers" yaml:"headers,omitempty"` // webhook: extra HTTP headers
+
+	SMTP     string   `mapstructure:"smtp" yaml:"smtp,omitempty"`         // email: server host:port
+	Username string   `mapstructure:"username" yaml:"username,omitempty"` // email: SMTP auth user (empty = no auth)
+	Password string   `mapstructure:"password" yaml:"password,omitempty"` // email: SMTP auth password
+	From     string   `mapstructure:"from" yaml:"from,omitempty"`
+	To       []string `mapstructure:"to" yaml:"to,omitempty"`
+}
+
+// Notification sink types.
+const (
+	SinkWebhook = "webhook"
+	SinkDesktop = "desktop"
+	SinkEmail   = "email"
+)
+
+// Webhook payload formats.
+const (
+	WebhookJSON  = "json"
+	WebhookSlack = "slack"
+	WebhookNtfy  = "ntfy"
+)
+
+// Notification events.
+const (
+	NotifySessionComplete = "session_complete" // The session was marked complete
+	NotifyStuck           = "stuck"            // A stuck note was added
+	NotifyError           = "error"            // An iteration failed or timed out
+	NotifyBudgetExceeded  = "budget_exceeded"  // The token or cost budget was exceeded
+)
+
+// NotificationEvents lists the events sinks can subscribe to.
+var NotificationEvents = []string{NotifySessionComplete, NotifyStuck, NotifyError, NotifyBudgetExceeded}
+
+// APIConfig serves the HTTP control API of a running build.
+type APIConfig struct {
+	Addr  string `mapstructure:"addr" yaml:"addr,omitempty"`   // Listen address, e.g. 127.0.0.1:7420 (empty = disabled, port 0 = random)
+	Token string `mapstructure:"token" yaml:"token,omitempty"` // Bearer token required on every request; may reference $VAR
+}
+
+// Spec section modes.
+const (
+	SpecSectionsAll      = "all"
+	SpecSectionsRelevant = "relevant"
+)
+
+// Budget actions.
+const (
+	BudgetPause = "pause"
+	BudgetStop  = "stop"
+)
+
+// PromptConfig sets the token budget prompts are compacted to.
+type PromptConfig struct {
+	MaxTokens int           `mapstructure:"max_tokens" yaml:"max_tokens,omitempty"` // Default budget (0 = unlimited)
+	Models    []ModelBudget `mapstructure:"models" yaml:"models,omitempty"`         // Per-model budgets; first match wins
+}
+
+// ModelBudget overrides the prompt budget for models matching a glob.
+type ModelBudget struct {
+	Match     string `mapstructure:"match" yaml:"match"`           // Glob on the full or bare model ID, e.g. anthropic/claude-haiku-*
+	MaxTokens int    `mapstructure:"max_tokens" yaml:"max_tokens"` // Budget for matching models (0 = unlimited)
+}
+
+// TaskBranchesConfig controls per-task git branches and worktrees.
+type TaskBranchesConfig struct {
+	Mode       string `mapstructure:"mode" yaml:"mode,omitempty"`               // off, branch, or worktree (default: off)
+	Prefix     string `mapstructure:"prefix" yaml:"prefix,omitempty"`           // Branch name prefix (default: iteratr/)
+	OnComplete string `mapstructure:"on_complete" yaml:"on_complete,omitempty"` // merge or leave (default: leave)
+	Base       string `mapstructure:"base" yaml:"base,omitempty"`               // Branch to fork from and merge into (default: current branch)
+}
+
+// Task branch modes.
+const (
+	TaskBranchesOff      = "off"
+	TaskBranchesBranch   = "branch"
+	TaskBranchesWorktree = "worktree"
+)
+
+// BackendConfig describes how to launch an ACP-speaking agent process.
+type BackendConfig struct {
+	Command string   `mapstructure:"command" yaml:"command"`
+	Args    []string `mapstructure:"args" yaml:"args,omitempty"`
+	Env     []string `mapstructure:"env" yaml:"env,omitempty"`           // KEY=VALUE entries added to the process environment
+	WorkDir string   `mapstructure:"work_dir" yaml:"work_dir,omitempty"` // Process working directory (default: project dir)
 }
 
 // Load loads configuration with full precedence:
@@ -41,7 +239,31 @@ func Load() (*Config, error) {
 	v.SetDefault("headless", false)
 	v.SetDefault("template", "")
 	v.SetDefault("spec_dir", "specs")
+	v.SetDefault("spec_sections", SpecSectionsAll)
 	v.SetDefault("commit_data_dir", false)
+	v.SetDefault("iteration_timeout", 0)
+	v.SetDefault("session_timeout", 0)
+	v.SetDefault("stall_timeout", 0)
+	v.SetDefault("timeout_retries", 0)
+	v.SetDefault("parallelism", 1)
+	v.SetDefault("agent.backend", "")
+	v.SetDefault("agent.max_restarts", 3)
+	v.SetDefault("commit.mode", CommitModeIteratr)
+	v.SetDefault("task_branches.mode", TaskBranchesOff)
+	v.SetDefault("task_branches.prefix", "iteratr/")
+	v.SetDefault("task_branches.on_complete", "leave")
+	v.SetDefault("budget.max_tokens", 0)
+	v.SetDefault("budget.max_cost", 0)
+	v.SetDefault("budget.on_exceed", BudgetPause)
+	v.SetDefault("budget.pricing_file", "")
+	v.SetDefault("prompt.max_tokens", 0)
+	v.SetDefault("checklist.import", false)
+	v.SetDefault("checklist.write_back", false)
+	v.SetDefault("verify.timeout", 10*time.Minute)
+	v.SetDefault("notifications.retries", 3)
+	v.SetDefault("notifications.timeout", 10*time.Second)
+	v.SetDefault("api.addr", "")
+	v.SetDefault("api.token", "")
 
 	// Setup ENV binding with ITERATR_ prefix
 	v.SetEnvPrefix("ITERATR")
@@ -77,9 +299,69 @@ func Load() (*Config, error) {
 	if err := v.BindEnv("spec_dir", "ITERATR_SPEC_DIR"); err != nil {
 		return nil, fmt.Errorf("binding spec_dir env: %w", err)
 	}
+	if err := v.BindEnv("spec_sections", "ITERATR_SPEC_SECTIONS"); err != nil {
+		return nil, fmt.Errorf("binding spec_sections env: %w", err)
+	}
 	if err := v.BindEnv("commit_data_dir", "ITERATR_COMMIT_DATA_DIR"); err != nil {
 		return nil, fmt.Errorf("binding commit_data_dir env: %w", err)
 	}
+	if err := v.BindEnv("iteration_timeout", "ITERATR_ITERATION_TIMEOUT"); err != nil {
+		return nil, fmt.Errorf("binding iteration_timeout env: %w", err)
+	}
+	if err := v.BindEnv("session_timeout", "ITERATR_SESSION_TIMEOUT"); err != nil {
+		return nil, fmt.Errorf("binding session_timeout env: %w", err)
+	}
+	if err := v.BindEnv("stall_timeout", "ITERATR_STALL_TIMEOUT"); err != nil {
+		return nil, fmt.Errorf("binding stall_timeout env: %w", err)
+	}
+	if err := v.BindEnv("parallelism", "ITERATR_PARALLELISM"); err != nil {
+		return nil, fmt.Errorf("binding parallelism env: %w", err)
+	}
+	if err := v.BindEnv("agent.backend", "ITERATR_AGENT_BACKEND"); err != nil {
+		return nil, fmt.Errorf("binding agent.backend env: %w", err)
+	}
+	if err := v.BindEnv("agent.max_restarts", "ITERATR_AGENT_MAX_RESTARTS"); err != nil {
+		return nil, fmt.Errorf("binding agent.max_restarts env: %w", err)
+	}
+	if err := v.BindEnv("commit.mode", "ITERATR_COMMIT_MODE"); err != nil {
+		return nil, fmt.Errorf("binding commit.mode env: %w", err)
+	}
+	if err := v.BindEnv("task_branches.mode", "ITERATR_TASK_BRANCHES_MODE"); err != nil {
+		return nil, fmt.Errorf("binding task_branches.mode env: %w", err)
+	}
+	if err := v.BindEnv("budget.max_tokens", "ITERATR_BUDGET_MAX_TOKENS"); err != nil {
+		return nil, fmt.Errorf("binding budget.max_tokens env: %w", err)
+	}
+	if err := v.BindEnv("budget.max_cost", "ITERATR_BUDGET_MAX_COST"); err != nil {
+		return nil, fmt.Errorf("binding budget.max_cost env: %w", err)
+	}
+	if err := v.BindEnv("budget.on_exceed", "ITERATR_BUDGET_ON_EXCEED"); err != nil {
+		return nil, fmt.Errorf("binding budget.on_exceed env: %w", err)
+	}
+	if err := v.BindEnv("prompt.max_tokens", "ITERATR_PROMPT_MAX_TOKENS"); err != nil {
+		return nil, fmt.Errorf("binding prompt.max_tokens env: %w", err)
+	}
+	if err := v.BindEnv("checklist.import", "ITERATR_CHECKLIST_IMPORT"); err != nil {
+		return nil, fmt.Errorf("binding checklist.import env: %w", err)
+	}
+	if err := v.BindEnv("checklist.write_back", "ITERATR_CHECKLIST_WRITE_BACK"); err != nil {
+		return nil, fmt.Errorf("binding checklist.write_back env: %w", err)
+	}
+	if err := v.BindEnv("verify.timeout", "ITERATR_VERIFY_TIMEOUT"); err != nil {
+		return nil, fmt.Errorf("binding verify.timeout env: %w", err)
+	}
+	if err := v.BindEnv("notifications.retries", "ITERATR_NOTIFICATIONS_RETRIES"); err != nil {
+		return nil, fmt.Errorf("binding notifications.retries env: %w", err)
+	}
+	if err := v.BindEnv("notifications.timeout", "ITERATR_NOTIFICATIONS_TIMEOUT"); err != nil {
+		return nil, fmt.Errorf("binding notifications.timeout env: %w", err)
+	}
+	if err := v.BindEnv("api.addr", "ITERATR_API_ADDR"); err != nil {
+		return nil, fmt.Errorf("binding api.addr env: %w", err)
+	}
+	if err := v.BindEnv("api.token", "ITERATR_API_TOKEN"); err != nil {
+		return nil, fmt.Errorf("binding api.token env: %w", err)
+	}
 
 	// Load global config first (if exists)
 	globalPath := GlobalPath()
@@ -113,9 +395,290 @@ func (c *Config) Validate() error {
 	if c.Model == "" {
 		return fmt.Errorf("model is required")
 	}
+	if err := c.Agent.Validate(); err != nil {
+		return err
+	}
+	if err := c.ValidateSpecSections(); err != nil {
+		return err
+	}
+	if err := c.Commit.Validate(); err != nil {
+		return err
+	}
+	if err := c.Budget.Validate(); err != nil {
+		return err
+	}
+	if err := c.Prompt.Validate(); err != nil {
+		return err
+	}
+	if err := c.ValidateTimeouts(); err != nil {
+		return err
+	}
+	if err := c.ValidateParallelism(); err != nil {
+		return err
+	}
+	if err := c.Verify.Validate(); err != nil {
+		return err
+	}
+	if err := c.Notifications.Validate(); err != nil {
+		return err
+	}
+	if err := c.API.Validate(); err != nil {
+		return err
+	}
+	return c.TaskBranches.Validate()
+}
+
+// Validate checks custom backends and the restart limit.
+func (a AgentConfig) Validate() error {
+	for name, b := range a.Backends {
+		if b.Command == "" {
+			return fmt.Errorf("agent backend %q: command is required", name)
+		}
+	}
+	if a.MaxRestarts < 0 {
+		return fmt.Errorf("agent.max_restarts: must not be negative")
+	}
 	return nil
 }
 
+// ValidateSpecSections checks the spec section mode.
+func (c *Config) ValidateSpecSections() error {
+	switch c.SpecSections {
+	case "", SpecSectionsAll, SpecSectionsRelevant:
+		return nil
+	}
+	return fmt.Errorf("spec_sections: invalid value %q (must be all or relevant)", c.SpecSections)
+}
+
+// ValidateTimeouts checks that timeouts and retries are not negative.
+func (c *Config) ValidateTimeouts() error {
+	for _, t := range []struct {
+		key string
+		d   time.Duration
+	}{
+		{"iteration_timeout", c.IterationTimeout},
+		{"session_timeout", c.SessionTimeout},
+		{"stall_timeout", c.StallTimeout},
+	} {
+		if t.d < 0 {
+			return fmt.Errorf("%s: must not be negative", t.key)
+		}
+	}
+	if c.TimeoutRetries < 0 {
+		return fmt.Errorf("timeout_retries: must not be negative")
+	}
+	return nil
+}
+
+// ValidateParallelism checks the number of parallel workers.
+// 0 and 1 both run tasks sequentially.
+func (c *Config) ValidateParallelism() error {
+	if c.Parallelism < 0 {
+		return fmt.Errorf("parallelism: must not be negative")
+	}
+	return nil
+}
+
+// Validate checks the budget limits and action.
+func (b BudgetConfig) Validate() error {
+	if b.MaxTokens < 0 {
+		return fmt.Errorf("budget.max_tokens: must not be negative")
+	}
+	if b.MaxCost < 0 {
+		return fmt.Errorf("budget.max_cost: must not be negative")
+	}
+	switch b.OnExceed {
+	case "", BudgetPause, BudgetStop:
+		return nil
+	}
+	return fmt.Errorf("budget.on_exceed: invalid value %q (must be pause or stop)", b.OnExceed)
+}
+
+// Enabled reports whether any budget limit is set.
+func (b BudgetConfig) Enabled() bool {
+	return b.MaxTokens > 0 || b.MaxCost > 0
+}
+
+// Validate checks that budgets are not negative and model globs are valid.
+func (p PromptConfig) Validate() error {
+	if p.MaxTokens < 0 {
+		return fmt.Errorf("prompt.max_tokens: must not be negative")
+	}
+	for i, m := range p.Models {
+		if m.Match == "" {
+			return fmt.Errorf("prompt.models[%d]: match is required", i)
+		}
+		if _, err := path.Match(m.Match, ""); err != nil {
+			return fmt.Errorf("prompt.models[%d]: invalid match %q: %w", i, m.Match, err)
+		}
+		if m.MaxTokens < 0 {
+			return fmt.Errorf("prompt.models[%d]: max_tokens must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// BudgetFor returns the prompt token budget for model. The first entry in
+// models whose glob matches the full model ID (e.g. anthropic/claude-sonnet-4-5)
+// or the bare ID after the provider prefix wins; otherwise max_tokens applies.
+func (p PromptConfig) BudgetFor(model string) int {
+	_, bare, _ := strings.Cut(model, "/")
+	for _, m := range p.Models {
+		if ok, _ := path.Match(m.Match, model); ok {
+			return m.MaxTokens
+		}
+		if ok, _ := path.Match(m.Match, bare); ok && bare != "" {
+			return m.MaxTokens
+		}
+	}
+	return p.MaxTokens
+}
+
+// Validate checks the verification timeout and rules.
+func (v VerifyConfig) Validate() error {
+	if v.Timeout < 0 {
+		return fmt.Errorf("verify.timeout: must not be negative")
+	}
+	for i, r := range v.Tasks {
+		if len(r.Commands) == 0 {
+			return fmt.Errorf("verify.tasks[%d]: commands are required", i)
+		}
+		if _, err := path.Match(r.Match, ""); err != nil {
+			return fmt.Errorf("verify.tasks[%d]: invalid match %q: %w", i, r.Match, err)
+		}
+	}
+	return nil
+}
+
+// Configured reports whether any verification commands are set.
+func (v VerifyConfig) Configured() bool {
+	return len(v.Commands) > 0 || len(v.Tasks) > 0
+}
+
+// CommandsFor returns the verification commands for a task: the global
+// commands followed by those of every rule matching the task's ID and tags.
+func (v VerifyConfig) CommandsFor(id string, tags []string) []string {
+	commands := slices.Clone(v.Commands)
+	for _, r := range v.Tasks {
+		if r.Match != "" {
+			if ok, _ := path.Match(r.Match, id); !ok {
+				continue
+			}
+		}
+		if r.Tag != "" && !slices.Contains(tags, r.Tag) {
+			continue
+		}
+		commands = append(commands, r.Commands...)
+	}
+	return commands
+}
+
+// Validate checks the retry settings and every sink.
+func (n NotificationsConfig) Validate() error {
+	if n.Retries < 0 {
+		return fmt.Errorf("notifications.retries: must not be negative")
+	}
+	if n.Timeout < 0 {
+		return fmt.Errorf("notifications.timeout: must not be negative")
+	}
+	for i, s := range n.Sinks {
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("notifications.sinks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the sink has the settings its type needs.
+func (s NotificationSink) Validate() error {
+	for _, e := range s.Events {
+		if !slices.Contains(NotificationEvents, e) {
+			return fmt.Errorf("events: invalid event %q (must be one of %s)", e, strings.Join(NotificationEvents, ", "))
+		}
+	}
+	switch s.Type {
+	case SinkWebhook:
+		if s.URL == "" {
+			return fmt.Errorf("url is required")
+		}
+		switch s.Format {
+		case "", WebhookJSON, WebhookSlack, WebhookNtfy:
+		default:
+			return fmt.Errorf("format: invalid value %q (must be json, slack, or ntfy)", s.Format)
+		}
+	case SinkDesktop:
+	case SinkEmail:
+		if _, _, err := net.SplitHostPort(s.SMTP); err != nil {
+			return fmt.Errorf("smtp: must be host:port: %w", err)
+		}
+		if s.From == "" || len(s.To) == 0 {
+			return fmt.Errorf("from and to are required")
+		}
+	default:
+		return fmt.Errorf("type: invalid value %q (must be webhook, desktop, or email)", s.Type)
+	}
+	return nil
+}
+
+// Wants reports whether the sink is subscribed to event.
+func (s NotificationSink) Wants(event string) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, event)
+}
+
+// Validate checks the listen address. Listening beyond loopback requires a
+// token, since the API can steer the agent.
+func (a APIConfig) Validate() error {
+	if a.Addr == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(a.Addr)
+	if err != nil {
+		return fmt.Errorf("api.addr: must be host:port: %w", err)
+	}
+	if a.Token == "" && !isLoopback(host) {
+		return fmt.Errorf("api.token: required when api.addr is not a loopback address")
+	}
+	return nil
+}
+
+// isLoopback reports whether host is localhost or a loopback IP.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Validate checks the commit mode.
+func (c CommitConfig) Validate() error {
+	switch c.Mode {
+	case "", CommitModeIteratr, CommitModeAgent:
+		return nil
+	}
+	return fmt.Errorf("commit.mode: invalid value %q (must be iteratr or agent)", c.Mode)
+}
+
+// Validate checks the task branch mode and completion action.
+func (t TaskBranchesConfig) Validate() error {
+	switch t.Mode {
+	case "", TaskBranchesOff, TaskBranchesBranch, TaskBranchesWorktree:
+	default:
+		return fmt.Errorf("task_branches.mode: invalid value %q (must be off, branch, or worktree)", t.Mode)
+	}
+	switch t.OnComplete {
+	case "", "merge", "leave":
+	default:
+		return fmt.Errorf("task_branches.on_complete: invalid value %q (must be merge or leave)", t.OnComplete)
+	}
+	return nil
+}
+
+// Enabled reports whether per-task branches are turned on.
+func (t TaskBranchesConfig) Enabled() bool {
+	return t.Mode == TaskBranchesBranch || t.Mode == TaskBranchesWorktree
+}
+
 // Exists returns true if any config file exists (global or project).
 func Exists() bool {
 	return fileExists(GlobalPath()) || fileExists(ProjectPath())
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestGlobalPath(t *testing.T) {
@@ -425,6 +427,236 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid task branches",
+			config: &Config{
+				Model:        "anthropic/claude-sonnet-4-5",
+				TaskBranches: TaskBranchesConfig{Mode: TaskBranchesWorktree, OnComplete: "merge"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid task branch mode",
+			config: &Config{
+				Model:        "anthropic/claude-sonnet-4-5",
+				TaskBranches: TaskBranchesConfig{Mode: "fork"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid task branch on_complete",
+			config: &Config{
+				Model:        "anthropic/claude-sonnet-4-5",
+				TaskBranches: TaskBranchesConfig{Mode: TaskBranchesBranch, OnComplete: "rebase"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid commit mode",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Commit: CommitConfig{Mode: CommitModeAgent},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid commit mode",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Commit: CommitConfig{Mode: "manual"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid budget",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Budget: BudgetConfig{MaxTokens: 1_000_000, MaxCost: 5, OnExceed: BudgetStop},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative budget",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Budget: BudgetConfig{MaxCost: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid timeouts",
+			config: &Config{
+				Model:            "anthropic/claude-sonnet-4-5",
+				IterationTimeout: 30 * time.Minute,
+				StallTimeout:     5 * time.Minute,
+				TimeoutRetries:   1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative stall timeout",
+			config: &Config{
+				Model:        "anthropic/claude-sonnet-4-5",
+				StallTimeout: -time.Second,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative parallelism",
+			config: &Config{
+				Model:       "anthropic/claude-sonnet-4-5",
+				Parallelism: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid prompt budgets",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Prompt: PromptConfig{MaxTokens: 40000, Models: []ModelBudget{{Match: "anthropic/*", MaxTokens: 80000}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid prompt model glob",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Prompt: PromptConfig{Models: []ModelBudget{{Match: "claude-[", MaxTokens: 1000}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative agent max_restarts",
+			config: &Config{
+				Model: "anthropic/claude-sonnet-4-5",
+				Agent: AgentConfig{MaxRestarts: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid budget on_exceed",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Budget: BudgetConfig{MaxTokens: 100, OnExceed: "warn"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "verify rule without commands",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Verify: VerifyConfig{Tasks: []VerifyRule{{Tag: "frontend"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid verify match",
+			config: &Config{
+				Model:  "anthropic/claude-sonnet-4-5",
+				Verify: VerifyConfig{Tasks: []VerifyRule{{Match: "[", Commands: []string{"make"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid notification sinks",
+			config: &Config{
+				Model: "anthropic/claude-sonnet-4-5",
+				Notifications: NotificationsConfig{Sinks: []NotificationSink{
+					{Type: SinkWebhook, URL: "https://ntfy.sh/builds", Format: WebhookNtfy, Events: []string{NotifyStuck}},
+					{Type: SinkDesktop},
+					{Type: SinkEmail, SMTP: "smtp.example.com:587", From: "ci@example.com", To: []string{"dev@example.com"}},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "webhook without url",
+			config: &Config{
+				Model:         "anthropic/claude-sonnet-4-5",
+				Notifications: NotificationsConfig{Sinks: []NotificationSink{{Type: SinkWebhook}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid webhook format",
+			config: &Config{
+				Model:         "anthropic/claude-sonnet-4-5",
+				Notifications: NotificationsConfig{Sinks: []NotificationSink{{Type: SinkWebhook, URL: "http://x", Format: "teams"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "email without port",
+			config: &Config{
+				Model:         "anthropic/claude-sonnet-4-5",
+				Notifications: NotificationsConfig{Sinks: []NotificationSink{{Type: SinkEmail, SMTP: "smtp.example.com", From: "a@b", To: []string{"c@d"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid notification event",
+			config: &Config{
+				Model:         "anthropic/claude-sonnet-4-5",
+				Notifications: NotificationsConfig{Sinks: []NotificationSink{{Type: SinkDesktop, Events: []string{"iteration"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative notification retries",
+			config: &Config{
+				Model:         "anthropic/claude-sonnet-4-5",
+				Notifications: NotificationsConfig{Retries: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "api on loopback without token",
+			config: &Config{
+				Model: "anthropic/claude-sonnet-4-5",
+				API:   APIConfig{Addr: "127.0.0.1:7420"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "api on all interfaces without token",
+			config: &Config{
+				Model: "anthropic/claude-sonnet-4-5",
+				API:   APIConfig{Addr: ":7420"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "api on all interfaces with token",
+			config: &Config{
+				Model: "anthropic/claude-sonnet-4-5",
+				API:   APIConfig{Addr: "0.0.0.0:7420", Token: "$ITERATR_TOKEN"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "api addr without port",
+			config: &Config{
+				Model: "anthropic/claude-sonnet-4-5",
+				API:   APIConfig{Addr: "localhost"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relevant spec sections",
+			config: &Config{
+				Model:        "anthropic/claude-sonnet-4-5",
+				SpecSections: SpecSectionsRelevant,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid spec sections",
+			config: &Config{
+				Model:        "anthropic/claude-sonnet-4-5",
+				SpecSections: "some",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -450,3 +682,466 @@ func containsAt(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoad_WithAgentBackends(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_AGENT_BACKEND", "")
+	t.Setenv("ITERATR_AGENT_MAX_RESTARTS", "")
+
+	content := `model: test-model
+agent:
+  backend: fake-acp
+  backends:
+    fake-acp:
+      command: ./bin/fake-acp
+      args: ["--fixture", "ci.jsonl"]
+      env: ["FAKE_ACP_DELAY=0"]
+      work_dir: testdata
+`
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Agent.Backend != "fake-acp" {
+		t.Errorf("Agent.Backend = %q, want fake-acp", cfg.Agent.Backend)
+	}
+	b, ok := cfg.Agent.Backends["fake-acp"]
+	if !ok {
+		t.Fatalf("Agent.Backends missing fake-acp: %+v", cfg.Agent.Backends)
+	}
+	if b.Command != "./bin/fake-acp" {
+		t.Errorf("Command = %q, want ./bin/fake-acp", b.Command)
+	}
+	if len(b.Args) != 2 || b.Args[0] != "--fixture" || b.Args[1] != "ci.jsonl" {
+		t.Errorf("Args = %v, want [--fixture ci.jsonl]", b.Args)
+	}
+	if len(b.Env) != 1 || b.Env[0] != "FAKE_ACP_DELAY=0" {
+		t.Errorf("Env = %v, want [FAKE_ACP_DELAY=0]", b.Env)
+	}
+	if b.WorkDir != "testdata" {
+		t.Errorf("WorkDir = %q, want testdata", b.WorkDir)
+	}
+	if cfg.Agent.MaxRestarts != 3 {
+		t.Errorf("Agent.MaxRestarts = %d, want default 3", cfg.Agent.MaxRestarts)
+	}
+
+	// ENV vars override the selected backend and restart limit
+	t.Setenv("ITERATR_AGENT_BACKEND", "opencode")
+	t.Setenv("ITERATR_AGENT_MAX_RESTARTS", "0")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Agent.Backend != "opencode" {
+		t.Errorf("Agent.Backend with ENV override = %q, want opencode", cfg.Agent.Backend)
+	}
+	if cfg.Agent.MaxRestarts != 0 {
+		t.Errorf("Agent.MaxRestarts with ENV override = %d, want 0", cfg.Agent.MaxRestarts)
+	}
+}
+
+func TestLoad_TaskBranchesDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_TASK_BRANCHES_MODE", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.TaskBranches.Mode != TaskBranchesOff || cfg.TaskBranches.Enabled() {
+		t.Errorf("TaskBranches.Mode = %q, want off", cfg.TaskBranches.Mode)
+	}
+	if cfg.TaskBranches.Prefix != "iteratr/" {
+		t.Errorf("TaskBranches.Prefix = %q, want iteratr/", cfg.TaskBranches.Prefix)
+	}
+	if cfg.TaskBranches.OnComplete != "leave" {
+		t.Errorf("TaskBranches.OnComplete = %q, want leave", cfg.TaskBranches.OnComplete)
+	}
+
+	t.Setenv("ITERATR_TASK_BRANCHES_MODE", "worktree")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.TaskBranches.Mode != TaskBranchesWorktree {
+		t.Errorf("TaskBranches.Mode with ENV override = %q, want worktree", cfg.TaskBranches.Mode)
+	}
+}
+
+func TestLoad_CommitDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_COMMIT_MODE", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Commit.Mode != CommitModeIteratr {
+		t.Errorf("Commit.Mode = %q, want iteratr", cfg.Commit.Mode)
+	}
+
+	t.Setenv("ITERATR_COMMIT_MODE", "agent")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Commit.Mode != CommitModeAgent {
+		t.Errorf("Commit.Mode with ENV override = %q, want agent", cfg.Commit.Mode)
+	}
+}
+
+func TestLoad_BudgetDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_BUDGET_MAX_TOKENS", "")
+	t.Setenv("ITERATR_BUDGET_MAX_COST", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Budget.Enabled() || cfg.Budget.OnExceed != BudgetPause {
+		t.Errorf("Budget = %+v, want disabled with on_exceed pause", cfg.Budget)
+	}
+
+	t.Setenv("ITERATR_BUDGET_MAX_TOKENS", "500000")
+	t.Setenv("ITERATR_BUDGET_MAX_COST", "2.5")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Budget.MaxTokens != 500000 || cfg.Budget.MaxCost != 2.5 {
+		t.Errorf("Budget with ENV override = %+v, want 500000 tokens and $2.50", cfg.Budget)
+	}
+}
+
+func TestLoad_Timeouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_ITERATION_TIMEOUT", "")
+	t.Setenv("ITERATR_STALL_TIMEOUT", "")
+
+	content := "model: test/model\niteration_timeout: 45m\nsession_timeout: 8h\ntimeout_retries: 2\n"
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.IterationTimeout != 45*time.Minute || cfg.SessionTimeout != 8*time.Hour || cfg.StallTimeout != 0 || cfg.TimeoutRetries != 2 {
+		t.Errorf("timeouts = %v/%v/%v retries %d", cfg.IterationTimeout, cfg.SessionTimeout, cfg.StallTimeout, cfg.TimeoutRetries)
+	}
+
+	t.Setenv("ITERATR_STALL_TIMEOUT", "90s")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.StallTimeout != 90*time.Second {
+		t.Errorf("StallTimeout with ENV override = %v, want 1m30s", cfg.StallTimeout)
+	}
+}
+
+func TestLoad_TemplateVars(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	content := "model: test/model\ntemplate_vars:\n  team: platform\n  test_cmd: go test ./...\n"
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.TemplateVars["team"] != "platform" || cfg.TemplateVars["test_cmd"] != "go test ./..." {
+		t.Errorf("TemplateVars = %v", cfg.TemplateVars)
+	}
+}
+
+func TestLoad_Specs(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	content := "model: test/model\nspecs:\n  - specs/auth.md\n  - specs/api/\n"
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Specs) != 2 || cfg.Specs[0] != "specs/auth.md" || cfg.Specs[1] != "specs/api/" {
+		t.Errorf("Specs = %v", cfg.Specs)
+	}
+	if cfg.SpecSections != SpecSectionsAll {
+		t.Errorf("SpecSections = %q, want %q", cfg.SpecSections, SpecSectionsAll)
+	}
+
+	t.Setenv("ITERATR_SPEC_SECTIONS", "relevant")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.SpecSections != SpecSectionsRelevant {
+		t.Errorf("SpecSections = %q, want %q from env", cfg.SpecSections, SpecSectionsRelevant)
+	}
+}
+
+func TestPromptConfig_BudgetFor(t *testing.T) {
+	p := PromptConfig{
+		MaxTokens: 40000,
+		Models: []ModelBudget{
+			{Match: "claude-haiku-*", MaxTokens: 20000},
+			{Match: "anthropic/*", MaxTokens: 80000},
+			{Match: "local/*", MaxTokens: 0},
+		},
+	}
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"anthropic/claude-haiku-4-5", 20000},
+		{"anthropic/claude-sonnet-4-5", 80000},
+		{"local/llama", 0},
+		{"openai/gpt-5", 40000},
+		{"gpt-5", 40000},
+	}
+	for _, tt := range tests {
+		if got := p.BudgetFor(tt.model); got != tt.want {
+			t.Errorf("BudgetFor(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestLoad_PromptBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_PROMPT_MAX_TOKENS", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Prompt.MaxTokens != 0 || cfg.Prompt.BudgetFor("test/model") != 0 {
+		t.Errorf("Prompt.MaxTokens = %d, want default 0 (unlimited)", cfg.Prompt.MaxTokens)
+	}
+
+	content := "model: test/model\nprompt:\n  models:\n    - match: \"openai/gpt-4.1\"\n      max_tokens: 100000\n"
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ITERATR_PROMPT_MAX_TOKENS", "12000")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Prompt.MaxTokens != 12000 || cfg.Prompt.BudgetFor("openai/gpt-4.1") != 100000 {
+		t.Errorf("Prompt = %+v", cfg.Prompt)
+	}
+}
+
+func TestLoad_Checklist(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_CHECKLIST_WRITE_BACK", "")
+
+	content := "model: test/model\nchecklist:\n  import: true\n"
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Checklist.Import || cfg.Checklist.WriteBack {
+		t.Errorf("Checklist = %+v, want import only", cfg.Checklist)
+	}
+
+	t.Setenv("ITERATR_CHECKLIST_WRITE_BACK", "true")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Checklist.WriteBack {
+		t.Errorf("Checklist.WriteBack = false, want true from env")
+	}
+}
+
+func TestVerifyConfig_CommandsFor(t *testing.T) {
+	v := VerifyConfig{
+		Commands: []string{"go test ./..."},
+		Tasks: []VerifyRule{
+			{Tag: "frontend", Commands: []string{"npm test"}},
+			{Match: "TAS-1*", Commands: []string{"make lint"}},
+			{Match: "TAS-2", Tag: "docs", Commands: []string{"make docs"}},
+		},
+	}
+	tests := []struct {
+		id   string
+		tags []string
+		want []string
+	}{
+		{"TAS-5", nil, []string{"go test ./..."}},
+		{"TAS-5", []string{"frontend"}, []string{"go test ./...", "npm test"}},
+		{"TAS-12", []string{"frontend"}, []string{"go test ./...", "npm test", "make lint"}},
+		{"TAS-2", nil, []string{"go test ./..."}},
+		{"TAS-2", []string{"docs"}, []string{"go test ./...", "make docs"}},
+	}
+	for _, tt := range tests {
+		if got := v.CommandsFor(tt.id, tt.tags); !slices.Equal(got, tt.want) {
+			t.Errorf("CommandsFor(%q, %v) = %v, want %v", tt.id, tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestLoad_Verify(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_VERIFY_TIMEOUT", "")
+
+	content := "model: test/model\nverify:\n  commands: [\"go test ./...\"]\n  tasks:\n    - tag: frontend\n      commands: [\"npm test\"]\n"
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Verify.Timeout != 10*time.Minute {
+		t.Errorf("Verify.Timeout = %v, want default 10m", cfg.Verify.Timeout)
+	}
+	if got := cfg.Verify.CommandsFor("TAS-1", []string{"frontend"}); !slices.Equal(got, []string{"go test ./...", "npm test"}) {
+		t.Errorf("Verify = %+v", cfg.Verify)
+	}
+
+	t.Setenv("ITERATR_VERIFY_TIMEOUT", "2m")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Verify.Timeout != 2*time.Minute {
+		t.Errorf("Verify.Timeout = %v, want 2m from env", cfg.Verify.Timeout)
+	}
+}
+
+func TestLoad_Notifications(t *testing.T) {
+	tmpDir := t.TempDir()
+	origWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origWd) }()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("ITERATR_NOTIFICATIONS_RETRIES", "")
+	t.Setenv("ITERATR_NOTIFICATIONS_TIMEOUT", "")
+
+	content := `model: test/model
+notifications:
+  sinks:
+    - type: webhook
+      format: slack
+      url: https://hooks.slack.com/services/x
+      events: [session_complete, error]
+      headers:
+        X-Token: secret
+`
+	if err := os.WriteFile("iteratr.yml", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	n := cfg.Notifications
+	if n.Retries != 3 || n.Timeout != 10*time.Second {
+		t.Errorf("Retries, Timeout = %d, %v; want defaults 3, 10s", n.Retries, n.Timeout)
+	}
+	if len(n.Sinks) != 1 || n.Sinks[0].Format != WebhookSlack || n.Sinks[0].Headers["x-token"] != "secret" {
+		t.Fatalf("Sinks = %+v", n.Sinks)
+	}
+	if !n.Sinks[0].Wants(NotifyError) || n.Sinks[0].Wants(NotifyStuck) {
+		t.Errorf("Wants() does not follow events %v", n.Sinks[0].Events)
+	}
+
+	t.Setenv("ITERATR_NOTIFICATIONS_RETRIES", "0")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Notifications.Retries != 0 {
+		t.Errorf("Retries = %d, want 0 from env", cfg.Notifications.Retries)
+	}
+}
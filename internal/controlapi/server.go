@@ -0,0 +1,311 @@
+// Package controlapi serves a local HTTP/JSON API that steers a running
+// session: pause, resume, stop, send a message, add a task, skip the current
+// task, and query its state. Headless CI runs and dashboards use it to
+// control sessions started with --headless.
+package controlapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/usage"
+)
+
+// maxBodySize limits request bodies.
+const maxBodySize = 1 << 20
+
+// ErrConflict is wrapped by controller errors caused by the session's current
+// state (no task in progress, unknown task, message queue full). The API
+// answers them with 409 Conflict.
+var ErrConflict = errors.New("conflict")
+
+// Controller is the part of the orchestrator the API steers. Defined here so
+// the orchestrator can implement it without an import cycle.
+type Controller interface {
+	Pause()                                                      // Pause after the current iteration
+	Resume()                                                     // Resume a paused session
+	Stop(reason string, now bool)                                // Stop after the current iteration, or right away
+	SendMessage(text string) error                               // Queue a message for the agent
+	AddTask(params session.TaskAddParams) (*session.Task, error) // Add a task to the session
+	SkipTask(id, reason string) (*session.Task, error)           // Block a task and cancel the agent run working on it
+	Status() (*Status, error)                                    // Current state of the session
+}
+
+// Status is the state of a running session returned by every endpoint that
+// changes it.
+type Status struct {
+	Session   string                   `json:"session"`
+	Iteration int                      `json:"iteration"`        // Current (or last) iteration
+	Paused    bool                     `json:"paused"`           // Paused, or pausing after the current iteration
+	Stopping  bool                     `json:"stopping"`         // A stop was requested
+	Complete  bool                     `json:"complete"`         // The session was marked complete
+	Current   []*session.Task          `json:"current"`          // Tasks in progress
+	Counts    map[string]int           `json:"counts"`           // Tasks by status
+	Tasks     map[string]*session.Task `json:"tasks"`            // Task ID -> Task
+	Usage     usage.Usage              `json:"usage"`            // Tokens and cost so far
+	Queued    int                      `json:"queued,omitempty"` // Messages waiting for the agent
+}
+
+// Server is the control API HTTP server.
+type Server struct {
+	ctrl      Controller
+	addr      string
+	token     string
+	stdServer *http.Server
+	url       string
+	mu        sync.Mutex
+}
+
+// New creates a control API server listening on addr. When token is set
+// (after expanding $VAR references) every request must carry it as a
+// bearer token. The server is not started until Start() is called.
+func New(ctrl Controller, addr, token string) *Server {
+	return &Server{
+		ctrl:  ctrl,
+		addr:  addr,
+		token: os.ExpandEnv(token),
+	}
+}
+
+// Start listens on the configured address and serves the API in the
+// background. Returns the base URL of the API.
+func (s *Server) Start(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stdServer != nil {
+		return "", fmt.Errorf("server already started")
+	}
+
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", s.addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	s.url = "http://" + listener.Addr().String()
+
+	s.stdServer = &http.Server{Handler: s.Handler()}
+	stdServer := s.stdServer
+	go func() {
+		if err := stdServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Error("Control API server error: %v", err)
+		}
+	}()
+
+	logger.Debug("Control API ready on %s", s.url)
+	return s.url, nil
+}
+
+// Stop shuts the server down.
+func (s *Server) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stdServer == nil {
+		return nil // Already stopped
+	}
+	if err := s.stdServer.Shutdown(context.Background()); err != nil {
+		return fmt.Errorf("failed to stop control API: %w", err)
+	}
+	s.stdServer = nil
+	return nil
+}
+
+// URL returns the base URL of the running API.
+func (s *Server) URL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.url
+}
+
+// Handler returns the API routes:
+//
+//	GET  /state     session state
+//	POST /pause     pause after the current iteration
+//	POST /resume    resume a paused session
+//	POST /stop      {"reason": "...", "now": false}
+//	POST /messages  {"text": "..."}
+//	POST /tasks     {"content": "...", "priority": 1, "description": "...", "criteria": [...], "tags": [...]}
+//	POST /skip      {"task": "TAS-3", "reason": "..."} (task defaults to the one in progress)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /state", s.handleState)
+	mux.HandleFunc("POST /pause", s.handlePause)
+	mux.HandleFunc("POST /resume", s.handleResume)
+	mux.HandleFunc("POST /stop", s.handleStop)
+	mux.HandleFunc("POST /messages", s.handleMessage)
+	mux.HandleFunc("POST /tasks", s.handleAddTask)
+	mux.HandleFunc("POST /skip", s.handleSkip)
+	return s.authorize(mux)
+}
+
+// authorize rejects requests without the configured bearer token.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	s.writeStatus(w, http.StatusOK)
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.ctrl.Pause()
+	s.writeStatus(w, http.StatusOK)
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.ctrl.Resume()
+	s.writeStatus(w, http.StatusOK)
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+		Now    bool   `json:"now"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	// Read the status first: stopping now cancels the session
+	status, err := s.ctrl.Status()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.ctrl.Stop(req.Reason, req.Now)
+	status.Stopping = true
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+func (s *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("text is required"))
+		return
+	}
+	if err := s.ctrl.SendMessage(req.Text); err != nil {
+		writeControllerError(w, err)
+		return
+	}
+	s.writeStatus(w, http.StatusAccepted)
+}
+
+func (s *Server) handleAddTask(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Content     string   `json:"content"`
+		Priority    int      `json:"priority"`
+		Description string   `json:"description"`
+		Criteria    []string `json:"criteria"`
+		Tags        []string `json:"tags"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("content is required"))
+		return
+	}
+	if req.Priority < 0 || req.Priority > 4 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("priority must be 0-4"))
+		return
+	}
+	task, err := s.ctrl.AddTask(session.TaskAddParams{
+		Content:     req.Content,
+		Priority:    req.Priority,
+		Description: req.Description,
+		Criteria:    req.Criteria,
+		Tags:        req.Tags,
+	})
+	if err != nil {
+		writeControllerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, task)
+}
+
+func (s *Server) handleSkip(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Task   string `json:"task"`
+		Reason string `json:"reason"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	task, err := s.ctrl.SkipTask(req.Task, req.Reason)
+	if err != nil {
+		writeControllerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+// writeStatus answers with the session status.
+func (s *Server) writeStatus(w http.ResponseWriter, code int) {
+	status, err := s.ctrl.Status()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, code, status)
+}
+
+// decode reads the JSON request body into v. An empty body leaves v as is.
+// Answers 400 and returns false if the body is not valid JSON.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v)
+	if err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeControllerError answers 409 for ErrConflict and 500 otherwise.
+func writeControllerError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, ErrConflict) {
+		code = http.StatusConflict
+	}
+	writeError(w, code, err)
+}
+
+// writeError answers {"error": "..."}.
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+// writeJSON answers with v as JSON.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debug("Failed to write control API response: %v", err)
+	}
+}
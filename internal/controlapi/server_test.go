@@ -0,0 +1,181 @@
+package controlapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// fakeController records the calls made by the API.
+type fakeController struct {
+	calls    []string
+	messages []string
+	added    []session.TaskAddParams
+	paused   bool
+	stopping bool
+	skipErr  error
+}
+
+func (f *fakeController) Pause()  { f.calls = append(f.calls, "pause"); f.paused = true }
+func (f *fakeController) Resume() { f.calls = append(f.calls, "resume"); f.paused = false }
+
+func (f *fakeController) Stop(reason string, now bool) {
+	f.calls = append(f.calls, fmt.Sprintf("stop %q now=%v", reason, now))
+	f.stopping = true
+}
+
+func (f *fakeController) SendMessage(text string) error {
+	f.messages = append(f.messages, text)
+	return nil
+}
+
+func (f *fakeController) AddTask(params session.TaskAddParams) (*session.Task, error) {
+	f.added = append(f.added, params)
+	return &session.Task{ID: "TAS-7", Content: params.Content, Status: "remaining", Priority: params.Priority}, nil
+}
+
+func (f *fakeController) SkipTask(id, reason string) (*session.Task, error) {
+	if f.skipErr != nil {
+		return nil, f.skipErr
+	}
+	f.calls = append(f.calls, fmt.Sprintf("skip %q %q", id, reason))
+	return &session.Task{ID: "TAS-3", Status: "blocked"}, nil
+}
+
+func (f *fakeController) Status() (*Status, error) {
+	return &Status{Session: "demo", Iteration: 4, Paused: f.paused, Stopping: f.stopping}, nil
+}
+
+// do sends a request to the API and returns the status code and body.
+func do(t *testing.T, srv *httptest.Server, method, path, body string, header ...string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestHandler(t *testing.T) {
+	ctrl := &fakeController{}
+	srv := httptest.NewServer(New(ctrl, "", "").Handler())
+	defer srv.Close()
+
+	code, body := do(t, srv, http.MethodGet, "/state", "")
+	var status Status
+	if err := json.Unmarshal([]byte(body), &status); code != http.StatusOK || err != nil || status.Session != "demo" || status.Iteration != 4 {
+		t.Errorf("GET /state = %d %s", code, body)
+	}
+
+	if code, body := do(t, srv, http.MethodPost, "/pause", ""); code != http.StatusOK || !strings.Contains(body, `"paused":true`) {
+		t.Errorf("POST /pause = %d %s", code, body)
+	}
+	if code, body := do(t, srv, http.MethodPost, "/resume", ""); code != http.StatusOK || !strings.Contains(body, `"paused":false`) {
+		t.Errorf("POST /resume = %d %s", code, body)
+	}
+	if code, body := do(t, srv, http.MethodPost, "/stop", `{"reason":"deploy freeze"}`); code != http.StatusAccepted || !strings.Contains(body, `"stopping":true`) {
+		t.Errorf("POST /stop = %d %s", code, body)
+	}
+	if code, _ := do(t, srv, http.MethodPost, "/skip", ""); code != http.StatusOK {
+		t.Errorf("POST /skip = %d", code)
+	}
+	want := []string{"pause", "resume", `stop "deploy freeze" now=false`, `skip "" ""`}
+	if fmt.Sprint(ctrl.calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %q, want %q", ctrl.calls, want)
+	}
+
+	if code, _ := do(t, srv, http.MethodPost, "/messages", `{"text":"use sqlite instead"}`); code != http.StatusAccepted || len(ctrl.messages) != 1 || ctrl.messages[0] != "use sqlite instead" {
+		t.Errorf("POST /messages = %d, messages %q", code, ctrl.messages)
+	}
+	if code, body := do(t, srv, http.MethodPost, "/tasks", `{"content":"Add login","priority":1,"tags":["auth"]}`); code != http.StatusCreated || !strings.Contains(body, `"id":"TAS-7"`) {
+		t.Errorf("POST /tasks = %d %s", code, body)
+	}
+	if len(ctrl.added) != 1 || ctrl.added[0].Priority != 1 || ctrl.added[0].Tags[0] != "auth" {
+		t.Errorf("added = %+v", ctrl.added)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	ctrl := &fakeController{skipErr: fmt.Errorf("%w: no task in progress", ErrConflict)}
+	srv := httptest.NewServer(New(ctrl, "", "").Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/messages", `{"text":"  "}`, http.StatusBadRequest},
+		{http.MethodPost, "/messages", `not json`, http.StatusBadRequest},
+		{http.MethodPost, "/tasks", `{}`, http.StatusBadRequest},
+		{http.MethodPost, "/tasks", `{"content":"x","priority":9}`, http.StatusBadRequest},
+		{http.MethodPost, "/skip", `{}`, http.StatusConflict},
+		{http.MethodGet, "/pause", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/nope", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		code, body := do(t, srv, tt.method, tt.path, tt.body)
+		if code != tt.want {
+			t.Errorf("%s %s %s = %d %s, want %d", tt.method, tt.path, tt.body, code, body, tt.want)
+		}
+	}
+	if len(ctrl.messages) != 0 || len(ctrl.added) != 0 {
+		t.Errorf("invalid requests reached the controller: %q %+v", ctrl.messages, ctrl.added)
+	}
+}
+
+func TestHandler_Token(t *testing.T) {
+	t.Setenv("API_TOKEN", "s3cret")
+	srv := httptest.NewServer(New(&fakeController{}, "", "$API_TOKEN").Handler())
+	defer srv.Close()
+
+	if code, _ := do(t, srv, http.MethodGet, "/state", ""); code != http.StatusUnauthorized {
+		t.Errorf("no token = %d, want 401", code)
+	}
+	if code, _ := do(t, srv, http.MethodGet, "/state", "", "Authorization", "Bearer wrong"); code != http.StatusUnauthorized {
+		t.Errorf("wrong token = %d, want 401", code)
+	}
+	if code, _ := do(t, srv, http.MethodGet, "/state", "", "Authorization", "Bearer s3cret"); code != http.StatusOK {
+		t.Errorf("expanded token = %d, want 200", code)
+	}
+}
+
+func TestServer_StartStop(t *testing.T) {
+	s := New(&fakeController{}, "127.0.0.1:0", "")
+	url, err := s.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "http://127.0.0.1:") || strings.HasSuffix(url, ":0") || s.URL() != url {
+		t.Errorf("Start() = %q, URL() = %q", url, s.URL())
+	}
+	resp, err := http.Get(url + "/state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /state = %d", resp.StatusCode)
+	}
+	if err := s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := http.Get(url + "/state"); err == nil {
+		t.Error("server still answering after Stop()")
+	}
+}
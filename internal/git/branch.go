@@ -0,0 +1,128 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// IsRepo reports whether dir is inside a git repository.
+func IsRepo(dir string) bool {
+	return isGitRepo(dir)
+}
+
+// CurrentBranch returns the checked out branch name ("HEAD" if detached).
+func CurrentBranch(dir string) (string, error) {
+	return runGitChecked(dir, "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// IsDirty reports whether the working tree has uncommitted changes.
+func IsDirty(dir string) (bool, error) {
+	status, err := runGitChecked(dir, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return status != "", nil
+}
+
+// BranchExists reports whether a local branch with the given name exists.
+func BranchExists(dir, name string) bool {
+	_, err := runGit(dir, "show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// Checkout switches to branch, creating it from base if it does not exist.
+func Checkout(dir, branch, base string) error {
+	if BranchExists(dir, branch) {
+		_, err := runGitChecked(dir, "checkout", branch)
+		return err
+	}
+	_, err := runGitChecked(dir, "checkout", "-b", branch, base)
+	return err
+}
+
+// AddWorktree creates a worktree at path with branch checked out,
+// creating the branch from base if it does not exist.
+// An existing worktree at path is reused.
+func AddWorktree(dir, path, branch, base string) error {
+	if isGitRepo(path) {
+		if current, err := CurrentBranch(path); err == nil && current == branch {
+			return nil
+		}
+	}
+	if BranchExists(dir, branch) {
+		_, err := runGitChecked(dir, "worktree", "add", path, branch)
+		return err
+	}
+	_, err := runGitChecked(dir, "worktree", "add", "-b", branch, path, base)
+	return err
+}
+
+// RemoveWorktree removes the worktree at path. The branch is kept.
+// Fails if the worktree has uncommitted or untracked files.
+func RemoveWorktree(dir, path string) error {
+	_, err := runGitChecked(dir, "worktree", "remove", path)
+	return err
+}
+
+// Merge merges branch into the checked out branch with a merge commit.
+// A conflicting merge is aborted, leaving the working tree unchanged.
+func Merge(dir, branch, message string) error {
+	if _, err := runGitChecked(dir, "merge", "--no-ff", "-m", message, branch); err != nil {
+		_, _ = runGit(dir, "merge", "--abort")
+		return err
+	}
+	return nil
+}
+
+// OverlappingChanges returns the files changed both on branch and on base
+// since they diverged, sorted. These are the files a merge of branch into
+// base may conflict on.
+func OverlappingChanges(dir, base, branch string) ([]string, error) {
+	fork, err := runGitChecked(dir, "merge-base", base, branch)
+	if err != nil {
+		return nil, err
+	}
+	onBase, err := runGitChecked(dir, "diff", "--name-only", fork, base)
+	if err != nil {
+		return nil, err
+	}
+	onBranch, err := runGitChecked(dir, "diff", "--name-only", fork, branch)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[string]bool)
+	for _, name := range strings.Split(onBase, "\n") {
+		if name != "" {
+			changed[name] = true
+		}
+	}
+	var overlap []string
+	for _, name := range strings.Split(onBranch, "\n") {
+		if changed[name] {
+			overlap = append(overlap, name)
+		}
+	}
+	sort.Strings(overlap)
+	return overlap, nil
+}
+
+// runGitChecked is like runGit but includes git's stderr in the error.
+func runGitChecked(dir string, args ...string) (string, error) {
+	out, err := runGit(dir, args...)
+	if err != nil {
+		return "", wrapGitError(args, err)
+	}
+	return out, nil
+}
+
+// wrapGitError adds the git arguments and stderr output to a command error.
+func wrapGitError(args []string, err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+}
@@ -0,0 +1,151 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// initRepo creates a temp git repo with one commit on branch "main".
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+	} {
+		if _, err := runGitChecked(dir, args...); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+	commitFile(t, dir, "README.md", "hello\n")
+	return dir
+}
+
+// commitFile writes a file and commits it.
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGitChecked(dir, "add", name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGitChecked(dir, "commit", "-m", "add "+name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckoutAndMerge(t *testing.T) {
+	dir := initRepo(t)
+
+	if err := Checkout(dir, "feature/x", "main"); err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if branch, _ := CurrentBranch(dir); branch != "feature/x" {
+		t.Fatalf("CurrentBranch() = %q, want feature/x", branch)
+	}
+	if !BranchExists(dir, "feature/x") {
+		t.Error("BranchExists(feature/x) = false")
+	}
+	commitFile(t, dir, "x.txt", "x\n")
+
+	if err := Checkout(dir, "main", ""); err != nil {
+		t.Fatalf("Checkout(main) error = %v", err)
+	}
+	if err := Merge(dir, "feature/x", "Merge feature/x"); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x.txt")); err != nil {
+		t.Errorf("merged file missing: %v", err)
+	}
+	if msg, _ := runGit(dir, "log", "-1", "--format=%s"); msg != "Merge feature/x" {
+		t.Errorf("merge commit message = %q", msg)
+	}
+}
+
+func TestMerge_ConflictAborts(t *testing.T) {
+	dir := initRepo(t)
+
+	if err := Checkout(dir, "conflict", "main"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, dir, "README.md", "branch\n")
+	if err := Checkout(dir, "main", ""); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, dir, "README.md", "main\n")
+
+	if err := Merge(dir, "conflict", "Merge conflict"); err == nil {
+		t.Fatal("Merge() should fail on conflict")
+	}
+	if dirty, _ := IsDirty(dir); dirty {
+		t.Error("working tree should be clean after aborted merge")
+	}
+}
+
+func TestOverlappingChanges(t *testing.T) {
+	dir := initRepo(t)
+
+	if err := Checkout(dir, "task/1", "main"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, dir, "README.md", "branch\n")
+	commitFile(t, dir, "a.txt", "a\n")
+	if err := Checkout(dir, "main", ""); err != nil {
+		t.Fatal(err)
+	}
+	if overlap, err := OverlappingChanges(dir, "main", "task/1"); err != nil || len(overlap) != 0 {
+		t.Fatalf("OverlappingChanges() = %v, %v; want none before main changes", overlap, err)
+	}
+
+	commitFile(t, dir, "b.txt", "b\n")
+	commitFile(t, dir, "README.md", "main\n")
+	overlap, err := OverlappingChanges(dir, "main", "task/1")
+	if err != nil {
+		t.Fatalf("OverlappingChanges() error = %v", err)
+	}
+	if len(overlap) != 1 || overlap[0] != "README.md" {
+		t.Errorf("OverlappingChanges() = %v, want [README.md]", overlap)
+	}
+}
+
+func TestWorktree(t *testing.T) {
+	dir := initRepo(t)
+	wt := filepath.Join(t.TempDir(), "wt")
+
+	if err := AddWorktree(dir, wt, "task/1", "main"); err != nil {
+		t.Fatalf("AddWorktree() error = %v", err)
+	}
+	if branch, _ := CurrentBranch(wt); branch != "task/1" {
+		t.Errorf("worktree branch = %q, want task/1", branch)
+	}
+	// Adding again reuses the existing worktree
+	if err := AddWorktree(dir, wt, "task/1", "main"); err != nil {
+		t.Errorf("AddWorktree() reuse error = %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(wt, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if dirty, _ := IsDirty(wt); !dirty {
+		t.Error("IsDirty() = false for worktree with untracked file")
+	}
+	if dirty, _ := IsDirty(dir); dirty {
+		t.Error("main working tree should not see worktree changes")
+	}
+
+	if err := RemoveWorktree(dir, wt); err == nil {
+		t.Error("RemoveWorktree() should refuse a dirty worktree")
+	}
+	if err := os.Remove(filepath.Join(wt, "new.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveWorktree(dir, wt); err != nil {
+		t.Fatalf("RemoveWorktree() error = %v", err)
+	}
+	if !BranchExists(dir, "task/1") {
+		t.Error("branch should be kept after removing worktree")
+	}
+}
@@ -0,0 +1,233 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// checkpointBranchTrailer records the checked out branch in checkpoint commits.
+const checkpointBranchTrailer = "Iteratr-Branch"
+
+// checkpointIdentity is the author/committer of checkpoint commits, so
+// snapshots work without a configured git user.
+var checkpointIdentity = []string{
+	"GIT_AUTHOR_NAME=iteratr",
+	"GIT_AUTHOR_EMAIL=iteratr@localhost",
+	"GIT_COMMITTER_NAME=iteratr",
+	"GIT_COMMITTER_EMAIL=iteratr@localhost",
+}
+
+// CreateCheckpoint snapshots the working tree of the repository containing dir
+// (tracked and untracked files, honoring .gitignore) as a commit stored under ref.
+// The commit's parent is HEAD. The working tree, index, and HEAD are not modified.
+// Paths in excludes (relative to dir) are left out of the snapshot.
+// Returns the SHA of the snapshot commit.
+func CreateCheckpoint(dir, ref, message string, excludes []string) (string, error) {
+	tmp, err := os.MkdirTemp("", "iteratr-checkpoint-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	// Stage everything into a private index, seeded from the real one so
+	// unchanged files are not rehashed
+	index := filepath.Join(tmp, "index")
+	if real, err := runGit(dir, "rev-parse", "--git-path", "index"); err == nil {
+		if !filepath.IsAbs(real) {
+			real = filepath.Join(dir, real)
+		}
+		_ = copyFile(real, index)
+	}
+	env := []string{"GIT_INDEX_FILE=" + index}
+	if _, err := runGitEnv(dir, env, append([]string{"add", "-A", "--", ":/"}, excludePathspecs(excludes)...)...); err != nil {
+		return "", err
+	}
+	tree, err := runGitEnv(dir, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+
+	var trailers []string
+	if branch, err := runGit(dir, "symbolic-ref", "--short", "-q", "HEAD"); err == nil && branch != "" {
+		trailers = append(trailers, checkpointBranchTrailer+": "+branch)
+	}
+	args := []string{"commit-tree", tree}
+	if parent, err := runGit(dir, "rev-parse", "--verify", "-q", "HEAD"); err == nil && parent != "" {
+		args = append(args, "-p", parent)
+	}
+	args = append(args, "-m", buildMessage(message, trailers))
+	sha, err := runGitEnv(dir, checkpointIdentity, args...)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := runGitChecked(dir, "update-ref", ref, sha); err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// RestoreCheckpoint makes the repository containing dir look as it did when
+// the checkpoint under ref was taken: HEAD is reset to the checkpoint's parent,
+// files are restored from the snapshot, and files created since are removed.
+// Changes that were uncommitted at checkpoint time are left uncommitted.
+// Paths in excludes (relative to dir) are not touched.
+// Fails if a different branch is checked out than when the checkpoint was taken.
+func RestoreCheckpoint(dir, ref string, excludes []string) error {
+	snap, err := runGit(dir, "rev-parse", "--verify", "-q", ref+"^{commit}")
+	if err != nil || snap == "" {
+		return fmt.Errorf("checkpoint %s not found", ref)
+	}
+	parent, _ := runGit(dir, "rev-parse", "--verify", "-q", snap+"^")
+
+	branch, _ := runGit(dir, "log", "-1", "--format=%(trailers:key="+checkpointBranchTrailer+",valueonly)", snap)
+	if branch != "" {
+		if current, _ := runGit(dir, "symbolic-ref", "--short", "-q", "HEAD"); current != branch {
+			return fmt.Errorf("checkpoint %s was taken on branch %s, check it out first", ref, branch)
+		}
+	}
+	top, err := runGitChecked(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+
+	// Move HEAD (and the index) back to where it was
+	resetIndex := func() error {
+		if parent == "" {
+			_, err := runGitChecked(dir, "read-tree", "--empty")
+			return err
+		}
+		_, err := runGitChecked(dir, "reset", "-q", parent)
+		return err
+	}
+	if err := resetIndex(); err != nil {
+		return err
+	}
+
+	// Remove files that are not part of the snapshot
+	out, err := runGitRaw(dir, "ls-tree", "-r", "-z", "--name-only", "--full-tree", snap)
+	if err != nil {
+		return err
+	}
+	snapFiles := make(map[string]bool)
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			snapFiles[p] = true
+		}
+	}
+	args := append([]string{"ls-files", "-z", "-c", "-o", "--exclude-standard", "--full-name", "--", ":/"}, excludePathspecs(excludes)...)
+	out, err = runGitRaw(dir, args...)
+	if err != nil {
+		return err
+	}
+	for _, p := range strings.Split(out, "\x00") {
+		if p == "" || snapFiles[p] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(top, p)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+	}
+
+	// Write the snapshot's files, then unstage them again
+	if len(snapFiles) > 0 {
+		if _, err := runGitChecked(dir, append([]string{"checkout", snap, "--", ":/"}, excludePathspecs(excludes)...)...); err != nil {
+			return err
+		}
+	}
+	return resetIndex()
+}
+
+// excludePathspecs converts paths relative to the command's directory into
+// exclude pathspecs.
+func excludePathspecs(excludes []string) []string {
+	var specs []string
+	for _, e := range excludes {
+		if e != "" && e != "." {
+			specs = append(specs, ":(exclude)"+e)
+		}
+	}
+	return specs
+}
+
+// runGitEnv is like runGitChecked with extra environment variables.
+func runGitEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", wrapGitError(args, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// copyFile copies src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ListRefs returns the full names of the refs under prefix (e.g. "refs/iteratr/").
+func ListRefs(dir, prefix string) ([]string, error) {
+	out, err := runGitChecked(dir, "for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// MoveRefs renames every ref under the from prefix to the same name under the
+// to prefix. Returns the number of refs moved.
+func MoveRefs(dir, from, to string) (int, error) {
+	refs, err := ListRefs(dir, from)
+	if err != nil {
+		return 0, err
+	}
+	for i, ref := range refs {
+		sha, err := runGitChecked(dir, "rev-parse", ref)
+		if err != nil {
+			return i, err
+		}
+		if _, err := runGitChecked(dir, "update-ref", to+strings.TrimPrefix(ref, from), sha); err != nil {
+			return i, err
+		}
+		if _, err := runGitChecked(dir, "update-ref", "-d", ref); err != nil {
+			return i, err
+		}
+	}
+	return len(refs), nil
+}
+
+// DeleteRefs deletes every ref under prefix. Returns the number of refs deleted.
+func DeleteRefs(dir, prefix string) (int, error) {
+	refs, err := ListRefs(dir, prefix)
+	if err != nil {
+		return 0, err
+	}
+	for i, ref := range refs {
+		if _, err := runGitChecked(dir, "update-ref", "-d", ref); err != nil {
+			return i, err
+		}
+	}
+	return len(refs), nil
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	dir := initRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "<missing>"
+		}
+		return string(data)
+	}
+
+	// State at checkpoint: committed README, uncommitted edit, untracked file, ignored data
+	write(".gitignore", "ignored.log\n")
+	commitFile(t, dir, ".gitignore", "ignored.log\n")
+	write("README.md", "edited\n")
+	write("notes.txt", "draft\n")
+	write("ignored.log", "log v1\n")
+	write(".iteratr/state", "v1\n")
+	head, _ := runGit(dir, "rev-parse", "HEAD")
+
+	const ref = "refs/iteratr/checkpoints/test/1"
+	sha, err := CreateCheckpoint(dir, ref, "checkpoint 1", []string{".iteratr"})
+	if err != nil {
+		t.Fatalf("CreateCheckpoint() error = %v", err)
+	}
+	if got, _ := runGit(dir, "rev-parse", ref); got != sha {
+		t.Errorf("ref %s = %q, want %q", ref, got, sha)
+	}
+	if dirty, _ := IsDirty(dir); !dirty {
+		t.Error("CreateCheckpoint() modified the working tree")
+	}
+
+	// Iteration work: commit, edits, new files, deletion
+	commitFile(t, dir, "feature.go", "package feature\n")
+	write("README.md", "rewritten\n")
+	write("sub/new.txt", "new\n")
+	write("ignored.log", "log v2\n")
+	write(".iteratr/state", "v2\n")
+	if err := os.Remove(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RestoreCheckpoint(dir, ref, []string{".iteratr"}); err != nil {
+		t.Fatalf("RestoreCheckpoint() error = %v", err)
+	}
+
+	if got, _ := runGit(dir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD = %q, want %q", got, head)
+	}
+	for name, want := range map[string]string{
+		"README.md":      "edited\n",
+		"notes.txt":      "draft\n",
+		"feature.go":     "<missing>",
+		"sub/new.txt":    "<missing>",
+		"ignored.log":    "log v2\n", // Ignored files are not part of checkpoints
+		".iteratr/state": "v2\n",     // Excluded paths are left alone
+	} {
+		if got := read(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	// Uncommitted changes stay uncommitted
+	changed, _ := ChangedPaths(dir)
+	if len(changed) != 3 {
+		t.Errorf("ChangedPaths() = %v, want README.md, notes.txt and .iteratr/state", changed)
+	}
+}
+
+func TestRestoreCheckpoint_Errors(t *testing.T) {
+	dir := initRepo(t)
+
+	if err := RestoreCheckpoint(dir, "refs/iteratr/checkpoints/none/1", nil); err == nil {
+		t.Error("RestoreCheckpoint() with missing ref: expected error")
+	}
+
+	const ref = "refs/iteratr/checkpoints/test/2"
+	if _, err := CreateCheckpoint(dir, ref, "checkpoint 2", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Checkout(dir, "other", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreCheckpoint(dir, ref, nil); err == nil {
+		t.Error("RestoreCheckpoint() on another branch: expected error")
+	}
+}
+
+func TestMoveAndDeleteRefs(t *testing.T) {
+	dir := initRepo(t)
+	for _, ref := range []string{"refs/iteratr/checkpoints/old/1", "refs/iteratr/checkpoints/old/2", "refs/iteratr/checkpoints/older/1"} {
+		if _, err := CreateCheckpoint(dir, ref, "checkpoint", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := MoveRefs(dir, "refs/iteratr/checkpoints/old/", "refs/iteratr/checkpoints/new/")
+	if err != nil || n != 2 {
+		t.Fatalf("MoveRefs() = %d, %v; want 2 refs moved", n, err)
+	}
+	refs, _ := ListRefs(dir, "refs/iteratr/checkpoints/")
+	want := []string{"refs/iteratr/checkpoints/new/1", "refs/iteratr/checkpoints/new/2", "refs/iteratr/checkpoints/older/1"}
+	if strings.Join(refs, ",") != strings.Join(want, ",") {
+		t.Errorf("refs after move = %v, want %v", refs, want)
+	}
+
+	n, err = DeleteRefs(dir, "refs/iteratr/checkpoints/new/")
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteRefs() = %d, %v; want 2 refs deleted", n, err)
+	}
+	refs, _ = ListRefs(dir, "refs/iteratr/checkpoints/")
+	if len(refs) != 1 || refs[0] != "refs/iteratr/checkpoints/older/1" {
+		t.Errorf("refs after delete = %v, want only older/1", refs)
+	}
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// CommitOptions configures Commit.
+type CommitOptions struct {
+	Paths    []string // Paths to commit, relative to dir (nil = all changes)
+	Message  string   // Commit message
+	Trailers []string // "Key: value" lines appended as git trailers
+}
+
+// Commit stages and commits changes in dir.
+// When Paths is set, only changed paths among them are staged and committed;
+// other staged changes are left in the index. Ignored and unchanged paths are skipped.
+// Returns the full SHA of the new commit, or "" if there was nothing to commit.
+func Commit(dir string, opts CommitOptions) (string, error) {
+	var paths []string
+	if opts.Paths != nil {
+		if len(opts.Paths) == 0 {
+			return "", nil
+		}
+		changed, err := ChangedPaths(dir, opts.Paths...)
+		if err != nil {
+			return "", err
+		}
+		if len(changed) == 0 {
+			return "", nil
+		}
+		// Status paths are relative to the repository root
+		for _, p := range changed {
+			paths = append(paths, ":(top,literal)"+p)
+		}
+		if _, err := runGitChecked(dir, append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+			return "", err
+		}
+	} else {
+		if _, err := runGitChecked(dir, "add", "-A"); err != nil {
+			return "", err
+		}
+		if _, err := runGit(dir, "diff", "--cached", "--quiet"); err == nil {
+			return "", nil // Nothing staged
+		}
+	}
+
+	args := []string{"commit", "-m", buildMessage(opts.Message, opts.Trailers)}
+	if paths != nil {
+		args = append(append(args, "--"), paths...)
+	}
+	if _, err := runGitChecked(dir, args...); err != nil {
+		return "", err
+	}
+	return runGitChecked(dir, "rev-parse", "HEAD")
+}
+
+// ChangedPaths returns the paths with uncommitted changes (modified, added,
+// deleted, or untracked), limited to the given pathspecs if any.
+// Ignored files are not included.
+func ChangedPaths(dir string, pathspecs ...string) ([]string, error) {
+	args := append([]string{"status", "--porcelain", "-z", "--untracked-files=all", "--"}, pathspecs...)
+	out, err := runGitRaw(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		paths = append(paths, entry[3:])
+		// Renames and copies are followed by the original path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+			if i < len(entries) && entries[i] != "" {
+				paths = append(paths, entries[i])
+			}
+		}
+	}
+	return paths, nil
+}
+
+// buildMessage appends trailers to a commit message as a trailer block.
+func buildMessage(message string, trailers []string) string {
+	message = strings.TrimSpace(message)
+	var lines []string
+	for _, t := range trailers {
+		if t = strings.TrimSpace(t); t != "" {
+			lines = append(lines, t)
+		}
+	}
+	if len(lines) == 0 {
+		return message
+	}
+	return message + "\n\n" + strings.Join(lines, "\n")
+}
+
+// runGitRaw runs a git command and returns untrimmed stdout
+// (needed for NUL-separated output).
+func runGitRaw(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", wrapGitError(args, err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommit_Paths(t *testing.T) {
+	dir := initRepo(t)
+	for name, content := range map[string]string{
+		"a.txt":      "a\n",
+		"sub/b.txt":  "b\n",
+		"other.txt":  "other\n",
+		"README.md":  "changed\n",
+		".gitignore": "ignored.txt\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sha, err := Commit(filepath.Join(dir, "sub"), CommitOptions{
+		Paths:    []string{"b.txt", "../a.txt", "../README.md", "../missing.txt"},
+		Message:  "Add files",
+		Trailers: []string{"Iteratr-Iteration: 3", " "},
+	})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if head, _ := runGit(dir, "rev-parse", "HEAD"); sha != head {
+		t.Errorf("Commit() = %q, want HEAD %q", sha, head)
+	}
+
+	files, _ := runGit(dir, "show", "--name-only", "--format=", "HEAD")
+	if files != "README.md\na.txt\nsub/b.txt" {
+		t.Errorf("committed files = %q", files)
+	}
+	msg, _ := runGit(dir, "log", "-1", "--format=%B")
+	if msg != "Add files\n\nIteratr-Iteration: 3" {
+		t.Errorf("commit message = %q", msg)
+	}
+	// Paths not listed stay uncommitted
+	changed, err := ChangedPaths(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(changed, ",") != ".gitignore,other.txt" {
+		t.Errorf("ChangedPaths() after commit = %v", changed)
+	}
+}
+
+func TestCommit_NothingToCommit(t *testing.T) {
+	dir := initRepo(t)
+
+	if sha, err := Commit(dir, CommitOptions{Paths: []string{"README.md"}, Message: "noop"}); err != nil || sha != "" {
+		t.Errorf("Commit(unchanged path) = %q, %v; want empty", sha, err)
+	}
+	if sha, err := Commit(dir, CommitOptions{Paths: []string{}, Message: "noop"}); err != nil || sha != "" {
+		t.Errorf("Commit(no paths) = %q, %v; want empty", sha, err)
+	}
+	if sha, err := Commit(dir, CommitOptions{Message: "noop"}); err != nil || sha != "" {
+		t.Errorf("Commit(all, clean tree) = %q, %v; want empty", sha, err)
+	}
+}
+
+func TestCommit_AllChanges(t *testing.T) {
+	dir := initRepo(t)
+	if err := os.Remove(filepath.Join(dir, "README.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sha, err := Commit(dir, CommitOptions{Message: "Replace readme"})
+	if err != nil || sha == "" {
+		t.Fatalf("Commit() = %q, %v", sha, err)
+	}
+	if dirty, _ := IsDirty(dir); dirty {
+		t.Error("working tree still dirty after committing all changes")
+	}
+}
+
+func TestChangedPaths_Rename(t *testing.T) {
+	dir := initRepo(t)
+	if _, err := runGitChecked(dir, "mv", "README.md", "DOCS.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := ChangedPaths(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(changed, ",") != "DOCS.md,README.md" {
+		t.Errorf("ChangedPaths() = %v, want both rename paths", changed)
+	}
+}
@@ -0,0 +1,47 @@
+// Package glob matches slash-separated paths against globs, as used by
+// permission rules and hook conditions.
+package glob
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Compile converts a glob to an anchored regular expression.
+// "**" matches across directories, "*" and "?" match within one path segment.
+func Compile(glob string) (*regexp.Regexp, error) {
+	return regexp.Compile(toRegexp(glob))
+}
+
+// toRegexp builds the expression for Compile. On Windows, backslashes in the
+// glob are path separators.
+func toRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	glob = filepath.ToSlash(glob)
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				// "**/" also matches zero directories
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
@@ -0,0 +1,29 @@
+package glob
+
+import "testing"
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		glob, path string
+		want       bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"internal/**/*.go", "internal/hooks/hooks.go", true},
+		{"internal/**/*.go", "internal/hooks.go", true},
+		{"internal/**", "internal/a/b/c.txt", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file/.txt", false},
+		{"a+b.txt", "a+b.txt", true},
+		{"a+b.txt", "aab.txt", false},
+	}
+	for _, tt := range tests {
+		re, err := Compile(tt.glob)
+		if err != nil {
+			t.Fatalf("Compile(%q) error = %v", tt.glob, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("Compile(%q).MatchString(%q) = %v, want %v", tt.glob, tt.path, got, tt.want)
+		}
+	}
+}
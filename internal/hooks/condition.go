@@ -0,0 +1,103 @@
+package hooks
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/glob"
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// Match reports whether the condition holds for an event. previous is the
+// result of the previous hook that ran in the same list (PreviousSuccess or
+// PreviousFailure), or "" if none ran. Conditions on the task never match
+// events without one, and iteration conditions never match events outside an
+// iteration.
+func (c *Condition) Match(vars Variables, previous string) bool {
+	if c == nil {
+		return true
+	}
+	if len(c.Changed) > 0 && !c.matchChanged(vars.Changed) {
+		return false
+	}
+	if len(c.Priority) > 0 && (vars.TaskID == "" || !slices.Contains(c.Priority, vars.TaskPriority)) {
+		return false
+	}
+	if len(c.Tags) > 0 && (vars.TaskID == "" || !slices.ContainsFunc(c.Tags, func(tag string) bool {
+		return slices.Contains(vars.TaskTags, tag)
+	})) {
+		return false
+	}
+	if c.Every > 0 {
+		n, err := strconv.Atoi(vars.Iteration)
+		if err != nil || n%c.Every != 0 {
+			return false
+		}
+	}
+	if c.Previous != "" && c.Previous != previous {
+		return false
+	}
+	return true
+}
+
+// matchChanged reports whether any changed path matches one of the globs.
+// Globs without a "/" match the file name in any directory.
+func (c *Condition) matchChanged(paths []string) bool {
+	if err := c.compileChanged(); err != nil {
+		logger.Warn("Hook condition never matches: %v", err)
+		return false
+	}
+	for i, re := range c.changed {
+		for _, p := range paths {
+			p = strings.TrimPrefix(filepath.ToSlash(p), "./")
+			if re.MatchString(p) || (!strings.Contains(filepath.ToSlash(c.Changed[i]), "/") && re.MatchString(path.Base(p))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compileChanged compiles the changed globs once.
+func (c *Condition) compileChanged() error {
+	c.compileOnce.Do(func() {
+		c.changed = make([]*regexp.Regexp, 0, len(c.Changed))
+		for _, g := range c.Changed {
+			re, err := glob.Compile(g)
+			if err != nil {
+				c.compileErr = fmt.Errorf("when.changed: invalid glob %q: %w", g, err)
+				return
+			}
+			c.changed = append(c.changed, re)
+		}
+	})
+	return c.compileErr
+}
+
+// Validate checks the condition's values and compiles its changed globs.
+func (c *Condition) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if err := c.compileChanged(); err != nil {
+		return err
+	}
+	for _, p := range c.Priority {
+		if p < 0 || p > 4 {
+			return fmt.Errorf("when.priority: invalid priority %d (must be 0-4)", p)
+		}
+	}
+	if c.Every < 0 {
+		return fmt.Errorf("when.every: must not be negative")
+	}
+	switch c.Previous {
+	case "", PreviousSuccess, PreviousFailure:
+		return nil
+	}
+	return fmt.Errorf("when.previous: invalid value %q (must be success or failure)", c.Previous)
+}
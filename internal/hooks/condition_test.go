@@ -0,0 +1,150 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConditionMatch(t *testing.T) {
+	task := Variables{Iteration: "6", TaskID: "TAS-1", TaskPriority: 0, TaskTags: []string{"backend", "api"}, Changed: []string{"internal/auth/login.go", "README.md"}}
+	noTask := Variables{Session: "s"}
+
+	tests := []struct {
+		name     string
+		cond     *Condition
+		vars     Variables
+		previous string
+		want     bool
+	}{
+		{"nil condition", nil, noTask, "", true},
+		{"changed base name glob", &Condition{Changed: []string{"*.go"}}, task, "", true},
+		{"changed path glob", &Condition{Changed: []string{"internal/**/*.go"}}, task, "", true},
+		{"changed no match", &Condition{Changed: []string{"*.ts", "web/**"}}, task, "", false},
+		{"changed without changes", &Condition{Changed: []string{"*.go"}}, noTask, "", false},
+		{"priority match", &Condition{Priority: []int{0, 1}}, task, "", true},
+		{"priority no match", &Condition{Priority: []int{2}}, task, "", false},
+		{"priority without task", &Condition{Priority: []int{0}}, noTask, "", false},
+		{"tags match any", &Condition{Tags: []string{"frontend", "api"}}, task, "", true},
+		{"tags no match", &Condition{Tags: []string{"frontend"}}, task, "", false},
+		{"every match", &Condition{Every: 3}, task, "", true},
+		{"every no match", &Condition{Every: 4}, task, "", false},
+		{"every without iteration", &Condition{Every: 2}, noTask, "", false},
+		{"previous failure", &Condition{Previous: PreviousFailure}, noTask, PreviousFailure, true},
+		{"previous success but failed", &Condition{Previous: PreviousSuccess}, noTask, PreviousFailure, false},
+		{"previous without earlier hook", &Condition{Previous: PreviousSuccess}, noTask, "", false},
+		{"all fields must match", &Condition{Changed: []string{"*.go"}, Tags: []string{"frontend"}}, task, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cond.Match(tt.vars, tt.previous); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionMatch_CompilesGlobsOnce(t *testing.T) {
+	// Built in code without Validate, matched from several hook callbacks
+	cond := &Condition{Changed: []string{"internal/**/*.go"}}
+	vars := Variables{Changed: []string{"internal/hooks/hooks.go"}}
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			if !cond.Match(vars, "") {
+				t.Error("Match() = false, want the changed glob to match")
+			}
+		})
+	}
+	wg.Wait()
+	if len(cond.changed) != 1 {
+		t.Errorf("compiled %d globs, want 1", len(cond.changed))
+	}
+}
+
+func TestConditionValidate(t *testing.T) {
+	valid := &Condition{Priority: []int{0, 4}, Every: 2, Previous: PreviousSuccess}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, c := range []*Condition{
+		{Priority: []int{5}},
+		{Every: -1},
+		{Previous: "always"},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", c)
+		}
+	}
+}
+
+func TestExecuteAll_Conditions(t *testing.T) {
+	ctx := context.Background()
+	vars := Variables{Iteration: "2", TaskID: "TAS-1", Changed: []string{"main.go"}}
+	hooks := []*HookConfig{
+		{Command: "echo go", When: &Condition{Changed: []string{"*.go"}}},
+		{Command: "echo web", When: &Condition{Changed: []string{"*.ts"}}},
+		{Command: "exit 1"},
+		{Command: "echo after-failure", When: &Condition{Previous: PreviousFailure}},
+		{Command: "echo after-success", When: &Condition{Previous: PreviousSuccess}},
+		{Command: "echo odd", When: &Condition{Every: 3}},
+	}
+
+	var started []int
+	output, err := ExecuteAllWithCallbacks(ctx, hooks, t.TempDir(), vars, func(i int, _ string) { started = append(started, i) }, nil)
+	if err != nil {
+		t.Fatalf("ExecuteAllWithCallbacks() error = %v", err)
+	}
+	if len(started) != 4 || started[0] != 0 || started[1] != 2 || started[2] != 3 || started[3] != 4 {
+		t.Errorf("started hooks = %v, want [0 2 3 4]", started)
+	}
+	for _, want := range []string{"go", "after-failure", "after-success"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q: %q", want, output)
+		}
+	}
+	if strings.Contains(output, "web") || strings.Contains(output, "odd") {
+		t.Errorf("output contains skipped hooks: %q", output)
+	}
+}
+
+func TestLoadConfig_ValidatesConditions(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `version: 1
+hooks:
+  on_task_start:
+    - command: "echo start"
+      when:
+        priority: [0]
+  pre_commit:
+    - command: "make lint"
+    - command: "echo lint failed"
+      when:
+        previous: sometimes
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadConfig(tmpDir)
+	if err == nil || !strings.Contains(err.Error(), "pre_commit[1]: when.previous") {
+		t.Fatalf("LoadConfig() error = %v, want pre_commit[1] condition error", err)
+	}
+
+	content = strings.Replace(content, "sometimes", "failure", 1)
+	if err := os.WriteFile(filepath.Join(tmpDir, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(cfg.Hooks.OnTaskStart) != 1 || cfg.Hooks.OnTaskStart[0].When.Priority[0] != 0 {
+		t.Errorf("OnTaskStart = %+v", cfg.Hooks.OnTaskStart)
+	}
+	if len(cfg.Hooks.PreCommit) != 2 || cfg.Hooks.PreCommit[1].When.Previous != PreviousFailure {
+		t.Errorf("PreCommit = %+v", cfg.Hooks.PreCommit)
+	}
+}
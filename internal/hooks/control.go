@@ -0,0 +1,97 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Control actions a hook with control: true can emit.
+const (
+	ActionBlockTask  = "block_task"  // Mark a task blocked, with reason as a stuck note
+	ActionReopenTask = "reopen_task" // Mark a task remaining again
+	ActionAddTasks   = "add_tasks"   // Add tasks
+	ActionAddNote    = "add_note"    // Add a note
+	ActionStop       = "stop"        // Stop the session after the current iteration
+)
+
+// Control is a control document emitted by a hook, e.g.
+// {"action":"block_task","task":"TAS-3","reason":"tests fail"}.
+type Control struct {
+	Action  string        `json:"action"`
+	Task    string        `json:"task,omitempty"`    // block_task, reopen_task: task ID or prefix
+	Reason  string        `json:"reason,omitempty"`  // Why; shown to the agent and in logs
+	Tasks   []ControlTask `json:"tasks,omitempty"`   // add_tasks
+	Type    string        `json:"type,omitempty"`    // add_note: learning, stuck, tip, decision
+	Content string        `json:"content,omitempty"` // add_note
+}
+
+// ControlTask is a task added by an add_tasks control document.
+type ControlTask struct {
+	Content     string   `json:"content"`
+	Priority    int      `json:"priority,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Validate checks that the control document has the fields its action needs.
+func (c Control) Validate() error {
+	switch c.Action {
+	case ActionBlockTask, ActionReopenTask:
+		if c.Task == "" {
+			return fmt.Errorf("%s: task is required", c.Action)
+		}
+	case ActionAddTasks:
+		if len(c.Tasks) == 0 {
+			return fmt.Errorf("add_tasks: tasks is required")
+		}
+		for i, t := range c.Tasks {
+			if t.Content == "" {
+				return fmt.Errorf("add_tasks: tasks[%d]: content is required", i)
+			}
+		}
+	case ActionAddNote:
+		if c.Content == "" {
+			return fmt.Errorf("add_note: content is required")
+		}
+	case ActionStop:
+	default:
+		return fmt.Errorf("unknown action %q", c.Action)
+	}
+	return nil
+}
+
+// ParseControls extracts control documents from hook output: either the
+// whole output is one JSON object, or each line holding a JSON object with
+// an "action" is one. Returns the output without those lines.
+func ParseControls(output string) (string, []Control) {
+	if c, ok := parseControl(strings.TrimSpace(output)); ok {
+		return "", []Control{c}
+	}
+
+	var controls []Control
+	var rest []string
+	for _, line := range strings.Split(output, "\n") {
+		if c, ok := parseControl(strings.TrimSpace(line)); ok {
+			controls = append(controls, c)
+			continue
+		}
+		rest = append(rest, line)
+	}
+	if len(controls) == 0 {
+		return output, nil
+	}
+	return strings.TrimSpace(strings.Join(rest, "\n")), controls
+}
+
+// parseControl parses s as a control document.
+func parseControl(s string) (Control, bool) {
+	if !strings.HasPrefix(s, "{") {
+		return Control{}, false
+	}
+	var c Control
+	if err := json.Unmarshal([]byte(s), &c); err != nil || c.Action == "" {
+		return Control{}, false
+	}
+	return c, true
+}
@@ -0,0 +1,92 @@
+package hooks
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseControls(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		wantText string
+		want     []string // actions
+	}{
+		{"plain text", "ok\n{not json}\n", "ok\n{not json}\n", nil},
+		{"json without action", `{"status":"ok"}`, `{"status":"ok"}`, nil},
+		{"whole output", "{\n  \"action\": \"stop\",\n  \"reason\": \"done\"\n}\n", "", []string{ActionStop}},
+		{"json lines mixed with text", "FAIL: TestLogin\n{\"action\":\"reopen_task\",\"task\":\"TAS-3\"}\n{\"action\":\"add_note\",\"content\":\"x\"}\nexit 1", "FAIL: TestLogin\nexit 1", []string{ActionReopenTask, ActionAddNote}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, controls := ParseControls(tt.output)
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			if len(controls) != len(tt.want) {
+				t.Fatalf("controls = %+v, want actions %v", controls, tt.want)
+			}
+			for i, c := range controls {
+				if c.Action != tt.want[i] {
+					t.Errorf("controls[%d].Action = %q, want %q", i, c.Action, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestControlValidate(t *testing.T) {
+	valid := []Control{
+		{Action: ActionBlockTask, Task: "TAS-1"},
+		{Action: ActionReopenTask, Task: "TAS-1"},
+		{Action: ActionAddTasks, Tasks: []ControlTask{{Content: "Fix lint"}}},
+		{Action: ActionAddNote, Content: "flaky"},
+		{Action: ActionStop},
+	}
+	for _, c := range valid {
+		if err := c.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", c, err)
+		}
+	}
+	invalid := []Control{
+		{Action: ActionBlockTask},
+		{Action: ActionAddTasks},
+		{Action: ActionAddTasks, Tasks: []ControlTask{{Priority: 1}}},
+		{Action: ActionAddNote},
+		{Action: "restart"},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", c)
+		}
+	}
+}
+
+func TestExecuteAll_Controls(t *testing.T) {
+	doc := `{"action":"block_task","task":"TAS-2","reason":"lint"}`
+	hooks := []*HookConfig{
+		{Command: "echo 'lint failed'; echo '" + doc + "'; exit 1", PipeOutput: true, Control: true},
+		{Command: "echo '" + doc + "'", PipeOutput: true}, // control not enabled: plain output
+	}
+
+	var results []HookResult
+	output, err := ExecuteAllPipedWithCallbacks(context.Background(), hooks, t.TempDir(), Variables{}, nil, func(_ int, r HookResult) {
+		results = append(results, r)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteAllPipedWithCallbacks() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if !results[0].Failed || len(results[0].Controls) != 1 || results[0].Controls[0].Task != "TAS-2" {
+		t.Errorf("results[0] = %+v, want failed with block_task control", results[0])
+	}
+	if len(results[1].Controls) != 0 {
+		t.Errorf("results[1].Controls = %+v, want none", results[1].Controls)
+	}
+	want := "[Hook command failed: exit status 1]\nlint failed\n" + doc + "\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+}
@@ -0,0 +1,89 @@
+package hooks
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Env returns the variables as ITERATR_* environment variables. Unset
+// variables are left out; ITERATR_CHANGED_FILES holds one path per line.
+func (v Variables) Env() []string {
+	var env []string
+	add := func(name, value string) {
+		if value != "" {
+			env = append(env, "ITERATR_"+name+"="+value)
+		}
+	}
+	add("HOOK", v.Hook)
+	add("SESSION", v.Session)
+	add("ITERATION", v.Iteration)
+	add("TASK_ID", v.TaskID)
+	add("TASK_CONTENT", v.TaskContent)
+	add("ERROR", v.Error)
+	add("NOTE_TYPE", v.NoteType)
+	add("NOTE_CONTENT", v.NoteContent)
+	add("DATA_DIR", v.DataDir)
+	if v.NATSPort > 0 {
+		add("NATS_PORT", strconv.Itoa(v.NATSPort))
+	}
+	add("CHANGED_FILES", strings.Join(v.Changed, "\n"))
+	return env
+}
+
+// Payload is the JSON document describing the triggering event that hooks
+// receive on stdin.
+type Payload struct {
+	Hook      string       `json:"hook"`
+	Session   string       `json:"session"`
+	Iteration int          `json:"iteration,omitempty"`
+	Task      *PayloadTask `json:"task,omitempty"`
+	Note      *PayloadNote `json:"note,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Changed   []string     `json:"changed"`
+	DataDir   string       `json:"data_dir,omitempty"`
+	NATSPort  int          `json:"nats_port,omitempty"`
+}
+
+// PayloadTask is the task of a hook payload.
+type PayloadTask struct {
+	ID       string   `json:"id"`
+	Content  string   `json:"content"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// PayloadNote is the note of an on_note_added hook payload.
+type PayloadNote struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// Payload returns the JSON payload for the variables.
+func (v Variables) Payload() []byte {
+	p := Payload{
+		Hook:     v.Hook,
+		Session:  v.Session,
+		Error:    v.Error,
+		Changed:  v.Changed,
+		DataDir:  v.DataDir,
+		NATSPort: v.NATSPort,
+	}
+	if p.Changed == nil {
+		p.Changed = []string{}
+	}
+	p.Iteration, _ = strconv.Atoi(v.Iteration)
+	if v.TaskID != "" {
+		p.Task = &PayloadTask{ID: v.TaskID, Content: v.TaskContent, Priority: v.TaskPriority, Tags: v.TaskTags}
+	}
+	if v.NoteType != "" || v.NoteContent != "" {
+		p.Note = &PayloadNote{Type: v.NoteType, Content: v.NoteContent}
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		// Only plain strings and numbers; cannot fail
+		return nil
+	}
+	return append(data, '\n')
+}
@@ -0,0 +1,114 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVariablesEnv(t *testing.T) {
+	vars := Variables{
+		Hook:      "post_iteration",
+		Session:   "demo",
+		Iteration: "4",
+		TaskID:    "TAS-2",
+		DataDir:   "/work/.iteratr",
+		NATSPort:  4222,
+		Changed:   []string{"a.go", "b/c.go"},
+	}
+	want := []string{
+		"ITERATR_HOOK=post_iteration",
+		"ITERATR_SESSION=demo",
+		"ITERATR_ITERATION=4",
+		"ITERATR_TASK_ID=TAS-2",
+		"ITERATR_DATA_DIR=/work/.iteratr",
+		"ITERATR_NATS_PORT=4222",
+		"ITERATR_CHANGED_FILES=a.go\nb/c.go",
+	}
+	if got := vars.Env(); !slices.Equal(got, want) {
+		t.Errorf("Env() = %q, want %q", got, want)
+	}
+}
+
+func TestVariablesPayload(t *testing.T) {
+	vars := Variables{
+		Hook:         "on_task_start",
+		Session:      "demo",
+		Iteration:    "3",
+		TaskID:       "TAS-1",
+		TaskContent:  `Handle "quoted" input`,
+		TaskPriority: 1,
+		TaskTags:     []string{"api"},
+	}
+	var p Payload
+	if err := json.Unmarshal(vars.Payload(), &p); err != nil {
+		t.Fatalf("Payload() is not JSON: %v", err)
+	}
+	if p.Hook != "on_task_start" || p.Iteration != 3 || p.Note != nil || p.Changed == nil {
+		t.Errorf("payload = %+v", p)
+	}
+	if p.Task == nil || p.Task.Content != vars.TaskContent || p.Task.Priority != 1 || p.Task.Tags[0] != "api" {
+		t.Errorf("payload task = %+v", p.Task)
+	}
+}
+
+func TestExecute_EnvStdinAndArgs(t *testing.T) {
+	ctx := context.Background()
+	vars := Variables{Hook: "on_task_complete", Session: "demo", TaskID: "TAS-7", TaskContent: `it's "done"`}
+
+	output, err := Execute(ctx, &HookConfig{Command: `echo "$ITERATR_HOOK $ITERATR_TASK_ID $ITERATR_TASK_CONTENT"`}, t.TempDir(), vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "on_task_complete TAS-7 it's \"done\"\n" {
+		t.Errorf("env output = %q", output)
+	}
+
+	output, err = Execute(ctx, &HookConfig{Command: "cat"}, t.TempDir(), vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p Payload
+	if err := json.Unmarshal([]byte(output), &p); err != nil || p.Task == nil || p.Task.ID != "TAS-7" {
+		t.Errorf("stdin payload = %q (%v)", output, err)
+	}
+
+	// args are passed as-is, without a shell to mangle quotes
+	output, err = Execute(ctx, &HookConfig{Args: []string{"printf", "%s|%s", "{{task_content}}", "$HOME"}}, t.TempDir(), vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != `it's "done"|$HOME` {
+		t.Errorf("args output = %q", output)
+	}
+
+	output, err = Execute(ctx, &HookConfig{Args: []string{"iteratr-no-such-program"}}, t.TempDir(), vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "[Hook command failed:") {
+		t.Errorf("missing program output = %q", output)
+	}
+}
+
+func TestHookConfigValidate(t *testing.T) {
+	for _, h := range []*HookConfig{
+		{Command: "echo"},
+		{Args: []string{"echo", "hi"}},
+	} {
+		if err := h.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", h, err)
+		}
+	}
+	for _, h := range []*HookConfig{
+		{Command: "echo", Args: []string{"echo"}},
+		{Args: []string{""}},
+		{Command: "echo", When: &Condition{Every: -1}},
+	} {
+		if err := h.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", h)
+		}
+	}
+}
@@ -36,31 +36,102 @@ func LoadConfig(workDir string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse hooks config: %w", err)
 	}
+	if err := cfg.Hooks.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid hooks config: %w", err)
+	}
 
 	logger.Debug("Loaded hooks config from %s (version: %d)", configPath, cfg.Version)
 	return &cfg, nil
 }
 
-// Variables holds template variables that can be expanded in hook commands.
+// Validate checks every hook.
+func (h *HooksConfig) Validate() error {
+	for _, point := range []struct {
+		name  string
+		hooks []*HookConfig
+	}{
+		{"session_start", h.SessionStart},
+		{"pre_iteration", h.PreIteration},
+		{"post_iteration", h.PostIteration},
+		{"session_end", h.SessionEnd},
+		{"on_task_complete", h.OnTaskComplete},
+		{"on_error", h.OnError},
+		{"on_task_start", h.OnTaskStart},
+		{"on_task_blocked", h.OnTaskBlocked},
+		{"on_note_added", h.OnNoteAdded},
+		{"pre_commit", h.PreCommit},
+		{"on_pause", h.OnPause},
+		{"on_resume", h.OnResume},
+	} {
+		for i, hook := range point.hooks {
+			if hook == nil {
+				continue
+			}
+			if err := hook.Validate(); err != nil {
+				return fmt.Errorf("%s[%d]: %w", point.name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks that the hook sets command or args, not both, and its
+// when condition.
+func (h *HookConfig) Validate() error {
+	if h.Command != "" && len(h.Args) > 0 {
+		return fmt.Errorf("command and args are mutually exclusive")
+	}
+	if len(h.Args) > 0 && h.Args[0] == "" {
+		return fmt.Errorf("args: program is empty")
+	}
+	return h.When.Validate()
+}
+
+// empty reports whether the hook has nothing to run.
+func (h *HookConfig) empty() bool {
+	return h == nil || (h.Command == "" && len(h.Args) == 0)
+}
+
+// display returns the hook's command with variables expanded, for logs and
+// the TUI.
+func (h *HookConfig) display(vars Variables) string {
+	if len(h.Args) > 0 {
+		return strings.Join(expandArgs(h.Args, vars), " ")
+	}
+	return expandVariables(h.Command, vars)
+}
+
+// Variables holds template variables that can be expanded in hook commands,
+// and the event details hook when conditions are matched against.
 type Variables struct {
+	Hook        string // Hook point, e.g. "post_iteration"
 	Session     string
 	Iteration   string
 	TaskID      string
 	TaskContent string
 	Error       string
+	NoteType    string
+	NoteContent string
+
+	TaskPriority int      // Priority of the task (set with TaskID)
+	TaskTags     []string // Tags of the task
+	Changed      []string // Files changed in the iteration, relative to the work dir
+	DataDir      string   // Absolute path of the data directory
+	NATSPort     int      // Port of the session's NATS server
 }
 
 // Execute runs a hook command and returns its output.
-// Template variables in the command ({{session}}, {{iteration}}) are expanded before execution.
+// Template variables in the command or args ({{session}}, {{iteration}}) are
+// expanded before execution. The hook also gets the variables as ITERATR_*
+// environment variables (see Env) and the event as JSON on stdin (see Payload).
 // On error, returns an error message as output and nil error (graceful degradation).
 // Only returns error for context cancellation.
 func Execute(ctx context.Context, hook *HookConfig, workDir string, vars Variables) (string, error) {
-	if hook == nil || hook.Command == "" {
+	if hook.empty() {
 		return "", nil
 	}
 
-	// Expand template variables in command
-	command := expandVariables(hook.Command, vars)
+	command := hook.display(vars)
 	logger.Debug("Executing hook command: %s", command)
 
 	// Determine timeout
@@ -73,9 +144,17 @@ func Execute(ctx context.Context, hook *HookConfig, workDir string, vars Variabl
 	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	// Execute command via shell
-	cmd := exec.CommandContext(execCtx, "sh", "-c", command)
+	// Execute args directly, or command via shell
+	var cmd *exec.Cmd
+	if len(hook.Args) > 0 {
+		args := expandArgs(hook.Args, vars)
+		cmd = exec.CommandContext(execCtx, args[0], args[1:]...)
+	} else {
+		cmd = exec.CommandContext(execCtx, "sh", "-c", command)
+	}
 	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), vars.Env()...)
+	cmd.Stdin = bytes.NewReader(vars.Payload())
 
 	// Capture stdout and stderr separately
 	var stdout, stderr bytes.Buffer
@@ -122,23 +201,7 @@ func Execute(ctx context.Context, hook *HookConfig, workDir string, vars Variabl
 // Returns combined output from all hooks separated by newlines.
 // Only returns error for context cancellation.
 func ExecuteAll(ctx context.Context, hooks []*HookConfig, workDir string, vars Variables) (string, error) {
-	if len(hooks) == 0 {
-		return "", nil
-	}
-
-	var outputs []string
-	for _, hook := range hooks {
-		output, err := Execute(ctx, hook, workDir, vars)
-		if err != nil {
-			// Context cancelled - propagate
-			return "", err
-		}
-		if output != "" {
-			outputs = append(outputs, output)
-		}
-	}
-
-	return strings.Join(outputs, "\n"), nil
+	return executeList(ctx, hooks, workDir, vars, nil, nil, false)
 }
 
 // ExecuteAllPiped runs multiple hook commands and returns only output from hooks with pipe_output: true.
@@ -146,24 +209,7 @@ func ExecuteAll(ctx context.Context, hooks []*HookConfig, workDir string, vars V
 // Returns combined piped output separated by newlines.
 // Only returns error for context cancellation.
 func ExecuteAllPiped(ctx context.Context, hooks []*HookConfig, workDir string, vars Variables) (string, error) {
-	if len(hooks) == 0 {
-		return "", nil
-	}
-
-	var pipedOutputs []string
-	for _, hook := range hooks {
-		output, err := Execute(ctx, hook, workDir, vars)
-		if err != nil {
-			// Context cancelled - propagate
-			return "", err
-		}
-		// Only include output if pipe_output is true
-		if hook.PipeOutput && output != "" {
-			pipedOutputs = append(pipedOutputs, output)
-		}
-	}
-
-	return strings.Join(pipedOutputs, "\n"), nil
+	return executeList(ctx, hooks, workDir, vars, nil, nil, true)
 }
 
 // HookResult contains the result of executing a single hook command.
@@ -172,6 +218,7 @@ type HookResult struct {
 	Output   string        // Command output (stdout + stderr)
 	Failed   bool          // Whether the command failed (non-zero exit or timeout)
 	Duration time.Duration // How long the command took
+	Controls []Control     // Control documents emitted by a hook with control: true
 }
 
 // OnHookStart is called before a hook command starts executing.
@@ -193,62 +240,7 @@ func ExecuteAllPipedWithCallbacks(
 	onStart OnHookStart,
 	onComplete OnHookComplete,
 ) (string, error) {
-	if len(hooks) == 0 {
-		return "", nil
-	}
-
-	var pipedOutputs []string
-	for i, hook := range hooks {
-		if hook == nil || hook.Command == "" {
-			continue
-		}
-
-		// Expand variables for the callback (show what's actually being run)
-		expandedCmd := expandVariables(hook.Command, vars)
-
-		// Notify start
-		if onStart != nil {
-			onStart(i, expandedCmd)
-		}
-
-		start := time.Now()
-		output, err := Execute(ctx, hook, workDir, vars)
-		elapsed := time.Since(start)
-
-		if err != nil {
-			// Context cancelled - notify and propagate
-			if onComplete != nil {
-				onComplete(i, HookResult{
-					Command:  expandedCmd,
-					Output:   output,
-					Failed:   true,
-					Duration: elapsed,
-				})
-			}
-			return "", err
-		}
-
-		// Determine if command failed (output starts with "[Hook" markers)
-		failed := strings.HasPrefix(output, "[Hook command failed:") ||
-			strings.HasPrefix(output, "[Hook timed out")
-
-		// Notify completion
-		if onComplete != nil {
-			onComplete(i, HookResult{
-				Command:  expandedCmd,
-				Output:   output,
-				Failed:   failed,
-				Duration: elapsed,
-			})
-		}
-
-		// Only include output if pipe_output is true
-		if hook.PipeOutput && output != "" {
-			pipedOutputs = append(pipedOutputs, output)
-		}
-	}
-
-	return strings.Join(pipedOutputs, "\n"), nil
+	return executeList(ctx, hooks, workDir, vars, onStart, onComplete, true)
 }
 
 // ExecuteAllWithCallbacks is like ExecuteAll but calls onStart/onComplete
@@ -263,18 +255,38 @@ func ExecuteAllWithCallbacks(
 	onStart OnHookStart,
 	onComplete OnHookComplete,
 ) (string, error) {
-	if len(hooks) == 0 {
-		return "", nil
-	}
+	return executeList(ctx, hooks, workDir, vars, onStart, onComplete, false)
+}
 
+// executeList runs the hooks whose when condition matches, in order, calling
+// onStart/onComplete (if set) around each one. Returns the combined output of
+// all hooks run, or only of those with pipe_output when pipedOnly is set.
+// Control documents are passed to onComplete instead of being returned.
+// Only returns error for context cancellation.
+func executeList(
+	ctx context.Context,
+	hooks []*HookConfig,
+	workDir string,
+	vars Variables,
+	onStart OnHookStart,
+	onComplete OnHookComplete,
+	pipedOnly bool,
+) (string, error) {
 	var outputs []string
+	previous := ""
 	for i, hook := range hooks {
-		if hook == nil || hook.Command == "" {
+		if hook.empty() {
+			continue
+		}
+		if !hook.When.Match(vars, previous) {
+			logger.Debug("Skipping hook, when condition not met: %s", hook.display(vars))
 			continue
 		}
 
-		expandedCmd := expandVariables(hook.Command, vars)
+		// Expand variables for the callback (show what's actually being run)
+		expandedCmd := hook.display(vars)
 
+		// Notify start
 		if onStart != nil {
 			onStart(i, expandedCmd)
 		}
@@ -284,6 +296,7 @@ func ExecuteAllWithCallbacks(
 		elapsed := time.Since(start)
 
 		if err != nil {
+			// Context cancelled - notify and propagate
 			if onComplete != nil {
 				onComplete(i, HookResult{
 					Command:  expandedCmd,
@@ -295,19 +308,32 @@ func ExecuteAllWithCallbacks(
 			return "", err
 		}
 
+		// Determine if command failed (output starts with "[Hook" markers)
 		failed := strings.HasPrefix(output, "[Hook command failed:") ||
 			strings.HasPrefix(output, "[Hook timed out")
+		previous = PreviousSuccess
+		if failed {
+			previous = PreviousFailure
+		}
+
+		// Take control documents out of the output; the caller applies them
+		var controls []Control
+		if hook.Control {
+			output, controls = ParseControls(output)
+		}
 
+		// Notify completion
 		if onComplete != nil {
 			onComplete(i, HookResult{
 				Command:  expandedCmd,
 				Output:   output,
 				Failed:   failed,
 				Duration: elapsed,
+				Controls: controls,
 			})
 		}
 
-		if output != "" {
+		if output != "" && (hook.PipeOutput || !pipedOnly) {
 			outputs = append(outputs, output)
 		}
 	}
@@ -323,6 +349,8 @@ func expandVariables(command string, vars Variables) string {
 		"{{task_id}}":      vars.TaskID,
 		"{{task_content}}": vars.TaskContent,
 		"{{error}}":        vars.Error,
+		"{{note_type}}":    vars.NoteType,
+		"{{note_content}}": vars.NoteContent,
 	}
 
 	result := command
@@ -331,3 +359,12 @@ func expandVariables(command string, vars Variables) string {
 	}
 	return result
 }
+
+// expandArgs replaces {{variable}} placeholders in each argument.
+func expandArgs(args []string, vars Variables) []string {
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = expandVariables(arg, vars)
+	}
+	return expanded
+}
@@ -1,5 +1,10 @@
 package hooks
 
+import (
+	"regexp"
+	"sync"
+)
+
 // Config is the top-level configuration for hooks loaded from .iteratr.hooks.yml.
 type Config struct {
 	Version int         `yaml:"version"`
@@ -14,14 +19,42 @@ type HooksConfig struct {
 	SessionEnd     []*HookConfig `yaml:"session_end"`
 	OnTaskComplete []*HookConfig `yaml:"on_task_complete"`
 	OnError        []*HookConfig `yaml:"on_error"`
+	OnTaskStart    []*HookConfig `yaml:"on_task_start"`   // A task is marked in_progress
+	OnTaskBlocked  []*HookConfig `yaml:"on_task_blocked"` // A task is marked blocked
+	OnNoteAdded    []*HookConfig `yaml:"on_note_added"`
+	PreCommit      []*HookConfig `yaml:"pre_commit"` // Before auto-commit; a failing hook skips the commit
+	OnPause        []*HookConfig `yaml:"on_pause"`
+	OnResume       []*HookConfig `yaml:"on_resume"`
 }
 
 // HookConfig defines a single hook's configuration.
 type HookConfig struct {
-	Command    string `yaml:"command"`
-	Timeout    int    `yaml:"timeout"`     // seconds, default 30
-	PipeOutput bool   `yaml:"pipe_output"` // default false
+	Command    string     `yaml:"command"`
+	Args       []string   `yaml:"args"`        // Program and arguments run without a shell (instead of command)
+	Timeout    int        `yaml:"timeout"`     // seconds, default 30
+	PipeOutput bool       `yaml:"pipe_output"` // default false
+	When       *Condition `yaml:"when"`        // Run only when the condition matches (nil = always)
+	Control    bool       `yaml:"control"`     // Apply JSON control documents in the output (see ParseControls)
 }
 
+// Condition restricts when a hook runs. All set fields must match.
+type Condition struct {
+	Changed  []string `yaml:"changed"`  // Globs; a file changed in the iteration must match one (** spans directories)
+	Priority []int    `yaml:"priority"` // The task's priority must be one of these (0=critical ... 4=backlog)
+	Tags     []string `yaml:"tags"`     // The task must have one of these tags
+	Every    int      `yaml:"every"`    // The iteration number must be a multiple of this
+	Previous string   `yaml:"previous"` // success or failure: result of the previous hook that ran in the same list
+
+	compileOnce sync.Once
+	changed     []*regexp.Regexp // Changed globs, compiled on first use
+	compileErr  error
+}
+
+// Results of the previous hook, matched by Condition.Previous.
+const (
+	PreviousSuccess = "success"
+	PreviousFailure = "failure"
+)
+
 // DefaultTimeout is the default timeout for hook execution in seconds.
 const DefaultTimeout = 30
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/verify"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -82,6 +83,9 @@ func (s *Server) handleTaskAdd(ctx context.Context, request mcp.CallToolRequest)
 		if statusVal, ok := taskMap["status"].(string); ok {
 			status = statusVal
 		}
+		if status == "completed" && s.verifyDir != nil && s.verify.Configured() {
+			return mcp.NewToolResultText(fmt.Sprintf("error: task %d: tasks cannot be added as completed while verification commands are configured. Add the task, then mark it completed with task-update.", i)), nil
+		}
 
 		// Extract optional priority (JSON numbers come as float64)
 		priority := 0
@@ -89,12 +93,27 @@ func (s *Server) handleTaskAdd(ctx context.Context, request mcp.CallToolRequest)
 			priority = int(priorityVal)
 		}
 
-		taskParams = append(taskParams, session.TaskAddParams{
+		params := session.TaskAddParams{
 			Content:  content,
 			Status:   status,
 			Priority: priority,
 			// Iteration will be set by store based on current iteration
-		})
+		}
+
+		// Extract optional details
+		params.Description, _ = taskMap["description"].(string)
+		params.Estimate, _ = taskMap["estimate"].(string)
+		var err error
+		if params.Criteria, err = stringList(taskMap, "criteria"); err == nil {
+			if params.Tags, err = stringList(taskMap, "tags"); err == nil {
+				params.SpecRefs, err = stringList(taskMap, "spec_refs")
+			}
+		}
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: task %d: %v", i, err)), nil
+		}
+
+		taskParams = append(taskParams, params)
 	}
 
 	// Validate in_progress constraints if any task requests that status
@@ -114,11 +133,7 @@ func (s *Server) handleTaskAdd(ctx context.Context, request mcp.CallToolRequest)
 		if err != nil {
 			return mcp.NewToolResultText(fmt.Sprintf("error: failed to load state: %v", err)), nil
 		}
-		currentIteration := 0
-		if len(state.Iterations) > 0 {
-			currentIteration = state.Iterations[len(state.Iterations)-1].Number
-		}
-		if msg := validateInProgress(state, currentIteration); msg != "" {
+		if msg := validateInProgress(state, s.currentIteration(state)); msg != "" {
 			return mcp.NewToolResultText(msg), nil
 		}
 	}
@@ -158,11 +173,8 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 		return mcp.NewToolResultText(fmt.Sprintf("error: failed to load state: %v", err)), nil
 	}
 
-	// Get current iteration number (last iteration in slice)
-	currentIteration := 0
-	if len(state.Iterations) > 0 {
-		currentIteration = state.Iterations[len(state.Iterations)-1].Number
-	}
+	// Get current iteration number (pinned, or last iteration in slice)
+	currentIteration := s.currentIteration(state)
 
 	// Track what we updated for the success message
 	updated := []string{}
@@ -176,6 +188,15 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 			}
 		}
 
+		// Run the verification gate before completing
+		verified := ""
+		if status == "completed" {
+			var msg string
+			if verified, msg = s.verifyTask(ctx, state, id, currentIteration); msg != "" {
+				return mcp.NewToolResultText(msg), nil
+			}
+		}
+
 		err := s.store.TaskStatus(ctx, s.sessName, session.TaskStatusParams{
 			ID:        id,
 			Status:    status,
@@ -185,6 +206,9 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 			return mcp.NewToolResultText(fmt.Sprintf("error: failed to update status: %v", err)), nil
 		}
 		updated = append(updated, fmt.Sprintf("status=%s", status))
+		if verified != "" {
+			updated = append(updated, verified)
+		}
 	}
 
 	// Update priority if provided (JSON numbers come as float64)
@@ -223,9 +247,63 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 		updated = append(updated, fmt.Sprintf("depends_on=%s", dependsOn))
 	}
 
+	// Update details if provided
+	details := session.TaskDetailsParams{ID: id, Iteration: currentIteration}
+	changed := []string{}
+	if description, ok := args["description"].(string); ok {
+		details.Description = &description
+		changed = append(changed, "description")
+	}
+	if estimate, ok := args["estimate"].(string); ok {
+		details.Estimate = &estimate
+		changed = append(changed, "estimate="+estimate)
+	}
+	for _, field := range []string{"criteria", "tags", "spec_refs"} {
+		list, err := stringList(args, field)
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+		}
+		if list == nil {
+			continue
+		}
+		switch field {
+		case "criteria":
+			details.Criteria = list
+		case "tags":
+			details.Tags = list
+		case "spec_refs":
+			details.SpecRefs = list
+		}
+		changed = append(changed, field)
+	}
+	if len(changed) > 0 {
+		if err := s.store.TaskDetails(ctx, s.sessName, details); err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: failed to update details: %v", err)), nil
+		}
+		updated = append(updated, changed...)
+	}
+
+	// Mark acceptance criteria if provided
+	for _, field := range []string{"check", "uncheck"} {
+		index, ok := args[field].(float64)
+		if !ok {
+			continue
+		}
+		err := s.store.TaskCriterion(ctx, s.sessName, session.TaskCriterionParams{
+			ID:        id,
+			Index:     int(index),
+			Done:      field == "check",
+			Iteration: currentIteration,
+		})
+		if err != nil {
+			return mcp.NewToolResultText(fmt.Sprintf("error: failed to %s criterion: %v", field, err)), nil
+		}
+		updated = append(updated, fmt.Sprintf("%s=%d", field, int(index)))
+	}
+
 	// Check if anything was actually updated
 	if len(updated) == 0 {
-		return mcp.NewToolResultText("error: no valid update parameters provided (status, priority, or depends_on required)"), nil
+		return mcp.NewToolResultText("error: no valid update parameters provided (status, priority, depends_on, details, check or uncheck required)"), nil
 	}
 
 	// Return success message
@@ -237,6 +315,56 @@ func (s *Server) handleTaskUpdate(ctx context.Context, request mcp.CallToolReque
 	return mcp.NewToolResultText(result), nil
 }
 
+// verifyTask runs the verification commands configured for a task being
+// marked completed and records the result. Returns a summary of the passed
+// commands (empty if there were none to run), or an error message for the
+// agent if the task must not be completed.
+func (s *Server) verifyTask(ctx context.Context, state *session.State, id string, iteration int) (string, string) {
+	if s.verifyDir == nil {
+		return "", ""
+	}
+	task, err := state.FindTask(id)
+	if err != nil {
+		return "", fmt.Sprintf("error: failed to update status: %v", err)
+	}
+
+	result, err := verify.Gate(ctx, s.store, s.sessName, task, s.verify, s.verifyDir(), iteration)
+	if err != nil {
+		return "", fmt.Sprintf("error: verification cancelled: %v", err)
+	}
+	if len(result.Commands) == 0 {
+		return "", ""
+	}
+
+	if !result.Passed {
+		return "", fmt.Sprintf("error: verification failed, %s was not marked completed.\n$ %s\n%s\n\nFix the failure, then mark the task completed again.",
+			task.ID, result.Failed, strings.TrimRight(result.Output, "\n"))
+	}
+	return fmt.Sprintf("verified (%s)", strings.Join(result.Commands, ", ")), ""
+}
+
+// stringList extracts an optional array of strings from args.
+// Returns nil if the field is absent.
+func stringList(args map[string]any, field string) ([]string, error) {
+	raw, ok := args[field]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("'%s' must be an array of strings", field)
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("'%s' must be an array of strings", field)
+		}
+		list = append(list, str)
+	}
+	return list, nil
+}
+
 // handleTaskList returns all tasks grouped by status.
 func (s *Server) handleTaskList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Call TaskList
@@ -259,6 +387,9 @@ func (s *Server) handleTaskList(ctx context.Context, request mcp.CallToolRequest
 		lines = append(lines, fmt.Sprintf("%s:", statusLabel))
 		for _, t := range tasks {
 			lines = append(lines, fmt.Sprintf("  [%s] %s", t.ID, t.Content))
+			for _, detail := range t.DetailLines() {
+				lines = append(lines, "      "+detail)
+			}
 		}
 	}
 
@@ -301,6 +432,15 @@ func (s *Server) handleTaskNext(ctx context.Context, request mcp.CallToolRequest
 	return mcp.NewToolResultText(string(output)), nil
 }
 
+// handleTaskGraph returns the task dependency graph with the critical path.
+func (s *Server) handleTaskGraph(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	state, err := s.store.LoadState(ctx, s.sessName)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("error: %v", err)), nil
+	}
+	return mcp.NewToolResultText(state.FormatGraph()), nil
+}
+
 // handleNoteAdd adds one or more notes to the session.
 func (s *Server) handleNoteAdd(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract arguments
@@ -438,12 +578,16 @@ func (s *Server) handleIterationSummary(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultText(fmt.Sprintf("error: failed to load state: %v", err)), nil
 	}
 
-	// Find the current (last) iteration number and check for existing summary
+	// Find the current iteration (pinned, or last) and check for existing summary
 	iterNum := 1
 	var currentIter *session.Iteration
-	if len(state.Iterations) > 0 {
-		currentIter = state.Iterations[len(state.Iterations)-1]
-		iterNum = currentIter.Number
+	if n := s.currentIteration(state); n > 0 {
+		iterNum = n
+	}
+	for _, iter := range state.Iterations {
+		if iter.Number == iterNum {
+			currentIter = iter
+		}
 	}
 
 	// Guard: if this iteration already has a summary, don't record a duplicate
@@ -3,9 +3,12 @@ package mcpserver
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/mark3labs/iteratr/internal/config"
 	"github.com/mark3labs/iteratr/internal/nats"
 	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -366,6 +369,51 @@ func TestHandleTaskUpdate_DependencyOnly(t *testing.T) {
 	}
 }
 
+func TestHandleTaskGraph_RejectsCycle(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	call := func(name string, args map[string]any) string {
+		t.Helper()
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}}
+		var result *mcp.CallToolResult
+		var err error
+		switch name {
+		case "task-add":
+			result, err = srv.handleTaskAdd(ctx, req)
+		case "task-update":
+			result, err = srv.handleTaskUpdate(ctx, req)
+		case "task-graph":
+			result, err = srv.handleTaskGraph(ctx, req)
+		}
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		return extractText(result)
+	}
+
+	call("task-add", map[string]any{"tasks": []any{
+		map[string]any{"content": "Create schema"},
+		map[string]any{"content": "Add models"},
+		map[string]any{"content": "Add API"},
+	}})
+	call("task-update", map[string]any{"id": "TAS-2", "depends_on": "TAS-1"})
+	call("task-update", map[string]any{"id": "TAS-3", "depends_on": "TAS-2"})
+
+	text := call("task-update", map[string]any{"id": "TAS-1", "depends_on": "TAS-3"})
+	if !strings.Contains(text, "dependency cycle: TAS-1 → TAS-3 → TAS-2 → TAS-1") {
+		t.Errorf("expected cycle rejection with path, got: %s", text)
+	}
+
+	text = call("task-graph", nil)
+	for _, want := range []string{"[TAS-2] remaining   Add models ← TAS-1", "Critical path (3 tasks): TAS-1 → TAS-2 → TAS-3"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in graph, got:\n%s", want, text)
+		}
+	}
+}
+
 func TestHandleTaskUpdate_MultipleFields(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -419,6 +467,163 @@ func TestHandleTaskUpdate_MultipleFields(t *testing.T) {
 	}
 }
 
+func TestHandleTaskUpdate_DetailsAndCriteria(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	addReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{
+					map[string]any{
+						"content":     "Add login form",
+						"description": "Email and password",
+						"criteria":    []any{"Shows errors", "Sets cookie"},
+						"tags":        []any{"auth"},
+					},
+				},
+			},
+		},
+	}
+	if _, err := srv.handleTaskAdd(ctx, addReq); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	updateReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-update",
+			Arguments: map[string]any{
+				"id":        "TAS-1",
+				"spec_refs": []any{"spec.md#Login"},
+				"estimate":  "2h",
+				"check":     float64(2),
+			},
+		},
+	}
+	result, err := srv.handleTaskUpdate(ctx, updateReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	text := extractText(result)
+	if !strings.Contains(text, "estimate=2h") || !strings.Contains(text, "spec_refs") || !strings.Contains(text, "check=2") {
+		t.Errorf("expected details and check in message, got: %s", text)
+	}
+
+	listResult, err := srv.handleTaskList(ctx, mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("handleTaskList returned error: %v", err)
+	}
+	list := extractText(listResult)
+	for _, want := range []string{"Email and password", "1. [ ] Shows errors", "2. [x] Sets cookie", "Tags: auth", "Spec: spec.md#Login", "Estimate: 2h"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("task list missing %q:\n%s", want, list)
+		}
+	}
+
+	badReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "task-update",
+			Arguments: map[string]any{"id": "TAS-1", "tags": "auth"},
+		},
+	}
+	result, err = srv.handleTaskUpdate(ctx, badReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "must be an array") {
+		t.Errorf("expected array error, got: %s", text)
+	}
+}
+
+func TestHandleTaskUpdate_VerificationGate(t *testing.T) {
+	srv, store, cleanup := setupTestServerWithStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	srv.SetVerify(config.VerifyConfig{
+		Commands: []string{"test -f ok"},
+		Tasks:    []config.VerifyRule{{Tag: "docs", Commands: []string{"echo docs checked"}}},
+	}, func() string { return dir })
+
+	addReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{map[string]any{"content": "Write docs", "tags": []any{"docs"}}},
+			},
+		},
+	}
+	if _, err := srv.handleTaskAdd(ctx, addReq); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	addCompletedReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "task-add",
+			Arguments: map[string]any{
+				"tasks": []any{map[string]any{"content": "Skip the gate", "status": "completed"}},
+			},
+		},
+	}
+	result, err := srv.handleTaskAdd(ctx, addCompletedReq)
+	if err != nil {
+		t.Fatalf("handleTaskAdd returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "cannot be added as completed") {
+		t.Errorf("expected completed task-add to be rejected, got: %s", text)
+	}
+
+	completeReq := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "task-update",
+			Arguments: map[string]any{"id": "TAS-1", "status": "completed"},
+		},
+	}
+	result, err = srv.handleTaskUpdate(ctx, completeReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	text := extractText(result)
+	if !strings.Contains(text, "verification failed") || !strings.Contains(text, "$ test -f ok") {
+		t.Errorf("expected verification failure, got: %s", text)
+	}
+
+	state, err := store.LoadState(ctx, "test-session")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	task := state.Tasks["TAS-1"]
+	if task.Status != "remaining" {
+		t.Errorf("expected task to stay remaining, got %s", task.Status)
+	}
+	if task.Verification == nil || task.Verification.Passed || task.Verification.Failed != "test -f ok" {
+		t.Errorf("expected failed verification to be recorded, got %+v", task.Verification)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "ok"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = srv.handleTaskUpdate(ctx, completeReq)
+	if err != nil {
+		t.Fatalf("handleTaskUpdate returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "status=completed, verified (test -f ok, echo docs checked)") {
+		t.Errorf("expected verified completion, got: %s", text)
+	}
+
+	state, err = store.LoadState(ctx, "test-session")
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if task := state.Tasks["TAS-1"]; task.Status != "completed" || !task.Verification.Passed {
+		t.Errorf("expected verified completed task, got %s %+v", task.Status, task.Verification)
+	}
+}
+
 func TestHandleTaskUpdate_MissingID(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -828,6 +1033,42 @@ func TestHandleIterationSummary_Success(t *testing.T) {
 	}
 }
 
+func TestHandleIterationSummary_PinnedIteration(t *testing.T) {
+	srv, store, cleanup := setupTestServerWithStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, n := range []int{1, 2} {
+		if err := store.IterationStart(ctx, srv.sessName, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A worker pinned to iteration 1 records its summary there, not on the latest
+	srv.SetIteration(1)
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "iteration-summary",
+			Arguments: map[string]any{"summary": "Worker summary"},
+		},
+	}
+	result, err := srv.handleIterationSummary(ctx, req)
+	if err != nil {
+		t.Fatalf("handleIterationSummary returned error: %v", err)
+	}
+	if text := extractText(result); !strings.Contains(text, "iteration #1") {
+		t.Errorf("expected summary for iteration #1, got: %s", text)
+	}
+
+	state, err := store.LoadState(ctx, srv.sessName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Iterations[0].Summary != "Worker summary" || state.Iterations[1].Summary != "" {
+		t.Errorf("summaries = %q, %q; want only iteration #1", state.Iterations[0].Summary, state.Iterations[1].Summary)
+	}
+}
+
 func TestHandleIterationSummary_MissingSummary(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -6,7 +6,9 @@ import (
 	"net"
 	"net/http"
 	"sync"
+	"sync/atomic"
 
+	"github.com/mark3labs/iteratr/internal/config"
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/mcp-go/server"
@@ -23,6 +25,9 @@ type Server struct {
 	stdServer  *http.Server // Standard HTTP server that uses the listener
 	port       int
 	mu         sync.Mutex
+	iteration  atomic.Int64 // Iteration tool calls are recorded against (0 = latest)
+	verify     config.VerifyConfig
+	verifyDir  func() string // Directory verification commands run in (nil = gate disabled)
 }
 
 // New creates a new MCP server instance for the given session.
@@ -34,6 +39,33 @@ func New(store *session.Store, sessionName string) *Server {
 	}
 }
 
+// SetIteration pins the iteration tool calls are recorded against. By default
+// the latest iteration is used; parallel workers run overlapping iterations,
+// so each worker's server is pinned to its own.
+func (s *Server) SetIteration(n int) {
+	s.iteration.Store(int64(n))
+}
+
+// SetVerify enables the verification gate: marking a task completed first
+// runs its verification commands in the directory returned by dir, and the
+// transition is rejected if one fails. Must be called before Start.
+func (s *Server) SetVerify(cfg config.VerifyConfig, dir func() string) {
+	s.verify = cfg
+	s.verifyDir = dir
+}
+
+// currentIteration returns the pinned iteration, or the latest one in state
+// (0 if there is none).
+func (s *Server) currentIteration(state *session.State) int {
+	if n := s.iteration.Load(); n > 0 {
+		return int(n)
+	}
+	if len(state.Iterations) > 0 {
+		return state.Iterations[len(state.Iterations)-1].Number
+	}
+	return 0
+}
+
 // Start starts the MCP HTTP server on a random available port.
 // Blocks until the server is ready to accept connections.
 // Returns the port number or an error if startup fails.
@@ -27,6 +27,29 @@ func (s *Server) registerTools() error {
 							"type":        "integer",
 							"description": "Priority level (0=critical, 1=high, 2=medium, 3=low, 4=backlog)",
 						},
+						"description": map[string]any{
+							"type":        "string",
+							"description": "Multi-line details beyond the one-line content",
+						},
+						"criteria": map[string]any{
+							"type":        "array",
+							"items":       map[string]any{"type": "string"},
+							"description": "Acceptance criteria that must hold when the task is done",
+						},
+						"tags": map[string]any{
+							"type":        "array",
+							"items":       map[string]any{"type": "string"},
+							"description": "Labels, e.g. backend",
+						},
+						"spec_refs": map[string]any{
+							"type":        "array",
+							"items":       map[string]any{"type": "string"},
+							"description": "Spec sections the task implements, e.g. spec.md#Login",
+						},
+						"estimate": map[string]any{
+							"type":        "string",
+							"description": "Free-form size estimate, e.g. 2h or S",
+						},
 					},
 					"required": []string{"content"},
 				})),
@@ -37,11 +60,18 @@ func (s *Server) registerTools() error {
 	// task-update: id required, other fields optional
 	s.mcpServer.AddTool(
 		mcp.NewTool("task-update",
-			mcp.WithDescription("Update task status, priority, or dependencies"),
+			mcp.WithDescription("Update task status, priority, dependencies, details, or acceptance criteria"),
 			mcp.WithString("id", mcp.Required(), mcp.Description("Task ID or prefix")),
 			mcp.WithString("status", mcp.Description("New status (remaining, in_progress, completed, blocked, cancelled)")),
 			mcp.WithNumber("priority", mcp.Description("New priority (0-4)")),
 			mcp.WithString("depends_on", mcp.Description("Task ID this task depends on")),
+			mcp.WithString("description", mcp.Description("New multi-line description")),
+			mcp.WithArray("criteria", mcp.WithStringItems(), mcp.Description("Replace the acceptance criteria (met criteria with unchanged text stay met)")),
+			mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Replace the labels")),
+			mcp.WithArray("spec_refs", mcp.WithStringItems(), mcp.Description("Replace the spec sections, e.g. spec.md#Login")),
+			mcp.WithString("estimate", mcp.Description("New size estimate")),
+			mcp.WithNumber("check", mcp.Description("Mark acceptance criterion N (1-based) as met")),
+			mcp.WithNumber("uncheck", mcp.Description("Mark acceptance criterion N (1-based) as not met")),
 		),
 		s.handleTaskUpdate,
 	)
@@ -62,6 +92,14 @@ func (s *Server) registerTools() error {
 		s.handleTaskNext,
 	)
 
+	// task-graph: dependency graph, critical path and blocking chains
+	s.mcpServer.AddTool(
+		mcp.NewTool("task-graph",
+			mcp.WithDescription("Show the task dependency graph, the critical path, and what blocks progress when no task is ready"),
+		),
+		s.handleTaskGraph,
+	)
+
 	// note-add: array of note objects
 	s.mcpServer.AddTool(
 		mcp.NewTool("note-add",
@@ -15,10 +15,11 @@ const (
 	StreamName = "iteratr_events"
 
 	// Event types
-	EventTypeTask      = "task"
-	EventTypeNote      = "note"
-	EventTypeIteration = "iteration"
-	EventTypeControl   = "control"
+	EventTypeTask       = "task"
+	EventTypeNote       = "note"
+	EventTypeIteration  = "iteration"
+	EventTypeControl    = "control"
+	EventTypePermission = "permission"
 )
 
 // SubjectForSession returns the wildcard subject pattern for all events in a session.
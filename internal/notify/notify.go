@@ -0,0 +1,172 @@
+// Package notify delivers session events to webhooks, the desktop and email.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// EventTest is sent by "iteratr notify test" to every sink regardless of
+// the events it is subscribed to.
+const EventTest = "test"
+
+// DefaultTimeout is the per-attempt timeout used when none is configured.
+const DefaultTimeout = 10 * time.Second
+
+// retryDelay is the wait before the first retry; it doubles for each retry.
+var retryDelay = time.Second
+
+// Event is a notification about a session.
+type Event struct {
+	Type    string    `json:"event"` // One of config.NotificationEvents or EventTest
+	Session string    `json:"session"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Result is the outcome of delivering an event to one sink.
+type Result struct {
+	Sink string // Sink description, e.g. "webhook slack hooks.slack.com"
+	Err  error  // nil if delivered
+}
+
+// Notifier sends events to the configured sinks.
+type Notifier struct {
+	cfg    config.NotificationsConfig
+	client *http.Client
+	wg     sync.WaitGroup
+}
+
+// New returns a notifier for cfg, or nil if no sinks are configured.
+// All methods are safe to call on a nil notifier.
+func New(cfg config.NotificationsConfig) *Notifier {
+	if len(cfg.Sinks) == 0 {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	return &Notifier{cfg: cfg, client: &http.Client{}}
+}
+
+// Notify sends e in the background to the sinks subscribed to it, logging
+// failed deliveries. Call Wait before exiting.
+func (n *Notifier) Notify(e Event) {
+	if n == nil {
+		return
+	}
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		for _, r := range n.Send(context.Background(), e) {
+			if r.Err != nil {
+				logger.Warn("Notification %s to %s failed: %v", e.Type, r.Sink, r.Err)
+			} else {
+				logger.Debug("Notification %s sent to %s", e.Type, r.Sink)
+			}
+		}
+	}()
+}
+
+// Wait waits for notifications sent by Notify to be delivered or give up.
+func (n *Notifier) Wait() {
+	if n == nil {
+		return
+	}
+	n.wg.Wait()
+}
+
+// Send delivers e to the sinks subscribed to it (every sink for EventTest),
+// retrying failed deliveries. Returns one result per sink sent to.
+func (n *Notifier) Send(ctx context.Context, e Event) []Result {
+	if n == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	var results []Result
+	for _, sink := range n.cfg.Sinks {
+		if e.Type != EventTest && !sink.Wants(e.Type) {
+			continue
+		}
+		results = append(results, Result{Sink: describe(sink), Err: n.deliver(ctx, sink, e)})
+	}
+	return results
+}
+
+// deliver sends e to sink, retrying up to the configured number of times
+// with exponential backoff. Errors marked permanent are not retried.
+func (n *Notifier) deliver(ctx context.Context, sink config.NotificationSink, e Event) error {
+	delay := retryDelay
+	var err error
+	for attempt := 0; attempt <= n.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			logger.Debug("Retrying notification to %s in %s: %v", describe(sink), delay, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+		err = n.deliverOnce(attemptCtx, sink, e)
+		cancel()
+		var perm *permanentError
+		if err == nil || errors.As(err, &perm) {
+			return err
+		}
+	}
+	return err
+}
+
+// deliverOnce makes one delivery attempt.
+func (n *Notifier) deliverOnce(ctx context.Context, sink config.NotificationSink, e Event) error {
+	switch sink.Type {
+	case config.SinkWebhook:
+		return n.sendWebhook(ctx, sink, e)
+	case config.SinkDesktop:
+		return sendDesktop(ctx, e)
+	case config.SinkEmail:
+		return sendEmail(ctx, sink, e)
+	}
+	return &permanentError{fmt.Errorf("unknown sink type %q", sink.Type)}
+}
+
+// permanentError is a delivery error retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// describe names a sink for logs and test output without leaking secrets in
+// its URL.
+func describe(sink config.NotificationSink) string {
+	switch sink.Type {
+	case config.SinkWebhook:
+		format := sink.Format
+		if format == "" {
+			format = config.WebhookJSON
+		}
+		host := "?"
+		if u, err := url.Parse(expand(sink.URL)); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		return fmt.Sprintf("webhook %s %s", format, host)
+	case config.SinkEmail:
+		return "email " + strings.Join(sink.To, ", ")
+	}
+	return sink.Type
+}
@@ -0,0 +1,254 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/config"
+)
+
+func init() {
+	retryDelay = time.Millisecond
+}
+
+// request is a request received by the webhook stand-in.
+type request struct {
+	header http.Header
+	body   string
+}
+
+// webhookServer starts an HTTP stand-in that answers with the given status
+// codes in turn (200 once they run out) and records the requests.
+func webhookServer(t *testing.T, statuses ...int) (*httptest.Server, func() []request) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, request{header: r.Header.Clone(), body: string(body)})
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]request(nil), requests...)
+	}
+}
+
+var stuck = Event{Type: config.NotifyStuck, Session: "demo", Title: "Agent is stuck", Message: "Cannot reach the database"}
+
+func TestSend_WebhookFormats(t *testing.T) {
+	srv, requests := webhookServer(t)
+	t.Setenv("NOTIFY_TOKEN", "s3cret")
+	n := New(config.NotificationsConfig{Sinks: []config.NotificationSink{
+		{Type: config.SinkWebhook, URL: srv.URL, Headers: map[string]string{"authorization": "Bearer $NOTIFY_TOKEN"}},
+		{Type: config.SinkWebhook, URL: srv.URL, Format: config.WebhookSlack},
+		{Type: config.SinkWebhook, URL: srv.URL, Format: config.WebhookNtfy},
+	}})
+
+	results := n.Send(context.Background(), stuck)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Sink, r.Err)
+		}
+	}
+	reqs := requests()
+
+	var got Event
+	if err := json.Unmarshal([]byte(reqs[0].body), &got); err != nil || got.Type != "stuck" || got.Session != "demo" || got.Time.IsZero() {
+		t.Errorf("json body = %s (%v)", reqs[0].body, err)
+	}
+	if auth := reqs[0].header.Get("Authorization"); auth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want expanded env", auth)
+	}
+
+	var slack map[string]string
+	if err := json.Unmarshal([]byte(reqs[1].body), &slack); err != nil || slack["text"] != "*Agent is stuck*\nCannot reach the database" {
+		t.Errorf("slack body = %s (%v)", reqs[1].body, err)
+	}
+
+	if reqs[2].body != "Cannot reach the database" || reqs[2].header.Get("Title") != "Agent is stuck" ||
+		reqs[2].header.Get("Priority") != "high" || reqs[2].header.Get("Tags") != "warning" {
+		t.Errorf("ntfy request = %+v", reqs[2])
+	}
+}
+
+func TestSend_Retries(t *testing.T) {
+	srv, requests := webhookServer(t, http.StatusBadGateway, http.StatusTooManyRequests)
+	n := New(config.NotificationsConfig{Retries: 2, Sinks: []config.NotificationSink{{Type: config.SinkWebhook, URL: srv.URL}}})
+	if results := n.Send(context.Background(), stuck); results[0].Err != nil {
+		t.Errorf("Send() error = %v, want success on third attempt", results[0].Err)
+	}
+	if got := len(requests()); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+
+	srv, requests = webhookServer(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	n = New(config.NotificationsConfig{Retries: 1, Sinks: []config.NotificationSink{{Type: config.SinkWebhook, URL: srv.URL}}})
+	if results := n.Send(context.Background(), stuck); results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "HTTP 502") {
+		t.Errorf("Send() error = %v, want HTTP 502 after retries", results[0].Err)
+	}
+	if got := len(requests()); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+
+	// Client errors are not retried
+	srv, requests = webhookServer(t, http.StatusNotFound)
+	n = New(config.NotificationsConfig{Retries: 3, Sinks: []config.NotificationSink{{Type: config.SinkWebhook, URL: srv.URL}}})
+	if results := n.Send(context.Background(), stuck); results[0].Err == nil {
+		t.Error("Send() succeeded on HTTP 404")
+	}
+	if got := len(requests()); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestSend_EventFilter(t *testing.T) {
+	srv, requests := webhookServer(t)
+	n := New(config.NotificationsConfig{Sinks: []config.NotificationSink{
+		{Type: config.SinkWebhook, URL: srv.URL, Events: []string{config.NotifySessionComplete}},
+	}})
+
+	if results := n.Send(context.Background(), stuck); len(results) != 0 {
+		t.Errorf("Send(stuck) = %+v, want no sinks", results)
+	}
+	if results := n.Send(context.Background(), Event{Type: EventTest, Title: "Test"}); len(results) != 1 || results[0].Err != nil {
+		t.Errorf("Send(test) = %+v, want delivered", results)
+	}
+
+	n.Notify(Event{Type: config.NotifySessionComplete, Title: "Done"})
+	n.Wait()
+	if got := len(requests()); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestNew_NoSinks(t *testing.T) {
+	n := New(config.NotificationsConfig{})
+	if n != nil {
+		t.Fatalf("New() = %v, want nil without sinks", n)
+	}
+	n.Notify(stuck)
+	n.Wait()
+	if results := n.Send(context.Background(), stuck); results != nil {
+		t.Errorf("Send() on nil notifier = %v", results)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		sink config.NotificationSink
+		want string
+	}{
+		{config.NotificationSink{Type: config.SinkWebhook, URL: "https://hooks.slack.com/services/T0/B0/secret", Format: config.WebhookSlack}, "webhook slack hooks.slack.com"},
+		{config.NotificationSink{Type: config.SinkWebhook, URL: "http://localhost:8080/hook"}, "webhook json localhost:8080"},
+		{config.NotificationSink{Type: config.SinkEmail, To: []string{"a@example.com", "b@example.com"}}, "email a@example.com, b@example.com"},
+		{config.NotificationSink{Type: config.SinkDesktop}, "desktop"},
+	}
+	for _, tt := range tests {
+		if got := describe(tt.sink); got != tt.want {
+			t.Errorf("describe(%+v) = %q, want %q", tt.sink, got, tt.want)
+		}
+	}
+}
+
+func TestDesktopCommand(t *testing.T) {
+	name, args, err := desktopCommand("darwin", `Build "done"`, "ok")
+	if err != nil || name != "osascript" || args[1] != `display notification "ok" with title "Build \"done\""` {
+		t.Errorf("darwin = %s %q, %v", name, args, err)
+	}
+	name, args, err = desktopCommand("linux", "Build", "ok")
+	if err != nil || name != "notify-send" || args[len(args)-2] != "Build" || args[len(args)-1] != "ok" {
+		t.Errorf("linux = %s %q, %v", name, args, err)
+	}
+	if _, _, err := desktopCommand("windows", "Build", "ok"); err == nil {
+		t.Error("windows: expected unsupported error")
+	}
+}
+
+// smtpServer starts a minimal SMTP stand-in and returns its address and a
+// channel receiving the DATA of each message.
+func smtpServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "DATA":
+				reply("354 end with .")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				messages <- data.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), messages
+}
+
+func TestSend_Email(t *testing.T) {
+	addr, messages := smtpServer(t)
+	n := New(config.NotificationsConfig{Sinks: []config.NotificationSink{
+		{Type: config.SinkEmail, SMTP: addr, From: "iteratr@example.com", To: []string{"dev@example.com"}},
+	}})
+
+	results := n.Send(context.Background(), stuck)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("Send() = %+v", results)
+	}
+	msg := <-messages
+	for _, want := range []string{"To: dev@example.com\r\n", "Subject: [iteratr] Agent is stuck\r\n", "Cannot reach the database", "Session: demo"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+}
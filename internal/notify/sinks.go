@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/config"
+)
+
+// expand replaces $VAR and ${VAR} with environment variables, so secrets
+// can stay out of config files.
+func expand(s string) string {
+	return os.ExpandEnv(s)
+}
+
+// sendWebhook POSTs e to the sink's URL in the sink's format. 4xx responses
+// other than 429 are permanent errors.
+func (n *Notifier) sendWebhook(ctx context.Context, sink config.NotificationSink, e Event) error {
+	body, headers, err := webhookRequest(sink.Format, e)
+	if err != nil {
+		return &permanentError{err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, expand(sink.URL), bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range sink.Headers {
+		req.Header.Set(k, expand(v))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+	err = fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
+
+// webhookRequest returns the body and headers of a webhook request.
+//   - json: the event as JSON
+//   - slack: {"text": ...} as accepted by Slack incoming webhooks (and
+//     Mattermost, Discord's /slack endpoint, ...)
+//   - ntfy: the message as plain text with Title, Tags and Priority headers
+func webhookRequest(format string, e Event) ([]byte, map[string]string, error) {
+	switch format {
+	case config.WebhookSlack:
+		body, err := json.Marshal(map[string]string{"text": fmt.Sprintf("*%s*\n%s", e.Title, e.Message)})
+		return body, map[string]string{"Content-Type": "application/json"}, err
+	case config.WebhookNtfy:
+		headers := map[string]string{
+			"Title":    e.Title,
+			"Tags":     ntfyTag(e.Type),
+			"Priority": "default",
+		}
+		if e.Type == config.NotifyStuck || e.Type == config.NotifyError || e.Type == config.NotifyBudgetExceeded {
+			headers["Priority"] = "high"
+		}
+		return []byte(e.Message), headers, nil
+	case "", config.WebhookJSON:
+		body, err := json.Marshal(e)
+		return body, map[string]string{"Content-Type": "application/json"}, err
+	}
+	return nil, nil, fmt.Errorf("unknown webhook format %q", format)
+}
+
+// ntfyTag returns the ntfy tag (shown as an emoji) for an event type.
+func ntfyTag(event string) string {
+	switch event {
+	case config.NotifySessionComplete:
+		return "white_check_mark"
+	case config.NotifyStuck:
+		return "warning"
+	case config.NotifyError:
+		return "rotating_light"
+	case config.NotifyBudgetExceeded:
+		return "money_with_wings"
+	}
+	return "bell"
+}
+
+// sendDesktop shows e as a desktop notification.
+func sendDesktop(ctx context.Context, e Event) error {
+	name, args, err := desktopCommand(runtime.GOOS, "iteratr: "+e.Title, e.Message)
+	if err != nil {
+		return &permanentError{err}
+	}
+	if output, err := exec.CommandContext(ctx, name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// desktopCommand returns the command showing a desktop notification on goos:
+// notify-send on Linux and BSDs, osascript on macOS.
+func desktopCommand(goos, title, message string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{"--app-name=iteratr", title, message}, nil
+	}
+	return "", nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// sendEmail sends e through the sink's SMTP server, upgrading to TLS when
+// the server supports STARTTLS.
+func sendEmail(ctx context.Context, sink config.NotificationSink, e Event) error {
+	host, _, err := net.SplitHostPort(sink.SMTP)
+	if err != nil {
+		return &permanentError{err}
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", sink.SMTP)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if sink.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", sink.Username, expand(sink.Password), host)); err != nil {
+			return &permanentError{fmt.Errorf("smtp auth: %w", err)}
+		}
+	}
+	if err := c.Mail(sink.From); err != nil {
+		return err
+	}
+	for _, to := range sink.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(emailMessage(sink.From, sink.To, e)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// emailMessage formats e as a plain text email.
+func emailMessage(from string, to []string, e Event) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[iteratr] "+e.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", e.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(e.Message, "\n", "\r\n"))
+	fmt.Fprintf(&b, "\r\n\r\nSession: %s\r\n", e.Session)
+	return []byte(b.String())
+}
@@ -0,0 +1,82 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/tui"
+	"github.com/mark3labs/iteratr/internal/usage"
+)
+
+// recordUsage stores the tokens and cost of a finished prompt on the current
+// iteration. Cost is computed from the pricing table when the agent did not
+// report one.
+func (o *Orchestrator) recordUsage(event agent.FinishEvent) {
+	o.recordIterationUsage(int(o.iteration.Load()), event)
+}
+
+// recordIterationUsage is recordUsage for a given iteration.
+func (o *Orchestrator) recordIterationUsage(iteration int, event agent.FinishEvent) {
+	u := o.pricing.Cost(event.Model, event.Usage)
+	if u.IsZero() {
+		return
+	}
+	if err := o.store.IterationUsage(o.ctx, o.cfg.SessionName, iteration, u); err != nil {
+		logger.Warn("Failed to record usage for iteration #%d: %v", iteration, err)
+	}
+}
+
+// budgetExceeded returns a description of the first budget limit the session
+// has reached, or "" if it is within budget.
+func budgetExceeded(budget config.BudgetConfig, used usage.Usage) string {
+	if budget.MaxTokens > 0 && used.Tokens() >= budget.MaxTokens {
+		return fmt.Sprintf("Token budget of %d reached (%d used)", budget.MaxTokens, used.Tokens())
+	}
+	if budget.MaxCost > 0 && used.Cost >= budget.MaxCost {
+		return fmt.Sprintf("Cost budget of $%.2f reached ($%.2f spent)", budget.MaxCost, used.Cost)
+	}
+	return ""
+}
+
+// checkBudget is called before each iteration. It reports whether the loop
+// should stop because the session is over budget. With on_exceed "pause" the
+// TUI pauses until the user resumes, after which the budget is ignored for
+// the rest of the run. Headless runs always stop. Returns ctx.Err() if the
+// context is cancelled while paused.
+func (o *Orchestrator) checkBudget() (bool, error) {
+	if !o.cfg.Budget.Enabled() || o.budgetOverride {
+		return false, nil
+	}
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load state for budget check: %v", err)
+		return false, nil
+	}
+	reason := budgetExceeded(o.cfg.Budget, state.Usage)
+	if reason == "" {
+		return false, nil
+	}
+
+	if o.cfg.Budget.OnExceed == config.BudgetStop || o.tuiProgram == nil {
+		o.notify(config.NotifyBudgetExceeded, "Budget exceeded", reason+", stopping")
+		logger.Info("%s, stopping", reason)
+		fmt.Printf("%s, stopping\n", reason)
+		return true, nil
+	}
+
+	o.notify(config.NotifyBudgetExceeded, "Budget exceeded", reason+", pausing until resumed")
+	logger.Info("%s, pausing", reason)
+	o.tuiProgram.Send(tui.ShowToastMsg{Text: reason + " - resume to continue"})
+	o.paused.Store(true)
+	if err := o.waitIfPaused(); err != nil {
+		return false, err
+	}
+	if o.stopRequested() {
+		return true, nil
+	}
+	logger.Info("Resumed over budget, budget ignored for the rest of this run")
+	o.budgetOverride = true
+	return false, nil
+}
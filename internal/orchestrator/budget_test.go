@@ -0,0 +1,96 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/usage"
+)
+
+func TestBudgetExceeded(t *testing.T) {
+	used := usage.Usage{InputTokens: 9_000, OutputTokens: 1_000, Cost: 1.25}
+	tests := []struct {
+		name   string
+		budget config.BudgetConfig
+		want   bool
+	}{
+		{"no limits", config.BudgetConfig{}, false},
+		{"under token limit", config.BudgetConfig{MaxTokens: 20_000}, false},
+		{"token limit reached", config.BudgetConfig{MaxTokens: 10_000}, true},
+		{"under cost limit", config.BudgetConfig{MaxCost: 2}, false},
+		{"cost limit reached", config.BudgetConfig{MaxTokens: 20_000, MaxCost: 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := budgetExceeded(tt.budget, used); (got != "") != tt.want {
+				t.Errorf("budgetExceeded() = %q, want exceeded=%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	o.pricing = usage.Pricing{"test-model": {Input: 1, Output: 2}}
+	o.iteration.Store(1)
+	ctx := context.Background()
+	if err := o.store.IterationStart(ctx, "branches", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	o.recordUsage(agent.FinishEvent{Model: "provider/test-model", Usage: usage.Usage{InputTokens: 1_000_000, OutputTokens: 500_000}})
+	o.recordUsage(agent.FinishEvent{Model: "provider/test-model", Usage: usage.Usage{InputTokens: 10, Cost: 0.5}})
+	o.recordUsage(agent.FinishEvent{Model: "provider/test-model"}) // Nothing reported, nothing recorded
+
+	state, err := o.store.LoadState(ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := usage.Usage{InputTokens: 1_000_010, OutputTokens: 500_000, Cost: 2.5}
+	if state.Usage != want || state.Iterations[0].Usage != want {
+		t.Errorf("usage = %+v (iteration %+v), want %+v", state.Usage, state.Iterations[0].Usage, want)
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	ctx := context.Background()
+	if err := o.store.IterationStart(ctx, "branches", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.IterationUsage(ctx, "branches", 1, usage.Usage{InputTokens: 5_000}); err != nil {
+		t.Fatal(err)
+	}
+
+	o.cfg.Budget = config.BudgetConfig{MaxTokens: 10_000, OnExceed: config.BudgetStop}
+	if stop, err := o.checkBudget(); stop || err != nil {
+		t.Fatalf("checkBudget() under budget = %v, %v", stop, err)
+	}
+
+	o.cfg.Budget.MaxTokens = 5_000
+	if stop, err := o.checkBudget(); !stop || err != nil {
+		t.Errorf("checkBudget() over budget with stop = %v, %v; want stop", stop, err)
+	}
+
+	// Pause without a TUI behaves like stop
+	o.cfg.Budget.OnExceed = config.BudgetPause
+	if stop, _ := o.checkBudget(); !stop {
+		t.Error("checkBudget() headless pause should stop")
+	}
+}
+
+func TestCheckBudget_Override(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	o.cfg.Budget = config.BudgetConfig{MaxCost: 1, OnExceed: config.BudgetPause}
+	if err := o.store.IterationUsage(context.Background(), "branches", 0, usage.Usage{Cost: 1.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set once the user resumes after a budget pause
+	o.budgetOverride = true
+	if stop, err := o.checkBudget(); stop || err != nil {
+		t.Errorf("checkBudget() after override = %v, %v; want continue", stop, err)
+	}
+}
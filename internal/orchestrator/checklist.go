@@ -0,0 +1,219 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/spec"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// importChecklist creates tasks for the spec's checklist items that have no
+// matching task yet. Checked items are imported as completed, and priority
+// and depends annotations are applied. Returns the tasks added.
+func (o *Orchestrator) importChecklist(iteration int, specs *spec.Spec) ([]*session.Task, error) {
+	items, err := specs.Checklist()
+	if err != nil {
+		return nil, err
+	}
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	// Checklist ids resolve to task IDs, including tasks imported earlier
+	ids := make(map[string]string)
+	seen := make(map[string]bool)
+	var pending []spec.ChecklistItem
+	var params []session.TaskAddParams
+	for _, item := range items {
+		if task := state.TaskByContent(item.Text); task != nil {
+			if item.ID != "" {
+				ids[item.ID] = task.ID
+			}
+			continue
+		}
+		key := strings.ToLower(item.Text)
+		if seen[key] {
+			logger.Warn("%s:%d: skipping duplicate checklist item %q", item.Path, item.Line, item.Text)
+			continue
+		}
+		seen[key] = true
+
+		p := session.TaskAddParams{Content: item.Text, Iteration: iteration}
+		if item.Checked {
+			p.Status = "completed"
+		}
+		if item.Priority > 0 {
+			p.Priority = item.Priority
+		}
+		pending = append(pending, item)
+		params = append(params, p)
+	}
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	added, err := o.store.TaskBatchAdd(o.ctx, o.cfg.SessionName, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add checklist tasks: %w", err)
+	}
+	for i, item := range pending {
+		if item.ID != "" {
+			ids[item.ID] = added[i].ID
+		}
+	}
+	for i, item := range pending {
+		task := added[i]
+		// TaskBatchAdd treats priority 0 as unset
+		if item.Priority == 0 {
+			if err := o.store.TaskPriority(o.ctx, o.cfg.SessionName, session.TaskPriorityParams{ID: task.ID, Priority: 0, Iteration: iteration}); err != nil {
+				return added, fmt.Errorf("failed to set priority of %s: %w", task.ID, err)
+			}
+		}
+		for _, dep := range item.DependsOn {
+			if ids[dep] == "" {
+				logger.Warn("%s:%d: dependency %q has no task, skipping", item.Path, item.Line, dep)
+				continue
+			}
+			if err := o.store.TaskDepends(o.ctx, o.cfg.SessionName, session.TaskDependsParams{ID: task.ID, DependsOn: ids[dep], Iteration: iteration}); err != nil {
+				return added, fmt.Errorf("failed to add dependency of %s on %s: %w", task.ID, ids[dep], err)
+			}
+		}
+	}
+	logger.Info("Imported %d task(s) from the spec checklist", len(added))
+	return added, nil
+}
+
+// runChecklistImport plans iteration #0 from the spec checklist without
+// running the agent. Returns false if the spec has no checklist items, in
+// which case the agent plans as usual.
+func (o *Orchestrator) runChecklistImport() (bool, error) {
+	specs, err := spec.Load(o.cfg.SpecPaths)
+	if err != nil {
+		return false, fmt.Errorf("failed to load spec: %w", err)
+	}
+	if !hasChecklist(specs) {
+		logger.Info("No checklist items in spec, planning with the agent")
+		return false, nil
+	}
+	added, err := o.importChecklist(0, specs)
+	if err != nil {
+		return false, fmt.Errorf("checklist import failed: %w", err)
+	}
+	if err := o.store.IterationComplete(o.ctx, o.cfg.SessionName, 0); err != nil {
+		return false, fmt.Errorf("failed to log iteration #0 complete: %w", err)
+	}
+	logger.Info("=== Iteration #0 (Planning Phase) completed from checklist ===")
+	o.saveSpecSnapshot(0, specs)
+
+	if o.cfg.Headless {
+		fmt.Printf("\n✓ Iteration #0 (planning) imported %d task(s) from the spec checklist\n\n", len(added))
+	}
+	return true, nil
+}
+
+// syncChecklist brings the task list in line with an edited spec checklist:
+// new items are imported and open tasks whose item was removed since the
+// snapshot are cancelled.
+func (o *Orchestrator) syncChecklist(iteration int, snapshot *session.SpecSnapshot, specs *spec.Spec) error {
+	if _, err := o.importChecklist(iteration, specs); err != nil {
+		return err
+	}
+
+	items, err := specs.Checklist()
+	if err != nil {
+		return err
+	}
+	current := make(map[string]bool, len(items))
+	for _, item := range items {
+		current[strings.ToLower(item.Text)] = true
+	}
+	// The snapshot was valid when recorded; ignore annotation errors
+	old, _ := spec.ParseChecklist("spec", snapshot.Content)
+
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	for _, item := range old {
+		if current[strings.ToLower(item.Text)] {
+			continue
+		}
+		task := state.TaskByContent(item.Text)
+		if task == nil || task.Status == "completed" || task.Status == "cancelled" {
+			continue
+		}
+		if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: "cancelled", Iteration: iteration}); err != nil {
+			return fmt.Errorf("failed to cancel %s: %w", task.ID, err)
+		}
+	}
+	return nil
+}
+
+// hasChecklist reports whether specs contain any checklist items.
+func hasChecklist(specs *spec.Spec) bool {
+	items, err := specs.Checklist()
+	return err == nil && len(items) > 0
+}
+
+// subscribeChecklistWriteBack ticks the spec checklist item matching each
+// task marked completed. Returns nil if the subscription failed.
+func (o *Orchestrator) subscribeChecklistWriteBack() *natsgo.Subscription {
+	subject := fmt.Sprintf("iteratr.%s.task", o.cfg.SessionName)
+	sub, err := o.nc.Subscribe(subject, func(msg *natsgo.Msg) {
+		var event struct {
+			Action string `json:"action"`
+			Meta   struct {
+				TaskID string `json:"task_id"`
+				Status string `json:"status"`
+			} `json:"meta"`
+		}
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			logger.Warn("Failed to parse task event for checklist write-back: %v", err)
+			return
+		}
+		if event.Action != "status" || event.Meta.Status != "completed" {
+			return
+		}
+		state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+		if err != nil {
+			logger.Warn("Failed to load state for checklist write-back: %v", err)
+			return
+		}
+		task, exists := state.Tasks[event.Meta.TaskID]
+		if !exists {
+			return
+		}
+		o.tickChecklist(task)
+	})
+	if err != nil {
+		logger.Warn("Failed to subscribe to task events for checklist write-back: %v", err)
+		return nil
+	}
+	return sub
+}
+
+// tickChecklist marks the spec checklist items matching task as checked.
+func (o *Orchestrator) tickChecklist(task *session.Task) {
+	specs, err := spec.Load(o.cfg.SpecPaths)
+	if err != nil {
+		logger.Warn("Failed to load spec for checklist write-back: %v", err)
+		return
+	}
+	content := strings.TrimSpace(task.Content)
+	done := func(text string) bool { return strings.EqualFold(text, content) }
+	for _, path := range specs.Sources {
+		n, err := spec.TickFile(path, done)
+		if err != nil {
+			logger.Warn("Failed to tick checklist in %s: %v", path, err)
+			continue
+		}
+		if n > 0 {
+			logger.Info("Task %s completed, ticked %d checklist item(s) in %s", task.ID, n, path)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRunIteration0_ImportsChecklist(t *testing.T) {
+	o, existing := setupGitSessionTest(t)
+	out := startFlakyRunner(t, o, 0)
+	specPath := filepath.Join(o.cfg.WorkDir, "SPEC.md")
+	content := "# Shop\n" +
+		"- [ ] Set up the database {id=db priority=critical}\n" +
+		"- [ ] Add login form {id=login}\n" +
+		"- [ ] Add signup form {depends=db,login priority=low}\n" +
+		"- [x] Write the README\n"
+	if err := os.WriteFile(specPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o.cfg.SpecPaths = []string{specPath}
+	o.cfg.Checklist.Import = true
+
+	if err := o.runIteration0(); err != nil {
+		t.Fatalf("runIteration0() error = %v", err)
+	}
+	if prompts := countEntries(t, filepath.Join(out, "prompts"), "prompt"); prompts != 0 {
+		t.Errorf("agent prompted %d times, want 0", prompts)
+	}
+
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Tasks) != 4 {
+		t.Fatalf("got %d tasks, want the existing task and 3 imported", len(state.Tasks))
+	}
+	db := state.TaskByContent("Set up the database")
+	signup := state.TaskByContent("Add signup form")
+	readme := state.TaskByContent("Write the README")
+	if db == nil || signup == nil || readme == nil {
+		t.Fatalf("missing imported tasks: %+v", state.Tasks)
+	}
+	if db.Priority != 0 || signup.Priority != 3 {
+		t.Errorf("priorities = %d, %d; want 0, 3", db.Priority, signup.Priority)
+	}
+	if want := []string{db.ID, existing.ID}; !reflect.DeepEqual(signup.DependsOn, want) {
+		t.Errorf("signup depends on %v, want %v", signup.DependsOn, want)
+	}
+	if readme.Status != "completed" {
+		t.Errorf("checked item status = %q, want completed", readme.Status)
+	}
+	if len(state.Iterations) != 1 || !state.Iterations[0].Complete {
+		t.Errorf("iteration #0 not recorded as complete: %+v", state.Iterations)
+	}
+	if state.Spec == nil || state.Spec.Iteration != 0 {
+		t.Errorf("spec snapshot = %+v, want one from iteration #0", state.Spec)
+	}
+
+	// An edited checklist is synced without re-planning with the agent
+	edited := "# Shop\n" +
+		"- [ ] Set up the database {id=db priority=critical}\n" +
+		"- [ ] Add login form {id=login}\n" +
+		"- [x] Write the README\n" +
+		"- [ ] Add checkout {depends=db}\n"
+	if err := os.WriteFile(specPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.checkSpecChange(1); err != nil {
+		t.Fatalf("checkSpecChange() error = %v", err)
+	}
+	if prompts := countEntries(t, filepath.Join(out, "prompts"), "prompt"); prompts != 0 {
+		t.Errorf("agent prompted %d times after a checklist edit, want 0", prompts)
+	}
+	state, err = o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task := state.TaskByContent("Add signup form"); task == nil || task.Status != "cancelled" {
+		t.Errorf("removed item's task = %+v, want cancelled", task)
+	}
+	if task := state.TaskByContent("Add checkout"); task == nil || !reflect.DeepEqual(task.DependsOn, []string{db.ID}) {
+		t.Errorf("added item's task = %+v, want it depending on %s", task, db.ID)
+	}
+}
+
+func TestTickChecklist(t *testing.T) {
+	o, task := setupGitSessionTest(t)
+	specPath := filepath.Join(o.cfg.WorkDir, "SPEC.md")
+	if err := os.WriteFile(specPath, []byte("- [ ] add login form {id=login}\n- [ ] Add checkout\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o.cfg.SpecPaths = []string{specPath}
+
+	o.tickChecklist(task)
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "- [x] add login form {id=login}\n- [ ] Add checkout\n"; string(data) != want {
+		t.Errorf("spec after tickChecklist() = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,193 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// CheckpointRef returns the git ref holding the working tree snapshot taken
+// when an iteration of a session started.
+func CheckpointRef(sessionName string, iteration int) string {
+	return fmt.Sprintf("%s%d", checkpointPrefix(sessionName), iteration)
+}
+
+// checkpointPrefix returns the ref namespace holding a session's checkpoints.
+func checkpointPrefix(sessionName string) string {
+	return "refs/iteratr/checkpoints/" + sessionName + "/"
+}
+
+// RenameCheckpoints moves a session's checkpoints to a new session name so
+// rollback keeps working after a rename. No-op outside a git repository.
+func RenameCheckpoints(workDir, from, to string) (int, error) {
+	if !isGitRepo(workDir) {
+		return 0, nil
+	}
+	return git.MoveRefs(workDir, checkpointPrefix(from), checkpointPrefix(to))
+}
+
+// DeleteCheckpoints removes a session's checkpoints. No-op outside a git repository.
+func DeleteCheckpoints(workDir, sessionName string) (int, error) {
+	if !isGitRepo(workDir) {
+		return 0, nil
+	}
+	return git.DeleteRefs(workDir, checkpointPrefix(sessionName))
+}
+
+// checkpointExcludes returns the paths (relative to workDir) left out of
+// checkpoints: the data dir, which holds the live event store.
+func checkpointExcludes(workDir, dataDir string) []string {
+	if !filepath.IsAbs(dataDir) {
+		return []string{filepath.Clean(dataDir)}
+	}
+	rel, err := filepath.Rel(workDir, dataDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil // Outside the work dir
+	}
+	return []string{rel}
+}
+
+// checkpoint snapshots the work dir at the start of an iteration so the
+// session can later be rolled back to it. No-op outside a git repository.
+func (o *Orchestrator) checkpoint(iteration int) {
+	if !isGitRepo(o.cfg.WorkDir) {
+		return
+	}
+	ref := CheckpointRef(o.cfg.SessionName, iteration)
+	message := fmt.Sprintf("iteratr checkpoint: %s iteration #%d", o.cfg.SessionName, iteration)
+	sha, err := git.CreateCheckpoint(o.cfg.WorkDir, ref, message, checkpointExcludes(o.cfg.WorkDir, o.cfg.DataDir))
+	if err != nil {
+		logger.Warn("Failed to checkpoint iteration #%d: %v", iteration, err)
+		return
+	}
+	logger.Debug("Checkpoint for iteration #%d: %s", iteration, sha)
+}
+
+// RollbackOptions configures Rollback.
+type RollbackOptions struct {
+	Session   string // Session name
+	WorkDir   string // Working directory the checkpoints were taken in
+	DataDir   string // Data directory (never touched by the file restore)
+	To        int    // Iteration to roll back to (its end state is restored)
+	KeepFiles bool   // Only rewind the session events, leave files alone
+}
+
+// RollbackResult describes a completed rollback.
+type RollbackResult struct {
+	State      *session.State // Session state after the rollback
+	Checkpoint string         // Restored checkpoint ref (empty if files were kept)
+}
+
+// Rollback undoes every iteration after opts.To. The working tree is restored
+// from the checkpoint taken when the following iteration started, then
+// compensating events are appended so the session state matches that point.
+func Rollback(ctx context.Context, store *session.Store, opts RollbackOptions) (*RollbackResult, error) {
+	state, err := store.LoadState(ctx, opts.Session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session state: %w", err)
+	}
+	found := false
+	for _, iter := range state.Iterations {
+		found = found || iter.Number == opts.To
+	}
+	if !found {
+		return nil, fmt.Errorf("iteration #%d not found in session %s", opts.To, opts.Session)
+	}
+	next := state.NextIteration(opts.To)
+	if next == nil {
+		return nil, fmt.Errorf("nothing to roll back: iteration #%d is the latest iteration", opts.To)
+	}
+
+	if err := store.CheckRollback(ctx, opts.Session, opts.To); err != nil {
+		return nil, err
+	}
+
+	result := &RollbackResult{}
+	if !opts.KeepFiles {
+		if !isGitRepo(opts.WorkDir) {
+			return nil, fmt.Errorf("%s is not a git repository, files cannot be restored", opts.WorkDir)
+		}
+		ref := CheckpointRef(opts.Session, next.Number)
+		if err := git.RestoreCheckpoint(opts.WorkDir, ref, checkpointExcludes(opts.WorkDir, opts.DataDir)); err != nil {
+			return nil, fmt.Errorf("failed to restore files: %w", err)
+		}
+		result.Checkpoint = ref
+	}
+
+	if result.State, err = store.Rollback(ctx, opts.Session, opts.To); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RequestRollback queues a rollback to the given iteration. It runs once the
+// current iteration has finished (immediately if the loop is paused).
+func (o *Orchestrator) RequestRollback(to int) {
+	logger.Debug("Rollback to iteration #%d requested", to)
+	select {
+	case o.rollbackChan <- to:
+	default:
+		logger.Warn("Rollback already pending, ignoring request for iteration #%d", to)
+	}
+}
+
+// applyPendingRollback runs a queued rollback, if any.
+func (o *Orchestrator) applyPendingRollback() {
+	select {
+	case to := <-o.rollbackChan:
+		o.rollback(to)
+	default:
+	}
+}
+
+// rollback rolls the session back to iteration `to` from within the loop and
+// records the target so the next iteration continues after it.
+func (o *Orchestrator) rollback(to int) {
+	logger.Info("Rolling back session '%s' to iteration #%d", o.cfg.SessionName, to)
+	result, err := Rollback(o.ctx, o.store, RollbackOptions{
+		Session: o.cfg.SessionName,
+		WorkDir: o.cfg.WorkDir,
+		DataDir: o.cfg.DataDir,
+		To:      to,
+	})
+	if err != nil {
+		logger.Error("Rollback to iteration #%d failed: %v", to, err)
+		if o.tuiProgram != nil {
+			o.tuiProgram.Send(tui.ShowToastMsg{Text: "Rollback failed: " + err.Error()})
+		}
+		if o.cfg.Headless {
+			fmt.Printf("[rollback] failed: %v\n", err)
+		}
+		return
+	}
+
+	o.fileTracker.Clear()
+	if o.fileWatcher != nil {
+		o.fileWatcher.Clear()
+	}
+	o.rolledBackTo = &to
+
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.StateUpdateMsg{State: result.State})
+		o.tuiProgram.Send(tui.ShowToastMsg{Text: fmt.Sprintf("Rolled back to iteration #%d", to)})
+	}
+	if o.cfg.Headless {
+		fmt.Printf("[rollback] restored iteration #%d\n", to)
+	}
+}
+
+// takeRollback returns and clears the iteration the loop was rolled back to.
+func (o *Orchestrator) takeRollback() (int, bool) {
+	if o.rolledBackTo == nil {
+		return 0, false
+	}
+	to := *o.rolledBackTo
+	o.rolledBackTo = nil
+	return to, true
+}
@@ -0,0 +1,207 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestCheckpointExcludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		workDir string
+		dataDir string
+		want    []string
+	}{
+		{"relative data dir", "/repo", ".iteratr", []string{".iteratr"}},
+		{"relative data dir is cleaned", "/repo", "./state/../.iteratr/", []string{".iteratr"}},
+		{"absolute data dir inside work dir", "/repo", "/repo/var/iteratr", []string{"var/iteratr"}},
+		{"absolute data dir outside work dir", "/repo", "/tmp/iteratr", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkpointExcludes(tt.workDir, tt.dataDir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkpointExcludes(%q, %q) = %v, want %v", tt.workDir, tt.dataDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollback_RestoresFilesAndState(t *testing.T) {
+	o, task := setupGitSessionTest(t)
+	ctx := context.Background()
+	dir := o.cfg.WorkDir
+	store := o.store
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		must(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	// Iteration 1 writes a file and completes the task
+	must(store.IterationStart(ctx, "branches", 1))
+	o.checkpoint(1)
+	write("login.go", "package login\n")
+	must(store.TaskStatus(ctx, "branches", session.TaskStatusParams{ID: task.ID, Status: "completed", Iteration: 1}))
+	must(store.IterationComplete(ctx, "branches", 1))
+
+	// Iteration 2 commits, edits, and adds a task
+	must(store.IterationStart(ctx, "branches", 2))
+	o.checkpoint(2)
+	commitIn(t, dir, "extra.go")
+	write("login.go", "package login // broken\n")
+	_, err := store.TaskAdd(ctx, "branches", session.TaskAddParams{Content: "Fix login", Iteration: 2})
+	must(err)
+	must(store.IterationComplete(ctx, "branches", 2))
+
+	if _, err := os.Stat(filepath.Join(dir, ".git", "refs", "iteratr", "checkpoints", "branches", "2")); err != nil {
+		t.Fatalf("checkpoint ref for iteration #2 missing: %v", err)
+	}
+
+	result, err := Rollback(ctx, store, RollbackOptions{Session: "branches", WorkDir: dir, DataDir: ".iteratr", To: 1})
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if result.Checkpoint != CheckpointRef("branches", 2) {
+		t.Errorf("Checkpoint = %q, want %q", result.Checkpoint, CheckpointRef("branches", 2))
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "login.go")); string(data) != "package login\n" {
+		t.Errorf("login.go = %q, want iteration #1 content", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "extra.go")); !os.IsNotExist(err) {
+		t.Errorf("extra.go should be gone after rollback, stat err = %v", err)
+	}
+
+	state, err := store.LoadState(ctx, "branches")
+	must(err)
+	if len(state.Tasks) != 1 || state.Tasks[task.ID].Status != "completed" {
+		t.Errorf("tasks after rollback = %+v, want only %s completed", state.Tasks, task.ID)
+	}
+	if len(state.Iterations) != 1 || state.Iterations[0].Number != 1 {
+		t.Errorf("iterations after rollback = %d, want only #1", len(state.Iterations))
+	}
+}
+
+func TestRollback_Errors(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	ctx := context.Background()
+	if err := o.store.IterationStart(ctx, "branches", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := RollbackOptions{Session: "branches", WorkDir: o.cfg.WorkDir, DataDir: ".iteratr"}
+	for _, to := range []int{1, 5} {
+		opts.To = to
+		if _, err := Rollback(ctx, o.store, opts); err == nil {
+			t.Errorf("Rollback(to=%d) expected error", to)
+		}
+	}
+
+	// Files cannot be restored without a checkpoint
+	if err := o.store.IterationStart(ctx, "branches", 2); err != nil {
+		t.Fatal(err)
+	}
+	opts.To = 1
+	if _, err := Rollback(ctx, o.store, opts); err == nil {
+		t.Error("Rollback() without checkpoint expected error")
+	}
+	opts.KeepFiles = true
+	if _, err := Rollback(ctx, o.store, opts); err != nil {
+		t.Errorf("Rollback(KeepFiles) error = %v", err)
+	}
+}
+
+func TestRollback_ParallelSessionLeavesFiles(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	ctx := context.Background()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two workers' iterations overlap
+	must(o.store.IterationStart(ctx, "branches", 1))
+	o.checkpoint(1)
+	must(o.store.IterationStart(ctx, "branches", 2))
+	o.checkpoint(2)
+	commitIn(t, o.cfg.WorkDir, "signup.go")
+	must(o.store.IterationComplete(ctx, "branches", 1))
+	must(o.store.IterationComplete(ctx, "branches", 2))
+
+	_, err := Rollback(ctx, o.store, RollbackOptions{Session: "branches", WorkDir: o.cfg.WorkDir, DataDir: ".iteratr", To: 1})
+	if err == nil || !strings.Contains(err.Error(), "parallel session") {
+		t.Fatalf("Rollback() error = %v, want parallel sessions refused", err)
+	}
+	if _, err := os.Stat(filepath.Join(o.cfg.WorkDir, "signup.go")); err != nil {
+		t.Errorf("files restored although the rollback was refused: %v", err)
+	}
+}
+
+func TestRequestRollback(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	o.rollbackChan = make(chan int, 1)
+	ctx := context.Background()
+	for i := 1; i <= 2; i++ {
+		if err := o.store.IterationStart(ctx, "branches", i); err != nil {
+			t.Fatal(err)
+		}
+		o.checkpoint(i)
+	}
+
+	o.RequestRollback(1)
+	o.RequestRollback(0) // Dropped: one rollback is already pending
+
+	if _, ok := o.takeRollback(); ok {
+		t.Fatal("takeRollback() before the rollback was applied")
+	}
+	o.applyPendingRollback()
+	to, ok := o.takeRollback()
+	if !ok || to != 1 {
+		t.Errorf("takeRollback() = %d, %v; want 1, true", to, ok)
+	}
+	if _, ok := o.takeRollback(); ok {
+		t.Error("takeRollback() should clear the target")
+	}
+	select {
+	case to := <-o.rollbackChan:
+		t.Errorf("unexpected pending rollback to #%d", to)
+	default:
+	}
+}
+
+func TestRenameAndDeleteCheckpoints(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	dir := o.cfg.WorkDir
+	o.checkpoint(1)
+	o.checkpoint(2)
+
+	if n, err := RenameCheckpoints(dir, "branches", "renamed"); err != nil || n != 2 {
+		t.Fatalf("RenameCheckpoints() = %d, %v; want 2", n, err)
+	}
+	if refs, _ := git.ListRefs(dir, checkpointPrefix("branches")); len(refs) != 0 {
+		t.Errorf("old checkpoints left behind: %v", refs)
+	}
+	if n, err := DeleteCheckpoints(dir, "renamed"); err != nil || n != 2 {
+		t.Fatalf("DeleteCheckpoints() = %d, %v; want 2", n, err)
+	}
+
+	// Outside a repository there is nothing to do
+	if n, err := DeleteCheckpoints(t.TempDir(), "renamed"); err != nil || n != 0 {
+		t.Errorf("DeleteCheckpoints() outside repo = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,182 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// DefaultCommitMessage is the auto-commit message template used when
+// commit.message is not configured.
+const DefaultCommitMessage = "{{subject}}\n\n{{summary}}"
+
+// commitVars holds the values substituted into commit message and trailer templates.
+type commitVars struct {
+	Session   string // Session name
+	Iteration int    // Iteration number
+	TaskID    string // ID of the task worked on (empty if none)
+	Task      string // First line of the task content
+	Summary   string // Iteration summary recorded by the agent
+	Branch    string // Task branch (task branch mode only)
+}
+
+// subject returns "<task id>: <task>" or "Iteration #N" when no task is known.
+func (v commitVars) subject() string {
+	switch {
+	case v.TaskID != "" && v.Task != "":
+		return v.TaskID + ": " + v.Task
+	case v.Task != "":
+		return v.Task
+	case v.Summary != "":
+		subject, _, _ := strings.Cut(v.Summary, "\n")
+		return subject
+	}
+	return fmt.Sprintf("Iteration #%d", v.Iteration)
+}
+
+// renderCommitTemplate replaces {{variable}} placeholders in a commit template.
+// Supports {{session}}, {{iteration}}, {{task_id}}, {{task}}, {{summary}},
+// {{branch}}, and {{subject}}.
+func renderCommitTemplate(tmpl string, vars commitVars) string {
+	summary := vars.Summary
+	if first, _, _ := strings.Cut(summary, "\n"); first == vars.subject() {
+		// Avoid repeating a one-line summary that already serves as the subject
+		summary = strings.TrimSpace(strings.TrimPrefix(summary, vars.subject()))
+	}
+	r := strings.NewReplacer(
+		"{{session}}", vars.Session,
+		"{{iteration}}", strconv.Itoa(vars.Iteration),
+		"{{task_id}}", vars.TaskID,
+		"{{task}}", vars.Task,
+		"{{summary}}", summary,
+		"{{branch}}", vars.Branch,
+		"{{subject}}", vars.subject(),
+	)
+	return r.Replace(tmpl)
+}
+
+// renderCommitMessage renders the message template and trailers.
+// Trailers whose value renders empty are dropped.
+func renderCommitMessage(tmpl string, trailers []string, vars commitVars) (string, []string) {
+	if tmpl == "" {
+		tmpl = DefaultCommitMessage
+	}
+	message := strings.TrimSpace(renderCommitTemplate(tmpl, vars))
+
+	var rendered []string
+	for _, t := range trailers {
+		t = strings.TrimSpace(renderCommitTemplate(t, vars))
+		if key, value, ok := strings.Cut(t, ":"); ok && key != "" && strings.TrimSpace(value) != "" {
+			rendered = append(rendered, t)
+		}
+	}
+	return message, rendered
+}
+
+// commitIteration stages the files modified during the iteration and commits
+// them through git, then records the commit SHA on the iteration.
+func (o *Orchestrator) commitIteration(ctx context.Context, iteration int) error {
+	vars := o.commitVars(ctx, iteration)
+	message, trailers := renderCommitMessage(o.cfg.Commit.Message, o.cfg.Commit.Trailers, vars)
+
+	sha, err := git.Commit(o.iterationDir(), git.CommitOptions{
+		Paths:    o.commitPaths(),
+		Message:  message,
+		Trailers: trailers,
+	})
+	if err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+	if sha == "" {
+		logger.Info("Auto-commit: nothing to commit")
+		return nil
+	}
+
+	logger.Info("Auto-commit created %s", sha)
+	if o.cfg.Headless {
+		subject, _, _ := strings.Cut(message, "\n")
+		fmt.Printf("[commit] %s %s\n", sha[:min(7, len(sha))], subject)
+	}
+	if err := o.store.IterationCommit(ctx, o.cfg.SessionName, iteration, sha); err != nil {
+		logger.Warn("Failed to record commit for iteration #%d: %v", iteration, err)
+	}
+	return nil
+}
+
+// commitPaths returns the paths to commit, relative to the work dir.
+// The data dir is excluded unless commit_data_dir is set. In worktree mode
+// nil is returned so every change in the task worktree is committed.
+func (o *Orchestrator) commitPaths() []string {
+	if tb := o.activeBranch.Load(); tb != nil && tb.dir != "" {
+		return nil
+	}
+
+	dataDir := filepath.Clean(o.cfg.DataDir)
+	if filepath.IsAbs(dataDir) {
+		if rel, err := filepath.Rel(o.cfg.WorkDir, dataDir); err == nil {
+			dataDir = rel
+		}
+	}
+
+	paths := []string{}
+	for _, p := range o.fileTracker.ModifiedPaths() {
+		if filepath.IsAbs(p) || p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+			continue // Outside the work dir
+		}
+		if p == dataDir || strings.HasPrefix(p, dataDir+string(filepath.Separator)) {
+			continue // Data dir is handled below
+		}
+		paths = append(paths, p)
+	}
+	if o.cfg.CommitDataDir {
+		paths = append(paths, dataDir)
+	}
+	return paths
+}
+
+// commitVars gathers the template values for an iteration's commit.
+// The task is the active task branch's task, otherwise the first task
+// worked in the iteration summary, otherwise the in-progress task.
+func (o *Orchestrator) commitVars(ctx context.Context, iteration int) commitVars {
+	vars := commitVars{Session: o.cfg.SessionName, Iteration: iteration}
+	tb := o.activeBranch.Load()
+	if tb != nil {
+		vars.Branch = tb.branch
+	}
+
+	state, err := o.store.LoadState(ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load session state for commit message: %v", err)
+		return vars
+	}
+
+	var task *session.Task
+	if tb != nil {
+		task = state.Tasks[tb.taskID]
+	}
+	for i := len(state.Iterations) - 1; i >= 0; i-- {
+		iter := state.Iterations[i]
+		if iter.Number != iteration {
+			continue
+		}
+		vars.Summary = strings.TrimSpace(iter.Summary)
+		if task == nil && len(iter.TasksWorked) > 0 {
+			task = state.Tasks[iter.TasksWorked[0]]
+		}
+		break
+	}
+	if task == nil {
+		task = state.InProgressTask()
+	}
+	if task != nil {
+		vars.TaskID = task.ID
+		vars.Task = task.Title()
+	}
+	return vars
+}
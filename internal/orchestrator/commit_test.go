@@ -0,0 +1,151 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
+)
+
+func TestRenderCommitMessage(t *testing.T) {
+	vars := commitVars{
+		Session:   "auth",
+		Iteration: 4,
+		TaskID:    "TAS-2",
+		Task:      "Add login form",
+		Summary:   "Added form and validation",
+	}
+
+	tests := []struct {
+		name         string
+		tmpl         string
+		trailers     []string
+		vars         commitVars
+		wantMessage  string
+		wantTrailers []string
+	}{
+		{
+			name:        "default template",
+			vars:        vars,
+			wantMessage: "TAS-2: Add login form\n\nAdded form and validation",
+		},
+		{
+			name:         "custom template with trailers",
+			tmpl:         "feat({{session}}): {{task}} [#{{iteration}}]",
+			trailers:     []string{"Iteratr-Task: {{task_id}}", "Iteratr-Branch: {{branch}}", "not a trailer"},
+			vars:         vars,
+			wantMessage:  "feat(auth): Add login form [#4]",
+			wantTrailers: []string{"Iteratr-Task: TAS-2"},
+		},
+		{
+			name:        "no task falls back to summary",
+			vars:        commitVars{Iteration: 2, Summary: "Refactored config loading"},
+			wantMessage: "Refactored config loading",
+		},
+		{
+			name:        "nothing known",
+			vars:        commitVars{Iteration: 7},
+			wantMessage: "Iteration #7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, trailers := renderCommitMessage(tt.tmpl, tt.trailers, tt.vars)
+			if msg != tt.wantMessage {
+				t.Errorf("message = %q, want %q", msg, tt.wantMessage)
+			}
+			if strings.Join(trailers, "|") != strings.Join(tt.wantTrailers, "|") {
+				t.Errorf("trailers = %v, want %v", trailers, tt.wantTrailers)
+			}
+		})
+	}
+}
+
+func TestCommitIteration(t *testing.T) {
+	o, task := setupGitSessionTest(t)
+	ctx := context.Background()
+	dir := o.cfg.WorkDir
+	o.cfg.Commit = config.CommitConfig{
+		Mode:     config.CommitModeIteratr,
+		Trailers: []string{"Iteratr-Session: {{session}}"},
+	}
+
+	if err := o.store.IterationStart(ctx, "branches", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.IterationSummary(ctx, "branches", 1, "Built the form", []string{task.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tracked file is committed, untracked-but-unreported file is not
+	for _, name := range []string{"login.go", "scratch.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	o.fileTracker.RecordChange(filepath.Join(dir, "login.go"), true, 1, 0)
+	o.fileTracker.RecordChange(filepath.Join(dir, ".iteratr", "data.db"), true, 1, 0)
+
+	if err := o.runAutoCommit(ctx, 1); err != nil {
+		t.Fatalf("runAutoCommit() error = %v", err)
+	}
+
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if files := git("show", "--name-only", "--format=", "HEAD"); files != "login.go" {
+		t.Errorf("committed files = %q, want login.go", files)
+	}
+	wantMsg := "TAS-1: Add login form\n\nBuilt the form\n\nIteratr-Session: branches"
+	if msg := git("log", "-1", "--format=%B"); msg != wantMsg {
+		t.Errorf("commit message = %q, want %q", msg, wantMsg)
+	}
+
+	state, err := o.store.LoadState(ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha := git("rev-parse", "HEAD"); state.Iterations[0].CommitSHA != sha {
+		t.Errorf("iteration CommitSHA = %q, want %q", state.Iterations[0].CommitSHA, sha)
+	}
+
+	// Nothing new to commit is not an error and records nothing
+	head := git("rev-parse", "HEAD")
+	if err := o.commitIteration(ctx, 1); err != nil {
+		t.Fatalf("commitIteration() error = %v", err)
+	}
+	if git("rev-parse", "HEAD") != head {
+		t.Error("empty auto-commit created a commit")
+	}
+}
+
+func TestCommitPaths(t *testing.T) {
+	dir := t.TempDir()
+	o := &Orchestrator{
+		cfg:         Config{WorkDir: dir, DataDir: ".iteratr"},
+		fileTracker: agent.NewFileTracker(dir),
+	}
+	for _, p := range []string{"a.go", ".iteratr/data.db", "../outside.go"} {
+		o.fileTracker.RecordChange(filepath.Join(dir, p), false, 1, 0)
+	}
+
+	if got := strings.Join(o.commitPaths(), ","); got != "a.go" {
+		t.Errorf("commitPaths() = %q, want a.go", got)
+	}
+	o.cfg.CommitDataDir = true
+	if got := strings.Join(o.commitPaths(), ","); got != "a.go,.iteratr" {
+		t.Errorf("commitPaths() with commit_data_dir = %q, want a.go,.iteratr", got)
+	}
+}
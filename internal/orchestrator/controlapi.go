@@ -0,0 +1,215 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/controlapi"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// errTaskSkipped is the cancellation cause of an agent run whose task was
+// skipped through the control API.
+var errTaskSkipped = errors.New("task skipped")
+
+// apiController implements controlapi.Controller for the orchestrator.
+type apiController struct {
+	o *Orchestrator
+}
+
+var _ controlapi.Controller = apiController{}
+
+// startAPI starts the control API when an address is configured.
+func (o *Orchestrator) startAPI() error {
+	if o.cfg.API.Addr == "" {
+		return nil
+	}
+	o.api = controlapi.New(apiController{o}, o.cfg.API.Addr, o.cfg.API.Token)
+	url, err := o.api.Start(o.ctx)
+	if err != nil {
+		o.api = nil
+		return err
+	}
+	logger.Info("Control API listening on %s", url)
+	if o.cfg.Headless {
+		fmt.Printf("Control API listening on %s\n", url)
+	}
+	return nil
+}
+
+// apiNotice reports something done through the control API as a toast, or
+// on stdout in headless mode.
+func (o *Orchestrator) apiNotice(format string, args ...any) {
+	msg := "[control API] " + fmt.Sprintf(format, args...)
+	logger.Info("%s", msg)
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.ShowToastMsg{Text: msg})
+	} else {
+		fmt.Println(msg)
+	}
+}
+
+// Pause pauses the loop after the current iteration.
+func (c apiController) Pause() {
+	o := c.o
+	if o.paused.Load() {
+		return
+	}
+	o.RequestPause()
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.PauseStateMsg{Paused: true})
+	}
+	o.apiNotice("pausing after the current iteration")
+}
+
+// Resume resumes a paused loop, or cancels a pending pause.
+func (c apiController) Resume() {
+	o := c.o
+	if !o.paused.Load() {
+		return
+	}
+	o.Resume()
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.PauseStateMsg{Paused: false})
+	}
+	o.apiNotice("resumed")
+}
+
+// Stop stops the loop before the next iteration (unblocking a pause), or
+// right away when now is set.
+func (c apiController) Stop(reason string, now bool) {
+	o := c.o
+	o.requestStop("control API", reason)
+	if now {
+		o.apiNotice("stopping now")
+		o.cancel()
+		return
+	}
+	o.apiNotice("stopping after the current iteration")
+}
+
+// SendMessage queues a message for the agent, delivered after the current
+// iteration like messages typed in the TUI (which shows it as queued). In
+// parallel mode it goes to the running workers' agents. Fails once the
+// session is stopping, since no agent may be left to read it.
+func (c apiController) SendMessage(text string) error {
+	o := c.o
+	if o.ctx.Err() != nil || o.stopRequest.Load() != nil || o.loopDone.Load() {
+		return fmt.Errorf("%w: the session is stopping, the message would not be delivered", controlapi.ErrConflict)
+	}
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.UserInputMsg{Text: text})
+		return nil
+	}
+	select {
+	case o.sendChan <- text:
+		o.apiNotice("message queued")
+		return nil
+	default:
+		return fmt.Errorf("%w: message queue is full", controlapi.ErrConflict)
+	}
+}
+
+// AddTask adds a task in the current iteration.
+func (c apiController) AddTask(params session.TaskAddParams) (*session.Task, error) {
+	o := c.o
+	params.Status = ""
+	params.Iteration = int(o.iteration.Load())
+	task, err := o.store.TaskAdd(o.ctx, o.cfg.SessionName, params)
+	if err != nil {
+		return nil, err
+	}
+	o.apiNotice("added %s", task.ID)
+	return task, nil
+}
+
+// SkipTask blocks a task (the one in progress when id is empty), records the
+// decision as a note, and cancels the agent run working on it so the loop
+// moves on.
+func (c apiController) SkipTask(id, reason string) (*session.Task, error) {
+	o := c.o
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	var task *session.Task
+	if id == "" {
+		var current []*session.Task
+		for _, t := range state.Tasks {
+			if t.Status == "in_progress" {
+				current = append(current, t)
+			}
+		}
+		switch len(current) {
+		case 0:
+			return nil, fmt.Errorf("%w: no task in progress", controlapi.ErrConflict)
+		case 1:
+			task = current[0]
+		default:
+			return nil, fmt.Errorf("%w: %d tasks in progress, name the one to skip", controlapi.ErrConflict, len(current))
+		}
+	} else if task, err = state.FindTask(id); err != nil {
+		return nil, fmt.Errorf("%w: %v", controlapi.ErrConflict, err)
+	}
+	if task.Status == "completed" || task.Status == "cancelled" {
+		return nil, fmt.Errorf("%w: %s is already %s", controlapi.ErrConflict, task.ID, task.Status)
+	}
+
+	iteration := int(o.iteration.Load())
+	wasRunning := task.Status == "in_progress"
+	if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: "blocked", Iteration: iteration}); err != nil {
+		return nil, err
+	}
+	note := fmt.Sprintf("%s was skipped through the control API", task.ID)
+	if reason != "" {
+		note += ": " + reason
+	}
+	if _, err := o.store.NoteAdd(o.ctx, o.cfg.SessionName, session.NoteAddParams{Content: note, Type: "decision", Iteration: iteration}); err != nil {
+		logger.Warn("Failed to add note for skipped %s: %v", task.ID, err)
+	}
+	if wasRunning && o.cancelRun(task.ID) {
+		o.apiNotice("skipped %s, cancelling the agent run", task.ID)
+	} else {
+		o.apiNotice("skipped %s", task.ID)
+	}
+
+	state, err = o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	return state.Tasks[task.ID], nil
+}
+
+// Status returns the session state with the loop's pause and stop state.
+func (c apiController) Status() (*controlapi.Status, error) {
+	o := c.o
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	status := &controlapi.Status{
+		Session:   o.cfg.SessionName,
+		Iteration: int(o.iteration.Load()),
+		Paused:    o.paused.Load(),
+		Stopping:  o.stopRequest.Load() != nil,
+		Complete:  state.Complete,
+		Current:   []*session.Task{},
+		Counts:    map[string]int{},
+		Tasks:     state.Tasks,
+		Usage:     state.Usage,
+		Queued:    len(o.sendChan),
+	}
+	for _, task := range state.Tasks {
+		status.Counts[task.Status]++
+		if task.Status == "in_progress" {
+			status.Current = append(status.Current, task)
+		}
+	}
+	slices.SortFunc(status.Current, func(a, b *session.Task) int { return strings.Compare(a.ID, b.ID) })
+	return status, nil
+}
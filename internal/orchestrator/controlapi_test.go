@@ -0,0 +1,197 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/controlapi"
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestAPIController_SkipTask(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{})
+	c := apiController{o}
+	ctx := o.ctx
+
+	if _, err := c.SkipTask("", ""); !errors.Is(err, controlapi.ErrConflict) {
+		t.Errorf("SkipTask() with nothing in progress = %v, want ErrConflict", err)
+	}
+
+	tasks, err := o.store.TaskBatchAdd(ctx, "test-session", []session.TaskAddParams{{Content: "Login"}, {Content: "Logout"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.TaskStatus(ctx, "test-session", session.TaskStatusParams{ID: tasks[0].ID, Status: "in_progress", Iteration: 1}); err != nil {
+		t.Fatal(err)
+	}
+	runCtx, stop := o.runnerContext(nil, "")
+	defer stop()
+
+	task, err := c.SkipTask("", "needs a product decision")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.ID != tasks[0].ID || task.Status != "blocked" {
+		t.Errorf("SkipTask() = %s %s, want %s blocked", task.ID, task.Status, tasks[0].ID)
+	}
+	select {
+	case <-runCtx.Done():
+		if !errors.Is(context.Cause(runCtx), errTaskSkipped) {
+			t.Errorf("run cancelled with %v, want errTaskSkipped", context.Cause(runCtx))
+		}
+	case <-time.After(time.Second):
+		t.Error("agent run was not cancelled")
+	}
+
+	state, err := o.store.LoadState(ctx, "test-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Notes) != 1 || state.Notes[0].Type != "decision" || state.Notes[0].Content != tasks[0].ID+" was skipped through the control API: needs a product decision" {
+		t.Errorf("notes = %+v", state.Notes)
+	}
+
+	if err := o.store.TaskStatus(ctx, "test-session", session.TaskStatusParams{ID: tasks[1].ID, Status: "completed", Iteration: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SkipTask(tasks[1].ID, ""); !errors.Is(err, controlapi.ErrConflict) {
+		t.Errorf("SkipTask(completed) = %v, want ErrConflict", err)
+	}
+	if _, err := c.SkipTask("TAS-99", ""); !errors.Is(err, controlapi.ErrConflict) {
+		t.Errorf("SkipTask(unknown) = %v, want ErrConflict", err)
+	}
+}
+
+func TestAPIController_StopWhilePaused(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{})
+	c := apiController{o}
+
+	o.paused.Store(true)
+	done := make(chan error, 1)
+	go func() { done <- o.waitIfPaused() }()
+
+	c.Stop("deploy freeze", false)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("waitIfPaused() = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitIfPaused() still blocked after stop")
+	}
+	if !o.stopRequested() {
+		t.Error("stopRequested() = false after Stop()")
+	}
+	if got := *o.stopRequest.Load(); got != "Stopped by control API: deploy freeze" {
+		t.Errorf("stop message = %q", got)
+	}
+	if err := c.SendMessage("one more thing"); !errors.Is(err, controlapi.ErrConflict) {
+		t.Errorf("SendMessage() while stopping = %v, want ErrConflict", err)
+	}
+	status, err := c.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Stopping || !status.Paused {
+		t.Errorf("Status() = %+v, want stopping and paused", status)
+	}
+}
+
+func TestWaitIfPaused_IgnoresStaleResume(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{})
+	o.resumeChan = make(chan struct{}, 1)
+
+	// Resuming before the loop blocks leaves a signal behind
+	o.RequestPause()
+	o.Resume()
+
+	o.paused.Store(true)
+	done := make(chan error, 1)
+	go func() { done <- o.waitIfPaused() }()
+	select {
+	case <-done:
+		t.Fatal("waitIfPaused() returned on a stale resume signal")
+	case <-time.After(50 * time.Millisecond):
+	}
+	o.Resume()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waitIfPaused() still blocked after Resume()")
+	}
+}
+
+func TestAPIController_MessagesAndStatus(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{})
+	o.sendChan = make(chan string, 1)
+	c := apiController{o}
+
+	if err := c.SendMessage("use sqlite"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SendMessage("and add an index"); !errors.Is(err, controlapi.ErrConflict) {
+		t.Errorf("SendMessage() on a full queue = %v, want ErrConflict", err)
+	}
+
+	o.iteration.Store(3)
+	task, err := c.AddTask(session.TaskAddParams{Content: "Add index", Priority: 1, Status: "completed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != "remaining" || task.Iteration != 3 {
+		t.Errorf("AddTask() = %+v, want remaining in iteration 3", task)
+	}
+
+	status, err := c.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Session != "test-session" || status.Iteration != 3 || status.Queued != 1 ||
+		status.Counts["remaining"] != 1 || len(status.Tasks) != 1 || len(status.Current) != 0 {
+		t.Errorf("Status() = %+v", status)
+	}
+}
+
+func TestAPIController_MessagesInParallelMode(t *testing.T) {
+	o, _, _ := setupParallelTest(t)
+	out := startFlakyRunner(t, o, 0)
+	o.cfg.Backend = "flaky-acp"
+	o.cfg.Iterations = 2
+	o.sendChan = make(chan string, 1)
+	srv := httptest.NewServer(controlapi.New(apiController{o}, "", "").Handler())
+	defer srv.Close()
+
+	post := func(body string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/messages", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post(`{"text":"use sqlite"}`); code != http.StatusAccepted {
+		t.Fatalf("POST /messages = %d, want 202", code)
+	}
+	if err := o.runParallel(1); err != nil {
+		t.Fatalf("runParallel() error = %v", err)
+	}
+	if n := countEntries(t, filepath.Join(out, "prompt-lines"), "use sqlite"); n != 2 {
+		t.Errorf("message reached %d workers, want 2", n)
+	}
+
+	if err := o.endSession(); err != nil {
+		t.Fatal(err)
+	}
+	if code := post(`{"text":"anyone there?"}`); code != http.StatusConflict {
+		t.Errorf("POST /messages after the loop ended = %d, want 409", code)
+	}
+}
@@ -0,0 +1,111 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// applyHookControls applies the control documents emitted by a hook. What was
+// done (or why it failed) is appended to the pending buffer so the agent sees
+// it next iteration, and shown as a toast or printed in headless mode.
+func (o *Orchestrator) applyHookControls(hookType string, controls []hooks.Control) {
+	for _, c := range controls {
+		var msg string
+		if err := c.Validate(); err != nil {
+			logger.Warn("Ignoring control document from %s hook: %v", hookType, err)
+			msg = fmt.Sprintf("[%s hook] ignored control document: %v", hookType, err)
+		} else if summary, err := o.applyHookControl(hookType, c); err != nil {
+			logger.Warn("Failed to apply %s from %s hook: %v", c.Action, hookType, err)
+			msg = fmt.Sprintf("[%s hook] %s failed: %v", hookType, c.Action, err)
+		} else {
+			logger.Info("%s hook: %s", hookType, summary)
+			msg = fmt.Sprintf("[%s hook] %s", hookType, summary)
+		}
+
+		o.appendPendingOutput(msg)
+		if o.tuiProgram != nil {
+			o.tuiProgram.Send(tui.ShowToastMsg{Text: msg})
+		} else {
+			fmt.Println(msg)
+		}
+	}
+}
+
+// applyHookControl applies one validated control document through the
+// session store. Returns a summary of what was done.
+func (o *Orchestrator) applyHookControl(hookType string, c hooks.Control) (string, error) {
+	iteration := int(o.iteration.Load())
+	reason := ""
+	if c.Reason != "" {
+		reason = ": " + c.Reason
+	}
+
+	switch c.Action {
+	case hooks.ActionBlockTask, hooks.ActionReopenTask:
+		state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+		if err != nil {
+			return "", fmt.Errorf("failed to load state: %w", err)
+		}
+		task, err := state.FindTask(c.Task)
+		if err != nil {
+			return "", err
+		}
+
+		status, verb := "blocked", "blocked"
+		if c.Action == hooks.ActionReopenTask {
+			status, verb = "remaining", "reopened"
+		}
+		if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: status, Iteration: iteration}); err != nil {
+			return "", err
+		}
+		if c.Action == hooks.ActionBlockTask && c.Reason != "" {
+			note := fmt.Sprintf("%s was blocked by a %s hook: %s", task.ID, hookType, c.Reason)
+			if _, err := o.store.NoteAdd(o.ctx, o.cfg.SessionName, session.NoteAddParams{Content: note, Type: "stuck", Iteration: iteration}); err != nil {
+				logger.Warn("Failed to add note for blocked %s: %v", task.ID, err)
+			}
+		}
+		return fmt.Sprintf("%s %s%s", verb, task.ID, reason), nil
+
+	case hooks.ActionAddTasks:
+		params := make([]session.TaskAddParams, len(c.Tasks))
+		for i, t := range c.Tasks {
+			params[i] = session.TaskAddParams{
+				Content:     t.Content,
+				Priority:    t.Priority,
+				Description: t.Description,
+				Tags:        t.Tags,
+				Iteration:   iteration,
+			}
+		}
+		tasks, err := o.store.TaskBatchAdd(o.ctx, o.cfg.SessionName, params)
+		if err != nil {
+			return "", err
+		}
+		ids := make([]string, len(tasks))
+		for i, t := range tasks {
+			ids[i] = t.ID
+		}
+		return fmt.Sprintf("added %s%s", strings.Join(ids, ", "), reason), nil
+
+	case hooks.ActionAddNote:
+		noteType := c.Type
+		if noteType == "" {
+			noteType = "learning"
+		}
+		note, err := o.store.NoteAdd(o.ctx, o.cfg.SessionName, session.NoteAddParams{Content: c.Content, Type: noteType, Iteration: iteration})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("added %s note %s", noteType, note.ID), nil
+
+	case hooks.ActionStop:
+		o.requestStop("hook", c.Reason)
+		return "stopping the session after this iteration" + reason, nil
+	}
+	return "", fmt.Errorf("unknown action %q", c.Action)
+}
@@ -0,0 +1,99 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestApplyHookControls(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{})
+	ctx := o.ctx
+	o.iteration.Store(3)
+
+	for _, content := range []string{"Login", "Signup"} {
+		if _, err := o.store.TaskAdd(ctx, "test-session", session.TaskAddParams{Content: content, Iteration: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := o.store.TaskStatus(ctx, "test-session", session.TaskStatusParams{ID: "TAS-2", Status: "completed", Iteration: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	list := []*hooks.HookConfig{{
+		Command: `printf '%s\n' 'tests failed' ` +
+			`'{"action":"block_task","task":"TAS-1","reason":"needs an API key"}' ` +
+			`'{"action":"reopen_task","task":"TAS-2","reason":"TestSignup fails"}' ` +
+			`'{"action":"add_tasks","tasks":[{"content":"Fix flaky TestSignup","priority":1,"tags":["tests"]}]}' ` +
+			`'{"action":"add_note","type":"tip","content":"run tests with -count=1"}' ` +
+			`'{"action":"reopen_task","task":"TAS-99"}' ` +
+			`'{"action":"restart"}'`,
+		PipeOutput: true,
+		Control:    true,
+	}}
+	o.runEventHooks("post_iteration", list, hooks.Variables{Session: "test-session"})
+
+	state, err := o.store.LoadState(ctx, "test-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := state.Tasks["TAS-1"].Status; got != "blocked" {
+		t.Errorf("TAS-1 status = %q, want blocked", got)
+	}
+	if got := state.Tasks["TAS-2"].Status; got != "remaining" {
+		t.Errorf("TAS-2 status = %q, want remaining", got)
+	}
+	added := state.Tasks["TAS-3"]
+	if added == nil || added.Content != "Fix flaky TestSignup" || added.Priority != 1 || added.Iteration != 3 {
+		t.Errorf("added task = %+v", added)
+	}
+	var noteTypes []string
+	for _, n := range state.Notes {
+		noteTypes = append(noteTypes, n.Type)
+		if n.Type == "stuck" && !strings.Contains(n.Content, "needs an API key") {
+			t.Errorf("stuck note = %q, want reason", n.Content)
+		}
+	}
+	if strings.Join(noteTypes, ",") != "stuck,tip" {
+		t.Errorf("note types = %v, want [stuck tip]", noteTypes)
+	}
+
+	pending := o.drainPendingOutput()
+	for _, want := range []string{
+		"tests failed",
+		"[post_iteration hook] blocked TAS-1: needs an API key",
+		"[post_iteration hook] reopened TAS-2: TestSignup fails",
+		"[post_iteration hook] added TAS-3",
+		"[post_iteration hook] reopen_task failed",
+		`[post_iteration hook] ignored control document: unknown action "restart"`,
+	} {
+		if !strings.Contains(pending, want) {
+			t.Errorf("pending output missing %q:\n%s", want, pending)
+		}
+	}
+	if strings.Contains(pending, `"action"`) {
+		t.Errorf("pending output contains control documents:\n%s", pending)
+	}
+	if o.stopRequested() {
+		t.Error("stopRequested() = true without a stop document")
+	}
+}
+
+func TestApplyHookControls_Stop(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{})
+
+	list := []*hooks.HookConfig{{Command: `echo '{"action":"stop","reason":"release branch frozen"}'`, Control: true}}
+	o.runEventHooks("pre_iteration", list, hooks.Variables{Session: "test-session"})
+
+	if !o.stopRequested() {
+		t.Fatal("stopRequested() = false after stop document")
+	}
+	if got := *o.stopRequest.Load(); got != "Stopped by hook: release branch frozen" {
+		t.Errorf("stop message = %q", got)
+	}
+	if stop, err := o.parallelStop(0); !stop || err != nil {
+		t.Errorf("parallelStop() = %v, %v; want stop", stop, err)
+	}
+}
@@ -0,0 +1,258 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// taskStatusHooks maps task statuses to the hook point run when a task
+// changes to that status.
+func (o *Orchestrator) taskStatusHooks(status string) (string, []*hooks.HookConfig) {
+	switch status {
+	case "in_progress":
+		return "on_task_start", o.hooksConfig.Hooks.OnTaskStart
+	case "completed":
+		return "on_task_complete", o.hooksConfig.Hooks.OnTaskComplete
+	case "blocked":
+		return "on_task_blocked", o.hooksConfig.Hooks.OnTaskBlocked
+	}
+	return "", nil
+}
+
+// subscribeHookEvents runs the on_task_start, on_task_complete,
+// on_task_blocked and on_note_added hooks as task and note events arrive.
+// Piped output is appended to the pending buffer for the next iteration.
+// Returns the subscriptions to unsubscribe when the loop ends.
+func (o *Orchestrator) subscribeHookEvents() []*natsgo.Subscription {
+	if o.hooksConfig == nil {
+		return nil
+	}
+	h := o.hooksConfig.Hooks
+	var subs []*natsgo.Subscription
+
+	if len(h.OnTaskStart) > 0 || len(h.OnTaskComplete) > 0 || len(h.OnTaskBlocked) > 0 {
+		logger.Debug("Subscribing to task status events for task hooks")
+		subject := fmt.Sprintf("iteratr.%s.task", o.cfg.SessionName)
+		sub, err := o.nc.Subscribe(subject, func(msg *natsgo.Msg) {
+			var event struct {
+				Action string `json:"action"`
+				Meta   struct {
+					TaskID    string `json:"task_id"`
+					Status    string `json:"status"`
+					Iteration int    `json:"iteration"`
+				} `json:"meta"`
+			}
+			if err := json.Unmarshal(msg.Data, &event); err != nil {
+				logger.Warn("Failed to parse task event for task hooks: %v", err)
+				return
+			}
+			if event.Action != "status" {
+				return
+			}
+			hookType, list := o.taskStatusHooks(event.Meta.Status)
+			if len(list) == 0 {
+				return
+			}
+
+			state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+			if err != nil {
+				logger.Warn("Failed to load state for %s: %v", hookType, err)
+				return
+			}
+			task, exists := state.Tasks[event.Meta.TaskID]
+			if !exists {
+				logger.Warn("Task %s not found in state for %s", event.Meta.TaskID, hookType)
+				return
+			}
+
+			logger.Info("Task %s is %s, executing %s hooks", task.ID, event.Meta.Status, hookType)
+			o.runEventHooks(hookType, list, o.taskHookVars(hookType, task, event.Meta.Iteration))
+		})
+		if err != nil {
+			logger.Warn("Failed to subscribe to task events: %v", err)
+			// Don't fail - hooks are optional
+		} else {
+			subs = append(subs, sub)
+		}
+	}
+
+	if len(h.OnNoteAdded) > 0 {
+		logger.Debug("Subscribing to note events for on_note_added hooks")
+		subject := fmt.Sprintf("iteratr.%s.note", o.cfg.SessionName)
+		sub, err := o.nc.Subscribe(subject, func(msg *natsgo.Msg) {
+			var event struct {
+				Action string `json:"action"`
+				Data   string `json:"data"`
+				Meta   struct {
+					Type      string `json:"type"`
+					Iteration int    `json:"iteration"`
+				} `json:"meta"`
+			}
+			if err := json.Unmarshal(msg.Data, &event); err != nil {
+				logger.Warn("Failed to parse note event for on_note_added: %v", err)
+				return
+			}
+			if event.Action != "add" {
+				return
+			}
+
+			vars := o.iterationHookVars("on_note_added", event.Meta.Iteration)
+			vars.NoteType = event.Meta.Type
+			vars.NoteContent = event.Data
+			o.runEventHooks("on_note_added", h.OnNoteAdded, vars)
+		})
+		if err != nil {
+			logger.Warn("Failed to subscribe to note events: %v", err)
+		} else {
+			subs = append(subs, sub)
+		}
+	}
+
+	return subs
+}
+
+// runEventHooks runs hooks triggered outside the iteration flow and appends
+// their piped output to the pending buffer.
+func (o *Orchestrator) runEventHooks(hookType string, list []*hooks.HookConfig, vars hooks.Variables) {
+	onStart, onComplete, _ := o.hookCallbacks(hookType)
+	output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, list, o.cfg.WorkDir, vars, onStart, onComplete)
+	if err != nil {
+		if o.ctx.Err() != nil {
+			logger.Debug("Context cancelled during %s hook execution", hookType)
+		} else {
+			logger.Error("%s hook execution failed: %v", hookType, err)
+		}
+		return
+	}
+	if output != "" {
+		// Append piped output to pending buffer (FIFO order)
+		logger.Debug("%s hook output: %d bytes (appending to pending buffer)", hookType, len(output))
+		o.appendPendingOutput(output)
+	}
+}
+
+// hookVars returns the hook variables common to every hook point: the
+// session, the files changed so far in the iteration, and where to reach the
+// session's data.
+func (o *Orchestrator) hookVars(hookType string, iteration int) hooks.Variables {
+	vars := hooks.Variables{
+		Hook:     hookType,
+		Session:  o.cfg.SessionName,
+		Changed:  o.changedPaths(),
+		DataDir:  o.cfg.DataDir,
+		NATSPort: o.natsPort,
+	}
+	if dir, err := filepath.Abs(o.cfg.DataDir); err == nil {
+		// Hooks may run in a task worktree
+		vars.DataDir = dir
+	}
+	if iteration > 0 {
+		vars.Iteration = strconv.Itoa(iteration)
+	}
+	return vars
+}
+
+// taskHookVars returns hook variables describing a task.
+func (o *Orchestrator) taskHookVars(hookType string, task *session.Task, iteration int) hooks.Variables {
+	vars := o.hookVars(hookType, iteration)
+	vars.TaskID = task.ID
+	vars.TaskContent = task.Content
+	vars.TaskPriority = task.Priority
+	vars.TaskTags = task.Tags
+	return vars
+}
+
+// iterationHookVars returns hook variables for an iteration, with the task
+// being worked on (if any).
+func (o *Orchestrator) iterationHookVars(hookType string, iteration int) hooks.Variables {
+	var task *session.Task
+	if state, err := o.store.LoadState(o.ctx, o.cfg.SessionName); err != nil {
+		logger.Warn("Failed to load state for hook variables: %v", err)
+	} else if tb := o.activeBranch.Load(); tb != nil {
+		task = state.Tasks[tb.taskID]
+	} else {
+		task = state.CurrentTask()
+	}
+	if task != nil {
+		return o.taskHookVars(hookType, task, iteration)
+	}
+	return o.hookVars(hookType, iteration)
+}
+
+// changedPaths returns the files changed in the current iteration, as
+// reported by the agent and seen by the file watcher, relative to the work dir.
+func (o *Orchestrator) changedPaths() []string {
+	paths := o.fileTracker.ModifiedPaths()
+	if o.fileWatcher != nil {
+		for _, p := range o.fileWatcher.ChangedPaths() {
+			if !slices.Contains(paths, p) {
+				paths = append(paths, p)
+			}
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// runPreCommitHooks runs the pre_commit hooks before an auto-commit. Piped
+// output is appended to the pending buffer. Returns false if a hook failed
+// and the commit should be skipped; the changes stay uncommitted and are
+// picked up by the next iteration's commit.
+func (o *Orchestrator) runPreCommitHooks(iteration int) bool {
+	if o.hooksConfig == nil || len(o.hooksConfig.Hooks.PreCommit) == 0 {
+		return true
+	}
+	logger.Debug("Executing %d pre_commit hook(s)", len(o.hooksConfig.Hooks.PreCommit))
+
+	failed := false
+	onStart, onComplete, _ := o.hookCallbacks("pre_commit")
+	trackFailure := func(hookIndex int, result hooks.HookResult) {
+		if result.Failed {
+			failed = true
+		}
+		if onComplete != nil {
+			onComplete(hookIndex, result)
+		}
+	}
+	output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.PreCommit, o.iterationDir(), o.iterationHookVars("pre_commit", iteration), onStart, trackFailure)
+	if err != nil {
+		logger.Error("pre_commit hook execution failed: %v", err)
+		return false
+	}
+	if output != "" {
+		o.appendPendingOutput(output)
+	}
+	if failed {
+		logger.Warn("pre_commit hook failed, skipping auto-commit for iteration #%d", iteration)
+		if o.cfg.Headless {
+			fmt.Printf("[pre_commit] hook failed, skipping auto-commit for iteration #%d\n", iteration)
+		}
+	}
+	return !failed
+}
+
+// runPauseHooks runs the on_pause hooks when the loop pauses, or the
+// on_resume hooks when it resumes. Piped output is appended to the pending
+// buffer for the next iteration.
+func (o *Orchestrator) runPauseHooks(paused bool) {
+	if o.hooksConfig == nil {
+		return
+	}
+	hookType, list := "on_resume", o.hooksConfig.Hooks.OnResume
+	if paused {
+		hookType, list = "on_pause", o.hooksConfig.Hooks.OnPause
+	}
+	if len(list) == 0 {
+		return
+	}
+	logger.Debug("Executing %d %s hook(s)", len(list), hookType)
+	o.runEventHooks(hookType, list, o.iterationHookVars(hookType, int(o.iteration.Load())))
+}
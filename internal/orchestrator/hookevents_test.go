@@ -0,0 +1,173 @@
+package orchestrator
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// setupHookEventsTest creates an orchestrator backed by embedded NATS with
+// the given hooks.
+func setupHookEventsTest(t *testing.T, h hooks.HooksConfig) *Orchestrator {
+	t.Helper()
+	if err := h.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	tmpDir := t.TempDir()
+
+	ns, port, err := nats.StartEmbeddedNATS(filepath.Join(tmpDir, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ns.Shutdown)
+
+	nc, err := nats.ConnectToPort(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Orchestrator{
+		cfg: Config{
+			SessionName: "test-session",
+			WorkDir:     tmpDir,
+		},
+		nc:          nc,
+		store:       session.NewStore(js, stream),
+		ctx:         ctx,
+		cancel:      func() {},
+		fileTracker: agent.NewFileTracker(tmpDir),
+		hooksConfig: &hooks.Config{Version: 1, Hooks: h},
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// waitForPendingOutput waits until the pending buffer contains want.
+func waitForPendingOutput(t *testing.T, o *Orchestrator, want string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	var output string
+	for time.Now().Before(deadline) {
+		o.pendingMu.Lock()
+		output = o.pendingHookOutput
+		o.pendingMu.Unlock()
+		if strings.Contains(output, want) {
+			return output
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("pending output %q does not contain %q", output, want)
+	return output
+}
+
+func TestSubscribeHookEvents_TaskAndNoteHooks(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{
+		OnTaskStart: []*hooks.HookConfig{
+			{Command: "echo 'started {{task_id}}'", PipeOutput: true, When: &hooks.Condition{Tags: []string{"api"}}},
+		},
+		OnTaskBlocked: []*hooks.HookConfig{
+			{Command: "echo 'blocked {{task_id}}'", PipeOutput: true},
+		},
+		OnNoteAdded: []*hooks.HookConfig{
+			{Command: "echo 'note {{note_type}}: {{note_content}}'", PipeOutput: true},
+		},
+	})
+	for _, sub := range o.subscribeHookEvents() {
+		defer func() { _ = sub.Unsubscribe() }()
+	}
+	ctx := o.ctx
+
+	ui, err := o.store.TaskAdd(ctx, "test-session", session.TaskAddParams{Content: "UI", Iteration: 1, Tags: []string{"web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, err := o.store.TaskAdd(ctx, "test-session", session.TaskAddParams{Content: "API", Iteration: 1, Tags: []string{"api"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the task tagged "api" matches the on_task_start condition
+	for _, id := range []string{ui.ID, api.ID} {
+		if err := o.store.TaskStatus(ctx, "test-session", session.TaskStatusParams{ID: id, Status: "in_progress", Iteration: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	output := waitForPendingOutput(t, o, "started "+api.ID)
+	if strings.Contains(output, "started "+ui.ID) {
+		t.Errorf("on_task_start ran for task without matching tag: %q", output)
+	}
+
+	if err := o.store.TaskStatus(ctx, "test-session", session.TaskStatusParams{ID: ui.ID, Status: "blocked", Iteration: 1}); err != nil {
+		t.Fatal(err)
+	}
+	waitForPendingOutput(t, o, "blocked "+ui.ID)
+
+	if _, err := o.store.NoteAdd(ctx, "test-session", session.NoteAddParams{Content: "flaky test", Type: "stuck", Iteration: 1}); err != nil {
+		t.Fatal(err)
+	}
+	waitForPendingOutput(t, o, "note stuck: flaky test")
+}
+
+func TestRunPreCommitHooks(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{
+		PreCommit: []*hooks.HookConfig{
+			{Command: "echo lint", PipeOutput: true, When: &hooks.Condition{Changed: []string{"*.go"}}},
+		},
+	})
+
+	// No Go files changed: the hook is skipped and the commit goes ahead
+	o.fileTracker.RecordChange(filepath.Join(o.cfg.WorkDir, "README.md"), false, 1, 0)
+	if !o.runPreCommitHooks(1) {
+		t.Error("runPreCommitHooks() = false with no matching hooks")
+	}
+	if o.hasPendingOutput() {
+		t.Errorf("skipped hook produced output: %q", o.drainPendingOutput())
+	}
+
+	o.fileTracker.RecordChange(filepath.Join(o.cfg.WorkDir, "cmd", "main.go"), false, 1, 0)
+	if !o.runPreCommitHooks(1) {
+		t.Error("runPreCommitHooks() = false for passing hook")
+	}
+	if got := o.drainPendingOutput(); !strings.Contains(got, "lint") {
+		t.Errorf("pending output = %q, want lint", got)
+	}
+
+	o.hooksConfig.Hooks.PreCommit = append(o.hooksConfig.Hooks.PreCommit, &hooks.HookConfig{Command: "exit 1"})
+	if o.runPreCommitHooks(1) {
+		t.Error("runPreCommitHooks() = true for failing hook")
+	}
+}
+
+func TestRunPauseHooks(t *testing.T) {
+	o := setupHookEventsTest(t, hooks.HooksConfig{
+		OnPause:  []*hooks.HookConfig{{Command: "echo paused by $ITERATR_HOOK in $ITERATR_SESSION", PipeOutput: true}},
+		OnResume: []*hooks.HookConfig{{Command: "echo resumed", PipeOutput: true}},
+	})
+
+	o.runPauseHooks(true)
+	if got := o.drainPendingOutput(); !strings.Contains(got, "paused by on_pause in test-session") || strings.Contains(got, "resumed") {
+		t.Errorf("on_pause output = %q", got)
+	}
+	o.runPauseHooks(false)
+	if got := o.drainPendingOutput(); !strings.Contains(got, "resumed") {
+		t.Errorf("on_resume output = %q", got)
+	}
+}
@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/notify"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// notify sends a notification about the session in the background, if
+// notification sinks are configured.
+func (o *Orchestrator) notify(event, title, message string) {
+	if o.notifier == nil {
+		return
+	}
+	logger.Debug("Sending %s notification: %s", event, title)
+	o.notifier.Notify(notify.Event{
+		Type:    event,
+		Session: o.cfg.SessionName,
+		Title:   title,
+		Message: message,
+	})
+}
+
+// subscribeNotifications sends session_complete and stuck notifications as
+// control and note events arrive, whether they come from the agent, the TUI
+// or the orchestrator. Returns the subscriptions to unsubscribe when the
+// loop ends.
+func (o *Orchestrator) subscribeNotifications() []*natsgo.Subscription {
+	if o.notifier == nil {
+		return nil
+	}
+	var subs []*natsgo.Subscription
+
+	subject := fmt.Sprintf("iteratr.%s.control", o.cfg.SessionName)
+	sub, err := o.nc.Subscribe(subject, func(msg *natsgo.Msg) {
+		var event struct {
+			Action string `json:"action"`
+		}
+		if err := json.Unmarshal(msg.Data, &event); err != nil || event.Action != "session_complete" {
+			return
+		}
+		o.notify(config.NotifySessionComplete, "Session complete", o.completionSummary())
+	})
+	if err != nil {
+		logger.Warn("Failed to subscribe to control events for notifications: %v", err)
+	} else {
+		subs = append(subs, sub)
+	}
+
+	subject = fmt.Sprintf("iteratr.%s.note", o.cfg.SessionName)
+	sub, err = o.nc.Subscribe(subject, func(msg *natsgo.Msg) {
+		var event struct {
+			Action string `json:"action"`
+			Data   string `json:"data"`
+			Meta   struct {
+				Type      string `json:"type"`
+				Iteration int    `json:"iteration"`
+			} `json:"meta"`
+		}
+		if err := json.Unmarshal(msg.Data, &event); err != nil || event.Action != "add" || event.Meta.Type != "stuck" {
+			return
+		}
+		o.notify(config.NotifyStuck, fmt.Sprintf("Stuck in iteration #%d", event.Meta.Iteration), event.Data)
+	})
+	if err != nil {
+		logger.Warn("Failed to subscribe to note events for notifications: %v", err)
+	} else {
+		subs = append(subs, sub)
+	}
+
+	return subs
+}
+
+// completionSummary describes a completed session's tasks and iterations.
+func (o *Orchestrator) completionSummary() string {
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load state for completion notification: %v", err)
+		return fmt.Sprintf("Session '%s' is complete", o.cfg.SessionName)
+	}
+	counts := map[string]int{}
+	for _, task := range state.Tasks {
+		counts[task.Status]++
+	}
+	summary := fmt.Sprintf("Session '%s' is complete after %d iterations: %d tasks completed",
+		o.cfg.SessionName, len(state.Iterations), counts["completed"])
+	if counts["blocked"] > 0 {
+		summary += fmt.Sprintf(", %d blocked", counts["blocked"])
+	}
+	if counts["cancelled"] > 0 {
+		summary += fmt.Sprintf(", %d cancelled", counts["cancelled"])
+	}
+	return summary
+}
@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/notify"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestSubscribeNotifications(t *testing.T) {
+	var mu sync.Mutex
+	var events []notify.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var e notify.Event
+		if err := json.Unmarshal(body, &e); err == nil {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		}
+	}))
+	defer srv.Close()
+
+	o := setupHookEventsTest(t, hooks.HooksConfig{})
+	o.notifier = notify.New(config.NotificationsConfig{Sinks: []config.NotificationSink{{Type: config.SinkWebhook, URL: srv.URL}}})
+	for _, sub := range o.subscribeNotifications() {
+		defer func() { _ = sub.Unsubscribe() }()
+	}
+	ctx := o.ctx
+
+	if _, err := o.store.NoteAdd(ctx, "test-session", session.NoteAddParams{Content: "learned a thing", Type: "learning", Iteration: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.store.NoteAdd(ctx, "test-session", session.NoteAddParams{Content: "missing API key", Type: "stuck", Iteration: 2}); err != nil {
+		t.Fatal(err)
+	}
+	task, err := o.store.TaskAdd(ctx, "test-session", session.TaskAddParams{Content: "Login", Iteration: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.TaskStatus(ctx, "test-session", session.TaskStatusParams{ID: task.ID, Status: "completed", Iteration: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.SessionComplete(ctx, "test-session"); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		o.notifier.Wait()
+		mu.Lock()
+		n := len(events)
+		mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 {
+		t.Fatalf("got %d notifications, want 2: %+v", len(events), events)
+	}
+	byType := map[string]notify.Event{}
+	for _, e := range events {
+		byType[e.Type] = e
+	}
+	if e := byType[config.NotifyStuck]; e.Message != "missing API key" || e.Session != "test-session" {
+		t.Errorf("stuck notification = %+v", e)
+	}
+	if e := byType[config.NotifySessionComplete]; !strings.Contains(e.Message, "1 tasks completed") {
+		t.Errorf("session_complete notification = %+v", e)
+	}
+}
@@ -2,12 +2,10 @@ package orchestrator
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,14 +13,19 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/controlapi"
 	ierr "github.com/mark3labs/iteratr/internal/errors"
 	"github.com/mark3labs/iteratr/internal/hooks"
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/mcpserver"
 	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/notify"
+	"github.com/mark3labs/iteratr/internal/permission"
 	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/iteratr/internal/template"
 	"github.com/mark3labs/iteratr/internal/tui"
+	"github.com/mark3labs/iteratr/internal/usage"
 	natsserver "github.com/nats-io/nats-server/v2/server"
 	natsgo "github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
@@ -30,46 +33,81 @@ import (
 
 // Config holds configuration for the orchestrator.
 type Config struct {
-	SessionName       string // Name of the session
-	SpecPath          string // Path to spec file
-	TemplatePath      string // Path to custom template (optional)
-	ExtraInstructions string // Extra instructions (optional)
-	Iterations        int    // Max iterations (0 = infinite)
-	DataDir           string // Data directory for persistent storage
-	WorkDir           string // Working directory for agent
-	Headless          bool   // Run without TUI
-	Model             string // Model to use (e.g., anthropic/claude-sonnet-4-5)
-	Reset             bool   // Reset session data before starting
-	AutoCommit        bool   // Auto-commit modified files after iteration
-	CommitDataDir     bool   // Include data_dir in auto-commit (default false)
+	SessionName       string                     // Name of the session
+	SpecPaths         []string                   // Spec files, directories, or globs
+	RelevantSpec      bool                       // Only include spec sections relevant to the current task
+	TemplatePath      string                     // Path to custom template (optional)
+	TemplateVars      map[string]string          // User-defined template variables (template_vars)
+	ExtraInstructions string                     // Extra instructions (optional)
+	Iterations        int                        // Max iterations (0 = infinite)
+	DataDir           string                     // Data directory for persistent storage
+	WorkDir           string                     // Working directory for agent
+	Headless          bool                       // Run without TUI
+	Model             string                     // Model to use (e.g., anthropic/claude-sonnet-4-5)
+	Backend           string                     // Agent backend name (empty = default backend)
+	Permissions       *permission.Policy         // Tool permission policy (nil = auto-grant all)
+	TaskBranches      config.TaskBranchesConfig  // Per-task git branch/worktree settings
+	Reset             bool                       // Reset session data before starting
+	AutoCommit        bool                       // Auto-commit modified files after iteration
+	CommitDataDir     bool                       // Include data_dir in auto-commit (default false)
+	Commit            config.CommitConfig        // Auto-commit mode, message template, and trailers
+	Budget            config.BudgetConfig        // Token and cost limits for the session
+	Prompt            config.PromptConfig        // Prompt token budgets per model
+	Checklist         config.ChecklistConfig     // Spec checklist import and write-back
+	Verify            config.VerifyConfig        // Commands that must pass before a task is completed
+	Notifications     config.NotificationsConfig // Webhook, desktop and email notification sinks
+	API               config.APIConfig           // HTTP control API address and token
+	Parallelism       int                        // Ready tasks worked on at once (<= 1 = sequential)
+	IterationTimeout  time.Duration              // Max agent run time per iteration (0 = none)
+	SessionTimeout    time.Duration              // Max wall-clock time for this run (0 = none)
+	StallTimeout      time.Duration              // Cancel the agent after this long without ACP updates (0 = none)
+	TimeoutRetries    int                        // Retries of a timed-out iteration before moving on
+	MaxRestarts       int                        // Agent crash restarts allowed per session (0 = none)
 }
 
 // Orchestrator manages the iteration loop with embedded NATS, agent runner, and TUI.
 type Orchestrator struct {
 	cfg               Config
-	ns                *natsserver.Server // Embedded NATS server (nil if node mode)
-	natsPort          int                // NATS server port
-	nc                *natsgo.Conn       // NATS connection
-	store             *session.Store     // Session store
-	mcpServer         *mcpserver.Server  // MCP tools server
-	runner            *agent.Runner      // Agent runner for opencode subprocess
-	tuiApp            *tui.App           // TUI application (nil if headless)
-	tuiProgram        *tea.Program       // Bubbletea program
-	tuiDone           chan struct{}      // TUI completion signal
-	sendChan          chan string        // Channel for user input messages from TUI to orchestrator
-	ctx               context.Context    // Context for cancellation
-	cancel            context.CancelFunc // Cancel function
-	stopped           bool               // Track if Stop() was already called
-	isPrimary         bool               // True if this instance owns the NATS server
-	hooksConfig       *hooks.Config      // Hooks configuration (nil if no hooks file)
-	fileTracker       *agent.FileTracker // Tracks files modified during iteration (ACP events)
-	fileWatcher       *agent.FileWatcher // Watches filesystem for all file changes (fsnotify)
-	autoCommit        bool               // Auto-commit modified files after iteration
-	pendingHookOutput string             // Buffer for hook output to be sent in next iteration
-	pendingMu         sync.Mutex         // Protects pendingHookOutput (needed for NATS callback)
-	paused            atomic.Bool        // Pause state (atomic for thread-safe access)
-	resumeChan        chan struct{}      // Signals resume from pause
-	hookCounter       atomic.Int64       // Counter for generating unique hook IDs
+	ns                *natsserver.Server                 // Embedded NATS server (nil if node mode)
+	natsPort          int                                // NATS server port
+	nc                *natsgo.Conn                       // NATS connection
+	store             *session.Store                     // Session store
+	mcpServer         *mcpserver.Server                  // MCP tools server
+	runner            *agent.Runner                      // Agent runner for opencode subprocess
+	tuiApp            *tui.App                           // TUI application (nil if headless)
+	tuiProgram        *tea.Program                       // Bubbletea program
+	tuiDone           chan struct{}                      // TUI completion signal
+	sendChan          chan string                        // Channel for user input messages from TUI to orchestrator
+	ctx               context.Context                    // Context for cancellation
+	cancel            context.CancelFunc                 // Cancel function
+	stopped           bool                               // Track if Stop() was already called
+	isPrimary         bool                               // True if this instance owns the NATS server
+	hooksConfig       *hooks.Config                      // Hooks configuration (nil if no hooks file)
+	fileTracker       *agent.FileTracker                 // Tracks files modified during iteration (ACP events)
+	fileWatcher       *agent.FileWatcher                 // Watches filesystem for all file changes (fsnotify)
+	autoCommit        bool                               // Auto-commit modified files after iteration
+	pendingHookOutput string                             // Buffer for hook output to be sent in next iteration
+	pendingMu         sync.Mutex                         // Protects pendingHookOutput (needed for NATS callback)
+	paused            atomic.Bool                        // Pause state (atomic for thread-safe access)
+	resumeChan        chan struct{}                      // Signals resume from pause
+	hookCounter       atomic.Int64                       // Counter for generating unique hook IDs
+	iteration         atomic.Int64                       // Current iteration number (for permission events)
+	stdinMu           sync.Mutex                         // Serializes headless permission prompts
+	baseBranch        string                             // Branch task branches fork from (empty = task branches disabled)
+	activeBranch      atomic.Pointer[taskBranch]         // Branch of the task currently being worked on (read by hook callbacks)
+	rollbackChan      chan int                           // Rollback requests from the TUI (target iteration)
+	rolledBackTo      *int                               // Iteration the loop was last rolled back to (nil = none pending)
+	pricing           usage.Pricing                      // Model pricing for agents that do not report cost
+	budgetOverride    bool                               // User resumed after a budget pause; limits ignored for this run
+	sessionDeadline   time.Time                          // When the session timeout expires (zero = no timeout)
+	restarts          int                                // Agent crash restarts so far in this run
+	stopRequest       atomic.Pointer[string]             // Who asked to stop the session and why (nil = not asked)
+	loopDone          atomic.Bool                        // The iteration loop finished; no agent takes user messages
+	stopChan          chan struct{}                      // Closed when a stop is first requested
+	notifier          *notify.Notifier                   // Notification sinks (nil = none configured)
+	api               *controlapi.Server                 // Control API server (nil = disabled)
+	runsMu            sync.Mutex                         // Protects runs
+	runs              map[string]context.CancelCauseFunc // Cancels agent runs by task ID ("" = sequential run)
 }
 
 // New creates a new Orchestrator with the given configuration.
@@ -86,18 +124,27 @@ func New(cfg Config) (*Orchestrator, error) {
 		cfg.WorkDir = wd
 	}
 
+	pricing, err := usage.LoadPricing(cfg.Budget.PricingFile)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create context for lifecycle management
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Orchestrator{
-		cfg:         cfg,
-		ctx:         ctx,
-		cancel:      cancel,
-		tuiDone:     make(chan struct{}),
-		sendChan:    make(chan string, 10), // Buffered channel for user input messages
-		fileTracker: agent.NewFileTracker(cfg.WorkDir),
-		autoCommit:  cfg.AutoCommit,
-		resumeChan:  make(chan struct{}, 1), // Buffered to prevent blocking on Resume()
+		cfg:          cfg,
+		ctx:          ctx,
+		cancel:       cancel,
+		tuiDone:      make(chan struct{}),
+		sendChan:     make(chan string, 10), // Buffered channel for user input messages
+		fileTracker:  agent.NewFileTracker(cfg.WorkDir),
+		autoCommit:   cfg.AutoCommit,
+		resumeChan:   make(chan struct{}, 1), // Buffered to prevent blocking on Resume()
+		rollbackChan: make(chan int, 1),      // Buffered to prevent blocking on RequestRollback()
+		stopChan:     make(chan struct{}),
+		pricing:      pricing,
+		notifier:     notify.New(cfg.Notifications),
 	}, nil
 }
 
@@ -136,6 +183,7 @@ func (o *Orchestrator) Start() error {
 	// 3.5. Start MCP tools server
 	logger.Debug("Starting MCP tools server")
 	o.mcpServer = mcpserver.New(o.store, o.cfg.SessionName)
+	o.mcpServer.SetVerify(o.cfg.Verify, o.iterationDir)
 	port, err := o.mcpServer.Start(o.ctx)
 	if err != nil {
 		logger.Error("Failed to start MCP server: %v", err)
@@ -211,6 +259,12 @@ func (o *Orchestrator) Start() error {
 		logger.Info("Hooks configuration loaded")
 	}
 
+	// 8. Start the control API (optional)
+	if err := o.startAPI(); err != nil {
+		logger.Error("Failed to start control API: %v", err)
+		return fmt.Errorf("failed to start control API: %w", err)
+	}
+
 	logger.Info("Orchestrator started successfully")
 	return nil
 }
@@ -227,6 +281,11 @@ func (o *Orchestrator) Run() error {
 		return fmt.Errorf("failed to load session state: %w", err)
 	}
 
+	// The session timeout covers this run, including iteration #0
+	if o.cfg.SessionTimeout > 0 {
+		o.sessionDeadline = time.Now().Add(o.cfg.SessionTimeout)
+	}
+
 	// Determine starting iteration number
 	// Fresh sessions start at iteration 0 (planning phase); resumed sessions skip to next iteration
 	startIteration := len(state.Iterations)
@@ -273,11 +332,13 @@ func (o *Orchestrator) Run() error {
 	if o.tuiProgram != nil {
 		// TUI mode - send output to TUI
 		o.runner = agent.NewRunner(agent.RunnerConfig{
+			Backend:      o.cfg.Backend,
 			Model:        o.cfg.Model,
 			WorkDir:      o.cfg.WorkDir,
 			SessionName:  o.cfg.SessionName,
 			NATSPort:     o.natsPort,
 			MCPServerURL: o.mcpServer.URL(),
+			OnPermission: o.permissionHandler(),
 			OnText: func(content string) {
 				o.tuiProgram.Send(tui.AgentOutputMsg{Content: content})
 			},
@@ -306,6 +367,7 @@ func (o *Orchestrator) Run() error {
 				o.tuiProgram.Send(tui.AgentThinkingMsg{Content: content})
 			},
 			OnFinish: func(event agent.FinishEvent) {
+				o.recordUsage(event)
 				o.tuiProgram.Send(tui.AgentFinishMsg{
 					Reason:   event.StopReason,
 					Error:    event.Error,
@@ -329,11 +391,13 @@ func (o *Orchestrator) Run() error {
 	} else {
 		// Headless mode - print to stdout
 		o.runner = agent.NewRunner(agent.RunnerConfig{
+			Backend:      o.cfg.Backend,
 			Model:        o.cfg.Model,
 			WorkDir:      o.cfg.WorkDir,
 			SessionName:  o.cfg.SessionName,
 			NATSPort:     o.natsPort,
 			MCPServerURL: o.mcpServer.URL(),
+			OnPermission: o.permissionHandler(),
 			OnText: func(content string) {
 				fmt.Print(content)
 			},
@@ -360,6 +424,7 @@ func (o *Orchestrator) Run() error {
 				fmt.Printf("\033[2m%s\033[0m", content)
 			},
 			OnFinish: func(event agent.FinishEvent) {
+				o.recordUsage(event)
 				// Print finish summary in headless mode
 				fmt.Printf("\n--- Agent finished: %s", event.StopReason)
 				if event.Error != "" {
@@ -369,6 +434,9 @@ func (o *Orchestrator) Run() error {
 				if event.Model != "" {
 					fmt.Printf(" | Model: %s", event.Model)
 				}
+				if u := o.pricing.Cost(event.Model, event.Usage); !u.IsZero() {
+					fmt.Printf(" | Usage: %s", u)
+				}
 				fmt.Println(" ---")
 			},
 			OnFileChange: func(change agent.FileChange) {
@@ -413,6 +481,15 @@ func (o *Orchestrator) Run() error {
 		}
 	}
 
+	// Send notifications for session completion and stuck notes
+	for _, sub := range o.subscribeNotifications() {
+		defer func() {
+			if err := sub.Unsubscribe(); err != nil {
+				logger.Debug("Failed to unsubscribe from notification events: %v", err)
+			}
+		}()
+	}
+
 	// Run Iteration #0 (planning phase) for fresh sessions
 	// No hooks are executed during iteration #0 — hooks are set up after.
 	if startIteration == 0 {
@@ -426,102 +503,31 @@ func (o *Orchestrator) Run() error {
 		startIteration = 1 // Main loop starts at iteration 1
 	}
 
-	// Subscribe to task completion events for on_task_complete hooks
+	// Subscribe to task and note events for task and note hooks
 	// (after iteration #0 so hooks don't fire during planning phase)
-	var taskCompleteSub *natsgo.Subscription
-	if o.hooksConfig != nil && len(o.hooksConfig.Hooks.OnTaskComplete) > 0 {
-		logger.Debug("Subscribing to task completion events for on_task_complete hooks")
-		subject := fmt.Sprintf("iteratr.%s.task", o.cfg.SessionName)
-		sub, err := o.nc.Subscribe(subject, func(msg *natsgo.Msg) {
-			// Parse event to check if it's a status=completed action
-			var event struct {
-				Action string          `json:"action"`
-				Meta   json.RawMessage `json:"meta"`
-			}
-			if err := json.Unmarshal(msg.Data, &event); err != nil {
-				logger.Warn("Failed to parse task event for on_task_complete: %v", err)
-				return
-			}
-
-			// Only process status=completed events
-			if event.Action != "status" {
-				return
-			}
-
-			var meta struct {
-				TaskID string `json:"task_id"`
-				Status string `json:"status"`
-			}
-			if err := json.Unmarshal(event.Meta, &meta); err != nil {
-				logger.Warn("Failed to parse task event metadata: %v", err)
-				return
-			}
-
-			if meta.Status != "completed" {
-				return
-			}
-
-			// Load current state to get task content
-			state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
-			if err != nil {
-				logger.Warn("Failed to load state for on_task_complete: %v", err)
-				return
-			}
-
-			task, exists := state.Tasks[meta.TaskID]
-			if !exists {
-				logger.Warn("Task %s not found in state for on_task_complete", meta.TaskID)
-				return
+	for _, sub := range o.subscribeHookEvents() {
+		defer func() {
+			if err := sub.Unsubscribe(); err != nil {
+				logger.Debug("Failed to unsubscribe from hook events: %v", err)
 			}
+		}()
+	}
 
-			// Execute on_task_complete hooks
-			logger.Info("Task %s completed, executing on_task_complete hooks", meta.TaskID)
-			hookVars := hooks.Variables{
-				Session:     o.cfg.SessionName,
-				TaskID:      meta.TaskID,
-				TaskContent: task.Content,
-			}
-			onStart, onComplete, _ := o.hookCallbacks("on_task_complete")
-			output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.OnTaskComplete, o.cfg.WorkDir, hookVars, onStart, onComplete)
-			if err != nil {
-				// Context cancelled or error - just log
-				if o.ctx.Err() != nil {
-					logger.Debug("Context cancelled during on_task_complete hook execution")
-				} else {
-					logger.Error("on_task_complete hook execution failed: %v", err)
+	// Tick spec checklist items as their tasks complete
+	if o.cfg.Checklist.WriteBack && len(o.cfg.SpecPaths) > 0 {
+		if sub := o.subscribeChecklistWriteBack(); sub != nil {
+			defer func() {
+				if err := sub.Unsubscribe(); err != nil {
+					logger.Debug("Failed to unsubscribe from checklist write-back: %v", err)
 				}
-				return
-			}
-
-			if output != "" {
-				// Append piped output to pending buffer (FIFO order)
-				logger.Debug("on_task_complete hook output: %d bytes (appending to pending buffer)", len(output))
-				o.appendPendingOutput(output)
-			}
-		})
-		if err != nil {
-			logger.Warn("Failed to subscribe to task completion events: %v", err)
-			// Don't fail - hooks are optional
-		} else {
-			taskCompleteSub = sub
-			logger.Debug("Subscribed to task completion events")
+			}()
 		}
 	}
-	// Unsubscribe on exit
-	if taskCompleteSub != nil {
-		defer func() {
-			if err := taskCompleteSub.Unsubscribe(); err != nil {
-				logger.Debug("Failed to unsubscribe from task complete: %v", err)
-			}
-		}()
-	}
 
 	// Execute session_start hooks if configured (after iteration #0, before main loop)
 	if o.hooksConfig != nil && len(o.hooksConfig.Hooks.SessionStart) > 0 {
 		logger.Debug("Executing %d session_start hook(s)", len(o.hooksConfig.Hooks.SessionStart))
-		hookVars := hooks.Variables{
-			Session: o.cfg.SessionName,
-		}
+		hookVars := o.hookVars("session_start", 0)
 		onStart, onComplete, _ := o.hookCallbacks("session_start")
 		output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.SessionStart, o.cfg.WorkDir, hookVars, onStart, onComplete)
 		if err != nil {
@@ -538,6 +544,24 @@ func (o *Orchestrator) Run() error {
 		}
 	}
 
+	if o.cfg.Parallelism > 1 {
+		// Work on ready tasks concurrently, each in its own worktree
+		if err := o.runParallel(startIteration); err != nil {
+			if o.ctx.Err() != nil {
+				logger.Info("Context cancelled, stopping parallel workers")
+				return nil
+			}
+			return err
+		}
+		if o.ctx.Err() != nil {
+			return nil
+		}
+		return o.endSession()
+	}
+
+	// Resolve the base branch for per-task branches (no-op unless enabled)
+	o.setupTaskBranches()
+
 	// Run iteration loop
 	iterationCount := 0
 	for {
@@ -558,6 +582,34 @@ func (o *Orchestrator) Run() error {
 			break
 		}
 
+		// Check session timeout
+		if o.sessionExpired() {
+			logger.Info("Reached session timeout of %s", o.cfg.SessionTimeout)
+			fmt.Printf("Reached session timeout of %s\n", o.cfg.SessionTimeout)
+			break
+		}
+
+		// Check whether a hook or the control API asked to stop
+		if o.stopRequested() {
+			break
+		}
+
+		// Check token and cost budget (may pause until the user resumes)
+		if stop, err := o.checkBudget(); err != nil {
+			logger.Info("Context cancelled during budget pause, stopping iteration loop")
+			return nil
+		} else if stop {
+			break
+		}
+
+		// Stop (or pause) instead of spinning when no task can be worked on
+		if stop, err := o.checkStalled(); err != nil {
+			logger.Info("Context cancelled during pause, stopping iteration loop")
+			return nil
+		} else if stop {
+			break
+		}
+
 		logger.Info("=== Starting iteration #%d ===", currentIteration)
 
 		// Clear file tracker and watcher for new iteration
@@ -568,16 +620,33 @@ func (o *Orchestrator) Run() error {
 		logger.Debug("File tracker cleared for iteration #%d", currentIteration)
 
 		// Log iteration start
+		o.iteration.Store(int64(currentIteration))
 		if err := o.store.IterationStart(o.ctx, o.cfg.SessionName, currentIteration); err != nil {
 			logger.Error("Failed to log iteration start: %v", err)
 			return fmt.Errorf("failed to log iteration start: %w", err)
 		}
+		o.checkpoint(currentIteration)
 
 		// Send iteration start message to TUI
 		if o.tuiProgram != nil {
 			o.tuiProgram.Send(tui.IterationStartMsg{Number: currentIteration})
 		}
 
+		// Re-plan the task list if the spec was edited since it was planned
+		if err := o.checkSpecChange(currentIteration); err != nil {
+			if o.ctx.Err() != nil {
+				logger.Info("Context cancelled during re-planning")
+				return nil
+			}
+			logger.Error("Re-planning failed: %v", err)
+			return err
+		}
+
+		// Switch to the task's branch/worktree before hooks and the agent run
+		if err := o.prepareTaskBranch(o.ctx, currentIteration); err != nil {
+			logger.Warn("Task branch setup failed: %v", err)
+		}
+
 		// Drain pending hook output from previous iterations (session_start, post_iteration, on_task_complete)
 		pendingOutput := o.drainPendingOutput()
 		if len(pendingOutput) > 0 {
@@ -588,12 +657,9 @@ func (o *Orchestrator) Run() error {
 		var hookOutput string
 		if o.hooksConfig != nil && len(o.hooksConfig.Hooks.PreIteration) > 0 {
 			logger.Debug("Executing %d pre-iteration hook(s)", len(o.hooksConfig.Hooks.PreIteration))
-			hookVars := hooks.Variables{
-				Session:   o.cfg.SessionName,
-				Iteration: strconv.Itoa(currentIteration),
-			}
+			hookVars := o.iterationHookVars("pre_iteration", currentIteration)
 			onStart, onComplete, _ := o.hookCallbacks("pre_iteration")
-			output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.PreIteration, o.cfg.WorkDir, hookVars, onStart, onComplete)
+			output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.PreIteration, o.iterationDir(), hookVars, onStart, onComplete)
 			if err != nil {
 				// Context cancelled - propagate
 				if o.ctx.Err() != nil {
@@ -622,26 +688,34 @@ func (o *Orchestrator) Run() error {
 
 		// Build prompt with current state
 		logger.Debug("Building prompt for iteration #%d", currentIteration)
-		prompt, err := template.BuildPrompt(o.ctx, template.BuildConfig{
+		prompt, compaction, err := template.BuildPrompt(o.ctx, template.BuildConfig{
 			SessionName:       o.cfg.SessionName,
 			Store:             o.store,
 			IterationNumber:   currentIteration,
-			SpecPath:          o.cfg.SpecPath,
+			SpecPaths:         o.cfg.SpecPaths,
+			RelevantSpec:      o.cfg.RelevantSpec,
 			TemplatePath:      o.cfg.TemplatePath,
 			ExtraInstructions: o.cfg.ExtraInstructions,
 			NATSPort:          o.natsPort,
+			Config:            o.templateConfig(),
+			Vars:              o.cfg.TemplateVars,
+			MaxTokens:         o.cfg.Prompt.BudgetFor(o.cfg.Model),
 		})
 		if err != nil {
 			logger.Error("Failed to build prompt: %v", err)
 			return fmt.Errorf("failed to build prompt: %w", err)
 		}
+		if compaction != nil {
+			o.recordCompaction(currentIteration, compaction)
+		}
 		logger.Debug("Prompt built, length: %d characters", len(prompt))
 
 		// Run agent iteration with panic recovery (reusing persistent ACP session)
 		// Hook output is sent as a separate content block before the main prompt
 		logger.Info("Running agent for iteration #%d", currentIteration)
+		// Timeouts and stalls are handled inside runAgent (retry or move on)
 		err = ierr.Recover(func() error {
-			return o.runner.RunIteration(o.ctx, prompt, hookOutput)
+			return o.runAgent(currentIteration, prompt, hookOutput)
 		})
 		if err != nil {
 			// Check if context was cancelled (TUI quit, signal, etc.) - exit gracefully
@@ -659,16 +733,15 @@ func (o *Orchestrator) Run() error {
 				logger.Error("Iteration #%d panicked with stack trace: %s", currentIteration, panicErr.StackTrace)
 			}
 
+			o.notify(config.NotifyError, fmt.Sprintf("Iteration #%d failed", currentIteration), err.Error())
+
 			// Execute on_error hooks if configured
 			if o.hooksConfig != nil && len(o.hooksConfig.Hooks.OnError) > 0 {
 				logger.Info("Executing on_error hooks for iteration #%d", currentIteration)
-				hookVars := hooks.Variables{
-					Session:   o.cfg.SessionName,
-					Iteration: strconv.Itoa(currentIteration),
-					Error:     err.Error(),
-				}
+				hookVars := o.iterationHookVars("on_error", currentIteration)
+				hookVars.Error = err.Error()
 				onStart, onComplete, _ := o.hookCallbacks("on_error")
-				hookOutput, hookErr := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.OnError, o.cfg.WorkDir, hookVars, onStart, onComplete)
+				hookOutput, hookErr := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.OnError, o.iterationDir(), hookVars, onStart, onComplete)
 				if hookErr != nil {
 					// Context cancelled - propagate
 					if o.ctx.Err() != nil {
@@ -766,12 +839,9 @@ func (o *Orchestrator) Run() error {
 		// Execute post-iteration hooks if configured
 		if o.hooksConfig != nil && len(o.hooksConfig.Hooks.PostIteration) > 0 {
 			logger.Debug("Executing %d post-iteration hook(s)", len(o.hooksConfig.Hooks.PostIteration))
-			hookVars := hooks.Variables{
-				Session:   o.cfg.SessionName,
-				Iteration: strconv.Itoa(currentIteration),
-			}
+			hookVars := o.iterationHookVars("post_iteration", currentIteration)
 			onStart, onComplete, _ := o.hookCallbacks("post_iteration")
-			output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.PostIteration, o.cfg.WorkDir, hookVars, onStart, onComplete)
+			output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.PostIteration, o.iterationDir(), hookVars, onStart, onComplete)
 			if err != nil {
 				// Context cancelled - propagate
 				if o.ctx.Err() != nil {
@@ -779,7 +849,7 @@ func (o *Orchestrator) Run() error {
 					return nil
 				}
 				logger.Error("Post-iteration hook execution failed: %v", err)
-			} else if output != "" {
+			} else if output != "" && o.stopRequest.Load() == nil {
 				// Send hook output to model with clear framing so the agent knows
 				// this is post-iteration verification, not a new task prompt.
 				logger.Debug("Post-iteration hook output: %d bytes (sending to model)", len(output))
@@ -806,15 +876,20 @@ func (o *Orchestrator) Run() error {
 			o.fileTracker.MergeWatcherPaths(watcherPaths)
 		}
 
-		// Run auto-commit if enabled and files were modified
-		if o.autoCommit && o.fileTracker.HasChanges() {
+		// Run auto-commit if enabled, files were modified and pre_commit hooks pass
+		if o.autoCommit && (o.fileTracker.HasChanges() || o.worktreeDirty()) && o.runPreCommitHooks(currentIteration) {
 			logger.Info("Auto-commit enabled with %d modified files, running commit", o.fileTracker.Count())
-			if err := o.runAutoCommit(o.ctx); err != nil {
+			if err := o.runAutoCommit(o.ctx, currentIteration); err != nil {
 				logger.Warn("Auto-commit failed: %v", err)
 				// Don't fail the iteration - just log the warning
 			}
 		}
 
+		// Merge or leave the task branch if its task is done
+		if err := o.finishTaskBranch(o.ctx); err != nil {
+			logger.Warn("Task branch completion failed: %v", err)
+		}
+
 		// Print completion message in headless mode
 		if o.cfg.Headless {
 			fmt.Printf("\n✓ Iteration #%d complete\n\n", currentIteration)
@@ -839,8 +914,17 @@ func (o *Orchestrator) Run() error {
 				select {
 				case <-o.tuiDone:
 					break postCompletionLoop
+				case <-o.stopChan:
+					break postCompletionLoop
 				case <-o.ctx.Done():
 					return nil
+				case to := <-o.rollbackChan:
+					o.rollback(to)
+					state, err = o.store.LoadState(o.ctx, o.cfg.SessionName)
+					if err == nil && !state.Complete {
+						logger.Info("Session rolled back, resuming iterations")
+						break postCompletionLoop
+					}
 				case userMsg := <-o.sendChan:
 					logger.Info("Processing user message after completion")
 					if o.tuiProgram != nil {
@@ -884,6 +968,9 @@ func (o *Orchestrator) Run() error {
 			return err
 		}
 
+		// Run a rollback requested during the iteration
+		o.applyPendingRollback()
+
 		// Check if paused - block until resumed or context cancelled
 		if err := o.waitIfPaused(); err != nil {
 			// Context cancelled during pause
@@ -891,10 +978,22 @@ func (o *Orchestrator) Run() error {
 			return nil
 		}
 
+		// After a rollback, continue numbering from the iteration rolled back to
+		if to, ok := o.takeRollback(); ok {
+			startIteration = to + 1 - (iterationCount + 1)
+		}
+
 		iterationCount++
 	}
 
 	logger.Info("Iteration loop finished for session '%s'", o.cfg.SessionName)
+	return o.endSession()
+}
+
+// endSession runs after the iteration loop: it delivers hook output still
+// pending to the agent, then runs the session_end hooks.
+func (o *Orchestrator) endSession() error {
+	o.loopDone.Store(true)
 
 	// Final delivery: if pending buffer has content, send to agent before session_end
 	// This gives the agent a chance to address test failures discovered in final post_iteration
@@ -920,10 +1019,8 @@ func (o *Orchestrator) Run() error {
 	// These run after final delivery. pipe_output is ignored - output is not piped anywhere (no more iterations)
 	if o.hooksConfig != nil && len(o.hooksConfig.Hooks.SessionEnd) > 0 {
 		logger.Info("Executing %d session_end hook(s)", len(o.hooksConfig.Hooks.SessionEnd))
-		hookVars := hooks.Variables{
-			Session: o.cfg.SessionName,
-			// Iteration is not set for session_end hooks (session-level, not iteration-level)
-		}
+		// Iteration is not set for session_end hooks (session-level, not iteration-level)
+		hookVars := o.hookVars("session_end", 0)
 		onStart, onComplete, _ := o.hookCallbacks("session_end")
 		_, err := hooks.ExecuteAllWithCallbacks(o.ctx, o.hooksConfig.Hooks.SessionEnd, o.cfg.WorkDir, hookVars, onStart, onComplete)
 		if err != nil {
@@ -942,6 +1039,27 @@ func (o *Orchestrator) Run() error {
 	return nil
 }
 
+// recordCompaction logs what was dropped to fit the prompt budget and records
+// it as a session event so it shows up in the log viewer.
+func (o *Orchestrator) recordCompaction(iteration int, c *template.Compaction) {
+	summary := c.Summary()
+	logger.Info("Iteration #%d: %s", iteration, summary)
+	if err := o.store.IterationCompact(o.ctx, o.cfg.SessionName, iteration, summary); err != nil {
+		logger.Warn("Failed to record prompt compaction for iteration #%d: %v", iteration, err)
+	}
+}
+
+// templateConfig returns the run configuration exposed to prompt templates as {{.Config}}.
+func (o *Orchestrator) templateConfig() template.ConfigInfo {
+	return template.ConfigInfo{
+		Model:      o.cfg.Model,
+		Iterations: o.cfg.Iterations,
+		AutoCommit: o.autoCommit,
+		Headless:   o.cfg.Headless,
+		WorkDir:    o.cfg.WorkDir,
+	}
+}
+
 // runIteration0 executes Iteration #0 (planning phase) for fresh sessions.
 // Uses the same MCP server as the main loop but with a planning-only prompt
 // that instructs the agent to load all tasks from the spec.
@@ -956,23 +1074,38 @@ func (o *Orchestrator) runIteration0() error {
 	}
 
 	// Log iteration start
+	o.iteration.Store(0)
 	if err := o.store.IterationStart(o.ctx, o.cfg.SessionName, 0); err != nil {
 		return fmt.Errorf("failed to log iteration #0 start: %w", err)
 	}
+	o.checkpoint(0)
 
 	// Send iteration start message to TUI
 	if o.tuiProgram != nil {
 		o.tuiProgram.Send(tui.IterationStartMsg{Number: 0})
 	}
 
+	// Import the spec checklist instead of asking the agent to plan
+	if o.cfg.Checklist.Import {
+		imported, err := o.runChecklistImport()
+		if err != nil {
+			return err
+		}
+		if imported {
+			return nil
+		}
+	}
+
 	// Build the planning prompt using the Iteration #0 template
 	prompt, err := template.BuildIteration0Prompt(o.ctx, template.BuildConfig{
 		SessionName:       o.cfg.SessionName,
 		Store:             o.store,
 		IterationNumber:   0,
-		SpecPath:          o.cfg.SpecPath,
+		SpecPaths:         o.cfg.SpecPaths,
 		ExtraInstructions: o.cfg.ExtraInstructions,
 		NATSPort:          o.natsPort,
+		Config:            o.templateConfig(),
+		Vars:              o.cfg.TemplateVars,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to build iteration #0 prompt: %w", err)
@@ -981,8 +1114,25 @@ func (o *Orchestrator) runIteration0() error {
 
 	// Run the agent using the main MCP server (same as iteration loop)
 	logger.Info("Running agent for Iteration #0")
-	if err := o.runner.RunIteration(o.ctx, prompt, ""); err != nil {
-		return fmt.Errorf("iteration #0 agent execution failed: %w", err)
+	for {
+		ctx, stop := o.iterationContext()
+		err = o.runner.RunIteration(ctx, prompt, "")
+		te := timeoutCause(ctx)
+		stop()
+		if te != nil && o.ctx.Err() == nil {
+			o.handleTimeout(0, te)
+			return fmt.Errorf("iteration #0 agent execution failed: %w", te)
+		}
+		if ierr.IsTransient(err) && o.ctx.Err() == nil {
+			if rerr := o.recoverAgent(0, err); rerr != nil {
+				return fmt.Errorf("iteration #0 agent execution failed: %w", rerr)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("iteration #0 agent execution failed: %w", err)
+		}
+		break
 	}
 
 	// Log iteration complete
@@ -992,6 +1142,9 @@ func (o *Orchestrator) runIteration0() error {
 
 	logger.Info("=== Iteration #0 (Planning Phase) completed ===")
 
+	// Later spec edits are detected against the spec the tasks were planned from
+	o.recordSpec(0)
+
 	if o.cfg.Headless {
 		fmt.Printf("\n✓ Iteration #0 (planning) complete\n\n")
 	}
@@ -1002,7 +1155,7 @@ func (o *Orchestrator) runIteration0() error {
 	}
 	if o.autoCommit && o.fileTracker.HasChanges() {
 		logger.Info("Auto-commit enabled with %d modified files after iteration #0", o.fileTracker.Count())
-		if err := o.runAutoCommit(o.ctx); err != nil {
+		if err := o.runAutoCommit(o.ctx, 0); err != nil {
 			logger.Warn("Auto-commit failed after iteration #0: %v", err)
 		}
 	}
@@ -1015,21 +1168,10 @@ func (o *Orchestrator) runIteration0() error {
 // Called after each agent response (iteration or user message).
 // Returns when channel is empty and all messages processed.
 func (o *Orchestrator) processUserMessages() error {
-	// Collect all queued messages
-	var messages []string
-	for {
-		select {
-		case <-o.ctx.Done():
-			return o.ctx.Err()
-		case userMsg := <-o.sendChan:
-			messages = append(messages, userMsg)
-		default:
-			// Channel empty, done collecting
-			goto send
-		}
+	if err := o.ctx.Err(); err != nil {
+		return err
 	}
-
-send:
+	messages := o.drainUserMessages()
 	if len(messages) == 0 {
 		return nil
 	}
@@ -1057,10 +1199,24 @@ send:
 	return nil
 }
 
+// drainUserMessages returns the messages queued in sendChan without waiting.
+func (o *Orchestrator) drainUserMessages() []string {
+	var messages []string
+	for {
+		select {
+		case userMsg := <-o.sendChan:
+			messages = append(messages, userMsg)
+		default:
+			return messages
+		}
+	}
+}
+
 // runAutoCommit executes auto-commit after iteration completes.
-// Checks if in git repo, builds commit prompt with file list and context,
-// and reuses existing Runner to send commit request to current ACP session.
-func (o *Orchestrator) runAutoCommit(ctx context.Context) error {
+// In the default "iteratr" mode the modified files are committed directly
+// (see commitIteration). In "agent" mode a commit prompt with the file list
+// and context is sent to the current ACP session instead.
+func (o *Orchestrator) runAutoCommit(ctx context.Context, iteration int) error {
 	// Check if in git repo
 	if !isGitRepo(o.cfg.WorkDir) {
 		logger.Debug("Not in git repo, skipping auto-commit")
@@ -1069,6 +1225,10 @@ func (o *Orchestrator) runAutoCommit(ctx context.Context) error {
 
 	logger.Info("Running auto-commit for %d modified file(s)", o.fileTracker.Count())
 
+	if o.cfg.Commit.Mode != config.CommitModeAgent {
+		return o.commitIteration(ctx, iteration)
+	}
+
 	// Build commit prompt with file list and context
 	prompt := o.buildCommitPrompt(ctx)
 
@@ -1117,7 +1277,11 @@ func (o *Orchestrator) buildCommitPrompt(ctx context.Context) string {
 
 	// Build prompt
 	var sb strings.Builder
-	sb.WriteString("Commit the following modified files:\n\n")
+	if len(paths) == 0 {
+		sb.WriteString("Commit all modified files shown by `git status`.\n")
+	} else {
+		sb.WriteString("Commit the following modified files:\n\n")
+	}
 	for _, p := range paths {
 		change := o.fileTracker.Get(p)
 		if change == nil {
@@ -1145,7 +1309,11 @@ func (o *Orchestrator) buildCommitPrompt(ctx context.Context) string {
 	}
 
 	sb.WriteString("\nInstructions:\n")
-	sb.WriteString("1. Stage only the listed files with `git add`\n")
+	if len(paths) == 0 {
+		sb.WriteString("1. Stage the modified files with `git add`\n")
+	} else {
+		sb.WriteString("1. Stage only the listed files with `git add`\n")
+	}
 	if o.cfg.CommitDataDir {
 		sb.WriteString(fmt.Sprintf("2. Also stage the data directory: `git add %s`\n", o.cfg.DataDir))
 		sb.WriteString("3. Create a commit with a clear, conventional message\n")
@@ -1173,6 +1341,16 @@ func (o *Orchestrator) Stop() error {
 	// Use MultiError to collect all shutdown errors
 	multiErr := &ierr.MultiError{}
 
+	// Stop accepting control API requests
+	if o.api != nil {
+		logger.Debug("Stopping control API")
+		if err := o.api.Stop(); err != nil {
+			logger.Error("Control API shutdown failed: %v", err)
+			multiErr.Append(err)
+		}
+		o.api = nil
+	}
+
 	// Cancel context to signal all goroutines to stop
 	if o.cancel != nil {
 		o.cancel()
@@ -1193,6 +1371,12 @@ func (o *Orchestrator) Stop() error {
 		o.tuiProgram = nil
 	}
 
+	// Let queued notifications (e.g. session complete) go out before exiting
+	if o.notifier != nil {
+		logger.Debug("Waiting for notifications to be delivered")
+		o.notifier.Wait()
+	}
+
 	// Stop file watcher
 	if o.fileWatcher != nil {
 		logger.Debug("Stopping file watcher")
@@ -1438,9 +1622,34 @@ func (o *Orchestrator) IsPaused() bool {
 	return o.paused.Load()
 }
 
+// requestStop asks the loop to stop before the next iteration. by says who
+// asked (e.g. "hook"); reason is optional. The first request wins.
+func (o *Orchestrator) requestStop(by, reason string) {
+	msg := "Stopped by " + by
+	if reason != "" {
+		msg += ": " + reason
+	}
+	if o.stopRequest.CompareAndSwap(nil, &msg) {
+		close(o.stopChan)
+	}
+}
+
+// stopRequested reports whether a stop was requested, logging who asked
+// when one was.
+func (o *Orchestrator) stopRequested() bool {
+	msg := o.stopRequest.Load()
+	if msg == nil {
+		return false
+	}
+	logger.Info("%s", *msg)
+	fmt.Println(*msg)
+	return true
+}
+
 // waitIfPaused blocks if the orchestrator is paused, waiting for resume or context cancellation.
 // Called after each iteration completes and user messages are processed.
-// Returns nil on resume, or ctx.Err() if context is cancelled.
+// Returns nil on resume or when a stop is requested, or ctx.Err() if context
+// is cancelled.
 func (o *Orchestrator) waitIfPaused() error {
 	// Fast path: if not paused, return immediately
 	if !o.paused.Load() {
@@ -1452,24 +1661,40 @@ func (o *Orchestrator) waitIfPaused() error {
 	if o.tuiProgram != nil {
 		o.tuiProgram.Send(tui.PauseStateMsg{Paused: true})
 	}
+	o.runPauseHooks(true)
 
-	// Block until resume signal or context cancellation
-	select {
-	case <-o.resumeChan:
-		// Drain channel in case of multiple signals (unlikely but safe)
+	// Block until resume signal or context cancellation.
+	// Rollbacks requested while paused run immediately.
+	for {
 		select {
 		case <-o.resumeChan:
-		default:
+			// Ignore a stale signal left by a resume that cancelled a pause
+			// request before the loop blocked
+			if o.paused.Load() {
+				continue
+			}
+			// Drain channel in case of multiple signals (unlikely but safe)
+			select {
+			case <-o.resumeChan:
+			default:
+			}
+			logger.Info("Orchestrator resumed")
+			o.runPauseHooks(false)
+			return nil
+		case to := <-o.rollbackChan:
+			o.rollback(to)
+		case <-o.stopChan:
+			logger.Info("Stop requested during pause")
+			return nil
+		case <-o.ctx.Done():
+			logger.Info("Context cancelled during pause")
+			return o.ctx.Err()
 		}
-		logger.Info("Orchestrator resumed")
-		return nil
-	case <-o.ctx.Done():
-		logger.Info("Context cancelled during pause")
-		return o.ctx.Err()
 	}
 }
 
-// hookCallbacks returns onStart and onComplete callbacks that send TUI messages.
+// hookCallbacks returns onStart and onComplete callbacks that send TUI messages
+// and apply the control documents hooks emit.
 // hookType is the lifecycle phase (e.g. "session_start", "pre_iteration").
 // Returns (onStart, onComplete, hookIDs) where hookIDs maps hook index → hookID.
 func (o *Orchestrator) hookCallbacks(hookType string) (hooks.OnHookStart, hooks.OnHookComplete, map[int]string) {
@@ -1477,7 +1702,9 @@ func (o *Orchestrator) hookCallbacks(hookType string) (hooks.OnHookStart, hooks.
 
 	if o.tuiProgram == nil {
 		// Headless mode - no TUI to notify
-		return nil, nil, hookIDs
+		return nil, func(_ int, result hooks.HookResult) {
+			o.applyHookControls(hookType, result.Controls)
+		}, hookIDs
 	}
 
 	onStart := func(hookIndex int, command string) {
@@ -1491,6 +1718,7 @@ func (o *Orchestrator) hookCallbacks(hookType string) (hooks.OnHookStart, hooks.
 	}
 
 	onComplete := func(hookIndex int, result hooks.HookResult) {
+		o.applyHookControls(hookType, result.Controls)
 		id, ok := hookIDs[hookIndex]
 		if !ok {
 			return
@@ -36,7 +36,7 @@ This is a test spec.
 	// Create orchestrator
 	orch, err := New(Config{
 		SessionName: "test-shutdown",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		Iterations:  1,
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
@@ -93,7 +93,7 @@ func TestShutdownIdempotency(t *testing.T) {
 	// Create orchestrator
 	orch, err := New(Config{
 		SessionName: "test-idempotency",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		Iterations:  1,
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
@@ -140,7 +140,7 @@ func TestContextCancellation(t *testing.T) {
 	// Create orchestrator
 	orch, err := New(Config{
 		SessionName: "test-context",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		Iterations:  1,
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
@@ -201,7 +201,7 @@ This is a test spec for iteration loop testing.
 	// Create orchestrator with limited iterations
 	orch, err := New(Config{
 		SessionName: "test-iteration-loop",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		Iterations:  2, // Run exactly 2 iterations
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
@@ -262,7 +262,7 @@ Resumable session test.
 	{
 		orch, err := New(Config{
 			SessionName: sessionName,
-			SpecPath:    specPath,
+			SpecPaths:   []string{specPath},
 			Iterations:  0, // Unlimited for manual control
 			DataDir:     dataDir,
 			WorkDir:     tmpDir,
@@ -297,7 +297,7 @@ Resumable session test.
 	{
 		orch, err := New(Config{
 			SessionName: sessionName,
-			SpecPath:    specPath,
+			SpecPaths:   []string{specPath},
 			Iterations:  0,
 			DataDir:     dataDir,
 			WorkDir:     tmpDir,
@@ -351,7 +351,7 @@ Test session complete signal.
 
 	orch, err := New(Config{
 		SessionName: sessionName,
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		Iterations:  0, // Unlimited
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
@@ -429,7 +429,7 @@ Model configuration test.
 
 			cfg := Config{
 				SessionName: "test-model-" + tt.name,
-				SpecPath:    specPath,
+				SpecPaths:   []string{specPath},
 				Iterations:  1,
 				DataDir:     dataDir,
 				WorkDir:     tmpDir,
@@ -484,7 +484,7 @@ Headless mode test.
 	// Create orchestrator in headless mode
 	orch, err := New(Config{
 		SessionName: "test-headless",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		Iterations:  1,
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
@@ -524,7 +524,7 @@ TUI mode test.
 	// Create orchestrator with TUI enabled
 	orch, err := New(Config{
 		SessionName: "test-tui",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		Iterations:  1,
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
@@ -754,7 +754,7 @@ func TestBuildCommitPrompt(t *testing.T) {
 			// Create orchestrator and start it to get a real store
 			orch, err := New(Config{
 				SessionName: "test-build-commit-prompt",
-				SpecPath:    filepath.Join(tmpDir, "test.md"),
+				SpecPaths:   []string{filepath.Join(tmpDir, "test.md")},
 				DataDir:     dataDir,
 				WorkDir:     tmpDir,
 				Headless:    true,
@@ -909,7 +909,7 @@ Test spec for verifying file tracking integration.
 	// Create orchestrator
 	orch, err := New(Config{
 		SessionName: "test-file-tracking",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
 		Headless:    true,
@@ -1078,7 +1078,7 @@ hooks:
 	// Create orchestrator
 	orch, err := New(Config{
 		SessionName: "test-session-start",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
 		Headless:    true,
@@ -1143,7 +1143,7 @@ hooks:
 	// Create orchestrator
 	orch, err := New(Config{
 		SessionName: "test-session-start-pipe",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
 		Headless:    true,
@@ -1206,7 +1206,7 @@ hooks:
 	// Create orchestrator
 	orch, err := New(Config{
 		SessionName: "test-session-start-cancel",
-		SpecPath:    specPath,
+		SpecPaths:   []string{specPath},
 		DataDir:     dataDir,
 		WorkDir:     tmpDir,
 		Headless:    true,
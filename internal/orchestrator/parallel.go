@@ -0,0 +1,720 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/mcpserver"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/template"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// worker is one agent working on a single ready task in its own worktree.
+type worker struct {
+	slot      int // 1-based worker number shown in the TUI
+	iteration int
+	task      *session.Task
+	branch    string
+	dir       string // Task worktree
+	runner    *agent.Runner
+	mcp       *mcpserver.Server
+
+	uncommitted bool // A pre_commit hook failed; changes were left in the worktree
+
+	mu      sync.Mutex
+	partial string   // Headless output not yet ended by a newline
+	inbox   []string // User messages waiting for the agent's current prompt to end
+	closed  bool     // The agent run ended; no more messages are taken
+}
+
+// workerResult is sent when a worker's agent run ends.
+type workerResult struct {
+	w   *worker
+	err error
+}
+
+// setupParallel resolves the branch worker branches fork from and are merged
+// into, and checks it out in the work dir. Tasks left in progress by an
+// earlier run are made ready again.
+func (o *Orchestrator) setupParallel() error {
+	if !git.IsRepo(o.cfg.WorkDir) {
+		return fmt.Errorf("parallelism requires a git repository")
+	}
+	base := o.cfg.TaskBranches.Base
+	current, err := git.CurrentBranch(o.cfg.WorkDir)
+	if err != nil {
+		return fmt.Errorf("failed to determine current branch: %w", err)
+	}
+	if base == "" {
+		if current == "HEAD" {
+			return fmt.Errorf("detached HEAD: set task_branches.base to run tasks in parallel")
+		}
+		base = current
+	}
+	if current != base {
+		if err := git.Checkout(o.cfg.WorkDir, base, ""); err != nil {
+			return err
+		}
+	}
+	o.baseBranch = base
+
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	for _, task := range state.Tasks {
+		if task.Status != "in_progress" {
+			continue
+		}
+		logger.Info("Task %s was left in progress, making it ready again", task.ID)
+		if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: "remaining", Iteration: task.Iteration}); err != nil {
+			return fmt.Errorf("failed to reset %s: %w", task.ID, err)
+		}
+	}
+	logger.Info("Running up to %d tasks in parallel (base=%s)", o.cfg.Parallelism, base)
+	return nil
+}
+
+// runParallel replaces the sequential iteration loop when parallelism > 1.
+// Each iteration is one worker running one ready task (remaining, with all
+// dependencies completed) in its own ACP session and task worktree. When a
+// worker's task is completed its branch is merged into the base branch;
+// files changed both by the task and on the base since the task started are
+// reported, and a merge conflict leaves the task blocked with a note.
+func (o *Orchestrator) runParallel(startIteration int) error {
+	if err := o.setupParallel(); err != nil {
+		return err
+	}
+
+	results := make(chan workerResult, o.cfg.Parallelism)
+	active := make(map[int]*worker) // By slot
+	var queued []string             // User messages for the next workers started
+	next := startIteration
+	stopping := false
+	for {
+		if len(active) == 0 {
+			if o.ctx.Err() != nil {
+				return nil
+			}
+			// Between rounds: user messages go to the next workers; pause
+			// and spec edits are handled as in the sequential loop
+			queued = append(queued, o.drainUserMessages()...)
+			if err := o.waitIfPaused(); err != nil {
+				logger.Info("Context cancelled during pause, stopping parallel workers")
+				return nil
+			}
+			if err := o.checkSpecChange(next - 1); err != nil {
+				if o.ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if !stopping {
+			stop, err := o.parallelStop(next - startIteration)
+			if err != nil {
+				return err
+			}
+			stopping = stop
+		}
+
+		if !stopping && !o.IsPaused() {
+			state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+			if err != nil {
+				return fmt.Errorf("failed to load state: %w", err)
+			}
+			for _, task := range state.ReadyTasks() {
+				if len(active) >= o.cfg.Parallelism || o.iterationLimitReached(next-startIteration) {
+					break
+				}
+				slot := 1
+				for active[slot] != nil {
+					slot++
+				}
+				w, err := o.startWorker(slot, next, task, queued, results)
+				if err != nil {
+					o.drainWorkers(active, results)
+					return fmt.Errorf("failed to start worker for %s: %w", task.ID, err)
+				}
+				active[slot] = w
+				next++
+			}
+			if len(active) > 0 && len(queued) > 0 {
+				if o.tuiProgram != nil {
+					for _, text := range queued {
+						o.tuiProgram.Send(tui.QueuedMessageProcessingMsg{Text: text})
+					}
+				}
+				queued = nil
+			}
+		}
+
+		if len(active) == 0 {
+			break
+		}
+		select {
+		case res := <-results:
+			delete(active, res.w.slot)
+			o.finishWorker(res.w, res.err)
+		case text := <-o.sendChan:
+			if !o.deliverToWorkers(active, text) {
+				queued = append(queued, text)
+			}
+		}
+	}
+	if len(queued) > 0 {
+		logger.Warn("%d user message(s) not delivered: no worker was started after they were sent", len(queued))
+	}
+
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if stopping || state.Complete {
+		return nil
+	}
+	if state.Stalled() {
+		chains := blockingChains(state)
+		logger.Warn("No ready tasks but work remains, blocked by: %s", strings.Join(chains, "; "))
+		if o.tuiProgram != nil {
+			o.tuiProgram.Send(tui.ShowToastMsg{Text: "No ready tasks, blocked by " + strings.Join(chains, "; ")})
+		} else {
+			fmt.Println("No ready tasks but work remains, stopping parallel workers. Blocked by:")
+			for _, chain := range chains {
+				fmt.Printf("  %s\n", chain)
+			}
+		}
+		return nil
+	}
+	logger.Info("All tasks done, marking session '%s' complete", o.cfg.SessionName)
+	if err := o.store.SessionComplete(o.ctx, o.cfg.SessionName); err != nil {
+		return fmt.Errorf("failed to mark session complete: %w", err)
+	}
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.SessionCompleteMsg{})
+	}
+	return nil
+}
+
+// parallelStop checks the limits that end the parallel loop: cancellation,
+// the iteration limit (counting started iterations), the session timeout,
+// a stop requested by a hook or the control API, the token and cost budget,
+// and session completion.
+func (o *Orchestrator) parallelStop(started int) (bool, error) {
+	if o.ctx.Err() != nil {
+		return true, nil
+	}
+	if o.iterationLimitReached(started) {
+		logger.Info("Reached iteration limit of %d", o.cfg.Iterations)
+		fmt.Printf("Reached iteration limit of %d\n", o.cfg.Iterations)
+		return true, nil
+	}
+	if o.sessionExpired() {
+		logger.Info("Reached session timeout of %s", o.cfg.SessionTimeout)
+		fmt.Printf("Reached session timeout of %s\n", o.cfg.SessionTimeout)
+		return true, nil
+	}
+	if o.stopRequested() {
+		return true, nil
+	}
+	if stop, err := o.checkBudget(); err != nil || stop {
+		return true, nil
+	}
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return true, fmt.Errorf("failed to load state: %w", err)
+	}
+	if state.Complete {
+		logger.Info("Session '%s' marked as complete", o.cfg.SessionName)
+		return true, nil
+	}
+	return false, nil
+}
+
+// iterationLimitReached reports whether started iterations reached the limit.
+func (o *Orchestrator) iterationLimitReached(started int) bool {
+	return o.cfg.Iterations > 0 && started >= o.cfg.Iterations
+}
+
+// drainWorkers waits for the active workers to finish and handles their results.
+func (o *Orchestrator) drainWorkers(active map[int]*worker, results chan workerResult) {
+	for len(active) > 0 {
+		res := <-results
+		delete(active, res.w.slot)
+		o.finishWorker(res.w, res.err)
+	}
+}
+
+// startWorker starts an iteration on task in a new worker: it creates the
+// task worktree, starts an agent with its own MCP server (pinned to the
+// iteration) in the worktree, and marks the task in progress. The result is
+// sent on results when the agent run ends. User messages queued while no
+// worker was running are added to the worker's assignment. If a step fails,
+// the steps before it are undone so the task is ready again and no worktree
+// is left behind.
+func (o *Orchestrator) startWorker(slot, iteration int, task *session.Task, messages []string, results chan<- workerResult) (*worker, error) {
+	w := &worker{slot: slot, iteration: iteration, task: task, branch: task.Branch}
+	if w.branch == "" {
+		w.branch = taskBranchName(o.cfg.TaskBranches.Prefix, task)
+	}
+	w.dir = o.worktreePath(w.branch)
+	if err := git.AddWorktree(o.cfg.WorkDir, w.dir, w.branch, o.baseBranch); err != nil {
+		return nil, err
+	}
+
+	started := false
+	undo := func(err error) (*worker, error) {
+		w.stop()
+		if started {
+			if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: "remaining", Iteration: iteration}); err != nil {
+				logger.Warn("Failed to reset %s: %v", task.ID, err)
+			}
+			if err := o.store.IterationComplete(o.ctx, o.cfg.SessionName, iteration); err != nil {
+				logger.Warn("Failed to log iteration #%d complete: %v", iteration, err)
+			}
+		}
+		if err := git.RemoveWorktree(o.cfg.WorkDir, w.dir); err != nil {
+			logger.Warn("Failed to remove worktree %s: %v", w.dir, err)
+		}
+		return nil, err
+	}
+
+	w.mcp = mcpserver.New(o.store, o.cfg.SessionName)
+	w.mcp.SetIteration(iteration)
+	w.mcp.SetVerify(o.cfg.Verify, func() string { return w.dir })
+	if _, err := w.mcp.Start(o.ctx); err != nil {
+		return undo(fmt.Errorf("failed to start MCP server: %w", err))
+	}
+	w.runner = agent.NewRunner(agent.RunnerConfig{
+		Backend:      o.cfg.Backend,
+		Model:        o.cfg.Model,
+		WorkDir:      w.dir,
+		SessionName:  o.cfg.SessionName,
+		NATSPort:     o.natsPort,
+		MCPServerURL: w.mcp.URL(),
+		OnPermission: o.permissionHandler(),
+		OnText:       o.workerOutput(w),
+		OnToolCall: func(event agent.ToolCallEvent) {
+			if event.Status == "pending" {
+				o.workerOutput(w)(fmt.Sprintf("\n[tool: %s]\n", event.Title))
+			}
+		},
+		OnFinish: func(event agent.FinishEvent) {
+			o.recordIterationUsage(iteration, event)
+		},
+	})
+	if err := w.runner.Start(o.ctx); err != nil {
+		return undo(fmt.Errorf("failed to start ACP session: %w", err))
+	}
+
+	logger.Info("=== Starting iteration #%d: worker %d on %s (%s) ===", iteration, slot, task.ID, w.branch)
+	if err := o.store.IterationStart(o.ctx, o.cfg.SessionName, iteration); err != nil {
+		return undo(fmt.Errorf("failed to log iteration start: %w", err))
+	}
+	started = true
+	if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: "in_progress", Iteration: iteration}); err != nil {
+		return undo(fmt.Errorf("failed to start %s: %w", task.ID, err))
+	}
+	if task.Branch != w.branch {
+		if err := o.store.TaskBranch(o.ctx, o.cfg.SessionName, session.TaskBranchParams{ID: task.ID, Branch: w.branch, Iteration: iteration}); err != nil {
+			logger.Warn("Failed to record branch for task %s: %v", task.ID, err)
+		}
+	}
+
+	// Hook output piped since the last iteration goes to the next worker,
+	// ahead of its own pre_iteration hook output
+	hookOutput := o.drainPendingOutput()
+	if output, _ := o.runWorkerHooks(w, "pre_iteration", o.workerHooks().PreIteration, o.workerHookVars("pre_iteration", w)); output != "" {
+		if hookOutput != "" {
+			hookOutput += "\n"
+		}
+		hookOutput += output
+	}
+
+	prompt, compaction, err := template.BuildPrompt(o.ctx, template.BuildConfig{
+		SessionName:       o.cfg.SessionName,
+		Store:             o.store,
+		IterationNumber:   iteration,
+		SpecPaths:         o.cfg.SpecPaths,
+		RelevantSpec:      o.cfg.RelevantSpec,
+		TemplatePath:      o.cfg.TemplatePath,
+		ExtraInstructions: o.cfg.ExtraInstructions,
+		NATSPort:          o.natsPort,
+		Config:            o.templateConfig(),
+		Vars:              o.cfg.TemplateVars,
+		MaxTokens:         o.cfg.Prompt.BudgetFor(o.cfg.Model),
+		TaskID:            task.ID,
+	})
+	if err != nil {
+		o.appendPendingOutput(hookOutput) // Keep it for the next worker
+		return undo(fmt.Errorf("failed to build prompt: %w", err))
+	}
+	if compaction != nil {
+		o.recordCompaction(iteration, compaction)
+	}
+	assignment := fmt.Sprintf(
+		"[PARALLEL WORKER %d - iteration #%d]\n"+
+			"You are one of several agents working on this session at the same time.\n"+
+			"Work ONLY on task %s: %s\n"+
+			"The task is already marked in_progress. Do NOT call task-next or start any other task.\n"+
+			"Your working directory is a git worktree on branch %s; iteratr commits and merges your work.\n"+
+			"When done, mark %s completed and record the iteration summary, then STOP.",
+		slot, iteration, task.ID, task.Title(), w.branch, task.ID,
+	)
+	if hookOutput != "" {
+		assignment += "\n\n" + hookOutput
+	}
+	if len(messages) > 0 {
+		assignment += "\n\nMessages from the user:\n" + strings.Join(messages, "\n\n")
+	}
+
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.WorkerStartMsg{Worker: slot, Iteration: iteration, Task: task, Branch: w.branch})
+	} else {
+		fmt.Printf("[worker %d] #%d %s: %s (%s)\n", slot, iteration, task.ID, task.Title(), w.branch)
+	}
+
+	go func() {
+		err := ierr.Recover(func() error {
+			ctx, stop := o.runnerContext(w.runner, task.ID)
+			defer stop()
+			err := w.runner.RunIteration(ctx, prompt, assignment)
+			if te := timeoutCause(ctx); te != nil && o.ctx.Err() == nil {
+				o.handleTimeout(iteration, te)
+				return nil
+			}
+			if errors.Is(context.Cause(ctx), errTaskSkipped) && o.ctx.Err() == nil {
+				return nil
+			}
+			if o.ctx.Err() == nil {
+				o.runWorkerIterationHooks(w, err)
+				o.sendWorkerMessages(w)
+			}
+			return err
+		})
+		if dropped := w.close(); len(dropped) > 0 {
+			logger.Info("Worker %d ended before %d user message(s) could be sent", w.slot, len(dropped))
+		}
+		results <- workerResult{w: w, err: err}
+	}()
+	return w, nil
+}
+
+// workerHooks returns the configured hooks (none without a hooks file).
+func (o *Orchestrator) workerHooks() hooks.HooksConfig {
+	if o.hooksConfig == nil {
+		return hooks.HooksConfig{}
+	}
+	return o.hooksConfig.Hooks
+}
+
+// workerHookVars returns the hook variables for a worker's task, with the
+// files changed in its worktree.
+func (o *Orchestrator) workerHookVars(hookType string, w *worker) hooks.Variables {
+	vars := o.taskHookVars(hookType, w.task, w.iteration)
+	changed, err := git.ChangedPaths(w.dir)
+	if err != nil {
+		logger.Warn("Failed to list changes in %s: %v", w.dir, err)
+	}
+	slices.Sort(changed)
+	vars.Changed = changed
+	return vars
+}
+
+// runWorkerHooks runs a worker's hooks of hookType in its worktree. Returns
+// their piped output and false if a hook failed or could not run.
+func (o *Orchestrator) runWorkerHooks(w *worker, hookType string, list []*hooks.HookConfig, vars hooks.Variables) (string, bool) {
+	if len(list) == 0 {
+		return "", true
+	}
+	logger.Debug("Worker %d: executing %d %s hook(s)", w.slot, len(list), hookType)
+	failed := false
+	onStart, onComplete, _ := o.hookCallbacks(hookType)
+	trackFailure := func(hookIndex int, result hooks.HookResult) {
+		if result.Failed {
+			failed = true
+		}
+		if onComplete != nil {
+			onComplete(hookIndex, result)
+		}
+	}
+	output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, list, w.dir, vars, onStart, trackFailure)
+	if err != nil {
+		if o.ctx.Err() == nil {
+			logger.Error("Worker %d: %s hook execution failed: %v", w.slot, hookType, err)
+		}
+		return "", false
+	}
+	return output, !failed
+}
+
+// runWorkerIterationHooks runs the on_error hooks after a failed agent run, or
+// the post_iteration hooks after a successful one, and sends their piped
+// output to the worker's agent to act on before the worker finishes.
+func (o *Orchestrator) runWorkerIterationHooks(w *worker, runErr error) {
+	var hookType, framed string
+	if runErr != nil {
+		vars := o.workerHookVars("on_error", w)
+		vars.Error = runErr.Error()
+		output, _ := o.runWorkerHooks(w, "on_error", o.workerHooks().OnError, vars)
+		if output == "" {
+			return
+		}
+		hookType = "on_error"
+		framed = fmt.Sprintf(
+			"[ON-ERROR HOOKS - iteration #%d]\n"+
+				"The iteration failed with error: %s\n\n"+
+				"Diagnostic output from error hooks:\n%s\n\n"+
+				"Fix the issue if possible, then STOP. Do NOT start another task.",
+			w.iteration, runErr.Error(), output,
+		)
+	} else {
+		output, _ := o.runWorkerHooks(w, "post_iteration", o.workerHooks().PostIteration, o.workerHookVars("post_iteration", w))
+		if output == "" || o.stopRequest.Load() != nil {
+			return
+		}
+		hookType = "post_iteration"
+		framed = fmt.Sprintf(
+			"[POST-ITERATION HOOKS - iteration #%d]\n"+
+				"The following output is from post-iteration hooks (linting, vetting, etc.).\n"+
+				"If there are errors or issues, fix them now. Do NOT start another task.\n"+
+				"When done fixing (or if no issues), STOP immediately.\n\n%s",
+			w.iteration, output,
+		)
+	}
+	if err := w.runner.SendMessages(o.ctx, []string{framed}); err != nil && o.ctx.Err() == nil {
+		logger.Error("Worker %d: failed to send %s hook output to agent: %v", w.slot, hookType, err)
+	}
+}
+
+// deliverToWorkers queues a user message for every active worker's agent.
+// Returns false if no worker took it.
+func (o *Orchestrator) deliverToWorkers(active map[int]*worker, text string) bool {
+	delivered := false
+	for _, w := range active {
+		if w.deliver(text) {
+			delivered = true
+		}
+	}
+	if delivered {
+		logger.Info("Queued user message for %d worker(s)", len(active))
+		if o.tuiProgram != nil {
+			o.tuiProgram.Send(tui.QueuedMessageProcessingMsg{Text: text})
+		}
+	}
+	return delivered
+}
+
+// sendWorkerMessages sends the user messages queued for a worker to its
+// agent once its prompt has ended, until none are left.
+func (o *Orchestrator) sendWorkerMessages(w *worker) {
+	for {
+		messages := w.takeMessages()
+		if len(messages) == 0 || o.ctx.Err() != nil {
+			return
+		}
+		logger.Info("Worker %d: sending %d user message(s) to agent", w.slot, len(messages))
+		if err := w.runner.SendMessages(o.ctx, messages); err != nil && o.ctx.Err() == nil {
+			logger.Error("Worker %d: failed to send user messages: %v", w.slot, err)
+		}
+	}
+}
+
+// deliver queues a user message for the worker's agent. Returns false if the
+// agent run already ended.
+func (w *worker) deliver(text string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return false
+	}
+	w.inbox = append(w.inbox, text)
+	return true
+}
+
+// takeMessages returns and clears the queued user messages.
+func (w *worker) takeMessages() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	messages := w.inbox
+	w.inbox = nil
+	return messages
+}
+
+// close stops the worker from taking user messages. Returns the messages
+// that were never sent.
+func (w *worker) close() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	messages := w.inbox
+	w.inbox = nil
+	return messages
+}
+
+// workerOutput returns the callback showing a worker's agent output: in its
+// TUI pane, or on stdout prefixed with the worker number.
+func (o *Orchestrator) workerOutput(w *worker) func(string) {
+	return func(text string) {
+		if o.tuiProgram != nil {
+			o.tuiProgram.Send(tui.WorkerOutputMsg{Worker: w.slot, Content: text})
+			return
+		}
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		lines := strings.Split(w.partial+text, "\n")
+		w.partial = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			if strings.TrimSpace(line) != "" {
+				fmt.Printf("[worker %d] %s\n", w.slot, line)
+			}
+		}
+	}
+}
+
+// stop shuts down the worker's agent and MCP server.
+func (w *worker) stop() {
+	if w.runner != nil {
+		w.runner.Stop()
+	}
+	if w.mcp != nil {
+		if err := w.mcp.Stop(); err != nil {
+			logger.Debug("Failed to stop worker MCP server: %v", err)
+		}
+	}
+}
+
+// finishWorker completes a worker's iteration: it commits the worktree,
+// merges the task branch if the task was completed, makes an unfinished task
+// ready again, and removes the worktree (the branch is kept).
+func (o *Orchestrator) finishWorker(w *worker, runErr error) {
+	w.stop()
+	if o.ctx.Err() != nil {
+		return
+	}
+	if runErr != nil {
+		logger.Error("Iteration #%d (worker %d, %s) failed: %v", w.iteration, w.slot, w.task.ID, runErr)
+	}
+	if err := o.store.IterationComplete(o.ctx, o.cfg.SessionName, w.iteration); err != nil {
+		logger.Error("Failed to log iteration #%d complete: %v", w.iteration, err)
+	}
+
+	if err := o.commitWorker(w); err != nil {
+		logger.Warn("Worker %d: commit failed: %v", w.slot, err)
+	}
+
+	result, ok := o.mergeWorker(w)
+	if w.uncommitted {
+		logger.Info("Keeping worktree %s with uncommitted changes", w.dir)
+	} else if err := git.RemoveWorktree(o.cfg.WorkDir, w.dir); err != nil {
+		logger.Warn("Failed to remove worktree %s: %v", w.dir, err)
+	}
+	logger.Info("=== Iteration #%d (worker %d, %s) finished: %s ===", w.iteration, w.slot, w.task.ID, result)
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.WorkerDoneMsg{Worker: w.slot, Result: result, OK: ok})
+	} else {
+		fmt.Printf("[worker %d] ✓ iteration #%d %s: %s\n", w.slot, w.iteration, w.task.ID, result)
+	}
+}
+
+// commitWorker commits every change in the worker's worktree using the
+// configured commit message template, once the pre_commit hooks pass. If a
+// hook fails the changes are left in the worktree, which the task's next
+// worker reuses.
+func (o *Orchestrator) commitWorker(w *worker) error {
+	if dirty, err := git.IsDirty(w.dir); err != nil || !dirty {
+		return err
+	}
+	output, ok := o.runWorkerHooks(w, "pre_commit", o.workerHooks().PreCommit, o.workerHookVars("pre_commit", w))
+	o.appendPendingOutput(output)
+	if !ok {
+		w.uncommitted = true
+		logger.Warn("Worker %d: pre_commit hook failed, leaving changes to %s uncommitted", w.slot, w.task.ID)
+		return nil
+	}
+
+	vars := o.commitVars(o.ctx, w.iteration)
+	vars.TaskID = w.task.ID
+	vars.Task = w.task.Title()
+	vars.Branch = w.branch
+	message, trailers := renderCommitMessage(o.cfg.Commit.Message, o.cfg.Commit.Trailers, vars)
+
+	sha, err := git.Commit(w.dir, git.CommitOptions{Message: message, Trailers: trailers})
+	if err != nil || sha == "" {
+		return err
+	}
+	logger.Info("Worker %d committed %s on %s", w.slot, sha, w.branch)
+	if err := o.store.IterationCommit(o.ctx, o.cfg.SessionName, w.iteration, sha); err != nil {
+		logger.Warn("Failed to record commit for iteration #%d: %v", w.iteration, err)
+	}
+	return nil
+}
+
+// mergeWorker merges the branch of a completed task into the base branch.
+// An unfinished task is made ready again. Returns the outcome shown to the
+// user and whether it went well.
+func (o *Orchestrator) mergeWorker(w *worker) (string, bool) {
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load state: %v", err)
+		return "unknown", false
+	}
+	task, exists := state.Tasks[w.task.ID]
+	if !exists {
+		return "task deleted", false
+	}
+	switch task.Status {
+	case "completed":
+		if w.uncommitted {
+			// Merging would leave the uncommitted changes behind
+			if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: "remaining", Iteration: w.iteration}); err != nil {
+				logger.Warn("Failed to reopen %s: %v", task.ID, err)
+			}
+			return "pre_commit failed, reopened", false
+		}
+	case "in_progress":
+		if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: "remaining", Iteration: w.iteration}); err != nil {
+			logger.Warn("Failed to reset %s: %v", task.ID, err)
+		}
+		return "unfinished", false
+	default:
+		return task.Status, task.Status != "blocked"
+	}
+
+	overlap, err := git.OverlappingChanges(o.cfg.WorkDir, o.baseBranch, w.branch)
+	if err != nil {
+		logger.Warn("Failed to compare %s with %s: %v", w.branch, o.baseBranch, err)
+	} else if len(overlap) > 0 {
+		logger.Warn("%s and other merged work both changed: %s", task.ID, strings.Join(overlap, ", "))
+	}
+
+	message := fmt.Sprintf("Merge %s: %s", task.ID, task.Title())
+	if err := git.Merge(o.cfg.WorkDir, w.branch, message); err != nil {
+		logger.Warn("Merge of %s failed: %v", w.branch, err)
+		if err := o.store.TaskStatus(o.ctx, o.cfg.SessionName, session.TaskStatusParams{ID: task.ID, Status: "blocked", Iteration: w.iteration}); err != nil {
+			logger.Warn("Failed to block %s: %v", task.ID, err)
+		}
+		files := "unknown files"
+		if len(overlap) > 0 {
+			files = strings.Join(overlap, ", ")
+		}
+		note := fmt.Sprintf("%s conflicts with work merged in parallel (%s). Its branch %s was not merged; resolve the conflict and merge it into %s.", task.ID, files, w.branch, o.baseBranch)
+		if _, err := o.store.NoteAdd(o.ctx, o.cfg.SessionName, session.NoteAddParams{Content: note, Type: "stuck", Iteration: w.iteration}); err != nil {
+			logger.Warn("Failed to add conflict note for %s: %v", task.ID, err)
+		}
+		return "conflict: " + files, false
+	}
+	logger.Info("Merged %s into %s", w.branch, o.baseBranch)
+	return "merged", true
+}
@@ -0,0 +1,304 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// setupParallelTest returns an orchestrator in parallel mode with two tasks.
+func setupParallelTest(t *testing.T) (*Orchestrator, *session.Task, *session.Task) {
+	t.Helper()
+	o, first := setupGitSessionTest(t)
+	second, err := o.store.TaskAdd(o.ctx, "branches", session.TaskAddParams{Content: "Add signup form"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.cfg.Parallelism = 2
+	if err := o.setupParallel(); err != nil {
+		t.Fatalf("setupParallel() error = %v", err)
+	}
+	return o, first, second
+}
+
+// startTestWorker creates a worker for task with its worktree, as startWorker
+// does, without running an agent. The task is marked with status.
+func startTestWorker(t *testing.T, o *Orchestrator, slot, iteration int, task *session.Task, status string) *worker {
+	t.Helper()
+	branch := taskBranchName("", task)
+	w := &worker{slot: slot, iteration: iteration, task: task, branch: branch, dir: o.worktreePath(branch)}
+	if err := git.AddWorktree(o.cfg.WorkDir, w.dir, branch, o.baseBranch); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.IterationStart(o.ctx, "branches", iteration); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.TaskStatus(o.ctx, "branches", session.TaskStatusParams{ID: task.ID, Status: status, Iteration: iteration}); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func writeIn(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFinishWorker_MergesCompletedTasks(t *testing.T) {
+	o, first, second := setupParallelTest(t)
+	w1 := startTestWorker(t, o, 1, 1, first, "completed")
+	w2 := startTestWorker(t, o, 2, 2, second, "completed")
+	writeIn(t, w1.dir, "login.txt", "login")
+	writeIn(t, w2.dir, "signup.txt", "signup")
+
+	o.finishWorker(w2, nil)
+	o.finishWorker(w1, nil)
+
+	for _, name := range []string{"login.txt", "signup.txt"} {
+		if _, err := os.Stat(filepath.Join(o.cfg.WorkDir, name)); err != nil {
+			t.Errorf("%s not merged into the base branch: %v", name, err)
+		}
+	}
+	for _, w := range []*worker{w1, w2} {
+		if _, err := os.Stat(w.dir); !os.IsNotExist(err) {
+			t.Errorf("worktree %s not removed", w.dir)
+		}
+	}
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iter := range state.Iterations {
+		if !iter.Complete || iter.CommitSHA == "" {
+			t.Errorf("iteration #%d = %+v, want complete with a commit", iter.Number, iter)
+		}
+	}
+}
+
+func TestFinishWorker_ConflictBlocksTask(t *testing.T) {
+	o, first, second := setupParallelTest(t)
+	w1 := startTestWorker(t, o, 1, 1, first, "completed")
+	w2 := startTestWorker(t, o, 2, 2, second, "completed")
+	writeIn(t, w1.dir, "forms.txt", "login")
+	writeIn(t, w2.dir, "forms.txt", "signup")
+
+	o.finishWorker(w1, nil)
+	o.finishWorker(w2, nil)
+
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := state.Tasks[second.ID].Status; status != "blocked" {
+		t.Errorf("conflicting task status = %q, want blocked", status)
+	}
+	if len(state.Notes) != 1 || state.Notes[0].Type != "stuck" || !strings.Contains(state.Notes[0].Content, "forms.txt") {
+		t.Errorf("notes = %+v, want a stuck note naming forms.txt", state.Notes)
+	}
+	data, err := os.ReadFile(filepath.Join(o.cfg.WorkDir, "forms.txt"))
+	if err != nil || string(data) != "login" {
+		t.Errorf("forms.txt on base = %q, %v; want the first merge kept", data, err)
+	}
+	if !git.BranchExists(o.cfg.WorkDir, w2.branch) {
+		t.Errorf("branch %s deleted, want it kept for resolution", w2.branch)
+	}
+}
+
+func TestFinishWorker_UnfinishedTaskIsReadyAgain(t *testing.T) {
+	o, first, _ := setupParallelTest(t)
+	w := startTestWorker(t, o, 1, 1, first, "in_progress")
+	writeIn(t, w.dir, "login.txt", "half done")
+
+	o.finishWorker(w, nil)
+
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := state.Tasks[first.ID].Status; status != "remaining" {
+		t.Errorf("unfinished task status = %q, want remaining", status)
+	}
+	if _, err := os.Stat(filepath.Join(o.cfg.WorkDir, "login.txt")); !os.IsNotExist(err) {
+		t.Error("unfinished work merged into the base branch")
+	}
+	if state.Iterations[0].CommitSHA == "" {
+		t.Error("unfinished work not committed on the task branch")
+	}
+}
+
+func TestRunParallel_RunsReadyTasksConcurrently(t *testing.T) {
+	o, first, second := setupParallelTest(t)
+	out := startFlakyRunner(t, o, 0)
+	o.cfg.Backend = "flaky-acp"
+	o.cfg.Iterations = 2
+	blocked, err := o.store.TaskAdd(o.ctx, "branches", session.TaskAddParams{Content: "Add checkout"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.TaskDepends(o.ctx, "branches", session.TaskDependsParams{ID: blocked.ID, DependsOn: first.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.runParallel(1); err != nil {
+		t.Fatalf("runParallel() error = %v", err)
+	}
+	if prompts := countEntries(t, filepath.Join(out, "prompts"), "prompt"); prompts != 2 {
+		t.Errorf("agent prompted %d times, want 2 (one per ready task)", prompts)
+	}
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Iterations) != 2 {
+		t.Fatalf("got %d iterations, want 2", len(state.Iterations))
+	}
+	for _, id := range []string{first.ID, second.ID} {
+		task := state.Tasks[id]
+		if task.Status != "remaining" || task.Branch == "" {
+			t.Errorf("task %s = %+v, want it unfinished on its own branch", id, task)
+		}
+	}
+	if task := state.Tasks[blocked.ID]; task.Branch != "" {
+		t.Errorf("task %s with an open dependency was started", blocked.ID)
+	}
+}
+
+func TestStartWorker_UndoesStepsOnFailure(t *testing.T) {
+	o, first, _ := setupParallelTest(t)
+	startFlakyRunner(t, o, 0)
+	o.cfg.Backend = "flaky-acp"
+	o.cfg.TemplatePath = filepath.Join(t.TempDir(), "missing.template")
+
+	if _, err := o.startWorker(1, 1, first, nil, make(chan workerResult, 1)); err == nil {
+		t.Fatal("startWorker() expected error for a missing template")
+	}
+	if _, err := os.Stat(o.worktreePath(taskBranchName("", first))); !os.IsNotExist(err) {
+		t.Errorf("worktree left behind: %v", err)
+	}
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task := state.Tasks[first.ID]; task.Status != "remaining" {
+		t.Errorf("task status = %q, want remaining so it is ready again", task.Status)
+	}
+	if len(state.Iterations) != 1 || !state.Iterations[0].Complete {
+		t.Errorf("iterations = %+v, want the started iteration closed", state.Iterations)
+	}
+}
+
+func TestFinishWorker_PreCommitHookFailureKeepsChanges(t *testing.T) {
+	o, first, second := setupParallelTest(t)
+	o.hooksConfig = &hooks.Config{Version: 1, Hooks: hooks.HooksConfig{
+		PreCommit: []*hooks.HookConfig{{Command: "! test -f broken.txt"}},
+	}}
+	w1 := startTestWorker(t, o, 1, 1, first, "completed")
+	w2 := startTestWorker(t, o, 2, 2, second, "completed")
+	writeIn(t, w1.dir, "broken.txt", "lint errors")
+	writeIn(t, w2.dir, "signup.txt", "signup")
+
+	o.finishWorker(w1, nil)
+	o.finishWorker(w2, nil)
+
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := state.Tasks[first.ID].Status; status != "remaining" {
+		t.Errorf("task with failing pre_commit = %q, want remaining", status)
+	}
+	if state.Iterations[0].CommitSHA != "" {
+		t.Error("changes committed despite the failing pre_commit hook")
+	}
+	if _, err := os.Stat(filepath.Join(w1.dir, "broken.txt")); err != nil {
+		t.Errorf("uncommitted changes not kept in the worktree: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(o.cfg.WorkDir, "broken.txt")); !os.IsNotExist(err) {
+		t.Error("task with failing pre_commit merged into the base branch")
+	}
+	if status := state.Tasks[second.ID].Status; status != "completed" {
+		t.Errorf("task with passing pre_commit = %q, want completed", status)
+	}
+	if _, err := os.Stat(filepath.Join(o.cfg.WorkDir, "signup.txt")); err != nil {
+		t.Errorf("task with passing pre_commit not merged: %v", err)
+	}
+}
+
+func TestRunParallel_RunsIterationHooksInWorktrees(t *testing.T) {
+	o, first, second := setupParallelTest(t)
+	out := startFlakyRunner(t, o, 0)
+	o.cfg.Backend = "flaky-acp"
+	o.cfg.Iterations = 2
+	dirs := filepath.Join(t.TempDir(), "dirs")
+	o.hooksConfig = &hooks.Config{Version: 1, Hooks: hooks.HooksConfig{
+		PreIteration:  []*hooks.HookConfig{{Command: "pwd >> " + dirs}},
+		PostIteration: []*hooks.HookConfig{{Command: "echo lint ok", PipeOutput: true}},
+	}}
+
+	if err := o.runParallel(1); err != nil {
+		t.Fatalf("runParallel() error = %v", err)
+	}
+	data, err := os.ReadFile(dirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range []*session.Task{first, second} {
+		dir := o.worktreePath(taskBranchName("", task))
+		if !strings.Contains(string(data), filepath.Base(dir)) {
+			t.Errorf("pre_iteration hook did not run in %s, ran in:\n%s", dir, data)
+		}
+	}
+	// One prompt per worker, plus the post_iteration output sent to each
+	if prompts := countEntries(t, filepath.Join(out, "prompts"), "prompt"); prompts != 4 {
+		t.Errorf("agent prompted %d times, want 4", prompts)
+	}
+}
+
+func TestRunParallel_QueuedMessagesGoToNextWorkers(t *testing.T) {
+	o, _, _ := setupParallelTest(t)
+	out := startFlakyRunner(t, o, 0)
+	o.cfg.Backend = "flaky-acp"
+	o.cfg.Iterations = 2
+	o.sendChan = make(chan string, 1)
+	o.sendChan <- "use sqlite"
+
+	if err := o.runParallel(1); err != nil {
+		t.Fatalf("runParallel() error = %v", err)
+	}
+	if n := countEntries(t, filepath.Join(out, "prompt-lines"), "use sqlite"); n != 2 {
+		t.Errorf("message sent in %d prompts, want 2 (one per worker)", n)
+	}
+}
+
+func TestWorker_UserMessages(t *testing.T) {
+	o, first, second := setupParallelTest(t)
+	out := startFlakyRunner(t, o, 0)
+	if err := o.runner.RunIteration(o.ctx, "work", ""); err != nil {
+		t.Fatal(err)
+	}
+	w1 := &worker{slot: 1, task: first, runner: o.runner}
+	w2 := &worker{slot: 2, task: second, runner: o.runner}
+	active := map[int]*worker{1: w1, 2: w2}
+
+	w2.close()
+	if !o.deliverToWorkers(active, "add an index") {
+		t.Fatal("deliverToWorkers() = false with a running worker")
+	}
+	o.sendWorkerMessages(w1)
+	if n := countEntries(t, filepath.Join(out, "prompt-lines"), "add an index"); n != 1 {
+		t.Errorf("message sent %d times, want once to the running worker", n)
+	}
+	if dropped := w1.close(); len(dropped) != 0 {
+		t.Errorf("close() = %q, want every message sent", dropped)
+	}
+	if o.deliverToWorkers(active, "too late") {
+		t.Error("deliverToWorkers() = true after every agent run ended")
+	}
+}
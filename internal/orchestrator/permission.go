@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/permission"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// Decision sources recorded in permission events.
+const (
+	permissionSourcePolicy   = "policy"   // Decided by an allow/deny rule
+	permissionSourceUser     = "user"     // Answered by the user (TUI modal or stdin)
+	permissionSourceHeadless = "headless" // "ask" denied because no user is available
+)
+
+// permissionHandler returns the agent permission handler for the configured
+// policy, or nil (auto-grant) if no policy is configured.
+func (o *Orchestrator) permissionHandler() agent.PermissionHandler {
+	if o.cfg.Permissions == nil {
+		return nil
+	}
+	return o.handlePermission
+}
+
+// handlePermission evaluates a tool permission request against the policy,
+// asks the user when the policy says "ask", and records the decision.
+func (o *Orchestrator) handlePermission(ctx context.Context, areq agent.PermissionRequest) agent.PermissionDecision {
+	req := permission.NewRequest(areq.ToolCallID, areq.Title, areq.Kind, areq.RawInput)
+	action, rule := o.cfg.Permissions.Evaluate(req)
+
+	allow := action == permission.Allow
+	source := permissionSourcePolicy
+	if action == permission.Ask {
+		switch {
+		case o.tuiProgram != nil:
+			allow = o.askTUI(ctx, req, rule)
+			source = permissionSourceUser
+		case o.cfg.Permissions.HeadlessPrompt():
+			o.stdinMu.Lock()
+			allow = askStdin(ctx, req, os.Stdin, os.Stdout)
+			o.stdinMu.Unlock()
+			source = permissionSourceUser
+		default:
+			allow = false
+			source = permissionSourceHeadless
+			fmt.Printf("[permission] denied %s (no interactive prompt in headless mode)\n", describeRequest(req))
+		}
+	}
+
+	decision := "deny"
+	if allow {
+		decision = "allow"
+	}
+	logger.Debug("Permission %s for %s (source=%s, rule=%s)", decision, describeRequest(req), source, rule)
+
+	if o.store != nil {
+		err := o.store.PermissionDecision(o.ctx, o.cfg.SessionName, session.PermissionDecisionParams{
+			ToolCallID: req.ToolCallID,
+			Title:      req.Title,
+			Kind:       req.Kind,
+			Path:       req.Path,
+			Command:    req.Command,
+			Decision:   decision,
+			Source:     source,
+			Rule:       rule,
+			Iteration:  int(o.iteration.Load()),
+		})
+		if err != nil {
+			logger.Warn("Failed to record permission decision: %v", err)
+		}
+	}
+
+	return agent.PermissionDecision{Allow: allow}
+}
+
+// askTUI shows the permission modal and blocks until the user answers.
+// Returns false if ctx is cancelled first.
+func (o *Orchestrator) askTUI(ctx context.Context, req permission.Request, rule string) bool {
+	reply := make(chan bool, 1)
+	o.tuiProgram.Send(tui.PermissionRequestMsg{
+		Title:   req.Title,
+		Kind:    req.Kind,
+		Path:    req.Path,
+		Command: req.Command,
+		Rule:    rule,
+		Reply:   reply,
+	})
+	select {
+	case allow := <-reply:
+		return allow
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// askStdin prompts on out and reads a y/N answer from in.
+// Anything other than "y" or "yes" denies. Returns false if ctx is cancelled first.
+func askStdin(ctx context.Context, req permission.Request, in io.Reader, out io.Writer) bool {
+	_, _ = fmt.Fprintf(out, "\n[permission] Allow %s? [y/N] ", describeRequest(req))
+
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(in).ReadString('\n')
+		answer <- line
+	}()
+
+	select {
+	case line := <-answer:
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true
+		}
+		return false
+	case <-ctx.Done():
+		_, _ = fmt.Fprintln(out)
+		return false
+	}
+}
+
+// describeRequest formats a request for log and prompt output.
+func describeRequest(req permission.Request) string {
+	if detail := req.Detail(); detail != "" {
+		return fmt.Sprintf("%s (%s)", req.Title, detail)
+	}
+	return req.Title
+}
@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/permission"
+)
+
+func TestAskStdin(t *testing.T) {
+	req := permission.Request{Title: "bash", Command: "make deploy"}
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		got := askStdin(context.Background(), req, strings.NewReader(tt.input), &out)
+		if got != tt.want {
+			t.Errorf("askStdin(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if !strings.Contains(out.String(), "bash (make deploy)") {
+			t.Errorf("prompt %q does not describe the request", out.String())
+		}
+	}
+}
+
+func TestAskStdin_Cancelled(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if askStdin(ctx, permission.Request{Title: "bash"}, pr, io.Discard) {
+		t.Error("askStdin() should deny when context is cancelled")
+	}
+}
+
+func TestHandlePermission_Headless(t *testing.T) {
+	policy, err := permission.New(config.PermissionsConfig{
+		Default: "ask",
+		Rules: []config.PermissionRule{
+			{Kind: "read", Action: "allow"},
+			{Kind: "execute", Command: "^rm ", Action: "deny"},
+		},
+	}, "")
+	if err != nil {
+		t.Fatalf("permission.New() error = %v", err)
+	}
+
+	// No store or TUI: decisions are made purely from the policy
+	o := &Orchestrator{cfg: Config{Headless: true, Permissions: policy}, ctx: context.Background()}
+	if o.permissionHandler() == nil {
+		t.Fatal("permissionHandler() should not be nil with a policy")
+	}
+
+	tests := []struct {
+		name string
+		req  agent.PermissionRequest
+		want bool
+	}{
+		{"allowed by rule", agent.PermissionRequest{Title: "read", Kind: "read"}, true},
+		{"denied by rule", agent.PermissionRequest{Title: "bash", Kind: "execute", RawInput: map[string]any{"command": "rm -rf x"}}, false},
+		{"ask denied headless", agent.PermissionRequest{Title: "bash", Kind: "execute", RawInput: map[string]any{"command": "ls"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := o.handlePermission(context.Background(), tt.req); got.Allow != tt.want {
+				t.Errorf("handlePermission() allow = %v, want %v", got.Allow, tt.want)
+			}
+		})
+	}
+
+	// No policy keeps the auto-grant behavior
+	if (&Orchestrator{}).permissionHandler() != nil {
+		t.Error("permissionHandler() should be nil without a policy")
+	}
+}
@@ -0,0 +1,43 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// restartRetry is the backoff used to bring a crashed agent back up.
+var restartRetry = ierr.RetryConfig{
+	MaxAttempts: 5,
+	InitialWait: time.Second,
+	MaxWait:     30 * time.Second,
+	Multiplier:  2.0,
+}
+
+// recoverAgent restarts the agent subprocess after it crashed during an
+// iteration, retrying with backoff. It returns an error once the session has
+// used up its max_restarts or the subprocess cannot be brought back.
+func (o *Orchestrator) recoverAgent(iteration int, cause error) error {
+	if o.restarts >= o.cfg.MaxRestarts {
+		return fmt.Errorf("agent crashed %d times, giving up (agent.max_restarts: %d): %w", o.restarts+1, o.cfg.MaxRestarts, cause)
+	}
+	o.restarts++
+	logger.Warn("Agent crashed during iteration #%d, restarting (%d of %d): %v", iteration, o.restarts, o.cfg.MaxRestarts, cause)
+
+	if err := ierr.Retry(o.ctx, restartRetry, o.runner.Restart); err != nil {
+		return fmt.Errorf("failed to restart agent: %w", err)
+	}
+
+	if err := o.store.IterationRestart(o.ctx, o.cfg.SessionName, iteration, o.restarts, cause.Error()); err != nil {
+		logger.Error("Failed to record agent restart for iteration #%d: %v", iteration, err)
+	}
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.ShowToastMsg{Text: fmt.Sprintf("Agent crashed, restarted (%d of %d)", o.restarts, o.cfg.MaxRestarts)})
+	} else {
+		fmt.Printf("\n--- Agent crashed during iteration #%d, restarted (%d of %d) ---\n", iteration, o.restarts, o.cfg.MaxRestarts)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+)
+
+// writeFlakyACP writes a fake agent that exits when prompted during its first
+// $FAKE_ACP_CRASHES starts and answers prompts afterwards. Starts are logged
+// to $FAKE_ACP_OUT/starts, prompts to $FAKE_ACP_OUT/prompts and the prompt
+// requests to $FAKE_ACP_OUT/prompt-lines.
+func writeFlakyACP(t *testing.T, dir string) string {
+	t.Helper()
+	script := `#!/bin/sh
+echo start >> "$FAKE_ACP_OUT/starts"
+starts=$(wc -l < "$FAKE_ACP_OUT/starts")
+while read line; do
+  id=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  case "$line" in
+    *'"initialize"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"agentInfo":{"name":"flaky","version":"0.1.0"}}}\n' "$id" ;;
+    *'"session/new"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"sessionId":"s1"}}\n' "$id" ;;
+    *'"session/prompt"'*)
+      echo prompt >> "$FAKE_ACP_OUT/prompts"
+      echo "$line" >> "$FAKE_ACP_OUT/prompt-lines"
+      [ "$starts" -le "$FAKE_ACP_CRASHES" ] && exit 1
+      printf '{"jsonrpc":"2.0","method":"session/update","params":{"sessionId":"s1","update":{"sessionUpdate":"agent_message_chunk","content":{"type":"text","text":"done"}}}}\n'
+      printf '{"jsonrpc":"2.0","id":%s,"result":{"stopReason":"end_turn"}}\n' "$id" ;;
+  esac
+done
+`
+	path := filepath.Join(dir, "flaky-acp")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// startFlakyRunner replaces the orchestrator's runner with one driving a fake
+// agent that crashes on its first crashes starts.
+func startFlakyRunner(t *testing.T, o *Orchestrator, crashes int) string {
+	t.Helper()
+	old := restartRetry
+	restartRetry = ierr.RetryConfig{MaxAttempts: 2, InitialWait: time.Millisecond, MaxWait: time.Millisecond, Multiplier: 1}
+	t.Cleanup(func() { restartRetry = old })
+
+	out := t.TempDir()
+	if err := agent.RegisterBackend(agent.Backend{
+		Name:    "flaky-acp",
+		Command: writeFlakyACP(t, out),
+		Env:     []string{"FAKE_ACP_OUT=" + out, "FAKE_ACP_CRASHES=" + strconv.Itoa(crashes)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	o.runner = agent.NewRunner(agent.RunnerConfig{Backend: "flaky-acp", WorkDir: o.cfg.WorkDir})
+	if err := o.runner.Start(o.ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(o.runner.Stop)
+	return out
+}
+
+func countEntries(t *testing.T, path, word string) int {
+	t.Helper()
+	data, _ := os.ReadFile(path)
+	return strings.Count(string(data), word)
+}
+
+func TestRunAgent_RestartsCrashedAgent(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	o.cfg.MaxRestarts = 3
+	out := startFlakyRunner(t, o, 1)
+	if err := o.store.IterationStart(o.ctx, "branches", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.runAgent(1, "work", ""); err != nil {
+		t.Fatalf("runAgent() error = %v, want nil after restart", err)
+	}
+	if starts := countEntries(t, filepath.Join(out, "starts"), "start"); starts != 2 {
+		t.Errorf("agent started %d times, want 2", starts)
+	}
+	if prompts := countEntries(t, filepath.Join(out, "prompts"), "prompt"); prompts != 2 {
+		t.Errorf("agent prompted %d times, want 2 (iteration re-run)", prompts)
+	}
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restarts := state.Iterations[0].Restarts; restarts != 1 {
+		t.Errorf("recorded restarts = %d, want 1", restarts)
+	}
+}
+
+func TestRunAgent_RestartLimit(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	o.cfg.MaxRestarts = 1
+	out := startFlakyRunner(t, o, 5)
+
+	err := o.runAgent(1, "work", "")
+	if err == nil || !strings.Contains(err.Error(), "max_restarts") {
+		t.Fatalf("runAgent() error = %v, want restart limit error", err)
+	}
+	if starts := countEntries(t, filepath.Join(out, "starts"), "start"); starts != 2 {
+		t.Errorf("agent started %d times, want 2 (one restart)", starts)
+	}
+	if o.restarts != 1 {
+		t.Errorf("restarts = %d, want 1", o.restarts)
+	}
+}
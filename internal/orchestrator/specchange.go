@@ -0,0 +1,180 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/spec"
+	"github.com/mark3labs/iteratr/internal/template"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// recordSpec snapshots the current spec as the one the task list is planned
+// against. Without spec paths there is nothing to record.
+func (o *Orchestrator) recordSpec(iteration int) {
+	if len(o.cfg.SpecPaths) == 0 {
+		return
+	}
+	specs, err := spec.Load(o.cfg.SpecPaths)
+	if err != nil {
+		logger.Warn("Failed to load spec for snapshot: %v", err)
+		return
+	}
+	o.saveSpecSnapshot(iteration, specs)
+}
+
+// saveSpecSnapshot records specs as the planned-against spec.
+func (o *Orchestrator) saveSpecSnapshot(iteration int, specs *spec.Spec) {
+	snapshot := session.SpecSnapshot{Hash: specs.Hash(), Content: specs.Content(), Iteration: iteration}
+	if err := o.store.SetSessionSpec(o.ctx, o.cfg.SessionName, snapshot); err != nil {
+		logger.Warn("Failed to record spec snapshot: %v", err)
+	}
+}
+
+// checkSpecChange compares the spec with the snapshot the task list was
+// planned against. When it was edited, the agent re-plans the task list
+// (adding and cancelling tasks only) before the iteration's own prompt, and
+// the spec diff and task changes are shown before the iteration continues.
+// With checklist import enabled, checklist specs are synced without the agent.
+// Sessions without a snapshot record one and carry on.
+func (o *Orchestrator) checkSpecChange(iteration int) error {
+	if len(o.cfg.SpecPaths) == 0 {
+		return nil
+	}
+	specs, err := spec.Load(o.cfg.SpecPaths)
+	if err != nil {
+		// Building the prompt reports the error
+		logger.Warn("Failed to load spec for change detection: %v", err)
+		return nil
+	}
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if state.Spec == nil {
+		o.saveSpecSnapshot(iteration, specs)
+		return nil
+	}
+	if state.Spec.Hash == specs.Hash() {
+		return nil
+	}
+
+	diff := spec.Diff(state.Spec.Content, specs.Content())
+	added, removed := spec.DiffStat(diff)
+	logger.Info("Spec changed since iteration #%d (+%d -%d lines), re-planning tasks", state.Spec.Iteration, added, removed)
+	if o.cfg.Checklist.Import && hasChecklist(specs) {
+		if err := o.syncChecklist(iteration, state.Spec, specs); err != nil {
+			return fmt.Errorf("checklist sync failed: %w", err)
+		}
+	} else if err := o.replan(iteration, diff); err != nil {
+		return err
+	}
+	if o.ctx.Err() != nil {
+		return nil
+	}
+
+	after, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	addedTasks, cancelledTasks := taskChanges(state, after)
+	o.saveSpecSnapshot(iteration, specs)
+
+	summary := fmt.Sprintf("spec changed (+%d -%d lines), %s", added, removed, describeTaskChanges(addedTasks, cancelledTasks))
+	logger.Info("Iteration #%d: %s", iteration, summary)
+	if err := o.store.IterationReplan(o.ctx, o.cfg.SessionName, iteration, summary); err != nil {
+		logger.Warn("Failed to record re-planning for iteration #%d: %v", iteration, err)
+	}
+
+	if o.tuiProgram != nil {
+		reply := make(chan struct{}, 1)
+		o.tuiProgram.Send(tui.SpecChangeMsg{
+			Iteration: iteration,
+			Diff:      diff,
+			Added:     addedTasks,
+			Cancelled: cancelledTasks,
+			Reply:     reply,
+		})
+		select {
+		case <-reply:
+		case <-o.ctx.Done():
+		}
+	} else {
+		fmt.Printf("\n--- Iteration #%d: %s ---\n%s", iteration, summary, diff)
+		for _, task := range addedTasks {
+			fmt.Printf("  + %s %s\n", task.ID, task.Content)
+		}
+		for _, task := range cancelledTasks {
+			fmt.Printf("  ✗ %s %s\n", task.ID, task.Content)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// replan asks the agent to update the task list for a spec diff.
+func (o *Orchestrator) replan(iteration int, diff string) error {
+	prompt, err := template.BuildReplanPrompt(o.ctx, template.BuildConfig{
+		SessionName:       o.cfg.SessionName,
+		Store:             o.store,
+		IterationNumber:   iteration,
+		SpecPaths:         o.cfg.SpecPaths,
+		ExtraInstructions: o.cfg.ExtraInstructions,
+		NATSPort:          o.natsPort,
+		Config:            o.templateConfig(),
+		Vars:              o.cfg.TemplateVars,
+	}, diff)
+	if err != nil {
+		return fmt.Errorf("failed to build re-planning prompt: %w", err)
+	}
+	if err := o.runAgent(iteration, prompt, ""); err != nil {
+		return fmt.Errorf("re-planning failed: %w", err)
+	}
+	return nil
+}
+
+// taskChanges returns the tasks added and cancelled between two states,
+// sorted by ID.
+func taskChanges(before, after *session.State) (added, cancelled []*session.Task) {
+	for id, task := range after.Tasks {
+		prev, existed := before.Tasks[id]
+		switch {
+		case !existed:
+			added = append(added, task)
+		case task.Status == "cancelled" && prev.Status != "cancelled":
+			cancelled = append(cancelled, task)
+		}
+	}
+	byID := func(tasks []*session.Task) {
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	}
+	byID(added)
+	byID(cancelled)
+	return added, cancelled
+}
+
+// describeTaskChanges summarizes task changes for logs and events,
+// e.g. "added TAS-7, TAS-8; cancelled TAS-3".
+func describeTaskChanges(added, cancelled []*session.Task) string {
+	ids := func(tasks []*session.Task) string {
+		parts := make([]string, len(tasks))
+		for i, task := range tasks {
+			parts[i] = task.ID
+		}
+		return strings.Join(parts, ", ")
+	}
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "added "+ids(added))
+	}
+	if len(cancelled) > 0 {
+		parts = append(parts, "cancelled "+ids(cancelled))
+	}
+	if len(parts) == 0 {
+		return "no task changes"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestCheckSpecChange_ReplansWhenSpecIsEdited(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	out := startFlakyRunner(t, o, 0)
+	specPath := filepath.Join(o.cfg.WorkDir, "SPEC.md")
+	if err := os.WriteFile(specPath, []byte("# Shop\n- Cart\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o.cfg.SpecPaths = []string{specPath}
+	if err := o.store.IterationStart(o.ctx, "branches", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first check records a baseline, an unchanged spec does nothing
+	for i := 0; i < 2; i++ {
+		if err := o.checkSpecChange(1); err != nil {
+			t.Fatalf("checkSpecChange() error = %v", err)
+		}
+	}
+	if prompts := countEntries(t, filepath.Join(out, "prompts"), "prompt"); prompts != 0 {
+		t.Fatalf("agent prompted %d times for an unchanged spec, want 0", prompts)
+	}
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Spec == nil || state.Spec.Content != "# Shop\n- Cart\n" {
+		t.Fatalf("baseline snapshot = %+v", state.Spec)
+	}
+
+	if err := os.WriteFile(specPath, []byte("# Shop\n- Cart\n- Checkout\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.checkSpecChange(2); err != nil {
+		t.Fatalf("checkSpecChange() error = %v", err)
+	}
+	if prompts := countEntries(t, filepath.Join(out, "prompts"), "prompt"); prompts != 1 {
+		t.Errorf("agent prompted %d times after a spec edit, want 1", prompts)
+	}
+
+	state, err = o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Spec == nil || state.Spec.Iteration != 2 || !strings.Contains(state.Spec.Content, "Checkout") {
+		t.Errorf("snapshot after re-planning = %+v, want the edited spec from iteration 2", state.Spec)
+	}
+	events, err := o.store.Events(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replans := 0
+	for _, event := range events {
+		if event.Action == "replan" {
+			replans++
+			if !strings.Contains(event.Data, "+1 -0 lines") {
+				t.Errorf("replan event = %q, want the diff stat", event.Data)
+			}
+		}
+	}
+	if replans != 1 {
+		t.Errorf("recorded %d replan events, want 1", replans)
+	}
+}
+
+func TestTaskChanges(t *testing.T) {
+	before := &session.State{Tasks: map[string]*session.Task{
+		"TAS-1": {ID: "TAS-1", Status: "remaining"},
+		"TAS-2": {ID: "TAS-2", Status: "cancelled"},
+		"TAS-3": {ID: "TAS-3", Status: "remaining"},
+	}}
+	after := &session.State{Tasks: map[string]*session.Task{
+		"TAS-1": {ID: "TAS-1", Status: "cancelled"},
+		"TAS-2": {ID: "TAS-2", Status: "cancelled"},
+		"TAS-3": {ID: "TAS-3", Status: "in_progress"},
+		"TAS-5": {ID: "TAS-5", Status: "remaining"},
+		"TAS-4": {ID: "TAS-4", Status: "remaining"},
+	}}
+
+	added, cancelled := taskChanges(before, after)
+	if got := describeTaskChanges(added, cancelled); got != "added TAS-4, TAS-5; cancelled TAS-1" {
+		t.Errorf("describeTaskChanges() = %q", got)
+	}
+	if got := describeTaskChanges(nil, nil); got != "no task changes" {
+		t.Errorf("describeTaskChanges(nil, nil) = %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// checkStalled is called before each iteration. When work remains but no
+// task is ready or in progress, it reports the dependency chains holding the
+// tasks up instead of running an iteration with nothing to do. The TUI pauses
+// until the user resumes (typically after unblocking a task); headless runs
+// stop. Returns ctx.Err() if the context is cancelled while paused.
+func (o *Orchestrator) checkStalled() (bool, error) {
+	state, err := o.store.LoadState(o.ctx, o.cfg.SessionName)
+	if err != nil {
+		logger.Warn("Failed to load state for ready task check: %v", err)
+		return false, nil
+	}
+	if !state.Stalled() {
+		return false, nil
+	}
+
+	chains := blockingChains(state)
+	logger.Warn("No ready tasks but work remains, blocked by: %s", strings.Join(chains, "; "))
+	if o.tuiProgram == nil {
+		fmt.Println("No ready tasks but work remains, stopping. Blocked by:")
+		for _, chain := range chains {
+			fmt.Printf("  %s\n", chain)
+		}
+		return true, nil
+	}
+
+	o.tuiProgram.Send(tui.ShowToastMsg{Text: "No ready tasks, blocked by " + strings.Join(chains, "; ") + " - resume to continue"})
+	o.paused.Store(true)
+	if err := o.waitIfPaused(); err != nil {
+		return false, err
+	}
+	if o.stopRequested() {
+		return true, nil
+	}
+	return false, nil
+}
+
+// blockingChains formats the chains blocking a stalled session's tasks.
+func blockingChains(state *session.State) []string {
+	var chains []string
+	for _, chain := range state.BlockingChains() {
+		chains = append(chains, chain.String())
+	}
+	return chains
+}
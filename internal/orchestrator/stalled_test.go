@@ -0,0 +1,39 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func TestCheckStalled(t *testing.T) {
+	o, task := setupGitSessionTest(t)
+	ctx := context.Background()
+	dependent, err := o.store.TaskAdd(ctx, "branches", session.TaskAddParams{Content: "Add logout"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.store.TaskDepends(ctx, "branches", session.TaskDependsParams{ID: dependent.ID, DependsOn: task.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if stop, err := o.checkStalled(); stop || err != nil {
+		t.Fatalf("checkStalled() with a ready task = %v, %v", stop, err)
+	}
+
+	if err := o.store.TaskStatus(ctx, "branches", session.TaskStatusParams{ID: task.ID, Status: "blocked"}); err != nil {
+		t.Fatal(err)
+	}
+	if stop, err := o.checkStalled(); !stop || err != nil {
+		t.Errorf("checkStalled() with only blocked work = %v, %v; want stop", stop, err)
+	}
+	state, err := o.store.LoadState(ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{dependent.ID + " → " + task.ID + " (blocked)"}
+	if got := blockingChains(state); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("blockingChains() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,257 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// maxBranchSlugLen limits the task content portion of generated branch names.
+const maxBranchSlugLen = 40
+
+// taskBranch is the git branch (and worktree, in worktree mode) of the task
+// currently being worked on. It is not changed once active, so callbacks can
+// use the one they load.
+type taskBranch struct {
+	taskID string
+	branch string
+	dir    string // Worktree path (empty in branch mode)
+}
+
+// taskBranchesEnabled reports whether per-task branches are active for this run.
+func (o *Orchestrator) taskBranchesEnabled() bool {
+	return o.cfg.TaskBranches.Enabled() && o.baseBranch != ""
+}
+
+// setupTaskBranches resolves the base branch for task branches.
+// Task branches are disabled (with a warning) outside a git repository or on a detached HEAD.
+func (o *Orchestrator) setupTaskBranches() {
+	if !o.cfg.TaskBranches.Enabled() {
+		return
+	}
+	if !git.IsRepo(o.cfg.WorkDir) {
+		logger.Warn("task_branches.mode=%s requires a git repository, disabling task branches", o.cfg.TaskBranches.Mode)
+		return
+	}
+
+	base := o.cfg.TaskBranches.Base
+	if base == "" {
+		current, err := git.CurrentBranch(o.cfg.WorkDir)
+		if err != nil {
+			logger.Warn("Failed to determine current branch, disabling task branches: %v", err)
+			return
+		}
+		if current == "HEAD" {
+			logger.Warn("Detached HEAD: set task_branches.base to use task branches")
+			return
+		}
+		base = current
+	}
+	o.baseBranch = base
+	logger.Info("Task branches enabled (mode=%s, base=%s, on_complete=%s)", o.cfg.TaskBranches.Mode, base, o.onCompleteAction())
+}
+
+// onCompleteAction returns the configured completion action (default: leave).
+func (o *Orchestrator) onCompleteAction() string {
+	if o.cfg.TaskBranches.OnComplete == "" {
+		return "leave"
+	}
+	return o.cfg.TaskBranches.OnComplete
+}
+
+// iterationDir returns the directory the current iteration runs in:
+// the task worktree in worktree mode, otherwise the work dir.
+func (o *Orchestrator) iterationDir() string {
+	if tb := o.activeBranch.Load(); tb != nil && tb.dir != "" {
+		return tb.dir
+	}
+	return o.cfg.WorkDir
+}
+
+// prepareTaskBranch switches to the branch of the task the next iteration
+// will work on: the in-progress task if any, otherwise the one TaskNext picks.
+// The branch is created from the base branch on first use and recorded on the task.
+func (o *Orchestrator) prepareTaskBranch(ctx context.Context, iteration int) error {
+	if !o.taskBranchesEnabled() {
+		return nil
+	}
+
+	state, err := o.store.LoadState(ctx, o.cfg.SessionName)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	task := state.CurrentTask()
+	if task == nil {
+		return nil
+	}
+	if active := o.activeBranch.Load(); active != nil {
+		if active.taskID == task.ID {
+			return nil
+		}
+		// Agent moved on without finishing the previous task - leave its branch as is
+		if err := o.releaseTaskBranch(); err != nil {
+			return err
+		}
+	}
+
+	name := task.Branch
+	if name == "" {
+		name = taskBranchName(o.cfg.TaskBranches.Prefix, task)
+	}
+
+	tb := &taskBranch{taskID: task.ID, branch: name}
+	switch o.cfg.TaskBranches.Mode {
+	case config.TaskBranchesWorktree:
+		tb.dir = o.worktreePath(name)
+		if err := git.AddWorktree(o.cfg.WorkDir, tb.dir, name, o.baseBranch); err != nil {
+			return err
+		}
+		o.runner.SetSessionDir(tb.dir)
+	default:
+		if err := git.Checkout(o.cfg.WorkDir, name, o.baseBranch); err != nil {
+			return err
+		}
+	}
+	o.activeBranch.Store(tb)
+	logger.Info("Task %s: working on branch %s", task.ID, name)
+	if o.cfg.Headless {
+		fmt.Printf("[branch] %s → %s\n", task.ID, name)
+	}
+
+	if task.Branch != name {
+		if err := o.store.TaskBranch(ctx, o.cfg.SessionName, session.TaskBranchParams{
+			ID:        task.ID,
+			Branch:    name,
+			Iteration: iteration,
+		}); err != nil {
+			logger.Warn("Failed to record branch for task %s: %v", task.ID, err)
+		}
+	}
+	return nil
+}
+
+// finishTaskBranch runs after an iteration. If the active task reached a
+// terminal state, it returns to the base branch and, for completed tasks with
+// on_complete=merge, merges the task branch. Otherwise the branch is left for review.
+func (o *Orchestrator) finishTaskBranch(ctx context.Context) error {
+	tb := o.activeBranch.Load()
+	if tb == nil {
+		return nil
+	}
+
+	state, err := o.store.LoadState(ctx, o.cfg.SessionName)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	task, exists := state.Tasks[tb.taskID]
+	if exists && task.Status != "completed" && task.Status != "cancelled" {
+		return nil // Still being worked on
+	}
+
+	if err := o.releaseTaskBranch(); err != nil {
+		return err
+	}
+
+	if !exists || task.Status != "completed" || o.onCompleteAction() != "merge" {
+		logger.Info("Leaving branch %s for review", tb.branch)
+		return nil
+	}
+
+	message := fmt.Sprintf("Merge %s: %s", task.ID, task.Title())
+	if err := git.Merge(o.cfg.WorkDir, tb.branch, message); err != nil {
+		return fmt.Errorf("merge of %s failed, branch left for review: %w", tb.branch, err)
+	}
+	logger.Info("Merged %s into %s", tb.branch, o.baseBranch)
+	if o.cfg.Headless {
+		fmt.Printf("[branch] merged %s into %s\n", tb.branch, o.baseBranch)
+	}
+	return nil
+}
+
+// releaseTaskBranch returns to the base branch (branch mode) or removes the
+// task worktree (worktree mode). The task branch itself is kept.
+// Refuses to switch away from uncommitted changes.
+func (o *Orchestrator) releaseTaskBranch() error {
+	tb := o.activeBranch.Load()
+	if tb == nil {
+		return nil
+	}
+
+	dir := o.cfg.WorkDir
+	if tb.dir != "" {
+		dir = tb.dir
+	}
+	if dirty, err := git.IsDirty(dir); err != nil {
+		return err
+	} else if dirty {
+		return fmt.Errorf("branch %s has uncommitted changes, staying on it", tb.branch)
+	}
+
+	if tb.dir != "" {
+		if err := git.RemoveWorktree(o.cfg.WorkDir, tb.dir); err != nil {
+			return err
+		}
+		o.runner.SetSessionDir("")
+	} else if err := git.Checkout(o.cfg.WorkDir, o.baseBranch, ""); err != nil {
+		return err
+	}
+	o.activeBranch.Store(nil)
+	return nil
+}
+
+// worktreeDirty reports whether the active task worktree has uncommitted changes.
+// The file watcher does not see worktrees (they live in the data dir), so this
+// is used to decide whether auto-commit has work to do.
+func (o *Orchestrator) worktreeDirty() bool {
+	tb := o.activeBranch.Load()
+	if tb == nil || tb.dir == "" {
+		return false
+	}
+	dirty, err := git.IsDirty(tb.dir)
+	return err == nil && dirty
+}
+
+// worktreePath returns the worktree location for a branch inside the data dir.
+func (o *Orchestrator) worktreePath(branch string) string {
+	dataDir := o.cfg.DataDir
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(o.cfg.WorkDir, dataDir)
+	}
+	return filepath.Join(dataDir, "worktrees", strings.ReplaceAll(branch, "/", "-"))
+}
+
+// taskBranchName builds a branch name like "iteratr/tas-3-add-login-form".
+func taskBranchName(prefix string, task *session.Task) string {
+	slug := slugify(task.Title())
+	if len(slug) > maxBranchSlugLen {
+		slug = strings.TrimRight(slug[:maxBranchSlugLen], "-")
+	}
+	name := strings.ToLower(task.ID)
+	if slug != "" {
+		name += "-" + slug
+	}
+	return prefix + name
+}
+
+// slugify lowercases s and replaces runs of non-alphanumeric characters with "-".
+func slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(sb.String(), "-")
+}
@@ -0,0 +1,239 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/git"
+	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestTaskBranchName(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"Add login form", "iteratr/tas-3-add-login-form"},
+		{"Fix: handle `nil` pointers!\nDetails here", "iteratr/tas-3-fix-handle-nil-pointers"},
+		{"Implement the extremely long task description that goes on and on", "iteratr/tas-3-implement-the-extremely-long-task-descri"},
+		{"???", "iteratr/tas-3"},
+	}
+	for _, tt := range tests {
+		got := taskBranchName("iteratr/", &session.Task{ID: "TAS-3", Content: tt.content})
+		if got != tt.want {
+			t.Errorf("taskBranchName(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+// setupTaskBranchTest creates a git repo and a session store with one task,
+// and enables task branches with the given mode.
+func setupTaskBranchTest(t *testing.T, mode, onComplete string) (*Orchestrator, *session.Task) {
+	t.Helper()
+	o, task := setupGitSessionTest(t)
+	o.cfg.TaskBranches = config.TaskBranchesConfig{Mode: mode, Prefix: "iteratr/", OnComplete: onComplete}
+	o.setupTaskBranches()
+	if o.baseBranch != "main" {
+		t.Fatalf("baseBranch = %q, want main", o.baseBranch)
+	}
+	return o, task
+}
+
+// setupGitSessionTest creates a git repo on branch main (with .iteratr/ ignored)
+// and an orchestrator backed by a real session store with one task.
+func setupGitSessionTest(t *testing.T) (*Orchestrator, *session.Task) {
+	t.Helper()
+	dir := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	gitCmd("init", "-b", "main")
+	gitCmd("config", "user.email", "test@test.com")
+	gitCmd("config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(".iteratr/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("add", ".gitignore")
+	gitCmd("commit", "-m", "init")
+
+	ns, port, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ns.Shutdown)
+	nc, err := nats.ConnectToPort(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := session.NewStore(js, stream)
+
+	task, err := store.TaskAdd(ctx, "branches", session.TaskAddParams{Content: "Add login form"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := &Orchestrator{
+		cfg: Config{
+			SessionName: "branches",
+			WorkDir:     dir,
+			DataDir:     ".iteratr",
+		},
+		ctx:         ctx,
+		store:       store,
+		runner:      agent.NewRunner(agent.RunnerConfig{WorkDir: dir}),
+		fileTracker: agent.NewFileTracker(dir),
+	}
+	return o, task
+}
+
+// commitIn writes and commits a file in dir.
+func commitIn(t *testing.T, dir, name string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", name}, {"commit", "-m", "add " + name}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestTaskBranch_BranchModeMerge(t *testing.T) {
+	o, task := setupTaskBranchTest(t, config.TaskBranchesBranch, "merge")
+	ctx := context.Background()
+
+	if err := o.prepareTaskBranch(ctx, 1); err != nil {
+		t.Fatalf("prepareTaskBranch() error = %v", err)
+	}
+	want := "iteratr/tas-1-add-login-form"
+	if branch, _ := git.CurrentBranch(o.cfg.WorkDir); branch != want {
+		t.Fatalf("current branch = %q, want %q", branch, want)
+	}
+	state, _ := o.store.LoadState(ctx, "branches")
+	if got := state.Tasks[task.ID].Branch; got != want {
+		t.Errorf("task branch = %q, want %q", got, want)
+	}
+
+	// Unfinished task keeps its branch
+	commitIn(t, o.cfg.WorkDir, "login.go")
+	if err := o.finishTaskBranch(ctx); err != nil {
+		t.Fatalf("finishTaskBranch() error = %v", err)
+	}
+	if o.activeBranch.Load() == nil {
+		t.Fatal("branch released before task completed")
+	}
+
+	if err := o.store.TaskStatus(ctx, "branches", session.TaskStatusParams{ID: task.ID, Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.finishTaskBranch(ctx); err != nil {
+		t.Fatalf("finishTaskBranch() error = %v", err)
+	}
+	if branch, _ := git.CurrentBranch(o.cfg.WorkDir); branch != "main" {
+		t.Errorf("current branch = %q, want main", branch)
+	}
+	if _, err := os.Stat(filepath.Join(o.cfg.WorkDir, "login.go")); err != nil {
+		t.Errorf("task work not merged into main: %v", err)
+	}
+}
+
+func TestTaskBranch_WorktreeModeLeave(t *testing.T) {
+	o, task := setupTaskBranchTest(t, config.TaskBranchesWorktree, "")
+	ctx := context.Background()
+
+	if err := o.store.TaskStatus(ctx, "branches", session.TaskStatusParams{ID: task.ID, Status: "in_progress"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.prepareTaskBranch(ctx, 1); err != nil {
+		t.Fatalf("prepareTaskBranch() error = %v", err)
+	}
+	wt := o.iterationDir()
+	if wt == o.cfg.WorkDir || o.runner.SessionDir() != wt {
+		t.Fatalf("iteration dir = %q, session dir = %q, want worktree", wt, o.runner.SessionDir())
+	}
+	if branch, _ := git.CurrentBranch(o.cfg.WorkDir); branch != "main" {
+		t.Errorf("main checkout moved to %q", branch)
+	}
+
+	if err := os.WriteFile(filepath.Join(wt, "login.go"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !o.worktreeDirty() {
+		t.Error("worktreeDirty() = false with uncommitted work")
+	}
+	commitIn(t, wt, "login.go")
+
+	if err := o.store.TaskStatus(ctx, "branches", session.TaskStatusParams{ID: task.ID, Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.finishTaskBranch(ctx); err != nil {
+		t.Fatalf("finishTaskBranch() error = %v", err)
+	}
+	if _, err := os.Stat(wt); !os.IsNotExist(err) {
+		t.Errorf("worktree not removed: %v", err)
+	}
+	if o.runner.SessionDir() != o.cfg.WorkDir {
+		t.Errorf("session dir = %q, want work dir", o.runner.SessionDir())
+	}
+	// on_complete=leave: branch kept, main untouched
+	if !git.BranchExists(o.cfg.WorkDir, "iteratr/tas-1-add-login-form") {
+		t.Error("task branch should be left for review")
+	}
+	if _, err := os.Stat(filepath.Join(o.cfg.WorkDir, "login.go")); !os.IsNotExist(err) {
+		t.Error("task work should not be merged with on_complete=leave")
+	}
+}
+
+// TestTaskBranch_ReadFromHookCallbacks switches task branches while hook
+// variables are read concurrently, as NATS event callbacks do (run with -race).
+func TestTaskBranch_ReadFromHookCallbacks(t *testing.T) {
+	o, task := setupTaskBranchTest(t, config.TaskBranchesBranch, "leave")
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 20 {
+			vars := o.iterationHookVars("on_note_added", 1)
+			if vars.TaskID != "" && vars.TaskID != task.ID {
+				t.Errorf("hook task = %q, want %q", vars.TaskID, task.ID)
+			}
+			_ = o.iterationDir()
+		}
+	}()
+	for range 5 {
+		if err := o.prepareTaskBranch(ctx, 1); err != nil {
+			t.Fatalf("prepareTaskBranch() error = %v", err)
+		}
+		if err := o.releaseTaskBranch(); err != nil {
+			t.Fatalf("releaseTaskBranch() error = %v", err)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,225 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+	"github.com/mark3labs/iteratr/internal/config"
+	ierr "github.com/mark3labs/iteratr/internal/errors"
+	"github.com/mark3labs/iteratr/internal/hooks"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/tui"
+)
+
+// Timeout kinds.
+const (
+	TimeoutIteration = "iteration"
+	TimeoutSession   = "session"
+	TimeoutStall     = "stall"
+)
+
+// TimeoutError is the cancellation cause of an agent run that ran out of time.
+type TimeoutError struct {
+	Kind  string        // TimeoutIteration, TimeoutSession, or TimeoutStall
+	Limit time.Duration // Configured limit that was hit
+}
+
+func (e *TimeoutError) Error() string {
+	switch e.Kind {
+	case TimeoutStall:
+		return fmt.Sprintf("agent stalled: no updates for %s", e.Limit)
+	case TimeoutSession:
+		return fmt.Sprintf("session timed out after %s", e.Limit)
+	}
+	return fmt.Sprintf("iteration timed out after %s", e.Limit)
+}
+
+// timeoutCause returns the *TimeoutError a context was cancelled with, or nil.
+func timeoutCause(ctx context.Context) *TimeoutError {
+	var te *TimeoutError
+	if errors.As(context.Cause(ctx), &te) {
+		return te
+	}
+	return nil
+}
+
+// sessionExpired reports whether the session timeout has passed.
+func (o *Orchestrator) sessionExpired() bool {
+	return !o.sessionDeadline.IsZero() && !time.Now().Before(o.sessionDeadline)
+}
+
+// iterationContext derives the context for one agent run. It is cancelled
+// with a *TimeoutError when the iteration or session timeout passes, or when
+// the agent sends no ACP updates for the stall timeout, and with
+// errTaskSkipped when its task is skipped. The returned stop function
+// releases its timers and must always be called.
+func (o *Orchestrator) iterationContext() (context.Context, context.CancelFunc) {
+	return o.runnerContext(o.runner, "")
+}
+
+// runnerContext is iterationContext for the agent driven by runner, working
+// on taskID ("" = whichever task the agent picks).
+func (o *Orchestrator) runnerContext(runner *agent.Runner, taskID string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(o.ctx)
+	o.runsMu.Lock()
+	if o.runs == nil {
+		o.runs = make(map[string]context.CancelCauseFunc)
+	}
+	o.runs[taskID] = cancel
+	o.runsMu.Unlock()
+	var timers []*time.Timer
+	if o.cfg.IterationTimeout > 0 {
+		timers = append(timers, time.AfterFunc(o.cfg.IterationTimeout, func() {
+			cancel(&TimeoutError{Kind: TimeoutIteration, Limit: o.cfg.IterationTimeout})
+		}))
+	}
+	if !o.sessionDeadline.IsZero() {
+		timers = append(timers, time.AfterFunc(time.Until(o.sessionDeadline), func() {
+			cancel(&TimeoutError{Kind: TimeoutSession, Limit: o.cfg.SessionTimeout})
+		}))
+	}
+	if o.cfg.StallTimeout > 0 {
+		go o.watchStall(ctx, cancel, runner)
+	}
+	return ctx, func() {
+		for _, t := range timers {
+			t.Stop()
+		}
+		o.runsMu.Lock()
+		delete(o.runs, taskID)
+		o.runsMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancelRun cancels the agent run working on taskID with errTaskSkipped:
+// the worker assigned the task, or else the sequential run. Reports whether
+// a run was cancelled.
+func (o *Orchestrator) cancelRun(taskID string) bool {
+	o.runsMu.Lock()
+	defer o.runsMu.Unlock()
+	cancel, ok := o.runs[taskID]
+	if !ok {
+		cancel, ok = o.runs[""]
+	}
+	if ok {
+		cancel(errTaskSkipped)
+	}
+	return ok
+}
+
+// watchStall cancels ctx when the agent has been silent for the stall timeout.
+func (o *Orchestrator) watchStall(ctx context.Context, cancel context.CancelCauseFunc, runner *agent.Runner) {
+	limit := o.cfg.StallTimeout
+	interval := min(max(limit/10, 10*time.Millisecond), 10*time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	started := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		last := runner.LastActivity()
+		if last.Before(started) {
+			last = started
+		}
+		if time.Since(last) >= limit {
+			cancel(&TimeoutError{Kind: TimeoutStall, Limit: limit})
+			return
+		}
+	}
+}
+
+// handleTimeout records a timed-out agent run and reports it in the TUI or on stdout.
+func (o *Orchestrator) handleTimeout(iteration int, te *TimeoutError) {
+	logger.Warn("Iteration #%d: %v", iteration, te)
+	if err := o.store.IterationTimeout(o.ctx, o.cfg.SessionName, iteration, te.Error()); err != nil {
+		logger.Error("Failed to record timeout for iteration #%d: %v", iteration, err)
+	}
+	o.notify(config.NotifyError, fmt.Sprintf("Iteration #%d timed out", iteration), te.Error())
+	if o.tuiProgram != nil {
+		o.tuiProgram.Send(tui.ShowToastMsg{Text: fmt.Sprintf("Iteration #%d: %s", iteration, te)})
+	} else {
+		fmt.Printf("\n--- Iteration #%d: %s ---\n", iteration, te)
+	}
+}
+
+// runTimeoutHooks runs on_error hooks for a timed-out agent run.
+// Hook output is queued for the next agent run.
+func (o *Orchestrator) runTimeoutHooks(iteration int, te *TimeoutError) {
+	if o.hooksConfig == nil || len(o.hooksConfig.Hooks.OnError) == 0 {
+		return
+	}
+	hookVars := o.iterationHookVars("on_error", iteration)
+	hookVars.Error = te.Error()
+	onStart, onComplete, _ := o.hookCallbacks("on_error")
+	output, err := hooks.ExecuteAllPipedWithCallbacks(o.ctx, o.hooksConfig.Hooks.OnError, o.iterationDir(), hookVars, onStart, onComplete)
+	if err != nil {
+		if o.ctx.Err() == nil {
+			logger.Error("on_error hook execution failed: %v", err)
+		}
+		return
+	}
+	if output != "" {
+		o.appendPendingOutput(output)
+	}
+}
+
+// runAgent runs the agent for an iteration under the configured timeouts.
+// A run that times out is recorded and retried up to timeout_retries times
+// (never after the session timeout); after that the iteration moves on with
+// whatever the agent got done. A run cut short by an agent crash is re-run
+// after recoverAgent restarts the subprocess. Other errors are returned as is.
+func (o *Orchestrator) runAgent(iteration int, prompt, hookOutput string) error {
+	attempt := 0
+	for {
+		ctx, stop := o.iterationContext()
+		err := o.runner.RunIteration(ctx, prompt, hookOutput)
+		te := timeoutCause(ctx)
+		skipped := errors.Is(context.Cause(ctx), errTaskSkipped)
+		stop()
+		if skipped && o.ctx.Err() == nil {
+			logger.Info("Task skipped, moving on from iteration #%d", iteration)
+			return nil
+		}
+		if te == nil && ierr.IsTransient(err) && o.ctx.Err() == nil {
+			if rerr := o.recoverAgent(iteration, err); rerr != nil {
+				return rerr
+			}
+			logger.Info("Re-running iteration #%d after agent restart", iteration)
+			continue
+		}
+		if te == nil || o.ctx.Err() != nil {
+			return err
+		}
+
+		o.handleTimeout(iteration, te)
+		o.runTimeoutHooks(iteration, te)
+		if te.Kind == TimeoutSession || attempt >= o.cfg.TimeoutRetries {
+			logger.Info("Moving on from iteration #%d after %s", iteration, te.Kind)
+			return nil
+		}
+		attempt++
+		logger.Info("Retrying iteration #%d (attempt %d of %d)", iteration, attempt+1, o.cfg.TimeoutRetries+1)
+		if pending := o.drainPendingOutput(); pending != "" {
+			hookOutput = joinOutput(hookOutput, pending)
+		}
+	}
+}
+
+// joinOutput concatenates non-empty hook outputs with a newline.
+func joinOutput(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + "\n" + b
+}
@@ -0,0 +1,131 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/agent"
+)
+
+// writeSilentACP writes a fake agent that never answers a prompt on its own
+// but stops it when it receives session/cancel. Prompts are logged to $FAKE_ACP_OUT/prompts.
+func writeSilentACP(t *testing.T, dir string) string {
+	t.Helper()
+	script := `#!/bin/sh
+while read line; do
+  id=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  case "$line" in
+    *'"initialize"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"agentInfo":{"name":"silent","version":"0.1.0"}}}\n' "$id" ;;
+    *'"session/new"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"sessionId":"s1"}}\n' "$id" ;;
+    *'"session/prompt"'*) prompt=$id; echo prompt >> "$FAKE_ACP_OUT/prompts" ;;
+    *'"session/cancel"'*) printf '{"jsonrpc":"2.0","id":%s,"result":{"stopReason":"cancelled"}}\n' "$prompt" ;;
+  esac
+done
+`
+	path := filepath.Join(dir, "silent-acp")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// startSilentRunner replaces the orchestrator's runner with one driving the silent fake agent.
+func startSilentRunner(t *testing.T, o *Orchestrator) string {
+	t.Helper()
+	out := t.TempDir()
+	if err := agent.RegisterBackend(agent.Backend{
+		Name:    "silent-acp",
+		Command: writeSilentACP(t, out),
+		Env:     []string{"FAKE_ACP_OUT=" + out},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	o.runner = agent.NewRunner(agent.RunnerConfig{Backend: "silent-acp", WorkDir: o.cfg.WorkDir})
+	if err := o.runner.Start(o.ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(o.runner.Stop)
+	return out
+}
+
+func TestTimeoutError(t *testing.T) {
+	tests := []struct {
+		err  *TimeoutError
+		want string
+	}{
+		{&TimeoutError{Kind: TimeoutIteration, Limit: 30 * time.Minute}, "iteration timed out after 30m0s"},
+		{&TimeoutError{Kind: TimeoutSession, Limit: 8 * time.Hour}, "session timed out after 8h0m0s"},
+		{&TimeoutError{Kind: TimeoutStall, Limit: 5 * time.Minute}, "agent stalled: no updates for 5m0s"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	if timeoutCause(ctx) != nil {
+		t.Error("timeoutCause() of a live context should be nil")
+	}
+	cancel(tests[0].err)
+	if got := timeoutCause(ctx); got != tests[0].err {
+		t.Errorf("timeoutCause() = %v, want %v", got, tests[0].err)
+	}
+}
+
+func TestRunAgent_StallRetriesThenMovesOn(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	o.cfg.StallTimeout = 200 * time.Millisecond
+	o.cfg.TimeoutRetries = 1
+	out := startSilentRunner(t, o)
+	if err := o.store.IterationStart(o.ctx, "branches", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := o.runAgent(1, "work", ""); err != nil {
+		t.Fatalf("runAgent() error = %v, want nil after moving on", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("runAgent() took %s", elapsed)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "prompts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompts := strings.Count(string(data), "prompt"); prompts != 2 {
+		t.Errorf("agent prompted %d times, want 2 (one retry)", prompts)
+	}
+	state, err := o.store.LoadState(o.ctx, "branches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeouts := state.Iterations[0].Timeouts
+	if len(timeouts) != 2 || !strings.HasPrefix(timeouts[0], "agent stalled") {
+		t.Errorf("recorded timeouts = %v, want two stalls", timeouts)
+	}
+}
+
+func TestRunAgent_SessionTimeoutIsNotRetried(t *testing.T) {
+	o, _ := setupGitSessionTest(t)
+	o.cfg.SessionTimeout = time.Hour
+	o.cfg.TimeoutRetries = 3
+	o.sessionDeadline = time.Now().Add(200 * time.Millisecond)
+	out := startSilentRunner(t, o)
+
+	if err := o.runAgent(1, "work", ""); err != nil {
+		t.Fatalf("runAgent() error = %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(out, "prompts"))
+	if prompts := strings.Count(string(data), "prompt"); prompts != 1 {
+		t.Errorf("agent prompted %d times, want 1", prompts)
+	}
+	if !o.sessionExpired() {
+		t.Error("sessionExpired() = false after the deadline")
+	}
+}
@@ -0,0 +1,211 @@
+// Package permission evaluates agent tool permission requests against a
+// configurable allow/deny/ask policy.
+package permission
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/glob"
+)
+
+// Action is the outcome of evaluating a permission request.
+type Action string
+
+const (
+	Allow Action = "allow" // Grant the tool call
+	Deny  Action = "deny"  // Reject the tool call
+	Ask   Action = "ask"   // Ask the user (TUI modal, stdin prompt, or deny in headless mode)
+)
+
+// Request describes a tool call the agent wants to perform.
+type Request struct {
+	ToolCallID string
+	Title      string // Tool title (e.g., "bash")
+	Kind       string // Tool kind (e.g., "execute", "edit")
+	Path       string // File path the tool operates on (if any)
+	Command    string // Shell command the tool runs (if any)
+}
+
+// NewRequest builds a Request from ACP tool call fields, extracting the
+// file path and shell command from the raw tool input.
+func NewRequest(toolCallID, title, kind string, rawInput map[string]any) Request {
+	req := Request{ToolCallID: toolCallID, Title: title, Kind: kind}
+	for _, key := range []string{"filePath", "file_path", "path", "file"} {
+		if v, ok := rawInput[key].(string); ok && v != "" {
+			req.Path = v
+			break
+		}
+	}
+	switch cmd := rawInput["command"].(type) {
+	case string:
+		req.Command = cmd
+	case []any:
+		parts := make([]string, 0, len(cmd))
+		for _, p := range cmd {
+			parts = append(parts, fmt.Sprint(p))
+		}
+		req.Command = strings.Join(parts, " ")
+	}
+	return req
+}
+
+// Detail returns the most descriptive target of the request (command or path).
+func (r Request) Detail() string {
+	if r.Command != "" {
+		return r.Command
+	}
+	return r.Path
+}
+
+// rule is a compiled PermissionRule.
+type rule struct {
+	desc    string
+	kind    string
+	path    *regexp.Regexp
+	command *regexp.Regexp
+	action  Action
+}
+
+// Policy is a compiled permission policy.
+type Policy struct {
+	defaultAction  Action
+	headlessPrompt bool
+	rules          []rule
+	workDir        string
+}
+
+// New compiles a policy from config. Relative path globs are matched against
+// paths relative to workDir.
+func New(cfg config.PermissionsConfig, workDir string) (*Policy, error) {
+	p := &Policy{
+		defaultAction: Allow,
+		workDir:       workDir,
+	}
+
+	if cfg.Default != "" {
+		action, err := parseAction(cfg.Default)
+		if err != nil {
+			return nil, fmt.Errorf("permissions.default: %w", err)
+		}
+		p.defaultAction = action
+	}
+
+	switch strings.ToLower(cfg.Headless) {
+	case "", "deny":
+	case "prompt":
+		p.headlessPrompt = true
+	default:
+		return nil, fmt.Errorf("permissions.headless: invalid value %q (must be prompt or deny)", cfg.Headless)
+	}
+
+	for i, rc := range cfg.Rules {
+		action, err := parseAction(rc.Action)
+		if err != nil {
+			return nil, fmt.Errorf("permissions.rules[%d]: %w", i, err)
+		}
+		r := rule{
+			desc:   describeRule(i, rc),
+			kind:   strings.ToLower(rc.Kind),
+			action: action,
+		}
+		if rc.Path != "" {
+			re, err := glob.Compile(rc.Path)
+			if err != nil {
+				return nil, fmt.Errorf("permissions.rules[%d]: invalid path glob: %w", i, err)
+			}
+			r.path = re
+		}
+		if rc.Command != "" {
+			re, err := regexp.Compile(rc.Command)
+			if err != nil {
+				return nil, fmt.Errorf("permissions.rules[%d]: invalid command regex: %w", i, err)
+			}
+			r.command = re
+		}
+		p.rules = append(p.rules, r)
+	}
+
+	return p, nil
+}
+
+// HeadlessPrompt reports whether "ask" decisions should prompt on stdin in headless mode.
+func (p *Policy) HeadlessPrompt() bool {
+	return p.headlessPrompt
+}
+
+// Evaluate returns the action for a request and a description of the rule
+// that produced it ("default" if no rule matched).
+func (p *Policy) Evaluate(req Request) (Action, string) {
+	for _, r := range p.rules {
+		if p.matches(r, req) {
+			return r.action, r.desc
+		}
+	}
+	return p.defaultAction, "default"
+}
+
+// matches reports whether all of a rule's matchers accept the request.
+func (p *Policy) matches(r rule, req Request) bool {
+	if r.kind != "" && r.kind != strings.ToLower(req.Kind) {
+		return false
+	}
+	if r.command != nil && (req.Command == "" || !r.command.MatchString(req.Command)) {
+		return false
+	}
+	if r.path != nil && (req.Path == "" || !p.matchPath(r.path, req.Path)) {
+		return false
+	}
+	return true
+}
+
+// matchPath matches a glob against the path as given, relative to the work
+// dir, and by base name (so "*.env" matches files in any directory).
+func (p *Policy) matchPath(re *regexp.Regexp, path string) bool {
+	candidates := []string{filepath.ToSlash(path), filepath.Base(path)}
+	if p.workDir != "" && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(p.workDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			candidates = append(candidates, filepath.ToSlash(rel))
+		}
+	}
+	for _, c := range candidates {
+		if re.MatchString(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAction validates an action string.
+func parseAction(s string) (Action, error) {
+	switch Action(strings.ToLower(s)) {
+	case Allow:
+		return Allow, nil
+	case Deny:
+		return Deny, nil
+	case Ask:
+		return Ask, nil
+	}
+	return "", fmt.Errorf("invalid action %q (must be allow, deny, or ask)", s)
+}
+
+// describeRule builds a short human-readable label for a rule.
+func describeRule(i int, rc config.PermissionRule) string {
+	var parts []string
+	if rc.Kind != "" {
+		parts = append(parts, "kind="+rc.Kind)
+	}
+	if rc.Path != "" {
+		parts = append(parts, "path="+rc.Path)
+	}
+	if rc.Command != "" {
+		parts = append(parts, "command="+rc.Command)
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("rules[%d]", i)
+	}
+	return fmt.Sprintf("rules[%d] %s", i, strings.Join(parts, " "))
+}
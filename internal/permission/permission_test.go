@@ -0,0 +1,129 @@
+package permission
+
+import (
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/config"
+)
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PermissionsConfig
+	}{
+		{"bad default", config.PermissionsConfig{Default: "maybe"}},
+		{"bad headless", config.PermissionsConfig{Headless: "ask"}},
+		{"bad rule action", config.PermissionsConfig{Rules: []config.PermissionRule{{Kind: "edit", Action: "sometimes"}}}},
+		{"bad command regex", config.PermissionsConfig{Rules: []config.PermissionRule{{Command: "(", Action: "deny"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg, "/repo"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	policy, err := New(config.PermissionsConfig{
+		Default:  "ask",
+		Headless: "prompt",
+		Rules: []config.PermissionRule{
+			{Kind: "execute", Command: `^rm\s`, Action: "deny"},
+			{Kind: "execute", Command: `^(go|git) `, Action: "allow"},
+			{Path: "**/*.env", Action: "deny"},
+			{Kind: "edit", Path: "internal/**", Action: "allow"},
+			{Kind: "read", Action: "allow"},
+		},
+	}, "/repo")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if !policy.HeadlessPrompt() {
+		t.Error("HeadlessPrompt() = false, want true")
+	}
+
+	tests := []struct {
+		name     string
+		req      Request
+		want     Action
+		wantRule string
+	}{
+		{"rm denied", Request{Kind: "execute", Command: "rm -rf /"}, Deny, `rules[0] kind=execute command=^rm\s`},
+		{"go allowed", Request{Kind: "execute", Command: "go test ./..."}, Allow, "rules[1] kind=execute command=^(go|git) "},
+		{"other command asks", Request{Kind: "execute", Command: "curl example.com"}, Ask, "default"},
+		{"env file denied by base name", Request{Kind: "read", Path: ".env"}, Deny, "rules[2] path=**/*.env"},
+		{"nested env file denied", Request{Kind: "edit", Path: "/repo/config/prod.env"}, Deny, "rules[2] path=**/*.env"},
+		{"edit under internal allowed", Request{Kind: "edit", Path: "/repo/internal/agent/acp.go"}, Allow, "rules[3] kind=edit path=internal/**"},
+		{"edit elsewhere asks", Request{Kind: "edit", Path: "/repo/README.md"}, Ask, "default"},
+		{"kind match is case-insensitive", Request{Kind: "READ", Path: "/repo/go.mod"}, Allow, "rules[4] kind=read"},
+		{"path rule needs a path", Request{Kind: "edit"}, Ask, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rule := policy.Evaluate(tt.req)
+			if got != tt.want || rule != tt.wantRule {
+				t.Errorf("Evaluate() = (%s, %q), want (%s, %q)", got, rule, tt.want, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestEvaluate_DefaultAllow(t *testing.T) {
+	policy, err := New(config.PermissionsConfig{}, "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if policy.HeadlessPrompt() {
+		t.Error("HeadlessPrompt() = true, want false (deny by default)")
+	}
+	if got, _ := policy.Evaluate(Request{Kind: "execute", Command: "ls"}); got != Allow {
+		t.Errorf("Evaluate() = %s, want allow", got)
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	req := NewRequest("call-1", "bash", "execute", map[string]any{
+		"command": []any{"git", "status"},
+	})
+	if req.Command != "git status" {
+		t.Errorf("Command = %q, want %q", req.Command, "git status")
+	}
+	if req.Detail() != "git status" {
+		t.Errorf("Detail() = %q", req.Detail())
+	}
+
+	req = NewRequest("call-2", "edit", "edit", map[string]any{"filePath": "/repo/main.go"})
+	if req.Path != "/repo/main.go" || req.Detail() != "/repo/main.go" {
+		t.Errorf("Path = %q, Detail() = %q", req.Path, req.Detail())
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", true}, // matched by base name
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/iteratr/main.go", true},
+		{"internal/**", "internal/a/b.go", true},
+		{"internal/**", "cmd/a.go", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"a.b", "axb", false},
+	}
+	for _, tt := range tests {
+		policy, err := New(config.PermissionsConfig{Rules: []config.PermissionRule{{Path: tt.glob, Action: "deny"}}}, "")
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		got, _ := policy.Evaluate(Request{Path: tt.path})
+		if (got == Deny) != tt.match {
+			t.Errorf("glob %q vs %q: match = %v, want %v", tt.glob, tt.path, got == Deny, tt.match)
+		}
+	}
+}
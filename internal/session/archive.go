@@ -0,0 +1,139 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ArchiveVersion is the session archive format version written by WriteArchive.
+const ArchiveVersion = 1
+
+// ArchiveHeader is the first line of a session archive. The remaining lines
+// are the session's events, one JSON object per line, in log order.
+type ArchiveHeader struct {
+	Version    int           `json:"version"`
+	Session    string        `json:"session"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Events     int           `json:"events"`          // Number of event lines that follow
+	Files      []ArchiveFile `json:"files,omitempty"` // Spec and template used by the session
+}
+
+// ArchiveFile is a file bundled with a session archive.
+type ArchiveFile struct {
+	Role    string `json:"role"`    // "spec" or "template"
+	Path    string `json:"path"`    // Path relative to the working directory at export time
+	Content string `json:"content"` // File contents
+}
+
+// Export writes all events of a session to w as a JSONL archive. Event IDs
+// are written explicitly so an import reproduces the same task and note IDs.
+func (s *Store) Export(ctx context.Context, w io.Writer, session string, files []ArchiveFile) (*ArchiveHeader, error) {
+	events, err := s.Events(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrSessionNotFound, session)
+	}
+
+	header := &ArchiveHeader{
+		Version:    ArchiveVersion,
+		Session:    session,
+		ExportedAt: time.Now().UTC(),
+		Events:     len(events),
+		Files:      files,
+	}
+	if err := WriteArchive(w, header, events); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// WriteArchive writes a header line followed by one line per event.
+func WriteArchive(w io.Writer, header *ArchiveHeader, events []Event) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("failed to write archive header: %w", err)
+	}
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to write event %s: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+// ReadArchive parses a JSONL session archive written by WriteArchive.
+func ReadArchive(r io.Reader) (*ArchiveHeader, []Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024) // Events can carry large agent output
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		return nil, nil, errors.New("empty archive")
+	}
+	var header ArchiveHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, nil, fmt.Errorf("invalid archive header: %w", err)
+	}
+	if header.Version < 1 || header.Version > ArchiveVersion {
+		return nil, nil, fmt.Errorf("unsupported archive version %d (supported: %d)", header.Version, ArchiveVersion)
+	}
+	if header.Session == "" {
+		return nil, nil, errors.New("invalid archive header: missing session name")
+	}
+
+	events := make([]Event, 0, header.Events)
+	line := 1
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, nil, fmt.Errorf("invalid event on line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if len(events) != header.Events {
+		return nil, nil, fmt.Errorf("archive is truncated: header lists %d events, found %d", header.Events, len(events))
+	}
+	return &header, events, nil
+}
+
+// Import replays archived events into the store under the given session name.
+// IDs and timestamps are kept, so LoadState yields the same state (including
+// the task and note counters) as in the exported session. The target session
+// must not have any events yet.
+func (s *Store) Import(ctx context.Context, session string, events []Event) error {
+	if err := ValidateName(session); err != nil {
+		return err
+	}
+	existing, err := s.Events(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to check session %q: %w", session, err)
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%w: %q has %d events", ErrSessionExists, session, len(existing))
+	}
+
+	for i, event := range events {
+		event.Session = session
+		if _, err := s.PublishEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to import event %d of %d: %w", i+1, len(events), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// newTestStore starts an embedded NATS server with its own data dir and
+// returns a store backed by it.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	t.Cleanup(ns.Shutdown)
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+	return NewStore(js, stream)
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := newTestStore(t)
+	dst := newTestStore(t)
+	session := "export-session"
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Another session in the source store must not leak into the archive
+	_, err := src.TaskAdd(ctx, "other", TaskAddParams{Content: "Unrelated"})
+	must(err)
+
+	must(src.IterationStart(ctx, session, 1))
+	task1, err := src.TaskAdd(ctx, session, TaskAddParams{Content: "Task one", Priority: 1, Iteration: 1})
+	must(err)
+	task2, err := src.TaskAdd(ctx, session, TaskAddParams{Content: "Task two", Iteration: 1})
+	must(err)
+	must(src.TaskDepends(ctx, session, TaskDependsParams{ID: task2.ID, DependsOn: task1.ID, Iteration: 1}))
+	must(src.TaskStatus(ctx, session, TaskStatusParams{ID: task1.ID, Status: "completed", Iteration: 1}))
+	note, err := src.NoteAdd(ctx, session, NoteAddParams{Content: "Uses <html> & quotes \"\"", Type: "learning", Iteration: 1})
+	must(err)
+	_, err = src.NoteAdd(ctx, session, NoteAddParams{Content: "Deleted later", Type: "tip", Iteration: 1})
+	must(err)
+	must(src.NoteDelete(ctx, session, NoteDeleteParams{ID: note.ID, Iteration: 1}))
+	must(src.IterationSummary(ctx, session, 1, "Did task one", []string{task1.ID}))
+	must(src.IterationComplete(ctx, session, 1))
+	must(src.SetSessionModel(ctx, session, "anthropic/claude-sonnet-4-5"))
+
+	var buf bytes.Buffer
+	files := []ArchiveFile{{Role: "spec", Path: "specs/export-session.md", Content: "# Spec\n"}}
+	header, err := src.Export(ctx, &buf, session, files)
+	must(err)
+
+	gotHeader, events, err := ReadArchive(bytes.NewReader(buf.Bytes()))
+	must(err)
+	if gotHeader.Session != session || gotHeader.Events != header.Events || len(events) != header.Events {
+		t.Fatalf("header = %+v with %d events, want session %q and %d events", gotHeader, len(events), session, header.Events)
+	}
+	if len(gotHeader.Files) != 1 || gotHeader.Files[0].Content != "# Spec\n" {
+		t.Errorf("files = %+v, want bundled spec", gotHeader.Files)
+	}
+
+	want, err := src.LoadState(ctx, session)
+	must(err)
+
+	t.Run("same name", func(t *testing.T) {
+		must(dst.Import(ctx, session, events))
+		got, err := dst.LoadState(ctx, session)
+		must(err)
+		assertJSONEqual(t, "state", got, want)
+
+		// New IDs continue from the imported counters
+		task3, err := dst.TaskAdd(ctx, session, TaskAddParams{Content: "Task three"})
+		must(err)
+		if task3.ID != "TAS-3" {
+			t.Errorf("next task ID = %s, want TAS-3", task3.ID)
+		}
+	})
+
+	t.Run("existing session is rejected", func(t *testing.T) {
+		if err := dst.Import(ctx, session, events); !errors.Is(err, ErrSessionExists) {
+			t.Errorf("Import() error = %v, want ErrSessionExists", err)
+		}
+	})
+
+	t.Run("renamed", func(t *testing.T) {
+		must(dst.Import(ctx, "copy", events))
+		got, err := dst.LoadState(ctx, "copy")
+		must(err)
+		want.Session = "copy"
+		assertJSONEqual(t, "state", got, want)
+	})
+}
+
+func TestExport_UnknownSession(t *testing.T) {
+	store := newTestStore(t)
+	var buf bytes.Buffer
+	if _, err := store.Export(context.Background(), &buf, "missing", nil); err == nil {
+		t.Error("expected error exporting unknown session")
+	}
+}
+
+func TestReadArchive_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"bad header", "not json\n"},
+		{"unsupported version", `{"version":99,"session":"s","events":0}` + "\n"},
+		{"missing session", `{"version":1,"events":0}` + "\n"},
+		{"truncated", `{"version":1,"session":"s","events":2}` + "\n" + `{"id":"1","type":"task","action":"add"}` + "\n"},
+		{"bad event", `{"version":1,"session":"s","events":1}` + "\n" + "{oops\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ReadArchive(strings.NewReader(tt.input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -98,3 +98,28 @@ func (s *Store) SessionRestart(ctx context.Context, session string) error {
 
 	return nil
 }
+
+// SetSessionSpec records the spec the task list is planned against.
+// Creates an event of type "control" with action "spec_snapshot".
+// The orchestrator compares later spec content with it to trigger re-planning.
+func (s *Store) SetSessionSpec(ctx context.Context, session string, snapshot SpecSnapshot) error {
+	meta, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spec snapshot metadata: %w", err)
+	}
+
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeControl,
+		Action:  "spec_snapshot",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Spec snapshot %.12s (iteration %d)", snapshot.Hash, snapshot.Iteration),
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish spec_snapshot event: %w", err)
+	}
+
+	return nil
+}
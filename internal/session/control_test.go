@@ -329,3 +329,36 @@ func TestSessionCompleteWithInProgressTask(t *testing.T) {
 		t.Errorf("Expected session to NOT be complete, but Complete=true")
 	}
 }
+
+func TestSetSessionSpec(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	state, err := store.LoadState(ctx, "spec-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Spec != nil {
+		t.Fatalf("Spec = %+v before any snapshot, want nil", state.Spec)
+	}
+
+	snapshot := SpecSnapshot{Hash: "abc", Content: "# Spec\n", Iteration: 2}
+	if err := store.SetSessionSpec(ctx, "spec-session", snapshot); err != nil {
+		t.Fatalf("SetSessionSpec() error = %v", err)
+	}
+	state, err = store.LoadState(ctx, "spec-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Spec == nil || *state.Spec != snapshot {
+		t.Errorf("Spec = %+v, want %+v", state.Spec, snapshot)
+	}
+
+	// An empty snapshot clears it
+	if err := store.SetSessionSpec(ctx, "spec-session", SpecSnapshot{}); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ = store.LoadState(ctx, "spec-session"); state.Spec != nil {
+		t.Errorf("Spec = %+v after clearing, want nil", state.Spec)
+	}
+}
@@ -0,0 +1,278 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BlockingChain is a chain of unfinished dependencies ending at the task that
+// holds it up.
+type BlockingChain struct {
+	Path   []string // Task IDs, each depending on the next
+	Reason string   // Why the last task holds up the chain: its status, "missing" or "cycle"
+}
+
+// String formats the chain as "TAS-5 → TAS-3 → TAS-1 (blocked)".
+func (c BlockingChain) String() string {
+	return fmt.Sprintf("%s (%s)", strings.Join(c.Path, " → "), c.Reason)
+}
+
+// isOpen reports whether a task still has work to do.
+func isOpen(task *Task) bool {
+	return task.Status != "completed" && task.Status != "cancelled"
+}
+
+// validateDependency checks that taskID may depend on depID: a task cannot
+// depend on itself, on a cancelled task, or on a task that already depends on
+// it, directly or transitively. The cycle error names the offending path.
+func (st *State) validateDependency(taskID, depID string) error {
+	if taskID == depID {
+		return fmt.Errorf("task cannot depend on itself")
+	}
+	if dep, exists := st.Tasks[depID]; exists && dep.Status == "cancelled" {
+		return fmt.Errorf("task cannot depend on cancelled task %s", depID)
+	}
+	if path := st.dependencyPath(depID, taskID); path != nil {
+		return fmt.Errorf("dependency cycle: %s", strings.Join(append([]string{taskID}, path...), " → "))
+	}
+	return nil
+}
+
+// dependencyPath returns the task IDs on a dependency chain from "from" to
+// "to" (both included), or nil if from does not depend on to.
+func (st *State) dependencyPath(from, to string) []string {
+	visited := make(map[string]bool)
+	var walk func(id string) []string
+	walk = func(id string) []string {
+		if id == to {
+			return []string{id}
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		task, exists := st.Tasks[id]
+		if !exists {
+			return nil
+		}
+		for _, dep := range task.DependsOn {
+			if path := walk(dep); path != nil {
+				return append([]string{id}, path...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// TopoOrder returns the tasks with every task after its dependencies. Tasks
+// that become available at the same time are ordered by priority, then ID.
+// Tasks on a dependency cycle (possible in sessions recorded before cycles
+// were rejected) come last.
+func (st *State) TopoOrder() []*Task {
+	pending := make(map[string]int, len(st.Tasks)) // Unplaced dependencies per task
+	dependents := make(map[string][]string)
+	var ready []*Task
+	for id, task := range st.Tasks {
+		for _, dep := range task.DependsOn {
+			if _, exists := st.Tasks[dep]; exists {
+				pending[id]++
+				dependents[dep] = append(dependents[dep], id)
+			}
+		}
+		if pending[id] == 0 {
+			ready = append(ready, task)
+		}
+	}
+
+	var order []*Task
+	placed := make(map[string]bool, len(st.Tasks))
+	for len(ready) > 0 {
+		sortTasksByPriority(ready)
+		task := ready[0]
+		ready = ready[1:]
+		order = append(order, task)
+		placed[task.ID] = true
+		for _, id := range dependents[task.ID] {
+			pending[id]--
+			if pending[id] == 0 {
+				ready = append(ready, st.Tasks[id])
+			}
+		}
+	}
+
+	var cyclic []*Task
+	for id, task := range st.Tasks {
+		if !placed[id] {
+			cyclic = append(cyclic, task)
+		}
+	}
+	sortTasksByPriority(cyclic)
+	return append(order, cyclic...)
+}
+
+// CriticalPath returns the longest chain of unfinished tasks, each depending
+// on the previous one, in the order they can be worked on. It is the minimum
+// number of iterations left even with unlimited parallelism.
+func (st *State) CriticalPath() []*Task {
+	longest := make(map[string][]*Task)
+	visiting := make(map[string]bool)
+	var chain func(task *Task) []*Task
+	chain = func(task *Task) []*Task {
+		if path, done := longest[task.ID]; done {
+			return path
+		}
+		if visiting[task.ID] {
+			return nil // Cycle; cut it here
+		}
+		visiting[task.ID] = true
+		var best []*Task
+		for _, depID := range task.DependsOn {
+			dep, exists := st.Tasks[depID]
+			if !exists || !isOpen(dep) {
+				continue
+			}
+			if path := chain(dep); len(path) > len(best) {
+				best = path
+			}
+		}
+		visiting[task.ID] = false
+		path := append(append([]*Task{}, best...), task)
+		longest[task.ID] = path
+		return path
+	}
+
+	var critical []*Task
+	for _, task := range st.TopoOrder() {
+		if !isOpen(task) {
+			continue
+		}
+		if path := chain(task); len(path) > len(critical) {
+			critical = path
+		}
+	}
+	return critical
+}
+
+// Stalled reports whether work remains but nothing can be worked on: no task
+// is ready or in progress, yet some are remaining or blocked.
+func (st *State) Stalled() bool {
+	open := false
+	for _, task := range st.Tasks {
+		switch task.Status {
+		case "in_progress":
+			return false
+		case "remaining", "blocked":
+			open = true
+		}
+	}
+	return open && len(st.ReadyTasks()) == 0
+}
+
+// BlockingChains explains why unfinished tasks cannot be worked on. For each
+// remaining or blocked task that no other waiting task depends on, it follows
+// the first unfinished dependency until reaching the task that holds the
+// chain up (blocked, cancelled, missing or on a cycle).
+func (st *State) BlockingChains() []BlockingChain {
+	waiting := make(map[string]bool)
+	for id, task := range st.Tasks {
+		if task.Status == "blocked" || (task.Status == "remaining" && !st.depsCompleted(task)) {
+			waiting[id] = true
+		}
+	}
+	dependedOn := make(map[string]bool)
+	for id := range waiting {
+		for _, dep := range st.Tasks[id].DependsOn {
+			dependedOn[dep] = true
+		}
+	}
+
+	var chains []BlockingChain
+	for _, task := range st.TopoOrder() {
+		if waiting[task.ID] && !dependedOn[task.ID] {
+			chains = append(chains, st.blockingChain(task))
+		}
+	}
+	return chains
+}
+
+// blockingChain follows task's first unfinished dependency to its cause.
+func (st *State) blockingChain(task *Task) BlockingChain {
+	chain := BlockingChain{Path: []string{task.ID}}
+	seen := map[string]bool{task.ID: true}
+	for {
+		if task.Status != "remaining" {
+			chain.Reason = task.Status
+			return chain
+		}
+		var next string
+		for _, dep := range task.DependsOn {
+			if d, exists := st.Tasks[dep]; !exists || d.Status != "completed" {
+				next = dep
+				break
+			}
+		}
+		if next == "" {
+			chain.Reason = "ready"
+			return chain
+		}
+		chain.Path = append(chain.Path, next)
+		dep, exists := st.Tasks[next]
+		switch {
+		case !exists:
+			chain.Reason = "missing"
+			return chain
+		case seen[next]:
+			chain.Reason = "cycle"
+			return chain
+		}
+		seen[next] = true
+		task = dep
+	}
+}
+
+// FormatGraph renders the task dependency graph for the task-graph tool:
+// every task in dependency order with the tasks it depends on, the critical
+// path, and the blocking chains if nothing can be worked on.
+func (st *State) FormatGraph() string {
+	if len(st.Tasks) == 0 {
+		return "No tasks"
+	}
+	var lines []string
+	for _, task := range st.TopoOrder() {
+		line := fmt.Sprintf("[%s] %-11s %s", task.ID, task.Status, task.Title())
+		if len(task.DependsOn) > 0 {
+			line += " ← " + strings.Join(task.DependsOn, ", ")
+		}
+		lines = append(lines, line)
+	}
+
+	if critical := st.CriticalPath(); len(critical) > 0 {
+		ids := make([]string, len(critical))
+		for i, task := range critical {
+			ids[i] = task.ID
+		}
+		lines = append(lines, "", fmt.Sprintf("Critical path (%d tasks): %s", len(ids), strings.Join(ids, " → ")))
+	} else {
+		lines = append(lines, "", "Critical path: none (all tasks finished)")
+	}
+
+	if st.Stalled() {
+		lines = append(lines, "", "No ready tasks. Blocked by:")
+		for _, chain := range st.BlockingChains() {
+			lines = append(lines, "  "+chain.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sortTasksByPriority sorts tasks by priority (lower is higher priority), then ID.
+func sortTasksByPriority(tasks []*Task) {
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Priority != tasks[j].Priority {
+			return tasks[i].Priority < tasks[j].Priority
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// graphState builds a state from "ID status deps..." specs.
+func graphState(specs ...string) *State {
+	st := &State{Tasks: make(map[string]*Task)}
+	for _, spec := range specs {
+		fields := strings.Fields(spec)
+		st.Tasks[fields[0]] = &Task{ID: fields[0], Content: "Task " + fields[0], Status: fields[1], Priority: 2, DependsOn: fields[2:]}
+	}
+	return st
+}
+
+func taskIDs(tasks []*Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestTopoOrderAndCriticalPath(t *testing.T) {
+	st := graphState(
+		"TAS-1 completed",
+		"TAS-2 remaining TAS-1",
+		"TAS-3 remaining TAS-2",
+		"TAS-4 remaining TAS-1",
+		"TAS-5 remaining TAS-3 TAS-4",
+		"TAS-6 remaining",
+	)
+	st.Tasks["TAS-6"].Priority = 0
+
+	if got, want := taskIDs(st.TopoOrder()), []string{"TAS-6", "TAS-1", "TAS-2", "TAS-3", "TAS-4", "TAS-5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TopoOrder() = %v, want %v", got, want)
+	}
+	// Completed tasks are not on the critical path
+	if got, want := taskIDs(st.CriticalPath()), []string{"TAS-2", "TAS-3", "TAS-5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CriticalPath() = %v, want %v", got, want)
+	}
+
+	// A legacy cycle doesn't hang either
+	cyclic := graphState("TAS-1 remaining TAS-2", "TAS-2 remaining TAS-1")
+	if got := taskIDs(cyclic.TopoOrder()); len(got) != 2 {
+		t.Errorf("TopoOrder() with cycle = %v, want both tasks", got)
+	}
+	_ = cyclic.CriticalPath()
+}
+
+func TestStalledAndBlockingChains(t *testing.T) {
+	st := graphState(
+		"TAS-1 blocked",
+		"TAS-2 remaining TAS-1",
+		"TAS-3 remaining TAS-2",
+		"TAS-4 cancelled",
+		"TAS-5 remaining TAS-4",
+		"TAS-6 completed",
+	)
+	if !st.Stalled() {
+		t.Fatal("Stalled() = false, want true")
+	}
+	var got []string
+	for _, chain := range st.BlockingChains() {
+		got = append(got, chain.String())
+	}
+	want := []string{"TAS-3 → TAS-2 → TAS-1 (blocked)", "TAS-5 → TAS-4 (cancelled)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BlockingChains() = %q, want %q", got, want)
+	}
+	if out := st.FormatGraph(); !strings.Contains(out, "Blocked by:\n  TAS-3 → TAS-2 → TAS-1 (blocked)") {
+		t.Errorf("FormatGraph() missing blocking chains:\n%s", out)
+	}
+
+	st.Tasks["TAS-7"] = &Task{ID: "TAS-7", Status: "remaining"}
+	if st.Stalled() {
+		t.Error("Stalled() = true with a ready task")
+	}
+}
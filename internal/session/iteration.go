@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/usage"
 )
 
 // IterationStart logs the start of a new iteration.
@@ -96,3 +97,194 @@ func (s *Store) IterationSummary(ctx context.Context, session string, number int
 
 	return nil
 }
+
+// IterationCommit records the commit created for an iteration.
+// Creates an event of type "iteration" with action "commit".
+func (s *Store) IterationCommit(ctx context.Context, session string, number int, sha string) error {
+	// Build metadata
+	meta, err := json.Marshal(map[string]any{
+		"number": number,
+		"sha":    sha,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration commit metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "commit",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Iteration %d committed %s", number, shortSHA(sha)),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration commit event: %w", err)
+	}
+
+	return nil
+}
+
+// IterationUsage records tokens and cost consumed by an agent prompt during an iteration.
+// Creates an event of type "iteration" with action "usage". Usage from several
+// prompts in the same iteration (e.g. user messages) accumulates.
+func (s *Store) IterationUsage(ctx context.Context, session string, number int, u usage.Usage) error {
+	// Build metadata
+	meta, err := json.Marshal(struct {
+		Number int `json:"number"`
+		usage.Usage
+	}{number, u})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration usage metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "usage",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Iteration %d used %d tokens ($%.4f)", number, u.Tokens(), u.Cost),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration usage event: %w", err)
+	}
+
+	return nil
+}
+
+// IterationTimeout records that an agent run was cut short by a timeout or stall.
+// Creates an event of type "iteration" with action "timeout".
+func (s *Store) IterationTimeout(ctx context.Context, session string, number int, reason string) error {
+	// Build metadata
+	meta, err := json.Marshal(map[string]any{
+		"number": number,
+		"reason": reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration timeout metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "timeout",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Iteration %d: %s", number, reason),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration timeout event: %w", err)
+	}
+
+	return nil
+}
+
+// IterationRestart records that the agent subprocess crashed during an
+// iteration and was restarted. Restart is the session-wide restart count.
+// Creates an event of type "iteration" with action "restart".
+func (s *Store) IterationRestart(ctx context.Context, session string, number, restart int, reason string) error {
+	// Build metadata
+	meta, err := json.Marshal(map[string]any{
+		"number":  number,
+		"restart": restart,
+		"reason":  reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration restart metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "restart",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Agent restarted during iteration %d (restart %d): %s", number, restart, reason),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration restart event: %w", err)
+	}
+
+	return nil
+}
+
+// IterationCompact records that an iteration's prompt was compacted to fit
+// its token budget. Summary describes what was dropped.
+// Creates an event of type "iteration" with action "compact".
+func (s *Store) IterationCompact(ctx context.Context, session string, number int, summary string) error {
+	// Build metadata
+	meta, err := json.Marshal(map[string]any{
+		"number": number,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration compact metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "compact",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Iteration %d: %s", number, summary),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration compact event: %w", err)
+	}
+
+	return nil
+}
+
+// shortSHA abbreviates a commit SHA to 7 characters for display.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// IterationReplan records that the spec changed before an iteration and the
+// task list was re-planned. Summary describes the spec and task changes.
+// Creates an event of type "iteration" with action "replan".
+func (s *Store) IterationReplan(ctx context.Context, session string, number int, summary string) error {
+	// Build metadata
+	meta, err := json.Marshal(map[string]any{
+		"number": number,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal iteration replan metadata: %w", err)
+	}
+
+	// Create event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "replan",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Iteration %d: %s", number, summary),
+	}
+
+	// Publish event
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish iteration replan event: %w", err)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/usage"
 )
 
 func TestIterationOperations(t *testing.T) {
@@ -236,4 +237,101 @@ func TestIterationOperations(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("IterationCommit records commit SHA", func(t *testing.T) {
+		sha := "0123456789abcdef0123456789abcdef01234567"
+		if err := store.IterationCommit(ctx, session, 1, sha); err != nil {
+			t.Fatalf("IterationCommit failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+
+		for _, iter := range state.Iterations {
+			switch iter.Number {
+			case 1:
+				if iter.CommitSHA != sha {
+					t.Errorf("expected commit SHA %q, got %q", sha, iter.CommitSHA)
+				}
+			default:
+				if iter.CommitSHA != "" {
+					t.Errorf("iteration %d: expected no commit SHA, got %q", iter.Number, iter.CommitSHA)
+				}
+			}
+		}
+	})
+
+	t.Run("IterationUsage accumulates per iteration and session", func(t *testing.T) {
+		before, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+
+		first := usage.Usage{InputTokens: 1000, OutputTokens: 200, Cost: 0.01}
+		second := usage.Usage{InputTokens: 500, CacheReadTokens: 3000, Cost: 0.005}
+		for _, u := range []usage.Usage{first, second} {
+			if err := store.IterationUsage(ctx, session, 1, u); err != nil {
+				t.Fatalf("IterationUsage failed: %v", err)
+			}
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		want := before.Iterations[0].Usage.Add(first).Add(second)
+		if got := state.Iterations[0].Usage; got != want {
+			t.Errorf("iteration usage = %+v, want %+v", got, want)
+		}
+		if got := state.Usage.Tokens() - before.Usage.Tokens(); got != first.Tokens()+second.Tokens() {
+			t.Errorf("session tokens increased by %d, want %d", got, first.Tokens()+second.Tokens())
+		}
+		if info := state.Info(); info.Usage != state.Usage {
+			t.Errorf("Info().Usage = %+v, want %+v", info.Usage, state.Usage)
+		}
+	})
+
+	t.Run("IterationTimeout records reasons", func(t *testing.T) {
+		for _, reason := range []string{"agent stalled: no updates for 10m0s", "iteration timed out after 30m0s"} {
+			if err := store.IterationTimeout(ctx, session, 4, reason); err != nil {
+				t.Fatalf("IterationTimeout failed: %v", err)
+			}
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		for _, iter := range state.Iterations {
+			want := 0
+			if iter.Number == 4 {
+				want = 2
+			}
+			if len(iter.Timeouts) != want {
+				t.Errorf("iteration %d: timeouts = %v, want %d", iter.Number, iter.Timeouts, want)
+			}
+		}
+	})
+
+	t.Run("IterationRestart counts restarts", func(t *testing.T) {
+		if err := store.IterationRestart(ctx, session, 4, 1, "transient error in agent: EOF"); err != nil {
+			t.Fatalf("IterationRestart failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, session)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		for _, iter := range state.Iterations {
+			want := 0
+			if iter.Number == 4 {
+				want = 1
+			}
+			if iter.Restarts != want {
+				t.Errorf("iteration %d: restarts = %d, want %d", iter.Number, iter.Restarts, want)
+			}
+		}
+	})
 }
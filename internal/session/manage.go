@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrSessionNotFound is returned when a session has no events.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionExists is returned when the target session already has events.
+	ErrSessionExists = errors.New("session already exists")
+)
+
+// maxNameLength is the longest session name accepted by ValidateName.
+const maxNameLength = 64
+
+// ValidateName checks that a session name is usable as a NATS subject token:
+// 1-64 characters, only letters, digits, hyphens, and underscores.
+func ValidateName(name string) error {
+	if name == "" {
+		return errors.New("session name cannot be empty")
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("session name too long (max %d characters)", maxNameLength)
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return fmt.Errorf("invalid session name %q: use only alphanumeric, hyphens, underscores", name)
+		}
+	}
+	return nil
+}
+
+// Info summarizes the state for session listings.
+func (st *State) Info() SessionInfo {
+	// Count completed tasks
+	completed := 0
+	for _, task := range st.Tasks {
+		if task.Status == "completed" {
+			completed++
+		}
+	}
+
+	// Find last activity timestamp (most recent event)
+	lastActivity := time.Time{} // Zero time
+	for _, task := range st.Tasks {
+		if task.UpdatedAt.After(lastActivity) {
+			lastActivity = task.UpdatedAt
+		}
+	}
+	if len(st.Notes) > 0 {
+		lastNote := st.Notes[len(st.Notes)-1]
+		if lastNote.CreatedAt.After(lastActivity) {
+			lastActivity = lastNote.CreatedAt
+		}
+	}
+	if len(st.Iterations) > 0 {
+		lastIter := st.Iterations[len(st.Iterations)-1]
+		if lastIter.EndedAt.After(lastActivity) {
+			lastActivity = lastIter.EndedAt
+		} else if lastIter.StartedAt.After(lastActivity) {
+			lastActivity = lastIter.StartedAt
+		}
+	}
+
+	return SessionInfo{
+		Name:           st.Session,
+		Complete:       st.Complete,
+		TasksTotal:     len(st.Tasks),
+		TasksCompleted: completed,
+		Iterations:     len(st.Iterations),
+		LastActivity:   lastActivity,
+		Model:          st.Model,
+		Usage:          st.Usage,
+	}
+}
+
+// RenameSession moves all events of a session to a new name. Subjects embed
+// the session name, so the events are republished (keeping IDs and
+// timestamps) under the new subject prefix before the old ones are purged.
+func (s *Store) RenameSession(ctx context.Context, from, to string) error {
+	if err := ValidateName(to); err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("session is already named %q", to)
+	}
+
+	events, err := s.Events(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("%w: %q", ErrSessionNotFound, from)
+	}
+	if err := s.Import(ctx, to, events); err != nil {
+		return err
+	}
+	return s.ResetSession(ctx, from)
+}
+
+// DeleteSession removes all events of a session.
+func (s *Store) DeleteSession(ctx context.Context, session string) error {
+	events, err := s.Events(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("%w: %q", ErrSessionNotFound, session)
+	}
+	return s.ResetSession(ctx, session)
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"auth", "Auth_2", "my-session"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", "has space", "dot.ted", "wild*", "gt>", strings.Repeat("a", 65)} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) = nil, want error", name)
+		}
+	}
+}
+
+func TestStateInfo(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(store.IterationStart(ctx, "info", 1))
+	task, err := store.TaskAdd(ctx, "info", TaskAddParams{Content: "One", Iteration: 1})
+	must(err)
+	_, err = store.TaskAdd(ctx, "info", TaskAddParams{Content: "Two", Iteration: 1})
+	must(err)
+	must(store.TaskStatus(ctx, "info", TaskStatusParams{ID: task.ID, Status: "completed", Iteration: 1}))
+	must(store.IterationComplete(ctx, "info", 1))
+	must(store.IterationStart(ctx, "info", 2))
+	must(store.SetSessionModel(ctx, "info", "test/model"))
+
+	state, err := store.LoadState(ctx, "info")
+	must(err)
+	info := state.Info()
+	if info.Name != "info" || info.TasksTotal != 2 || info.TasksCompleted != 1 || info.Iterations != 2 || info.Model != "test/model" {
+		t.Errorf("Info() = %+v", info)
+	}
+	if !info.LastActivity.Equal(state.Iterations[1].StartedAt) {
+		t.Errorf("LastActivity = %v, want start of iteration 2 (%v)", info.LastActivity, state.Iterations[1].StartedAt)
+	}
+}
+
+func TestRenameSession(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := store.TaskAdd(ctx, "old", TaskAddParams{Content: "Task one"})
+	must(err)
+	_, err = store.NoteAdd(ctx, "old", NoteAddParams{Content: "Note", Type: "tip"})
+	must(err)
+	_, err = store.TaskAdd(ctx, "taken", TaskAddParams{Content: "Other"})
+	must(err)
+	before, err := store.LoadState(ctx, "old")
+	must(err)
+
+	if err := store.RenameSession(ctx, "missing", "new"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("rename missing session: error = %v, want ErrSessionNotFound", err)
+	}
+	if err := store.RenameSession(ctx, "old", "taken"); !errors.Is(err, ErrSessionExists) {
+		t.Errorf("rename onto existing session: error = %v, want ErrSessionExists", err)
+	}
+	if err := store.RenameSession(ctx, "old", "bad.name"); err == nil {
+		t.Error("rename to invalid name: expected error")
+	}
+
+	must(store.RenameSession(ctx, "old", "new"))
+
+	after, err := store.LoadState(ctx, "new")
+	must(err)
+	before.Session = "new"
+	assertJSONEqual(t, "renamed state", after, before)
+
+	infos, err := store.ListSessions(ctx)
+	must(err)
+	for _, info := range infos {
+		if info.Name == "old" {
+			t.Error("old session still listed after rename")
+		}
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	if _, err := store.TaskAdd(ctx, "doomed", TaskAddParams{Content: "Task"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteSession(ctx, "doomed"); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	events, err := store.Events(ctx, "doomed")
+	if err != nil || len(events) != 0 {
+		t.Errorf("events after delete = %d, %v; want none", len(events), err)
+	}
+	if err := store.DeleteSession(ctx, "doomed"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("second delete: error = %v, want ErrSessionNotFound", err)
+	}
+}
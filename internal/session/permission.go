@@ -0,0 +1,55 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// PermissionDecisionParams describes a tool permission decision to record.
+type PermissionDecisionParams struct {
+	ToolCallID string `json:"tool_call_id"`
+	Title      string `json:"title"`             // Tool title (e.g., "bash")
+	Kind       string `json:"kind"`              // Tool kind (e.g., "execute")
+	Path       string `json:"path,omitempty"`    // File path the tool operates on
+	Command    string `json:"command,omitempty"` // Shell command the tool runs
+	Decision   string `json:"decision"`          // allow or deny
+	Source     string `json:"source"`            // policy, user, or headless
+	Rule       string `json:"rule,omitempty"`    // Policy rule that matched
+	Iteration  int    `json:"iteration"`
+}
+
+// PermissionDecision records how a tool permission request was answered.
+// Creates an event of type "permission" with action "decision".
+func (s *Store) PermissionDecision(ctx context.Context, session string, params PermissionDecisionParams) error {
+	meta, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal permission metadata: %w", err)
+	}
+
+	target := params.Command
+	if target == "" {
+		target = params.Path
+	}
+	data := fmt.Sprintf("%s %s", params.Decision, params.Title)
+	if target != "" {
+		data += ": " + target
+	}
+
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypePermission,
+		Action:  "decision",
+		Meta:    meta,
+		Data:    data,
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to publish permission decision event: %w", err)
+	}
+
+	return nil
+}
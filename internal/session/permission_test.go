@@ -0,0 +1,87 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+	natsclient "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestPermissionDecision(t *testing.T) {
+	// Start embedded NATS server
+	srv, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to start NATS: %v", err)
+	}
+	defer srv.Shutdown()
+
+	// Connect to NATS in-process
+	nc, err := natsclient.Connect("", natsclient.InProcessServer(srv))
+	if err != nil {
+		t.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	// Create JetStream context
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatalf("Failed to create JetStream: %v", err)
+	}
+
+	// Setup stream
+	ctx := context.Background()
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("Failed to setup stream: %v", err)
+	}
+
+	store := NewStore(js, stream)
+	sessionName := "test-permission"
+
+	err = store.PermissionDecision(ctx, sessionName, PermissionDecisionParams{
+		ToolCallID: "call-1",
+		Title:      "bash",
+		Kind:       "execute",
+		Command:    "rm -rf build",
+		Decision:   "deny",
+		Source:     "policy",
+		Rule:       "rules[0] command=^rm ",
+		Iteration:  3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to record permission decision: %v", err)
+	}
+
+	// Read the event back from the permission subject
+	msg, err := stream.GetLastMsgForSubject(ctx, nats.SubjectForEvent(sessionName, nats.EventTypePermission))
+	if err != nil {
+		t.Fatalf("Failed to get permission event: %v", err)
+	}
+
+	var event Event
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		t.Fatalf("Failed to unmarshal event: %v", err)
+	}
+	if event.Type != nats.EventTypePermission || event.Action != "decision" {
+		t.Errorf("Expected permission/decision event, got %s/%s", event.Type, event.Action)
+	}
+	if event.Data != "deny bash: rm -rf build" {
+		t.Errorf("Unexpected event data: %q", event.Data)
+	}
+
+	var meta PermissionDecisionParams
+	if err := json.Unmarshal(event.Meta, &meta); err != nil {
+		t.Fatalf("Failed to unmarshal meta: %v", err)
+	}
+	if meta.Source != "policy" || meta.Iteration != 3 || meta.ToolCallID != "call-1" {
+		t.Errorf("Unexpected meta: %+v", meta)
+	}
+
+	// Permission events must not disturb state reduction
+	if _, err := store.LoadState(ctx, sessionName); err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+// NextIteration returns the first iteration started after iteration n, or nil
+// if n is the latest iteration. Its start marks the rollback point for n.
+func (st *State) NextIteration(n int) *Iteration {
+	var next *Iteration
+	for _, iter := range st.Iterations {
+		if iter.Number > n && (next == nil || iter.Number < next.Number) {
+			next = iter
+		}
+	}
+	return next
+}
+
+// Rollback rewinds a session to the end of iteration `to` by appending
+// compensating events: tasks and notes created later are deleted, tasks and
+// notes changed later are restored, and later iterations are dropped.
+// The task and note ID counters are not rewound, so IDs are never reused.
+// Sessions whose iterations overlapped (parallel workers) cannot be rolled
+// back. Returns the state after the rollback.
+func (s *Store) Rollback(ctx context.Context, session string, to int) (*State, error) {
+	events, err := s.Events(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session events: %w", err)
+	}
+	current, target, err := rollbackStates(session, events, to)
+	if err != nil {
+		return nil, err
+	}
+
+	compensating, err := compensatingEvents(session, to, current, target)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range compensating {
+		if _, err := s.PublishEvent(ctx, event); err != nil {
+			return nil, fmt.Errorf("failed to publish rollback event: %w", err)
+		}
+	}
+
+	for _, event := range compensating {
+		current.Apply(event)
+	}
+	return current, nil
+}
+
+// CheckRollback returns the error Rollback would fail with, without changing
+// the session. Used to refuse a rollback before restoring files.
+func (s *Store) CheckRollback(ctx context.Context, session string, to int) error {
+	events, err := s.Events(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load session events: %w", err)
+	}
+	_, _, err = rollbackStates(session, events, to)
+	return err
+}
+
+// rollbackStates replays events into the current state and the state at the
+// end of iteration `to`: the events before the (latest) start of the next
+// iteration.
+func rollbackStates(session string, events []Event, to int) (current, target *State, err error) {
+	current = newState(session)
+	for _, event := range events {
+		current.Apply(event)
+	}
+	if !current.hasIteration(to) {
+		return nil, nil, fmt.Errorf("iteration #%d not found in session %s", to, session)
+	}
+	next := current.NextIteration(to)
+	if next == nil {
+		return nil, nil, fmt.Errorf("nothing to roll back: iteration #%d is the latest iteration", to)
+	}
+
+	cut := -1
+	for i, event := range events {
+		if event.Type == nats.EventTypeIteration && event.Action == "start" && eventNumber(event) == next.Number {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		return nil, nil, fmt.Errorf("start of iteration #%d not found in the event log, cannot roll back", next.Number)
+	}
+	target = newState(session)
+	for _, event := range events[:cut] {
+		target.Apply(event)
+	}
+	if !target.hasIteration(to) {
+		return nil, nil, fmt.Errorf("iteration #%d starts after iteration #%d in the event log, cannot roll back", to, next.Number)
+	}
+	// Parallel workers run overlapping iterations, so cutting the log would
+	// also drop later events of iterations that are kept
+	if n := overlappingIteration(events[cut:], to); n > 0 {
+		return nil, nil, fmt.Errorf("iteration #%d was still running when iteration #%d started (parallel session), rollback is not supported", n, next.Number)
+	}
+	return current, target, nil
+}
+
+// compensatingEvents builds the events that turn current into target.
+func compensatingEvents(session string, to int, current, target *State) ([]Event, error) {
+	meta, err := json.Marshal(map[string]any{
+		"number": to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rollback metadata: %w", err)
+	}
+	events := []Event{{
+		Session: session,
+		Type:    nats.EventTypeIteration,
+		Action:  "rollback",
+		Meta:    meta,
+		Data:    fmt.Sprintf("Rolled back to iteration %d", to),
+	}}
+
+	// Tasks: delete new ones, restore changed or deleted ones
+	ids := make([]string, 0, len(current.Tasks)+len(target.Tasks))
+	for id := range current.Tasks {
+		ids = append(ids, id)
+	}
+	for id := range target.Tasks {
+		if _, exists := current.Tasks[id]; !exists {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		before, existed := target.Tasks[id]
+		if !existed {
+			meta, _ := json.Marshal(map[string]any{"task_id": id, "iteration": to})
+			events = append(events, Event{
+				Session: session,
+				Type:    nats.EventTypeTask,
+				Action:  "delete",
+				Meta:    meta,
+				Data:    fmt.Sprintf("Rollback: deleted task %s", id),
+			})
+			continue
+		}
+		restore, err := restoreEvent(session, nats.EventTypeTask, id, current.Tasks[id], before)
+		if err != nil {
+			return nil, err
+		}
+		if restore != nil {
+			events = append(events, *restore)
+		}
+	}
+
+	// Notes: same as tasks
+	currentNotes := make(map[string]*Note, len(current.Notes))
+	for _, note := range current.Notes {
+		currentNotes[note.ID] = note
+	}
+	targetNotes := make(map[string]*Note, len(target.Notes))
+	for _, note := range target.Notes {
+		targetNotes[note.ID] = note
+		restore, err := restoreEvent(session, nats.EventTypeNote, note.ID, currentNotes[note.ID], note)
+		if err != nil {
+			return nil, err
+		}
+		if restore != nil {
+			events = append(events, *restore)
+		}
+	}
+	for _, note := range current.Notes {
+		if _, existed := targetNotes[note.ID]; !existed {
+			meta, _ := json.Marshal(map[string]any{"note_id": note.ID, "iteration": to})
+			events = append(events, Event{
+				Session: session,
+				Type:    nats.EventTypeNote,
+				Action:  "delete",
+				Meta:    meta,
+				Data:    fmt.Sprintf("Rollback: deleted note %s", note.ID),
+			})
+		}
+	}
+
+	// Session completion flag
+	if current.Complete != target.Complete {
+		action, data := "session_restart", "Session restarted"
+		if target.Complete {
+			action, data = "session_complete", "Session marked as complete"
+		}
+		events = append(events, Event{
+			Session: session,
+			Type:    nats.EventTypeControl,
+			Action:  action,
+			Data:    "Rollback: " + data,
+		})
+	}
+
+	// Spec snapshot, so edits made after the rollback point trigger re-planning again
+	if !reflect.DeepEqual(current.Spec, target.Spec) {
+		snapshot := SpecSnapshot{}
+		if target.Spec != nil {
+			snapshot = *target.Spec
+		}
+		meta, err := json.Marshal(snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal spec snapshot: %w", err)
+		}
+		events = append(events, Event{
+			Session: session,
+			Type:    nats.EventTypeControl,
+			Action:  "spec_snapshot",
+			Meta:    meta,
+			Data:    "Rollback: restored spec snapshot",
+		})
+	}
+
+	return events, nil
+}
+
+// restoreEvent returns a "restore" event carrying before as its metadata,
+// or nil if current already equals before.
+func restoreEvent(session, eventType, id string, current, before any) (*Event, error) {
+	want, err := json.Marshal(before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s %s: %w", eventType, id, err)
+	}
+	if got, err := json.Marshal(current); err == nil && bytes.Equal(got, want) {
+		return nil, nil
+	}
+	return &Event{
+		Session: session,
+		Type:    eventType,
+		Action:  "restore",
+		Meta:    want,
+		Data:    fmt.Sprintf("Rollback: restored %s %s", eventType, id),
+	}, nil
+}
+
+// newState returns an empty state for a session.
+func newState(session string) *State {
+	return &State{
+		Session: session,
+		Tasks:   make(map[string]*Task),
+	}
+}
+
+// hasIteration reports whether iteration n exists in the state.
+func (st *State) hasIteration(n int) bool {
+	for _, iter := range st.Iterations {
+		if iter.Number == n {
+			return true
+		}
+	}
+	return false
+}
+
+// overlappingIteration returns the number of an iteration up to `to` that
+// starts or completes in events, or 0 if there is none.
+func overlappingIteration(events []Event, to int) int {
+	for _, event := range events {
+		if event.Type != nats.EventTypeIteration || (event.Action != "start" && event.Action != "complete") {
+			continue
+		}
+		if n := eventNumber(event); n <= to {
+			return n
+		}
+	}
+	return 0
+}
+
+// eventNumber returns the "number" field of an iteration event's metadata.
+func eventNumber(event Event) int {
+	var meta struct {
+		Number int `json:"number"`
+	}
+	_ = json.Unmarshal(event.Meta, &meta)
+	return meta.Number
+}
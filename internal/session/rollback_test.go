@@ -0,0 +1,218 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/nats"
+)
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+
+	store := NewStore(js, stream)
+	session := "rollback-session"
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Iteration 1: two tasks, one note
+	must(store.IterationStart(ctx, session, 1))
+	task1, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Task one", Iteration: 1})
+	must(err)
+	task2, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Task two", Iteration: 1})
+	must(err)
+	note1, err := store.NoteAdd(ctx, session, NoteAddParams{Content: "Keep me", Type: "learning", Iteration: 1})
+	must(err)
+	must(store.TaskStatus(ctx, session, TaskStatusParams{ID: task1.ID, Status: "completed", Iteration: 1}))
+	must(store.IterationSummary(ctx, session, 1, "Did task one", []string{task1.ID}))
+	must(store.IterationComplete(ctx, session, 1))
+	must(store.IterationCommit(ctx, session, 1, "abc1234"))
+	must(store.SetSessionSpec(ctx, session, SpecSnapshot{Hash: "v1", Content: "# Spec v1\n", Iteration: 1}))
+
+	target, err := store.LoadState(ctx, session)
+	must(err)
+
+	// Iterations 2 and 3 change, delete, and add things
+	must(store.IterationStart(ctx, session, 2))
+	must(store.TaskStatus(ctx, session, TaskStatusParams{ID: task2.ID, Status: "completed", Iteration: 2}))
+	must(store.TaskContent(ctx, session, TaskContentParams{ID: task1.ID, Content: "Task one (edited)", Iteration: 2}))
+	task3, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Task three", Iteration: 2})
+	must(err)
+	must(store.NoteDelete(ctx, session, NoteDeleteParams{ID: note1.ID, Iteration: 2}))
+	_, err = store.NoteAdd(ctx, session, NoteAddParams{Content: "Drop me", Type: "tip", Iteration: 2})
+	must(err)
+	must(store.SetSessionSpec(ctx, session, SpecSnapshot{Hash: "v2", Content: "# Spec v2\n", Iteration: 2}))
+	must(store.IterationComplete(ctx, session, 2))
+	must(store.IterationStart(ctx, session, 3))
+	must(store.TaskDelete(ctx, session, TaskDeleteParams{ID: task2.ID, Iteration: 3}))
+	must(store.TaskStatus(ctx, session, TaskStatusParams{ID: task3.ID, Status: "completed", Iteration: 3}))
+	must(store.SessionComplete(ctx, session))
+
+	t.Run("invalid targets", func(t *testing.T) {
+		if _, err := store.Rollback(ctx, session, 7); err == nil {
+			t.Error("expected error for unknown iteration")
+		}
+		if _, err := store.Rollback(ctx, session, 3); err == nil {
+			t.Error("expected error when rolling back to the latest iteration")
+		}
+	})
+
+	t.Run("restores state as of iteration 1", func(t *testing.T) {
+		returned, err := store.Rollback(ctx, session, 1)
+		must(err)
+		loaded, err := store.LoadState(ctx, session)
+		must(err)
+
+		for name, got := range map[string]*State{"returned": returned, "loaded": loaded} {
+			if got.Complete {
+				t.Errorf("%s: session still complete", name)
+			}
+			assertJSONEqual(t, name+" tasks", got.Tasks, target.Tasks)
+			assertJSONEqual(t, name+" notes", got.Notes, target.Notes)
+			assertJSONEqual(t, name+" iterations", got.Iterations, target.Iterations)
+			assertJSONEqual(t, name+" spec", got.Spec, target.Spec)
+		}
+		// Counters are not rewound so IDs are never reused
+		if loaded.TaskCounter != 3 || loaded.NoteCounter != 2 {
+			t.Errorf("counters = %d/%d, want 3/2", loaded.TaskCounter, loaded.NoteCounter)
+		}
+	})
+
+	t.Run("rolled back iterations can be rerun and rolled back again", func(t *testing.T) {
+		must(store.IterationStart(ctx, session, 2))
+		_, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Task four", Iteration: 2})
+		must(err)
+
+		state, err := store.Rollback(ctx, session, 1)
+		must(err)
+		assertJSONEqual(t, "tasks", state.Tasks, target.Tasks)
+		assertJSONEqual(t, "iterations", state.Iterations, target.Iterations)
+	})
+}
+
+func TestRollback_Refused(t *testing.T) {
+	ctx := context.Background()
+	ns, _, err := nats.StartEmbeddedNATS(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to start NATS: %v", err)
+	}
+	defer ns.Shutdown()
+
+	nc, err := nats.ConnectInProcess(ns)
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := nats.CreateJetStream(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream: %v", err)
+	}
+	stream, err := nats.SetupStream(ctx, js)
+	if err != nil {
+		t.Fatalf("failed to setup stream: %v", err)
+	}
+	store := NewStore(js, stream)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// assertRefused checks that rolling back to `to` fails and changes nothing.
+	assertRefused := func(t *testing.T, session string, to int, want string) {
+		t.Helper()
+		before, err := store.Events(ctx, session)
+		must(err)
+		if err := store.CheckRollback(ctx, session, to); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckRollback(%d) error = %v, want %q", to, err, want)
+		}
+		if _, err := store.Rollback(ctx, session, to); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Rollback(%d) error = %v, want %q", to, err, want)
+		}
+		after, err := store.Events(ctx, session)
+		must(err)
+		if len(after) != len(before) {
+			t.Errorf("refused rollback published %d event(s)", len(after)-len(before))
+		}
+	}
+
+	t.Run("iteration not started before the next one", func(t *testing.T) {
+		// An imported log where iteration 2 starts first
+		session := "out-of-order"
+		must(store.IterationStart(ctx, session, 2))
+		must(store.IterationStart(ctx, session, 1))
+		_, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Task one", Iteration: 1})
+		must(err)
+		must(store.IterationComplete(ctx, session, 1))
+		must(store.IterationComplete(ctx, session, 2))
+
+		assertRefused(t, session, 1, "cannot roll back")
+		state, err := store.LoadState(ctx, session)
+		must(err)
+		if len(state.Tasks) != 1 {
+			t.Errorf("tasks = %d, want the task kept", len(state.Tasks))
+		}
+	})
+
+	t.Run("parallel session", func(t *testing.T) {
+		session := "parallel"
+		must(store.IterationStart(ctx, session, 1))
+		must(store.IterationStart(ctx, session, 2))
+		_, err := store.TaskAdd(ctx, session, TaskAddParams{Content: "Task two", Iteration: 2})
+		must(err)
+		_, err = store.TaskAdd(ctx, session, TaskAddParams{Content: "Task one", Iteration: 1})
+		must(err)
+		must(store.IterationComplete(ctx, session, 1))
+		must(store.IterationComplete(ctx, session, 2))
+
+		assertRefused(t, session, 1, "parallel session")
+	})
+}
+
+func TestNextIteration(t *testing.T) {
+	st := &State{Iterations: []*Iteration{{Number: 0}, {Number: 1}, {Number: 3}}}
+	if next := st.NextIteration(1); next == nil || next.Number != 3 {
+		t.Errorf("NextIteration(1) = %v, want #3", next)
+	}
+	if next := st.NextIteration(3); next != nil {
+		t.Errorf("NextIteration(3) = #%d, want nil", next.Number)
+	}
+}
+
+func assertJSONEqual(t *testing.T, name string, got, want any) {
+	t.Helper()
+	g, _ := json.Marshal(got)
+	w, _ := json.Marshal(want)
+	if string(g) != string(w) {
+		t.Errorf("%s mismatch:\n got: %s\nwant: %s", name, g, w)
+	}
+}
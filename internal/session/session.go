@@ -8,6 +8,7 @@ import (
 
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/nats"
+	"github.com/mark3labs/iteratr/internal/usage"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
@@ -19,7 +20,7 @@ type Event struct {
 	Timestamp time.Time       `json:"timestamp"` // When the event occurred
 	Session   string          `json:"session"`   // Session name
 	Type      string          `json:"type"`      // Event type: task, note, inbox, iteration, control
-	Action    string          `json:"action"`    // Action type: add, status, mark_read, start, complete, etc.
+	Action    string          `json:"action"`    // Action type: add, status, mark_read, start, complete, rollback, etc.
 	Meta      json.RawMessage `json:"meta"`      // Action-specific metadata
 	Data      string          `json:"data"`      // Primary content (task text, note text, etc.)
 }
@@ -87,6 +88,16 @@ type State struct {
 	Iterations  []*Iteration     `json:"iterations"`   // Iteration history
 	Complete    bool             `json:"complete"`     // Session marked complete
 	Model       string           `json:"model"`        // Last model used for this session
+	Usage       usage.Usage      `json:"usage"`        // Tokens and cost across all iterations (rollbacks do not refund)
+	Spec        *SpecSnapshot    `json:"spec"`         // Spec the task list was last planned against
+}
+
+// SpecSnapshot records the spec content the task list was planned against,
+// so edits made mid-session can be detected and diffed.
+type SpecSnapshot struct {
+	Hash      string `json:"hash"`
+	Content   string `json:"content"`
+	Iteration int    `json:"iteration"` // Iteration the snapshot was taken in
 }
 
 // Task represents a task in the task system.
@@ -98,7 +109,33 @@ type Task struct {
 	DependsOn []string  `json:"depends_on"` // Task IDs this task is blocked by
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-	Iteration int       `json:"iteration"` // Iteration that last modified this task
+	Iteration int       `json:"iteration"`        // Iteration that last modified this task
+	Branch    string    `json:"branch,omitempty"` // Git branch created for this task (task branch mode)
+
+	Description string      `json:"description,omitempty"` // Multi-line details beyond the one-line Content
+	Criteria    []Criterion `json:"criteria,omitempty"`    // Acceptance criteria checklist
+	Tags        []string    `json:"tags,omitempty"`        // Labels, e.g. "backend"
+	SpecRefs    []string    `json:"spec_refs,omitempty"`   // Spec sections, e.g. "spec.md#Login"
+	Estimate    string      `json:"estimate,omitempty"`    // Free-form size, e.g. "2h" or "S"
+
+	Verification *Verification `json:"verification,omitempty"` // Latest run of the verification commands
+}
+
+// Verification is the result of running a task's verification commands
+// before it is marked completed.
+type Verification struct {
+	Passed    bool      `json:"passed"`
+	Commands  []string  `json:"commands"`         // Commands run, in order
+	Failed    string    `json:"failed,omitempty"` // Command that failed (empty if passed)
+	Output    string    `json:"output,omitempty"` // Tail of the failed command's output
+	Iteration int       `json:"iteration"`        // Iteration the verification ran in
+	At        time.Time `json:"at"`
+}
+
+// Criterion is an acceptance criterion of a task.
+type Criterion struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
 }
 
 // Note represents a note recorded during a session.
@@ -113,23 +150,29 @@ type Note struct {
 
 // Iteration represents a single iteration execution.
 type Iteration struct {
-	Number      int       `json:"number"`
-	StartedAt   time.Time `json:"started_at"`
-	EndedAt     time.Time `json:"ended_at,omitempty"`
-	Complete    bool      `json:"complete"`
-	Summary     string    `json:"summary,omitempty"`      // What was accomplished
-	TasksWorked []string  `json:"tasks_worked,omitempty"` // Task IDs touched
-	TaskStarted bool      `json:"task_started,omitempty"` // Whether a task was set to in_progress during this iteration
+	Number      int         `json:"number"`
+	StartedAt   time.Time   `json:"started_at"`
+	EndedAt     time.Time   `json:"ended_at,omitempty"`
+	Complete    bool        `json:"complete"`
+	Summary     string      `json:"summary,omitempty"`      // What was accomplished
+	TasksWorked []string    `json:"tasks_worked,omitempty"` // Task IDs touched
+	TaskStarted bool        `json:"task_started,omitempty"` // Whether a task was set to in_progress during this iteration
+	CommitSHA   string      `json:"commit_sha,omitempty"`   // Commit created by auto-commit for this iteration
+	Usage       usage.Usage `json:"usage"`                  // Tokens and cost consumed by this iteration
+	Timeouts    []string    `json:"timeouts,omitempty"`     // Timeouts and stalls that cut agent runs short
+	Restarts    int         `json:"restarts,omitempty"`     // Times the agent crashed and was restarted
 }
 
 // SessionInfo provides summary information about a session for UI display.
 type SessionInfo struct {
-	Name           string    `json:"name"`
-	Complete       bool      `json:"complete"`
-	TasksTotal     int       `json:"tasks_total"`
-	TasksCompleted int       `json:"tasks_completed"`
-	LastActivity   time.Time `json:"last_activity"`
-	Model          string    `json:"model"` // Last model used for this session
+	Name           string      `json:"name"`
+	Complete       bool        `json:"complete"`
+	TasksTotal     int         `json:"tasks_total"`
+	TasksCompleted int         `json:"tasks_completed"`
+	Iterations     int         `json:"iterations"`
+	LastActivity   time.Time   `json:"last_activity"`
+	Model          string      `json:"model"` // Last model used for this session
+	Usage          usage.Usage `json:"usage"` // Tokens and cost across all iterations
 }
 
 // Apply applies an event to the state, implementing the reduce pattern.
@@ -164,9 +207,14 @@ func (st *State) applyTaskEvent(event Event) {
 	case "add":
 		// Parse metadata for status, priority, and iteration
 		var meta struct {
-			Status    string `json:"status"`
-			Priority  int    `json:"priority"`
-			Iteration int    `json:"iteration"`
+			Status      string   `json:"status"`
+			Priority    int      `json:"priority"`
+			Iteration   int      `json:"iteration"`
+			Description string   `json:"description"`
+			Criteria    []string `json:"criteria"`
+			Tags        []string `json:"tags"`
+			SpecRefs    []string `json:"spec_refs"`
+			Estimate    string   `json:"estimate"`
 		}
 		_ = json.Unmarshal(event.Meta, &meta)
 
@@ -200,6 +248,12 @@ func (st *State) applyTaskEvent(event Event) {
 			CreatedAt: event.Timestamp,
 			UpdatedAt: event.Timestamp,
 			Iteration: meta.Iteration,
+
+			Description: meta.Description,
+			Criteria:    updateCriteria(nil, meta.Criteria),
+			Tags:        meta.Tags,
+			SpecRefs:    meta.SpecRefs,
+			Estimate:    meta.Estimate,
 		}
 		st.Tasks[event.ID] = task
 		st.TaskCounter++
@@ -273,6 +327,117 @@ func (st *State) applyTaskEvent(event Event) {
 			task.Iteration = meta.Iteration
 		}
 
+	case "undepends":
+		// Parse metadata for task ID and dependency
+		var meta struct {
+			TaskID    string `json:"task_id"`
+			DependsOn string `json:"depends_on"`
+			Iteration int    `json:"iteration"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Remove dependency if task exists
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			deps := task.DependsOn[:0]
+			for _, dep := range task.DependsOn {
+				if dep != meta.DependsOn {
+					deps = append(deps, dep)
+				}
+			}
+			task.DependsOn = deps
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
+	case "details":
+		// Parse metadata for task ID and the detail fields that changed;
+		// absent fields are left unchanged
+		var meta struct {
+			TaskID      string    `json:"task_id"`
+			Description *string   `json:"description"`
+			Criteria    *[]string `json:"criteria"`
+			Tags        *[]string `json:"tags"`
+			SpecRefs    *[]string `json:"spec_refs"`
+			Estimate    *string   `json:"estimate"`
+			Iteration   int       `json:"iteration"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			if meta.Description != nil {
+				task.Description = *meta.Description
+			}
+			if meta.Criteria != nil {
+				task.Criteria = updateCriteria(task.Criteria, *meta.Criteria)
+			}
+			if meta.Tags != nil {
+				task.Tags = *meta.Tags
+			}
+			if meta.SpecRefs != nil {
+				task.SpecRefs = *meta.SpecRefs
+			}
+			if meta.Estimate != nil {
+				task.Estimate = *meta.Estimate
+			}
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
+	case "criterion":
+		// Parse metadata for task ID, criterion index (1-based) and state
+		var meta struct {
+			TaskID    string `json:"task_id"`
+			Index     int    `json:"index"`
+			Done      bool   `json:"done"`
+			Iteration int    `json:"iteration"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		if task, exists := st.Tasks[meta.TaskID]; exists && meta.Index >= 1 && meta.Index <= len(task.Criteria) {
+			task.Criteria[meta.Index-1].Done = meta.Done
+			task.UpdatedAt = event.Timestamp
+			task.Iteration = meta.Iteration
+		}
+
+	case "verify":
+		// Parse metadata for task ID and verification result
+		var meta struct {
+			TaskID    string   `json:"task_id"`
+			Passed    bool     `json:"passed"`
+			Commands  []string `json:"commands"`
+			Failed    string   `json:"failed"`
+			Output    string   `json:"output"`
+			Iteration int      `json:"iteration"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			task.Verification = &Verification{
+				Passed:    meta.Passed,
+				Commands:  meta.Commands,
+				Failed:    meta.Failed,
+				Output:    meta.Output,
+				Iteration: meta.Iteration,
+				At:        event.Timestamp,
+			}
+			task.UpdatedAt = event.Timestamp
+		}
+
+	case "branch":
+		// Parse metadata for task ID and branch name
+		var meta struct {
+			TaskID    string `json:"task_id"`
+			Branch    string `json:"branch"`
+			Iteration int    `json:"iteration"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Record branch if task exists
+		if task, exists := st.Tasks[meta.TaskID]; exists {
+			task.Branch = meta.Branch
+			task.UpdatedAt = event.Timestamp
+		}
+
 	case "content":
 		// Parse metadata for task ID and iteration
 		var meta struct {
@@ -297,6 +462,18 @@ func (st *State) applyTaskEvent(event Event) {
 
 		// Remove task from state if it exists
 		delete(st.Tasks, meta.TaskID)
+
+	case "restore":
+		// Metadata is the full task as of a rollback point
+		var task Task
+		if err := json.Unmarshal(event.Meta, &task); err != nil || task.ID == "" {
+			return
+		}
+		if task.DependsOn == nil {
+			task.DependsOn = []string{}
+		}
+		// Replace (or re-create) the task without touching the ID counter
+		st.Tasks[task.ID] = &task
 	}
 }
 
@@ -374,6 +551,28 @@ func (st *State) applyNoteEvent(event Event) {
 				break
 			}
 		}
+
+	case "restore":
+		// Metadata is the full note as of a rollback point
+		var note Note
+		if err := json.Unmarshal(event.Meta, &note); err != nil || note.ID == "" {
+			return
+		}
+		// Replace the note in place if it exists
+		for i, existing := range st.Notes {
+			if existing.ID == note.ID {
+				st.Notes[i] = &note
+				return
+			}
+		}
+		// Otherwise re-insert it in creation order (without touching the ID counter)
+		i := len(st.Notes)
+		for i > 0 && st.Notes[i-1].CreatedAt.After(note.CreatedAt) {
+			i--
+		}
+		st.Notes = append(st.Notes, nil)
+		copy(st.Notes[i+1:], st.Notes[i:])
+		st.Notes[i] = &note
 	}
 }
 
@@ -428,6 +627,86 @@ func (st *State) applyIterationEvent(event Event) {
 				break
 			}
 		}
+
+	case "commit":
+		// Parse metadata for iteration number and commit SHA
+		var meta struct {
+			Number int    `json:"number"`
+			SHA    string `json:"sha"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Record commit on the iteration
+		for _, iter := range st.Iterations {
+			if iter.Number == meta.Number {
+				iter.CommitSHA = meta.SHA
+				break
+			}
+		}
+
+	case "usage":
+		// Parse metadata for iteration number and usage
+		var meta struct {
+			Number int `json:"number"`
+			usage.Usage
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Accumulate on the iteration and the session
+		st.Usage = st.Usage.Add(meta.Usage)
+		for _, iter := range st.Iterations {
+			if iter.Number == meta.Number {
+				iter.Usage = iter.Usage.Add(meta.Usage)
+				break
+			}
+		}
+
+	case "timeout":
+		// Parse metadata for iteration number and reason
+		var meta struct {
+			Number int    `json:"number"`
+			Reason string `json:"reason"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Record the timeout on the iteration
+		for _, iter := range st.Iterations {
+			if iter.Number == meta.Number {
+				iter.Timeouts = append(iter.Timeouts, meta.Reason)
+				break
+			}
+		}
+
+	case "restart":
+		// Parse metadata for iteration number
+		var meta struct {
+			Number int `json:"number"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Count the restart on the iteration
+		for _, iter := range st.Iterations {
+			if iter.Number == meta.Number {
+				iter.Restarts++
+				break
+			}
+		}
+
+	case "rollback":
+		// Parse metadata for the iteration rolled back to
+		var meta struct {
+			Number int `json:"number"`
+		}
+		_ = json.Unmarshal(event.Meta, &meta)
+
+		// Drop iterations after the rollback point
+		kept := st.Iterations[:0]
+		for _, iter := range st.Iterations {
+			if iter.Number <= meta.Number {
+				kept = append(kept, iter)
+			}
+		}
+		st.Iterations = kept
 	}
 }
 
@@ -447,6 +726,15 @@ func (st *State) applyControlEvent(event Event) {
 		if meta.Model != "" {
 			st.Model = meta.Model
 		}
+	case "spec_snapshot":
+		// An empty hash clears the snapshot (rollback to before the first one)
+		var snapshot SpecSnapshot
+		_ = json.Unmarshal(event.Meta, &snapshot)
+		if snapshot.Hash == "" {
+			st.Spec = nil
+		} else {
+			st.Spec = &snapshot
+		}
 	}
 }
 
@@ -473,46 +761,7 @@ func (s *Store) ListSessions(ctx context.Context) ([]SessionInfo, error) {
 			continue // Skip sessions we can't load
 		}
 
-		// Count completed tasks
-		completed := 0
-		for _, task := range state.Tasks {
-			if task.Status == "completed" {
-				completed++
-			}
-		}
-
-		// Find last activity timestamp (most recent event)
-		lastActivity := time.Time{} // Zero time
-		for _, task := range state.Tasks {
-			if task.UpdatedAt.After(lastActivity) {
-				lastActivity = task.UpdatedAt
-			}
-		}
-		if len(state.Notes) > 0 {
-			lastNote := state.Notes[len(state.Notes)-1]
-			if lastNote.CreatedAt.After(lastActivity) {
-				lastActivity = lastNote.CreatedAt
-			}
-		}
-		if len(state.Iterations) > 0 {
-			lastIter := state.Iterations[len(state.Iterations)-1]
-			if lastIter.EndedAt.After(lastActivity) {
-				lastActivity = lastIter.EndedAt
-			} else if lastIter.StartedAt.After(lastActivity) {
-				lastActivity = lastIter.StartedAt
-			}
-		}
-
-		// Build SessionInfo
-		info := SessionInfo{
-			Name:           name,
-			Complete:       state.Complete,
-			TasksTotal:     len(state.Tasks),
-			TasksCompleted: completed,
-			LastActivity:   lastActivity,
-			Model:          state.Model,
-		}
-		infos = append(infos, info)
+		infos = append(infos, state.Info())
 	}
 
 	// Sort by LastActivity descending (most recent first)
@@ -534,6 +783,36 @@ func (s *Store) ListSessions(ctx context.Context) ([]SessionInfo, error) {
 func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 	logger.Debug("Loading state for session: %s", session)
 
+	// Initialize empty state
+	state := newState(session)
+
+	// Reduce events into state
+	totalEvents, err := s.readEvents(ctx, session, state.Apply)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("State loaded: %d total events, %d tasks, %d notes, %d iterations",
+		totalEvents, len(state.Tasks), len(state.Notes), len(state.Iterations))
+
+	return state, nil
+}
+
+// Events returns all events of a session in log order.
+// Events without an ID are assigned their stream sequence, as in LoadState.
+func (s *Store) Events(ctx context.Context, session string) ([]Event, error) {
+	var events []Event
+	if _, err := s.readEvents(ctx, session, func(event Event) {
+		events = append(events, event)
+	}); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// readEvents reads all events for a session from the stream in order and
+// calls fn for each one. Returns the number of messages read.
+func (s *Store) readEvents(ctx context.Context, session string, fn func(Event)) (int, error) {
 	// Create a consumer filtered to this session's events
 	consumer, err := s.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
 		FilterSubject: nats.SubjectForSession(session),
@@ -542,16 +821,10 @@ func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 	})
 	if err != nil {
 		logger.Error("Failed to create consumer for session %s: %v", session, err)
-		return nil, fmt.Errorf("failed to create consumer: %w", err)
-	}
-
-	// Initialize empty state
-	state := &State{
-		Session: session,
-		Tasks:   make(map[string]*Task),
+		return 0, fmt.Errorf("failed to create consumer: %w", err)
 	}
 
-	// Fetch events in batches and reduce into state
+	// Fetch events in batches
 	// Using a large batch size to minimize round trips
 	const batchSize = 1000
 	malformedCount := 0
@@ -586,8 +859,7 @@ func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 				event.ID = fmt.Sprintf("%d", meta.Sequence.Stream)
 			}
 
-			// Apply event to state (reduce)
-			state.Apply(event)
+			fn(event)
 
 			// Acknowledge message
 			_ = msg.Ack()
@@ -606,8 +878,5 @@ func (s *Store) LoadState(ctx context.Context, session string) (*State, error) {
 		logger.Warn("Skipped %d malformed events while loading state", malformedCount)
 	}
 
-	logger.Debug("State loaded: %d total events, %d tasks, %d notes, %d iterations",
-		totalEvents, len(state.Tasks), len(state.Notes), len(state.Iterations))
-
-	return state, nil
+	return totalEvents, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -17,6 +18,58 @@ type TaskAddParams struct {
 	Status    string `json:"status,omitempty"`   // Optional: remaining, in_progress, completed, blocked, cancelled
 	Priority  int    `json:"priority,omitempty"` // Optional: 0=critical, 1=high, 2=medium, 3=low, 4=backlog
 	Iteration int    `json:"iteration"`
+
+	Description string   `json:"description,omitempty"` // Optional multi-line details
+	Criteria    []string `json:"criteria,omitempty"`    // Optional acceptance criteria, all unmet
+	Tags        []string `json:"tags,omitempty"`        // Optional labels
+	SpecRefs    []string `json:"spec_refs,omitempty"`   // Optional spec sections, e.g. "spec.md#Login"
+	Estimate    string   `json:"estimate,omitempty"`    // Optional free-form size, e.g. "2h"
+}
+
+// addMeta returns the "add" event metadata for params with the given status.
+func (params TaskAddParams) addMeta(status string) json.RawMessage {
+	metaMap := map[string]any{
+		"status":    status,
+		"iteration": params.Iteration,
+	}
+	// Only include priority if explicitly set (non-zero)
+	if params.Priority != 0 {
+		metaMap["priority"] = params.Priority
+	}
+	if params.Description != "" {
+		metaMap["description"] = params.Description
+	}
+	if len(params.Criteria) > 0 {
+		metaMap["criteria"] = params.Criteria
+	}
+	if len(params.Tags) > 0 {
+		metaMap["tags"] = params.Tags
+	}
+	if len(params.SpecRefs) > 0 {
+		metaMap["spec_refs"] = params.SpecRefs
+	}
+	if params.Estimate != "" {
+		metaMap["estimate"] = params.Estimate
+	}
+	meta, _ := json.Marshal(metaMap)
+	return meta
+}
+
+// task returns the task created by an "add" event for params.
+func (params TaskAddParams) task(id, status string, now time.Time) *Task {
+	return &Task{
+		ID:          id,
+		Content:     params.Content,
+		Status:      status,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Iteration:   params.Iteration,
+		Description: params.Description,
+		Criteria:    updateCriteria(nil, params.Criteria),
+		Tags:        params.Tags,
+		SpecRefs:    params.SpecRefs,
+		Estimate:    params.Estimate,
+	}
 }
 
 // TaskStatusParams represents the parameters for updating task status.
@@ -70,17 +123,6 @@ func (s *Store) TaskAdd(ctx context.Context, session string, params TaskAddParam
 	id := fmt.Sprintf("TAS-%d", state.TaskCounter+1)
 	now := time.Now()
 
-	// Create event metadata
-	metaMap := map[string]any{
-		"status":    status,
-		"iteration": params.Iteration,
-	}
-	// Only include priority if explicitly set (non-zero)
-	if params.Priority != 0 {
-		metaMap["priority"] = params.Priority
-	}
-	meta, _ := json.Marshal(metaMap)
-
 	// Create and publish event
 	event := Event{
 		ID:        id,
@@ -89,7 +131,7 @@ func (s *Store) TaskAdd(ctx context.Context, session string, params TaskAddParam
 		Type:      nats.EventTypeTask,
 		Action:    "add",
 		Data:      params.Content,
-		Meta:      meta,
+		Meta:      params.addMeta(status),
 	}
 
 	_, err = s.PublishEvent(ctx, event)
@@ -98,16 +140,7 @@ func (s *Store) TaskAdd(ctx context.Context, session string, params TaskAddParam
 	}
 
 	// Build task object to return
-	task := &Task{
-		ID:        id,
-		Content:   params.Content,
-		Status:    status,
-		CreatedAt: now,
-		UpdatedAt: now,
-		Iteration: params.Iteration,
-	}
-
-	return task, nil
+	return params.task(id, status, now), nil
 }
 
 // TaskBatchAdd creates multiple tasks in a single operation.
@@ -161,16 +194,6 @@ func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAd
 		counter++
 		id := fmt.Sprintf("TAS-%d", counter)
 
-		metaMap := map[string]any{
-			"status":    status,
-			"iteration": params.Iteration,
-		}
-		// Only include priority if explicitly set (non-zero)
-		if params.Priority != 0 {
-			metaMap["priority"] = params.Priority
-		}
-		meta, _ := json.Marshal(metaMap)
-
 		event := Event{
 			ID:        id,
 			Timestamp: now,
@@ -178,7 +201,7 @@ func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAd
 			Type:      nats.EventTypeTask,
 			Action:    "add",
 			Data:      params.Content,
-			Meta:      meta,
+			Meta:      params.addMeta(status),
 		}
 
 		_, err := s.PublishEvent(ctx, event)
@@ -186,14 +209,7 @@ func (s *Store) TaskBatchAdd(ctx context.Context, session string, tasks []TaskAd
 			return nil, fmt.Errorf("failed to publish task %q: %w", params.Content, err)
 		}
 
-		result = append(result, &Task{
-			ID:        id,
-			Content:   params.Content,
-			Status:    status,
-			CreatedAt: now,
-			UpdatedAt: now,
-			Iteration: params.Iteration,
-		})
+		result = append(result, params.task(id, status, now))
 	}
 
 	return result, nil
@@ -300,6 +316,56 @@ func (s *Store) TaskPriority(ctx context.Context, session string, params TaskPri
 	return err
 }
 
+// TaskBranchParams represents the parameters for recording a task's git branch.
+type TaskBranchParams struct {
+	ID        string `json:"id"`     // Task ID or prefix (3+ chars)
+	Branch    string `json:"branch"` // Git branch the task is worked on
+	Iteration int    `json:"iteration"`
+}
+
+// TaskBranch records the git branch created for a task.
+// The ID parameter supports prefix matching (minimum 3 characters).
+func (s *Store) TaskBranch(ctx context.Context, session string, params TaskBranchParams) error {
+	// Validate required fields
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+	if params.Branch == "" {
+		return fmt.Errorf("branch is required")
+	}
+
+	// Load current state to resolve task ID prefix
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	// Resolve task ID (supports prefix matching)
+	taskID, err := resolveTaskID(state, params.ID)
+	if err != nil {
+		return err
+	}
+
+	// Create event metadata
+	meta, _ := json.Marshal(map[string]any{
+		"task_id":   taskID,
+		"branch":    params.Branch,
+		"iteration": params.Iteration,
+	})
+
+	// Create and publish event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeTask,
+		Action:  "branch",
+		Data:    params.Branch,
+		Meta:    meta,
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	return err
+}
+
 // TaskContentParams represents the parameters for updating task content.
 type TaskContentParams struct {
 	ID        string `json:"id"`      // Task ID (exact match)
@@ -344,6 +410,232 @@ func (s *Store) TaskContent(ctx context.Context, session string, params TaskCont
 	return err
 }
 
+// TaskDetailsParams represents the parameters for updating a task's details.
+// Nil fields are left unchanged; an empty non-nil slice clears the field.
+type TaskDetailsParams struct {
+	ID          string   `json:"id"`                    // Task ID or prefix (8+ chars)
+	Description *string  `json:"description,omitempty"` // Multi-line details
+	Criteria    []string `json:"criteria,omitempty"`    // Acceptance criteria; met criteria whose text is kept stay met
+	Tags        []string `json:"tags,omitempty"`        // Labels
+	SpecRefs    []string `json:"spec_refs,omitempty"`   // Spec sections
+	Estimate    *string  `json:"estimate,omitempty"`    // Free-form size
+	Iteration   int      `json:"iteration"`
+}
+
+// TaskDetails updates the description, acceptance criteria, tags, spec
+// references and estimate of an existing task.
+func (s *Store) TaskDetails(ctx context.Context, session string, params TaskDetailsParams) error {
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+
+	// Load current state to resolve task ID prefixes
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	taskID, err := resolveTaskID(state, params.ID)
+	if err != nil {
+		return err
+	}
+
+	// Create event metadata with only the fields being changed
+	metaMap := map[string]any{
+		"task_id":   taskID,
+		"iteration": params.Iteration,
+	}
+	if params.Description != nil {
+		metaMap["description"] = *params.Description
+	}
+	if params.Criteria != nil {
+		metaMap["criteria"] = params.Criteria
+	}
+	if params.Tags != nil {
+		metaMap["tags"] = params.Tags
+	}
+	if params.SpecRefs != nil {
+		metaMap["spec_refs"] = params.SpecRefs
+	}
+	if params.Estimate != nil {
+		metaMap["estimate"] = *params.Estimate
+	}
+	if len(metaMap) == 2 {
+		return fmt.Errorf("no task details to update")
+	}
+	meta, _ := json.Marshal(metaMap)
+
+	// Create and publish event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeTask,
+		Action:  "details",
+		Data:    taskID,
+		Meta:    meta,
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	return err
+}
+
+// TaskCriterionParams represents the parameters for marking an acceptance criterion.
+type TaskCriterionParams struct {
+	ID        string `json:"id"`    // Task ID or prefix (8+ chars)
+	Index     int    `json:"index"` // 1-based criterion number
+	Done      bool   `json:"done"`  // Whether the criterion is met
+	Iteration int    `json:"iteration"`
+}
+
+// TaskCriterion marks an acceptance criterion of a task as met or unmet.
+func (s *Store) TaskCriterion(ctx context.Context, session string, params TaskCriterionParams) error {
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+
+	// Load current state to resolve the task and check the index
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	taskID, err := resolveTaskID(state, params.ID)
+	if err != nil {
+		return err
+	}
+	if n := len(state.Tasks[taskID].Criteria); params.Index < 1 || params.Index > n {
+		return fmt.Errorf("invalid criterion %d: task %s has %d acceptance criteria", params.Index, taskID, n)
+	}
+
+	// Create event metadata
+	meta, _ := json.Marshal(map[string]any{
+		"task_id":   taskID,
+		"index":     params.Index,
+		"done":      params.Done,
+		"iteration": params.Iteration,
+	})
+
+	// Create and publish event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeTask,
+		Action:  "criterion",
+		Data:    state.Tasks[taskID].Criteria[params.Index-1].Text,
+		Meta:    meta,
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	return err
+}
+
+// TaskVerifyParams represents the result of running a task's verification commands.
+type TaskVerifyParams struct {
+	ID        string   `json:"id"`       // Task ID or prefix (3+ chars)
+	Passed    bool     `json:"passed"`   // Whether every command succeeded
+	Commands  []string `json:"commands"` // Commands run, in order
+	Failed    string   `json:"failed"`   // Command that failed (empty if passed)
+	Output    string   `json:"output"`   // Output of the failed command
+	Iteration int      `json:"iteration"`
+}
+
+// TaskVerify records the result of a task's verification commands.
+// The ID parameter supports prefix matching (minimum 3 characters).
+func (s *Store) TaskVerify(ctx context.Context, session string, params TaskVerifyParams) error {
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+	if len(params.Commands) == 0 {
+		return fmt.Errorf("verification commands are required")
+	}
+
+	// Load current state to resolve task ID prefix
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	taskID, err := resolveTaskID(state, params.ID)
+	if err != nil {
+		return err
+	}
+
+	// Create event metadata
+	meta, _ := json.Marshal(map[string]any{
+		"task_id":   taskID,
+		"passed":    params.Passed,
+		"commands":  params.Commands,
+		"failed":    params.Failed,
+		"output":    params.Output,
+		"iteration": params.Iteration,
+	})
+
+	result := "passed"
+	if !params.Passed {
+		result = "failed"
+	}
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeTask,
+		Action:  "verify",
+		Data:    result,
+		Meta:    meta,
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	return err
+}
+
+// updateCriteria returns criteria with the given texts, keeping criteria
+// from old that are already met when their text is unchanged.
+func updateCriteria(old []Criterion, texts []string) []Criterion {
+	met := make(map[string]bool)
+	for _, c := range old {
+		if c.Done {
+			met[c.Text] = true
+		}
+	}
+	var criteria []Criterion
+	for _, text := range texts {
+		if text = strings.TrimSpace(text); text != "" {
+			criteria = append(criteria, Criterion{Text: text, Done: met[text]})
+		}
+	}
+	return criteria
+}
+
+// Title returns the first line of the task's content, for listings, branch
+// names and commit messages.
+func (t *Task) Title() string {
+	line, _, _ := strings.Cut(strings.TrimSpace(t.Content), "\n")
+	return strings.TrimSpace(line)
+}
+
+// DetailLines formats the task's description, acceptance criteria, tags, spec
+// references and estimate for task listings, one item per line. Returns nil
+// if the task has no details.
+func (t *Task) DetailLines() []string {
+	var lines []string
+	if t.Description != "" {
+		lines = append(lines, strings.Split(strings.TrimSpace(t.Description), "\n")...)
+	}
+	if len(t.Criteria) > 0 {
+		lines = append(lines, "Acceptance criteria:")
+		for i, c := range t.Criteria {
+			check := " "
+			if c.Done {
+				check = "x"
+			}
+			lines = append(lines, fmt.Sprintf("  %d. [%s] %s", i+1, check, c.Text))
+		}
+	}
+	if len(t.Tags) > 0 {
+		lines = append(lines, "Tags: "+strings.Join(t.Tags, ", "))
+	}
+	if len(t.SpecRefs) > 0 {
+		lines = append(lines, "Spec: "+strings.Join(t.SpecRefs, ", "))
+	}
+	if t.Estimate != "" {
+		lines = append(lines, "Estimate: "+t.Estimate)
+	}
+	return lines
+}
+
 // TaskDeleteParams represents the parameters for deleting a task.
 type TaskDeleteParams struct {
 	ID        string `json:"id"`        // Task ID (exact match)
@@ -424,9 +716,9 @@ func (s *Store) TaskDepends(ctx context.Context, session string, params TaskDepe
 		return fmt.Errorf("failed to resolve depends_on task: %w", err)
 	}
 
-	// Validate that task isn't depending on itself
-	if taskID == dependsOnID {
-		return fmt.Errorf("task cannot depend on itself")
+	// Reject self-dependencies, cancelled dependencies and cycles
+	if err := state.validateDependency(taskID, dependsOnID); err != nil {
+		return err
 	}
 
 	// Create event metadata
@@ -449,6 +741,62 @@ func (s *Store) TaskDepends(ctx context.Context, session string, params TaskDepe
 	return err
 }
 
+// TaskUndepends removes a dependency from an existing task.
+// The ID and DependsOn parameters support prefix matching (minimum 8 characters).
+func (s *Store) TaskUndepends(ctx context.Context, session string, params TaskDependsParams) error {
+	// Validate required fields
+	if params.ID == "" {
+		return fmt.Errorf("task ID is required")
+	}
+	if params.DependsOn == "" {
+		return fmt.Errorf("depends_on is required")
+	}
+
+	// Load current state to resolve task ID prefixes
+	state, err := s.LoadState(ctx, session)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	// Resolve task ID (supports prefix matching)
+	taskID, err := resolveTaskID(state, params.ID)
+	if err != nil {
+		return err
+	}
+
+	// Resolve dependency task ID; a dependency on a deleted task can only be
+	// removed by its full ID
+	deps := state.Tasks[taskID].DependsOn
+	dependsOnID := params.DependsOn
+	if !slices.Contains(deps, dependsOnID) {
+		if dependsOnID, err = resolveTaskID(state, params.DependsOn); err != nil {
+			return fmt.Errorf("failed to resolve depends_on task: %w", err)
+		}
+		if !slices.Contains(deps, dependsOnID) {
+			return fmt.Errorf("task %s does not depend on %s", taskID, dependsOnID)
+		}
+	}
+
+	// Create event metadata
+	meta, _ := json.Marshal(map[string]any{
+		"task_id":    taskID,
+		"depends_on": dependsOnID,
+		"iteration":  params.Iteration,
+	})
+
+	// Create and publish event
+	event := Event{
+		Session: session,
+		Type:    nats.EventTypeTask,
+		Action:  "undepends",
+		Data:    dependsOnID, // Store dependency ID in data field for convenience
+		Meta:    meta,
+	}
+
+	_, err = s.PublishEvent(ctx, event)
+	return err
+}
+
 // TaskList returns all tasks grouped by status.
 func (s *Store) TaskList(ctx context.Context, session string) (*TaskListResult, error) {
 	// Load current state
@@ -522,6 +870,15 @@ func findTaskByContent(state *State, content string) string {
 	return ""
 }
 
+// TaskByContent returns the task whose content matches content
+// (case-insensitive, ignoring surrounding whitespace), or nil.
+func (st *State) TaskByContent(content string) *Task {
+	if id := findTaskByContent(st, content); id != "" {
+		return st.Tasks[id]
+	}
+	return nil
+}
+
 // TaskNext returns the highest priority unblocked task.
 // A task is "ready" if it has status "remaining" and all its dependencies are completed.
 // Returns nil if no ready tasks exist.
@@ -531,42 +888,74 @@ func (s *Store) TaskNext(ctx context.Context, session string) (*Task, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
+	return state.NextTask(), nil
+}
 
-	var bestTask *Task
-	for _, task := range state.Tasks {
-		// Skip non-remaining tasks
-		if task.Status != "remaining" {
-			continue
+// NextTask returns the highest priority ready task, or nil if none is ready.
+// A task is "ready" if it has status "remaining" and all its dependencies are completed.
+func (st *State) NextTask() *Task {
+	if ready := st.ReadyTasks(); len(ready) > 0 {
+		return ready[0]
+	}
+	return nil
+}
+
+// ReadyTasks returns the ready tasks in the order NextTask would pick them:
+// by priority (lower is higher priority), then by ID.
+func (st *State) ReadyTasks() []*Task {
+	var ready []*Task
+	for _, task := range st.Tasks {
+		if task.Status == "remaining" && st.depsCompleted(task) {
+			ready = append(ready, task)
 		}
+	}
+	sortTasksByPriority(ready)
+	return ready
+}
 
-		// Check if all dependencies are completed
-		allDepsCompleted := true
-		for _, depID := range task.DependsOn {
-			if depTask, exists := state.Tasks[depID]; exists {
-				if depTask.Status != "completed" {
-					allDepsCompleted = false
-					break
-				}
-			} else {
-				// Dependency doesn't exist - treat as unresolved
-				allDepsCompleted = false
-				break
-			}
+// depsCompleted reports whether all of task's dependencies are completed.
+// A dependency that doesn't exist is treated as unresolved.
+func (st *State) depsCompleted(task *Task) bool {
+	for _, depID := range task.DependsOn {
+		if dep, exists := st.Tasks[depID]; !exists || dep.Status != "completed" {
+			return false
 		}
+	}
+	return true
+}
 
-		if !allDepsCompleted {
+// InProgressTask returns the in-progress task with the highest priority, or nil.
+func (st *State) InProgressTask() *Task {
+	var best *Task
+	for _, task := range st.Tasks {
+		if task.Status != "in_progress" {
 			continue
 		}
-
-		// This task is ready - compare priority (lower is higher priority)
-		// Use ID as tiebreaker for deterministic selection among equal priorities
-		if bestTask == nil || task.Priority < bestTask.Priority ||
-			(task.Priority == bestTask.Priority && task.ID < bestTask.ID) {
-			bestTask = task
+		if best == nil || task.Priority < best.Priority ||
+			(task.Priority == best.Priority && task.ID < best.ID) {
+			best = task
 		}
 	}
+	return best
+}
+
+// CurrentTask returns the task the next iteration is expected to work on:
+// the in-progress task if any, otherwise the next ready task. Nil if neither exists.
+func (st *State) CurrentTask() *Task {
+	if task := st.InProgressTask(); task != nil {
+		return task
+	}
+	return st.NextTask()
+}
 
-	return bestTask, nil
+// FindTask returns the task with the given ID or unique ID prefix
+// (minimum 3 characters).
+func (st *State) FindTask(idOrPrefix string) (*Task, error) {
+	taskID, err := resolveTaskID(st, idOrPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return st.Tasks[taskID], nil
 }
 
 // resolveTaskID resolves a task ID or prefix to a full task ID.
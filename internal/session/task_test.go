@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -369,6 +370,147 @@ func TestTaskOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("TaskDepends rejects cycles and cancelled dependencies", func(t *testing.T) {
+		cycleSession := "test-session-dep-cycle"
+
+		var ids []string
+		for _, content := range []string{"Schema", "Models", "API", "Dropped"} {
+			task, err := store.TaskAdd(ctx, cycleSession, TaskAddParams{Content: content, Iteration: 1})
+			if err != nil {
+				t.Fatalf("TaskAdd failed: %v", err)
+			}
+			ids = append(ids, task.ID)
+		}
+		// API -> Models -> Schema
+		for _, dep := range [][2]string{{ids[1], ids[0]}, {ids[2], ids[1]}} {
+			if err := store.TaskDepends(ctx, cycleSession, TaskDependsParams{ID: dep[0], DependsOn: dep[1], Iteration: 1}); err != nil {
+				t.Fatalf("TaskDepends failed: %v", err)
+			}
+		}
+
+		err := store.TaskDepends(ctx, cycleSession, TaskDependsParams{ID: ids[0], DependsOn: ids[2], Iteration: 1})
+		want := "dependency cycle: " + ids[0] + " → " + ids[2] + " → " + ids[1] + " → " + ids[0]
+		if err == nil || err.Error() != want {
+			t.Errorf("cycle error = %v, want %q", err, want)
+		}
+
+		if err := store.TaskStatus(ctx, cycleSession, TaskStatusParams{ID: ids[3], Status: "cancelled", Iteration: 1}); err != nil {
+			t.Fatalf("TaskStatus failed: %v", err)
+		}
+		err = store.TaskDepends(ctx, cycleSession, TaskDependsParams{ID: ids[2], DependsOn: ids[3], Iteration: 1})
+		if err == nil || !strings.Contains(err.Error(), "cancelled") {
+			t.Errorf("cancelled dependency error = %v, want rejection", err)
+		}
+
+		state, err := store.LoadState(ctx, cycleSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if deps := state.Tasks[ids[0]].DependsOn; len(deps) != 0 {
+			t.Errorf("rejected dependency recorded: %v", deps)
+		}
+	})
+
+	t.Run("Task details and acceptance criteria", func(t *testing.T) {
+		detailsSession := "test-session-details"
+
+		task, err := store.TaskAdd(ctx, detailsSession, TaskAddParams{
+			Content:     "Add login form",
+			Description: "Email and password.\nShow errors inline.",
+			Criteria:    []string{"Invalid password shows an error", "Session cookie is set"},
+			Tags:        []string{"auth", "ui"},
+			SpecRefs:    []string{"spec.md#Login"},
+			Estimate:    "2h",
+			Iteration:   1,
+		})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if len(task.Criteria) != 2 || task.Estimate != "2h" {
+			t.Errorf("returned task = %+v, want details set", task)
+		}
+
+		if err := store.TaskCriterion(ctx, detailsSession, TaskCriterionParams{ID: task.ID, Index: 1, Done: true, Iteration: 2}); err != nil {
+			t.Fatalf("TaskCriterion failed: %v", err)
+		}
+		if err := store.TaskCriterion(ctx, detailsSession, TaskCriterionParams{ID: task.ID, Index: 3, Done: true, Iteration: 2}); err == nil {
+			t.Error("expected error for out of range criterion")
+		}
+
+		// Replace criteria, keeping the met one, and clear the tags
+		estimate := "1d"
+		err = store.TaskDetails(ctx, detailsSession, TaskDetailsParams{
+			ID:        task.ID,
+			Criteria:  []string{"Invalid password shows an error", "Remember me works"},
+			Tags:      []string{},
+			Estimate:  &estimate,
+			Iteration: 2,
+		})
+		if err != nil {
+			t.Fatalf("TaskDetails failed: %v", err)
+		}
+		if err := store.TaskDetails(ctx, detailsSession, TaskDetailsParams{ID: task.ID}); err == nil {
+			t.Error("expected error when no details are given")
+		}
+
+		state, err := store.LoadState(ctx, detailsSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		got := state.Tasks[task.ID]
+		wantCriteria := []Criterion{{Text: "Invalid password shows an error", Done: true}, {Text: "Remember me works"}}
+		if !reflect.DeepEqual(got.Criteria, wantCriteria) {
+			t.Errorf("criteria = %+v, want %+v", got.Criteria, wantCriteria)
+		}
+		if got.Description != "Email and password.\nShow errors inline." || len(got.Tags) != 0 || got.Estimate != "1d" {
+			t.Errorf("task = %+v, want description kept, tags cleared and estimate updated", got)
+		}
+		wantLines := []string{
+			"Email and password.",
+			"Show errors inline.",
+			"Acceptance criteria:",
+			"  1. [x] Invalid password shows an error",
+			"  2. [ ] Remember me works",
+			"Spec: spec.md#Login",
+			"Estimate: 1d",
+		}
+		if lines := got.DetailLines(); !reflect.DeepEqual(lines, wantLines) {
+			t.Errorf("DetailLines() = %q, want %q", lines, wantLines)
+		}
+	})
+
+	t.Run("TaskUndepends removes a dependency", func(t *testing.T) {
+		undependSession := "test-session-undepends"
+
+		schema, err := store.TaskAdd(ctx, undependSession, TaskAddParams{Content: "Schema", Iteration: 1})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		api, err := store.TaskAdd(ctx, undependSession, TaskAddParams{Content: "API", Iteration: 1})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		if err := store.TaskDepends(ctx, undependSession, TaskDependsParams{ID: api.ID, DependsOn: schema.ID, Iteration: 1}); err != nil {
+			t.Fatalf("TaskDepends failed: %v", err)
+		}
+
+		if err := store.TaskUndepends(ctx, undependSession, TaskDependsParams{ID: api.ID, DependsOn: schema.ID, Iteration: 2}); err != nil {
+			t.Fatalf("TaskUndepends failed: %v", err)
+		}
+		state, err := store.LoadState(ctx, undependSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if deps := state.Tasks[api.ID].DependsOn; len(deps) != 0 {
+			t.Errorf("dependency not removed: %v", deps)
+		}
+
+		err = store.TaskUndepends(ctx, undependSession, TaskDependsParams{ID: api.ID, DependsOn: schema.ID, Iteration: 2})
+		if err == nil || !strings.Contains(err.Error(), "does not depend on") {
+			t.Errorf("removing a missing dependency error = %v, want rejection", err)
+		}
+	})
+
 	t.Run("TaskDepends prevents duplicate dependencies", func(t *testing.T) {
 		// Use a dedicated session
 		dupDepSession := "test-session-dup-dep"
@@ -817,4 +959,133 @@ func TestTaskOperations(t *testing.T) {
 			t.Errorf("expected 3 tasks, got %d", len(tasks))
 		}
 	})
+	t.Run("TaskBranch records branch on task", func(t *testing.T) {
+		branchSession := "test-session-branch"
+
+		task, err := store.TaskAdd(ctx, branchSession, TaskAddParams{Content: "Branch task", Iteration: 1})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+
+		err = store.TaskBranch(ctx, branchSession, TaskBranchParams{ID: task.ID, Branch: "iteratr/tas-1-branch-task", Iteration: 2})
+		if err != nil {
+			t.Fatalf("TaskBranch failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, branchSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if got := state.Tasks[task.ID].Branch; got != "iteratr/tas-1-branch-task" {
+			t.Errorf("expected branch 'iteratr/tas-1-branch-task', got '%s'", got)
+		}
+
+		if err := store.TaskBranch(ctx, branchSession, TaskBranchParams{ID: task.ID}); err == nil {
+			t.Error("expected error for empty branch")
+		}
+		if err := store.TaskBranch(ctx, branchSession, TaskBranchParams{ID: "TAS-99", Branch: "x"}); err == nil {
+			t.Error("expected error for unknown task")
+		}
+	})
+
+	t.Run("TaskVerify records the latest verification", func(t *testing.T) {
+		verifySession := "test-session-verify"
+
+		task, err := store.TaskAdd(ctx, verifySession, TaskAddParams{Content: "Verified task", Iteration: 1})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+
+		err = store.TaskVerify(ctx, verifySession, TaskVerifyParams{
+			ID:        task.ID,
+			Commands:  []string{"go vet ./...", "go test ./..."},
+			Failed:    "go test ./...",
+			Output:    "--- FAIL: TestLogin",
+			Iteration: 2,
+		})
+		if err != nil {
+			t.Fatalf("TaskVerify failed: %v", err)
+		}
+		if err := store.TaskVerify(ctx, verifySession, TaskVerifyParams{ID: task.ID, Passed: true, Commands: []string{"go test ./..."}, Iteration: 3}); err != nil {
+			t.Fatalf("TaskVerify failed: %v", err)
+		}
+
+		state, err := store.LoadState(ctx, verifySession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		v := state.Tasks[task.ID].Verification
+		if v == nil || !v.Passed || v.Failed != "" || v.Iteration != 3 || v.At.IsZero() {
+			t.Errorf("expected latest passing verification, got %+v", v)
+		}
+
+		if err := store.TaskVerify(ctx, verifySession, TaskVerifyParams{ID: task.ID}); err == nil {
+			t.Error("expected error without commands")
+		}
+		if _, err := state.FindTask("TAS-99"); err == nil {
+			t.Error("expected error for unknown task")
+		}
+	})
+
+	t.Run("CurrentTask prefers in-progress task over next ready task", func(t *testing.T) {
+		currentSession := "test-session-current"
+
+		ready, err := store.TaskAdd(ctx, currentSession, TaskAddParams{Content: "Ready task", Priority: 0, Iteration: 1})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		state, err := store.LoadState(ctx, currentSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if got := state.CurrentTask(); got == nil || got.ID != ready.ID {
+			t.Errorf("CurrentTask() = %v, want next ready task %s", got, ready.ID)
+		}
+
+		active, err := store.TaskAdd(ctx, currentSession, TaskAddParams{Content: "Active task", Priority: 3, Status: "in_progress", Iteration: 1})
+		if err != nil {
+			t.Fatalf("TaskAdd failed: %v", err)
+		}
+		state, err = store.LoadState(ctx, currentSession)
+		if err != nil {
+			t.Fatalf("LoadState failed: %v", err)
+		}
+		if got := state.CurrentTask(); got == nil || got.ID != active.ID {
+			t.Errorf("CurrentTask() = %v, want in-progress task %s", got, active.ID)
+		}
+	})
+
+	t.Run("ReadyTasks orders unblocked tasks by priority", func(t *testing.T) {
+		state := &State{Tasks: map[string]*Task{
+			"TAS-1": {ID: "TAS-1", Status: "completed", Priority: 2},
+			"TAS-2": {ID: "TAS-2", Status: "remaining", Priority: 2, DependsOn: []string{"TAS-1"}},
+			"TAS-3": {ID: "TAS-3", Status: "remaining", Priority: 1},
+			"TAS-4": {ID: "TAS-4", Status: "remaining", Priority: 0, DependsOn: []string{"TAS-3"}},
+			"TAS-5": {ID: "TAS-5", Status: "in_progress", Priority: 0},
+			"TAS-6": {ID: "TAS-6", Status: "remaining", Priority: 2},
+		}}
+		var ids []string
+		for _, task := range state.ReadyTasks() {
+			ids = append(ids, task.ID)
+		}
+		if got := strings.Join(ids, ","); got != "TAS-3,TAS-2,TAS-6" {
+			t.Errorf("ReadyTasks() = %s, want TAS-3,TAS-2,TAS-6", got)
+		}
+		if next := state.NextTask(); next == nil || next.ID != "TAS-3" {
+			t.Errorf("NextTask() = %v, want TAS-3", next)
+		}
+	})
+}
+
+func TestTask_Title(t *testing.T) {
+	for content, want := range map[string]string{
+		"Add login":                   "Add login",
+		"Add login\nwith remember me": "Add login",
+		"\n  Add login  \nmore":       "Add login",
+		"":                            "",
+	} {
+		if got := (&Task{Content: content}).Title(); got != want {
+			t.Errorf("Title(%q) = %q, want %q", content, got, want)
+		}
+	}
 }
@@ -0,0 +1,37 @@
+package spec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	udiff "github.com/aymanbagabas/go-udiff"
+)
+
+// Hash returns a hex SHA-256 of the spec content, used to detect edits
+// between iterations. Checklist ticks are ignored, so checklist write-back
+// does not count as an edit.
+func (s *Spec) Hash() string {
+	sum := sha256.Sum256([]byte(uncheck(s.Content())))
+	return hex.EncodeToString(sum[:])
+}
+
+// Diff returns a unified diff from old to new spec content, or "" if they
+// are equal.
+func Diff(old, new string) string {
+	return udiff.Unified("spec (planned)", "spec (now)", old, new)
+}
+
+// DiffStat counts the added and removed lines in a unified diff.
+func DiffStat(diff string) (added, removed int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
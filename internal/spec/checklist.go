@@ -0,0 +1,192 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// checklistRe matches a markdown checklist item: "- [ ] text" or "- [x] text".
+var checklistRe = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*?)\s*$`)
+
+// annotationRe matches a trailing annotation block: "{id=db priority=1 depends=setup,auth}".
+var annotationRe = regexp.MustCompile(`\s*\{([^{}]*)\}$`)
+
+// priorityNames maps priority names to task priorities.
+var priorityNames = map[string]int{
+	"critical": 0,
+	"high":     1,
+	"medium":   2,
+	"low":      3,
+	"backlog":  4,
+}
+
+// ChecklistItem is a "- [ ] text" item in a spec file. Items may end with an
+// annotation block setting an id, a priority, and the ids of items they
+// depend on, e.g. "- [ ] Add the login form {id=login priority=high depends=db}".
+type ChecklistItem struct {
+	Path      string   // Spec file the item appears in
+	Line      int      // 1-based line number
+	Text      string   // Item text without checkbox and annotations
+	Checked   bool     // "- [x]"
+	ID        string   // id= annotation (referenced by depends=)
+	Priority  int      // priority= annotation (0-4), -1 if not set
+	DependsOn []string // depends= annotation
+}
+
+// Checklist returns the checklist items of every spec file in order,
+// skipping fenced code blocks. Dependencies must name an item id.
+func (s *Spec) Checklist() ([]ChecklistItem, error) {
+	var items []ChecklistItem
+	ids := make(map[string]bool)
+	for _, f := range s.Files {
+		fileItems, err := ParseChecklist(f.Path, f.Content)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range fileItems {
+			if item.ID == "" {
+				continue
+			}
+			if ids[item.ID] {
+				return nil, fmt.Errorf("%s:%d: duplicate checklist id %q", item.Path, item.Line, item.ID)
+			}
+			ids[item.ID] = true
+		}
+		items = append(items, fileItems...)
+	}
+	for _, item := range items {
+		for _, dep := range item.DependsOn {
+			if !ids[dep] {
+				return nil, fmt.Errorf("%s:%d: unknown checklist id %q in depends", item.Path, item.Line, dep)
+			}
+		}
+	}
+	return items, nil
+}
+
+// ParseChecklist parses the checklist items in content, skipping fenced code
+// blocks. path is only used in items and error messages.
+func ParseChecklist(path, content string) ([]ChecklistItem, error) {
+	var items []ChecklistItem
+	fence := ""
+	for i, line := range strings.Split(content, "\n") {
+		fence = updateFence(fence, line)
+		if fence != "" {
+			continue
+		}
+		m := checklistRe.FindStringSubmatch(line)
+		if m == nil || m[4] == "" {
+			continue
+		}
+		item := ChecklistItem{
+			Path:     path,
+			Line:     i + 1,
+			Text:     m[4],
+			Checked:  m[2] != " ",
+			Priority: -1,
+		}
+		if a := annotationRe.FindStringSubmatchIndex(item.Text); a != nil {
+			if err := item.annotate(item.Text[a[2]:a[3]]); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, item.Line, err)
+			}
+			item.Text = item.Text[:a[0]]
+		}
+		if item.Text != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// annotate applies "key=value" pairs from an annotation block.
+func (item *ChecklistItem) annotate(block string) error {
+	for _, field := range strings.Fields(block) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return fmt.Errorf("invalid checklist annotation %q (want key=value)", field)
+		}
+		switch key {
+		case "id":
+			item.ID = value
+		case "priority":
+			priority, ok := priorityNames[strings.ToLower(value)]
+			if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 4 {
+				priority, ok = n, true
+			}
+			if !ok {
+				return fmt.Errorf("invalid checklist priority %q (use 0-4 or critical, high, medium, low, backlog)", value)
+			}
+			item.Priority = priority
+		case "depends":
+			for _, dep := range strings.Split(value, ",") {
+				if dep != "" {
+					item.DependsOn = append(item.DependsOn, dep)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown checklist annotation %q (use id, priority or depends)", key)
+		}
+	}
+	return nil
+}
+
+// Tick marks the unchecked items of content for which done returns true as
+// checked ("- [x]"). Returns the updated content and the number of items ticked.
+func Tick(content string, done func(text string) bool) (string, int) {
+	lines := strings.Split(content, "\n")
+	fence := ""
+	ticked := 0
+	for i, line := range lines {
+		fence = updateFence(fence, line)
+		if fence != "" {
+			continue
+		}
+		m := checklistRe.FindStringSubmatchIndex(line)
+		if m == nil || line[m[4]:m[5]] != " " {
+			continue
+		}
+		text := annotationRe.ReplaceAllString(strings.TrimSpace(line[m[8]:m[9]]), "")
+		if text == "" || !done(text) {
+			continue
+		}
+		lines[i] = line[:m[4]] + "x" + line[m[5]:]
+		ticked++
+	}
+	return strings.Join(lines, "\n"), ticked
+}
+
+// TickFile applies Tick to the file at path, rewriting it only if an item
+// was ticked. Returns the number of items ticked.
+func TickFile(path string, done func(text string) bool) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	content, ticked := Tick(string(data), done)
+	if ticked == 0 {
+		return 0, nil
+	}
+	if err := os.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	return ticked, nil
+}
+
+// uncheck returns content with every checklist item unchecked, so ticking
+// items does not count as a spec edit.
+func uncheck(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if m := checklistRe.FindStringSubmatchIndex(line); m != nil {
+			lines[i] = line[:m[4]] + " " + line[m[5]:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,92 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChecklist(t *testing.T) {
+	content := "# Tasks\n" +
+		"- [ ] Set up the database {id=db priority=critical}\n" +
+		"  * [x] Add login form {depends=db,auth priority=1}\n" +
+		"- [ ]\n" +
+		"```\n- [ ] Not a task\n```\n" +
+		"+ [X] Write docs\n" +
+		"- plain bullet\n"
+
+	items, err := ParseChecklist("spec.md", content)
+	if err != nil {
+		t.Fatalf("ParseChecklist() error = %v", err)
+	}
+	want := []ChecklistItem{
+		{Path: "spec.md", Line: 2, Text: "Set up the database", ID: "db", Priority: 0},
+		{Path: "spec.md", Line: 3, Text: "Add login form", Checked: true, Priority: 1, DependsOn: []string{"db", "auth"}},
+		{Path: "spec.md", Line: 8, Text: "Write docs", Checked: true, Priority: -1},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("ParseChecklist() =\n%+v\nwant\n%+v", items, want)
+	}
+
+	for _, bad := range []string{"- [ ] Task {priority=urgent}", "- [ ] Task {owner=me}", "- [ ] Task {id}"} {
+		if _, err := ParseChecklist("spec.md", bad); err == nil || !strings.Contains(err.Error(), "spec.md:1") {
+			t.Errorf("ParseChecklist(%q) error = %v, want error with position", bad, err)
+		}
+	}
+}
+
+func TestChecklist_ValidatesIDs(t *testing.T) {
+	s := &Spec{Files: []File{
+		{Path: "a.md", Content: "- [ ] One {id=one}\n"},
+		{Path: "b.md", Content: "- [ ] Two {depends=one}\n"},
+	}}
+	items, err := s.Checklist()
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Checklist() = %v, %v; want 2 items", items, err)
+	}
+
+	s.Files[1].Content = "- [ ] Two {depends=three}\n"
+	if _, err := s.Checklist(); err == nil || !strings.Contains(err.Error(), `unknown checklist id "three"`) {
+		t.Errorf("Checklist() error = %v, want unknown id", err)
+	}
+	s.Files[1].Content = "- [ ] Two {id=one}\n"
+	if _, err := s.Checklist(); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Checklist() error = %v, want duplicate id", err)
+	}
+}
+
+func TestTickFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.md")
+	content := "- [ ] Set up the database {id=db}\n- [ ] Add login form\n```\n- [ ] Set up the database\n```\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	done := func(text string) bool { return text == "Set up the database" }
+	n, err := TickFile(path, done)
+	if err != nil || n != 1 {
+		t.Fatalf("TickFile() = %d, %v; want 1", n, err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "- [x] Set up the database {id=db}\n- [ ] Add login form\n```\n- [ ] Set up the database\n```\n"
+	if string(data) != want {
+		t.Errorf("file after TickFile() = %q, want %q", data, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want 0600 preserved", info.Mode().Perm())
+	}
+
+	if n, err := TickFile(path, done); err != nil || n != 0 {
+		t.Errorf("second TickFile() = %d, %v; want 0", n, err)
+	}
+}
+
+func TestHash_IgnoresTicks(t *testing.T) {
+	open := &Spec{Files: []File{{Path: "spec.md", Content: "- [ ] Task\n"}}}
+	ticked := &Spec{Files: []File{{Path: "spec.md", Content: "- [x] Task\n"}}}
+	if open.Hash() != ticked.Hash() {
+		t.Error("Hash() should ignore checklist ticks")
+	}
+}
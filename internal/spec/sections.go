@@ -0,0 +1,144 @@
+package spec
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// headingRe matches an ATX markdown heading and captures its level and title.
+var headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// wordRe splits text into lowercase search terms.
+var wordRe = regexp.MustCompile(`[a-z0-9][a-z0-9_-]*`)
+
+// stopWords are ignored when matching a task against spec sections.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"that": true, "this": true, "add": true, "use": true, "make": true, "all": true,
+	"are": true, "should": true, "when": true, "new": true, "update": true, "implement": true,
+	"task": true, "tasks": true, "not": true, "can": true, "its": true, "has": true,
+}
+
+// Section is a heading and the text up to the next heading of any level.
+// Text before the first heading of a file is a section with Level 0.
+type Section struct {
+	Path    string // Spec file the section belongs to
+	Level   int    // Heading level 1-6 (0 = preamble)
+	Heading string // Heading title without the leading #s
+	Text    string // Heading line and body
+}
+
+// Sections splits every spec file at its markdown headings. Headings inside
+// fenced code blocks are ignored.
+func (s *Spec) Sections() []Section {
+	if s == nil {
+		return nil
+	}
+	var sections []Section
+	for _, f := range s.Files {
+		cur := Section{Path: f.Path}
+		var body []string
+		flush := func() {
+			cur.Text = strings.Join(body, "\n")
+			if strings.TrimSpace(cur.Text) != "" {
+				sections = append(sections, cur)
+			}
+		}
+		fence := ""
+		for _, line := range strings.Split(f.Content, "\n") {
+			wasFenced := fence != ""
+			fence = updateFence(fence, line)
+			if m := headingRe.FindStringSubmatch(line); m != nil && !wasFenced {
+				flush()
+				cur = Section{Path: f.Path, Level: len(m[1]), Heading: m[2]}
+				body = nil
+			}
+			body = append(body, line)
+		}
+		flush()
+	}
+	return sections
+}
+
+// Relevant returns the parts of the spec that relate to query, typically the
+// content of the task an iteration will work on. Preambles and top-level
+// (#) sections are always kept as context; other sections are kept when their
+// heading or body mentions a term from query, together with the headings of
+// their parent sections. Sections keep their original order. If nothing
+// matches, the full spec is returned.
+func (s *Spec) Relevant(query string) string {
+	terms := searchTerms(query)
+	sections := s.Sections()
+	if len(terms) == 0 || len(sections) == 0 {
+		return s.Content()
+	}
+
+	keep := make([]bool, len(sections))
+	matched := false
+	for i, sec := range sections {
+		if sec.Level <= 1 {
+			keep[i] = true
+			continue
+		}
+		if sectionMatches(sec, terms) {
+			keep[i] = true
+			matched = true
+			// Keep the parent headings so the section reads in context
+			level := sec.Level
+			for j := i - 1; j >= 0 && sections[j].Path == sec.Path && level > 1; j-- {
+				if sections[j].Level < level {
+					keep[j] = true
+					level = sections[j].Level
+				}
+			}
+		}
+	}
+	if !matched {
+		return s.Content()
+	}
+
+	var parts []string
+	path := ""
+	multi := len(s.Files) > 1
+	for i, sec := range sections {
+		if !keep[i] {
+			continue
+		}
+		text := strings.TrimRight(sec.Text, "\n")
+		if multi && sec.Path != path {
+			text = "<!-- spec: " + filepath.ToSlash(sec.Path) + " -->\n" + text
+			path = sec.Path
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// searchTerms returns the distinct lowercase words of query worth matching on.
+func searchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, w := range wordRe.FindAllString(strings.ToLower(query), -1) {
+		if len(w) < 3 || stopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// sectionMatches reports whether a section's words include any of terms.
+func sectionMatches(sec Section, terms []string) bool {
+	words := make(map[string]bool)
+	for _, w := range wordRe.FindAllString(strings.ToLower(sec.Text), -1) {
+		words[w] = true
+	}
+	for _, t := range terms {
+		if words[t] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+// Package spec loads the spec files a session works from. A session may
+// reference several files, directories or globs; markdown include directives
+// are resolved at load time and the result can be narrowed to the sections
+// relevant to the task at hand.
+package spec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxIncludeDepth bounds nested include directives.
+const maxIncludeDepth = 10
+
+// includeRe matches an include directive on its own line:
+//
+//	<!-- include: path/to/file.md -->
+//
+// Paths are relative to the file containing the directive.
+var includeRe = regexp.MustCompile(`^\s*<!--\s*include:\s*(.+?)\s*-->\s*$`)
+
+// File is a loaded spec file with its includes expanded.
+type File struct {
+	Path    string // Path as resolved from the spec list
+	Content string // Content with include directives replaced
+}
+
+// Spec is the ordered set of spec files for a session.
+type Spec struct {
+	Files   []File
+	Sources []string // Every file read, including included files
+}
+
+// Resolve expands spec entries into file paths. Each entry may be a file, a
+// directory (every .md file below it, sorted) or a glob. Paths are returned
+// in entry order without duplicates. An entry that matches nothing is an error.
+func Resolve(entries []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		p = filepath.Clean(p)
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+
+	for _, entry := range entries {
+		if entry == "" {
+			continue
+		}
+		info, err := os.Stat(entry)
+		switch {
+		case err == nil && info.IsDir():
+			files, err := markdownFiles(entry)
+			if err != nil {
+				return nil, err
+			}
+			if len(files) == 0 {
+				return nil, fmt.Errorf("spec directory %s contains no .md files", entry)
+			}
+			for _, f := range files {
+				add(f)
+			}
+		case err == nil:
+			add(entry)
+		case strings.ContainsAny(entry, "*?["):
+			matches, err := filepath.Glob(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid spec glob %q: %w", entry, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("spec glob %q matches no files", entry)
+			}
+			for _, m := range matches {
+				if fi, err := os.Stat(m); err == nil && !fi.IsDir() {
+					add(m)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("spec file not found: %s", entry)
+		}
+	}
+	return paths, nil
+}
+
+// markdownFiles returns the .md files below dir in lexical order.
+func markdownFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec directory %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Load resolves entries and reads every spec file, expanding includes.
+func Load(entries []string) (*Spec, error) {
+	paths, err := Resolve(entries)
+	if err != nil {
+		return nil, err
+	}
+	s := &Spec{}
+	for _, path := range paths {
+		content, err := s.readWithIncludes(path, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.Files = append(s.Files, File{Path: path, Content: content})
+	}
+	return s, nil
+}
+
+// readWithIncludes reads path and replaces include directives with the
+// included file's content. stack holds the files being included to detect cycles.
+// Every file read is added to s.Sources.
+func (s *Spec) readWithIncludes(path string, stack []string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range stack {
+		if p == abs {
+			return "", fmt.Errorf("spec include cycle: %s includes itself", path)
+		}
+	}
+	if len(stack) >= maxIncludeDepth {
+		return "", fmt.Errorf("spec include %s: nested more than %d levels deep", path, maxIncludeDepth)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if len(stack) > 0 {
+			return "", fmt.Errorf("spec include %s: %w", path, err)
+		}
+		return "", fmt.Errorf("failed to read spec file: %w", err)
+	}
+	if !slices.Contains(s.Sources, path) {
+		s.Sources = append(s.Sources, path)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	fence := ""
+	for i, line := range lines {
+		fence = updateFence(fence, line)
+		m := includeRe.FindStringSubmatch(line)
+		if m == nil || fence != "" {
+			continue
+		}
+		target := m[1]
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		included, err := s.readWithIncludes(target, append(stack, abs))
+		if err != nil {
+			return "", err
+		}
+		lines[i] = strings.TrimRight(included, "\n")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// updateFence tracks fenced code blocks: it returns the open fence marker
+// after line, or "" outside a code block.
+func updateFence(fence, line string) string {
+	trimmed := strings.TrimSpace(line)
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, marker) {
+			if fence == "" {
+				return marker
+			}
+			if fence == marker {
+				return ""
+			}
+		}
+	}
+	return fence
+}
+
+// Content returns the spec text. A single file is returned as is; multiple
+// files are concatenated, each introduced by a "<!-- spec: path -->" marker.
+func (s *Spec) Content() string {
+	if s == nil || len(s.Files) == 0 {
+		return ""
+	}
+	if len(s.Files) == 1 {
+		return s.Files[0].Content
+	}
+	parts := make([]string, len(s.Files))
+	for i, f := range s.Files {
+		parts[i] = fmt.Sprintf("<!-- spec: %s -->\n%s", filepath.ToSlash(f.Path), strings.TrimRight(f.Content, "\n"))
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
@@ -0,0 +1,161 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files under dir from a path -> content map.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFiles(t, ".", map[string]string{
+		"specs/SPEC.md":      "# Main\n",
+		"specs/api/auth.md":  "# Auth\n",
+		"specs/api/users.md": "# Users\n",
+		"specs/notes.txt":    "not a spec\n",
+	})
+
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+		wantErr bool
+	}{
+		{"single file", []string{"specs/SPEC.md"}, []string{"specs/SPEC.md"}, false},
+		{"directory is recursive and sorted", []string{"specs"}, []string{"specs/SPEC.md", "specs/api/auth.md", "specs/api/users.md"}, false},
+		{"glob", []string{"specs/api/*.md"}, []string{"specs/api/auth.md", "specs/api/users.md"}, false},
+		{"entry order without duplicates", []string{"specs/api/users.md", "specs/api"}, []string{"specs/api/users.md", "specs/api/auth.md"}, false},
+		{"missing file", []string{"specs/missing.md"}, nil, true},
+		{"glob without matches", []string{"specs/*.yml"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_Includes(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFiles(t, ".", map[string]string{
+		"specs/SPEC.md":          "# Main\n<!-- include: parts/api.md -->\n```\n<!-- include: parts/api.md -->\n```\n",
+		"specs/parts/api.md":     "## API\n<!-- include: ../shared/errors.md -->\n",
+		"specs/shared/errors.md": "### Errors\nReturn JSON.\n",
+		"specs/loop.md":          "<!-- include: loop.md -->\n",
+	})
+
+	s, err := Load([]string{"specs/SPEC.md"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := "# Main\n## API\n### Errors\nReturn JSON.\n```\n<!-- include: parts/api.md -->\n```\n"
+	if got := s.Content(); got != want {
+		t.Errorf("Content() = %q, want %q", got, want)
+	}
+	wantSources := []string{"specs/SPEC.md", "specs/parts/api.md", "specs/shared/errors.md"}
+	if !reflect.DeepEqual(s.Sources, wantSources) {
+		t.Errorf("Sources = %v, want %v", s.Sources, wantSources)
+	}
+
+	if _, err := Load([]string{"specs/loop.md"}); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Load(loop) error = %v, want include cycle", err)
+	}
+}
+
+func TestContent_MultipleFiles(t *testing.T) {
+	s := &Spec{Files: []File{{Path: "specs/a.md", Content: "# A\n"}, {Path: "specs/b.md", Content: "# B\n"}}}
+	want := "<!-- spec: specs/a.md -->\n# A\n\n<!-- spec: specs/b.md -->\n# B\n"
+	if got := s.Content(); got != want {
+		t.Errorf("Content() = %q, want %q", got, want)
+	}
+}
+
+func TestSections(t *testing.T) {
+	s := &Spec{Files: []File{{Path: "spec.md", Content: "Intro\n# Title\nOverview\n## Login\n```\n# not a heading\n```\n## Billing\nInvoices\n"}}}
+	var headings []string
+	for _, sec := range s.Sections() {
+		headings = append(headings, sec.Heading)
+	}
+	if want := []string{"", "Title", "Login", "Billing"}; !reflect.DeepEqual(headings, want) {
+		t.Errorf("section headings = %q, want %q", headings, want)
+	}
+}
+
+func TestRelevant(t *testing.T) {
+	content := `# Shop
+Build a small shop.
+
+## Auth
+### Login form
+Email and password fields.
+### Sessions
+Cookies expire after a day.
+
+## Billing
+Invoices are emailed monthly.
+`
+	s := &Spec{Files: []File{{Path: "spec.md", Content: content}}}
+
+	got := s.Relevant("Add the login form")
+	for _, want := range []string{"# Shop", "Build a small shop.", "## Auth", "### Login form"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Relevant() missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"Sessions", "Billing"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("Relevant() should not contain %q:\n%s", unwanted, got)
+		}
+	}
+
+	if got := s.Relevant("Refactor the database layer"); got != content {
+		t.Errorf("Relevant() without matches should return the full spec, got:\n%s", got)
+	}
+	if got := s.Relevant(""); got != content {
+		t.Error("Relevant(\"\") should return the full spec")
+	}
+}
+
+func TestHashAndDiff(t *testing.T) {
+	a := &Spec{Files: []File{{Path: "spec.md", Content: "# Shop\n- Login\n- Cart\n"}}}
+	b := &Spec{Files: []File{{Path: "spec.md", Content: "# Shop\n- Login\n- Checkout\n"}}}
+	if a.Hash() == b.Hash() {
+		t.Error("Hash() should differ for different content")
+	}
+	if a.Hash() != (&Spec{Files: a.Files}).Hash() {
+		t.Error("Hash() should be stable for equal content")
+	}
+
+	if diff := Diff(a.Content(), a.Content()); diff != "" {
+		t.Errorf("Diff() of equal content = %q, want empty", diff)
+	}
+	diff := Diff(a.Content(), b.Content())
+	if !strings.Contains(diff, "-- Cart") || !strings.Contains(diff, "+- Checkout") {
+		t.Errorf("Diff() missing changed lines:\n%s", diff)
+	}
+	if added, removed := DiffStat(diff); added != 1 || removed != 1 {
+		t.Errorf("DiffStat() = +%d -%d, want +1 -1", added, removed)
+	}
+}
@@ -0,0 +1,169 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// EstimateTokens approximates the token count of s at four bytes per token,
+// which is close enough for English prose and code to budget a prompt.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// Compaction describes what was dropped from a prompt to fit its token budget.
+type Compaction struct {
+	Budget             int      // Token budget the prompt was fitted to
+	Before             int      // Estimated tokens before compaction
+	After              int      // Estimated tokens after compaction
+	TruncatedSummaries int      // Iteration summaries cut to their first line
+	DroppedIterations  []int    // Iteration numbers removed from the history
+	DroppedNotes       []string // Note IDs removed, lowest value first
+}
+
+// Summary returns a one-line description for logs and session events.
+func (c *Compaction) Summary() string {
+	var parts []string
+	if c.TruncatedSummaries > 0 {
+		parts = append(parts, fmt.Sprintf("shortened %d iteration summaries", c.TruncatedSummaries))
+	}
+	if n := len(c.DroppedIterations); n > 0 {
+		parts = append(parts, fmt.Sprintf("dropped %d iterations from history", n))
+	}
+	if n := len(c.DroppedNotes); n > 0 {
+		parts = append(parts, fmt.Sprintf("dropped %d notes (%s)", n, strings.Join(c.DroppedNotes, ", ")))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "nothing left to drop")
+	}
+	summary := fmt.Sprintf("Prompt compacted from ~%d to ~%d tokens (budget %d): %s", c.Before, c.After, c.Budget, strings.Join(parts, ", "))
+	if c.After > c.Budget {
+		summary += "; spec, tasks and remaining notes still exceed the budget"
+	}
+	return summary
+}
+
+// droppableNoteTypes lists note types that may be dropped to fit the budget,
+// lowest value first. Decisions and stuck notes are always kept.
+var droppableNoteTypes = []string{"learning", "tip"}
+
+// compact shrinks data until the rendered prompt fits maxTokens. History
+// summaries are cut to their first line, then the oldest iterations are
+// folded into a one-line digest, then the oldest learning and tip notes are
+// dropped. The spec, tasks, extra instructions and template text are never
+// touched. It returns the prompt that fits (or the smallest one possible) and
+// a report, which is nil when the prompt already fit.
+func compact(name, text string, data *Data, maxTokens int) (string, *Compaction, error) {
+	prompt, err := Render(name, text, data)
+	if err != nil || maxTokens <= 0 {
+		return prompt, nil, err
+	}
+	tokens := EstimateTokens(prompt)
+	if tokens <= maxTokens {
+		return prompt, nil, nil
+	}
+	report := &Compaction{Budget: maxTokens, Before: tokens}
+
+	// Each step removes one thing and re-renders until the prompt fits.
+	// A removal is only reported if the template actually showed it. The
+	// digest line can make the first dropped iterations cost more than they
+	// save, so the smallest prompt so far is what gets returned.
+	best := prompt
+	steps := []func(*Data) func(*Compaction){truncateSummaries, dropOldestIteration, dropNote}
+	for _, step := range steps {
+		for tokens > maxTokens {
+			record := step(data)
+			if record == nil {
+				break
+			}
+			prompt, err = Render(name, text, data)
+			if err != nil {
+				return "", nil, err
+			}
+			if smaller := EstimateTokens(prompt); smaller < tokens {
+				record(report)
+				best, tokens = prompt, smaller
+				report.DroppedIterations = report.DroppedIterations[:0]
+				for _, iter := range data.dropped {
+					report.DroppedIterations = append(report.DroppedIterations, iter.Number)
+				}
+			}
+		}
+	}
+	report.After = tokens
+	return best, report, nil
+}
+
+// truncateSummaries cuts every multi-line iteration summary to its first line.
+// Iterations are copied so the session state is left alone.
+func truncateSummaries(data *Data) func(*Compaction) {
+	count := 0
+	iterations := make([]*session.Iteration, len(data.Iterations))
+	for i, iter := range data.Iterations {
+		iterations[i] = iter
+		if first, _, ok := strings.Cut(iter.Summary, "\n"); ok {
+			short := *iter
+			short.Summary = first
+			iterations[i] = &short
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	data.Iterations = iterations
+	return func(c *Compaction) { c.TruncatedSummaries += count }
+}
+
+// dropOldestIteration removes the oldest iteration that has a summary and
+// folds it into the digest of dropped iterations.
+func dropOldestIteration(data *Data) func(*Compaction) {
+	for i, iter := range data.Iterations {
+		if iter.Summary == "" {
+			continue
+		}
+		data.Iterations = append(data.Iterations[:i:i], data.Iterations[i+1:]...)
+		data.dropped = append(data.dropped, iter)
+		data.Digest = digest(data)
+		// compact reports every iteration in the digest of the prompt it keeps
+		return func(*Compaction) {}
+	}
+	return nil
+}
+
+// digest summarizes the dropped iterations in one line, e.g.
+// "Iterations 3-9: 7 tasks completed (full history: iteratr session show demo)".
+func digest(data *Data) string {
+	first, last := data.dropped[0].Number, data.dropped[len(data.dropped)-1].Number
+	span := fmt.Sprintf("Iteration %d", first)
+	if last != first {
+		span = fmt.Sprintf("Iterations %d-%d", first, last)
+	}
+	completed := 0
+	for _, task := range data.Tasks {
+		if task.Status == "completed" && task.Iteration >= first && task.Iteration <= last {
+			completed++
+		}
+	}
+	tasks := "tasks"
+	if completed == 1 {
+		tasks = "task"
+	}
+	return fmt.Sprintf("%s: %d %s completed (full history: iteratr session show %s)", span, completed, tasks, data.Session)
+}
+
+// dropNote removes the oldest note of the lowest-value droppable type.
+func dropNote(data *Data) func(*Compaction) {
+	for _, noteType := range droppableNoteTypes {
+		for i, note := range data.Notes {
+			if note.Type != noteType {
+				continue
+			}
+			data.Notes = append(data.Notes[:i:i], data.Notes[i+1:]...)
+			return func(c *Compaction) { c.DroppedNotes = append(c.DroppedNotes, note.ID) }
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+func budgetTestData() *Data {
+	state := &session.State{
+		Tasks: map[string]*session.Task{"TAS-1": {ID: "TAS-1", Content: "Ship it", Status: "remaining"}},
+		Notes: []*session.Note{
+			{ID: "NOT-1", Type: "learning", Content: strings.Repeat("old lesson ", 20), Iteration: 1},
+			{ID: "NOT-2", Type: "decision", Content: strings.Repeat("use sqlite ", 20), Iteration: 1},
+			{ID: "NOT-3", Type: "tip", Content: strings.Repeat("run make ", 20), Iteration: 2},
+			{ID: "NOT-4", Type: "learning", Content: strings.Repeat("new lesson ", 20), Iteration: 3},
+			{ID: "NOT-5", Type: "stuck", Content: strings.Repeat("flaky test ", 20), Iteration: 3},
+		},
+	}
+	for i := 1; i <= 3; i++ {
+		state.Iterations = append(state.Iterations, &session.Iteration{
+			Number:  i,
+			Summary: "Did things\n" + strings.Repeat("details ", 50),
+		})
+	}
+	data := NewData(state)
+	data.Spec = strings.Repeat("spec ", 100)
+	return data
+}
+
+func TestEstimateTokens(t *testing.T) {
+	for s, want := range map[string]int{"": 0, "abc": 1, "abcd": 1, "abcde": 2} {
+		if got := EstimateTokens(s); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestCompact_FitsWithoutChanges(t *testing.T) {
+	prompt, report, err := compact("test", DefaultTemplate, budgetTestData(), 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report != nil {
+		t.Errorf("report = %+v, want nil when the prompt fits", report)
+	}
+	if !strings.Contains(prompt, "old lesson") {
+		t.Error("notes missing from uncompacted prompt")
+	}
+}
+
+func TestCompact_DropsHistoryThenLowValueNotes(t *testing.T) {
+	data := budgetTestData()
+	full, err := Render("test", DefaultTemplate, budgetTestData())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Enough room for everything except history details and two notes
+	budget := EstimateTokens(full) - 350
+	prompt, report, err := compact("test", DefaultTemplate, data, budget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report == nil {
+		t.Fatal("expected a compaction report")
+	}
+	if report.Before != EstimateTokens(full) || report.After != EstimateTokens(prompt) || report.After > budget {
+		t.Errorf("report tokens = %d -> %d (budget %d), prompt is %d", report.Before, report.After, budget, EstimateTokens(prompt))
+	}
+	if report.TruncatedSummaries != 3 {
+		t.Errorf("TruncatedSummaries = %d, want 3", report.TruncatedSummaries)
+	}
+	if strings.Contains(prompt, "details") {
+		t.Error("history details should be truncated")
+	}
+	if !strings.Contains(prompt, "spec spec") || !strings.Contains(prompt, "Ship it") {
+		t.Error("spec and tasks must be kept intact")
+	}
+	if !strings.Contains(prompt, "use sqlite") || !strings.Contains(prompt, "flaky test") {
+		t.Error("decision and stuck notes must be kept")
+	}
+	if len(report.DroppedNotes) != 1 || report.DroppedNotes[0] != "NOT-1" {
+		t.Errorf("DroppedNotes = %v, want only the oldest learning note", report.DroppedNotes)
+	}
+}
+
+func TestCompact_ReportsWhenBudgetCannotBeMet(t *testing.T) {
+	data := budgetTestData()
+	prompt, report, err := compact("test", DefaultTemplate, data, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report == nil {
+		t.Fatal("expected a compaction report")
+	}
+	if len(report.DroppedIterations) != 3 {
+		t.Errorf("DroppedIterations = %v, want all 3", report.DroppedIterations)
+	}
+	if got := strings.Join(report.DroppedNotes, ","); got != "NOT-1,NOT-4,NOT-3" {
+		t.Errorf("DroppedNotes = %s, want learning notes oldest first, then tips", got)
+	}
+	if !strings.Contains(prompt, "spec spec") {
+		t.Error("spec must be kept even over budget")
+	}
+	if summary := report.Summary(); !strings.Contains(summary, "still exceed the budget") || !strings.Contains(summary, "dropped 3 notes") {
+		t.Errorf("Summary() = %q", summary)
+	}
+	// Session state is never modified
+	if len(data.State.Notes) != 5 || strings.Count(data.State.Iterations[0].Summary, "\n") != 1 {
+		t.Error("compaction modified the session state")
+	}
+}
+
+func TestCompact_DigestsDroppedIterations(t *testing.T) {
+	data := budgetTestData()
+	data.Session = "demo"
+	data.Tasks = append(data.Tasks,
+		&session.Task{ID: "TAS-2", Content: "Set up", Status: "completed", Iteration: 1},
+		&session.Task{ID: "TAS-3", Content: "Add login", Status: "completed", Iteration: 2},
+		&session.Task{ID: "TAS-4", Content: "Add logout", Status: "completed", Iteration: 3},
+	)
+	prompt, report, err := compact("test", "{{history}}{{spec}}", data, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.DroppedIterations) != 3 {
+		t.Errorf("DroppedIterations = %v, want all 3", report.DroppedIterations)
+	}
+	want := "## Recent Progress\n- Iterations 1-3: 3 tasks completed (full history: iteratr session show demo)\n"
+	if !strings.HasPrefix(prompt, want) {
+		t.Errorf("prompt = %q, want it to start with %q", prompt, want)
+	}
+	if strings.Contains(prompt, "Did things") {
+		t.Error("dropped iterations should only appear in the digest")
+	}
+}
+
+func TestDigest_SingleIteration(t *testing.T) {
+	data := budgetTestData()
+	data.Session = "demo"
+	if dropOldestIteration(data) == nil {
+		t.Fatal("dropOldestIteration() found nothing to drop")
+	}
+	if want := "Iteration 1: 0 tasks completed (full history: iteratr session show demo)"; data.Digest != want {
+		t.Errorf("Digest = %q, want %q", data.Digest, want)
+	}
+	if len(data.Iterations) != 2 || len(data.State.Iterations) != 3 {
+		t.Errorf("iterations = %d (state %d), want 2 (state 3)", len(data.Iterations), len(data.State.Iterations))
+	}
+}
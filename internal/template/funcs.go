@@ -0,0 +1,91 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// maxIncludeDepth bounds nested {{include}} calls so a partial that includes
+// itself fails instead of recursing forever.
+const maxIncludeDepth = 10
+
+// funcMap returns the functions available to templates rendered with data.
+//
+// The original placeholders are zero-argument functions, so existing
+// templates keep working unchanged:
+//   - {{session}}, {{iteration}}, {{spec}}, {{extra}}, {{port}}, {{binary}}
+//   - {{notes}}, {{tasks}}, {{history}} - pre-formatted sections (empty if none)
+//
+// Helpers:
+//   - {{include "file"}} - render another template file with the same data
+//   - {{var "name"}} - user-defined variable ("" if unset)
+//   - {{tasksWithStatus "remaining"}} - tasks with the given status
+//   - {{join .List ", "}}, {{upper s}}, {{lower s}}, {{trim s}}
+func funcMap(data *Data, depth int) template.FuncMap {
+	// Sections render from data so prompt compaction applies to them
+	state := &session.State{Notes: data.Notes, Iterations: data.Iterations}
+	if data.State != nil {
+		state.Tasks = data.State.Tasks
+	}
+	return template.FuncMap{
+		"session":   func() string { return data.Session },
+		"iteration": func() string { return strconv.Itoa(data.Iteration) },
+		"spec":      func() string { return data.Spec },
+		"extra":     func() string { return data.Extra },
+		"port":      func() string { return strconv.Itoa(data.Port) },
+		"binary":    func() string { return data.Binary },
+		"notes":     func() string { return formatNotes(state) },
+		"tasks":     func() string { return formatTasks(state) },
+		"history":   func() string { return withDigest(formatIterationHistory(state), data.Digest) },
+
+		"include": func(name string) (string, error) {
+			return include(name, data, depth)
+		},
+		"var": func(name string) string { return data.Vars[name] },
+		"tasksWithStatus": func(status string) []*session.Task {
+			var tasks []*session.Task
+			for _, task := range data.Tasks {
+				if task.Status == status {
+					tasks = append(tasks, task)
+				}
+			}
+			return tasks
+		},
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"trim":  strings.TrimSpace,
+	}
+}
+
+// withDigest puts the digest of dropped iterations at the top of the
+// formatted history, so the agent still knows the work happened.
+func withDigest(history, digest string) string {
+	if digest == "" {
+		return history
+	}
+	const header = "## Recent Progress\n"
+	return header + "- " + digest + "\n" + strings.TrimPrefix(history, header)
+}
+
+// include renders the partial at name (relative to the work dir) with data.
+func include(name string, data *Data, depth int) (string, error) {
+	if depth >= maxIncludeDepth {
+		return "", fmt.Errorf("include %q: nested more than %d levels deep", name, maxIncludeDepth)
+	}
+	path := name
+	if !filepath.IsAbs(path) && data.Config.WorkDir != "" {
+		path = filepath.Join(data.Config.WorkDir, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("include %q: %w", name, err)
+	}
+	return render(name, string(content), data, depth+1)
+}
@@ -28,3 +28,33 @@ Read the spec above and load ALL tasks into the system using the task-add tool.
 - Core feature tasks: priority 1-2
 - Polish/cleanup tasks: priority 3-4
 {{extra}}`
+
+// ReplanTemplate is the prompt template for re-planning when the spec changes
+// mid-session. It follows the Iteration #0 flow but only lets the agent add
+// tasks for new requirements and cancel tasks the spec dropped.
+const ReplanTemplate = `# iteratr Session — Iteration #{{iteration}} (Re-planning)
+Session: {{session}}
+
+The spec was edited after the task list was planned. Bring the task list in
+line with the spec before work continues.
+
+## Spec Changes
+` + "```diff" + `
+{{.SpecDiff}}` + "```" + `
+
+## Spec
+{{spec}}
+
+{{tasks}}
+
+## Your Job
+Compare the spec changes above with the current tasks and update the task list.
+
+### Rules
+- Add tasks for new or changed requirements using the task-add tool
+- Cancel tasks the spec no longer asks for using task-update with status "cancelled"
+- Do NOT change completed tasks, and do not edit or re-prioritize other tasks
+- Do NOT start implementing anything — planning only
+- Do NOT call iteration-summary — the iteration continues after re-planning
+- If the changes need no task updates, reply that nothing changed
+{{extra}}`
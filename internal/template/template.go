@@ -4,58 +4,134 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strconv"
+	"sort"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/mark3labs/iteratr/internal/git"
 	"github.com/mark3labs/iteratr/internal/logger"
 	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/spec"
 )
 
-// Variables holds the data to be injected into template placeholders.
-type Variables struct {
-	Session   string // Session name
-	Iteration string // Current iteration number
-	Spec      string // Spec file content
-	Notes     string // Formatted notes from previous iterations
-	Tasks     string // Formatted task list
-	History   string // Formatted iteration history
-	Extra     string // Extra instructions
-	Port      string // NATS server port
-	Binary    string // Full path to iteratr binary
+// Data is the value templates are executed against. Fields are available as
+// {{.Session}}, {{.State.Tasks}}, {{.Git.Branch}} and so on; the original
+// placeholders ({{session}}, {{tasks}}, ...) are template functions that
+// render the same pre-formatted sections as before.
+type Data struct {
+	Session    string               // Session name
+	Iteration  int                  // Current iteration number
+	Spec       string               // Spec content (all files, or the sections relevant to Task)
+	SpecFiles  []spec.File          // Every spec file with includes resolved
+	SpecDiff   string               // Unified diff of spec edits since the last planning (re-planning only)
+	Task       *session.Task        // Task the iteration is expected to work on (nil if none)
+	Extra      string               // Extra instructions
+	Port       int                  // NATS server port
+	Binary     string               // Full path to iteratr binary
+	State      *session.State       // Full session state
+	Tasks      []*session.Task      // Tasks in creation order
+	Notes      []*session.Note      // Notes in creation order (compaction may drop some)
+	Iterations []*session.Iteration // Iteration history (compaction may drop or shorten some)
+	Digest     string               // One-line digest of iterations compaction dropped ("" if none)
+	Git        *git.Info            // Repository status (nil outside a git repository)
+	Config     ConfigInfo           // Run configuration
+	Vars       map[string]string    // User-defined variables (template_vars in iteratr.yml)
+
+	dropped []*session.Iteration // Iterations compaction dropped, oldest first
 }
 
-// Render replaces {{variable}} placeholders in template with actual values.
-// Supports the following variables:
-// - {{session}} - Session name
-// - {{iteration}} - Current iteration number
-// - {{spec}} - Spec file content
-// - {{notes}} - Formatted notes (empty if none)
-// - {{tasks}} - Formatted task list
-// - {{history}} - Formatted iteration history
-// - {{extra}} - Extra instructions (empty if none)
-// - {{port}} - NATS server port
-// - {{binary}} - Full path to iteratr binary
-func Render(template string, vars Variables) string {
-	result := template
-
-	replacements := map[string]string{
-		"{{session}}":   vars.Session,
-		"{{iteration}}": vars.Iteration,
-		"{{spec}}":      vars.Spec,
-		"{{notes}}":     vars.Notes,
-		"{{tasks}}":     vars.Tasks,
-		"{{history}}":   vars.History,
-		"{{extra}}":     vars.Extra,
-		"{{port}}":      vars.Port,
-		"{{binary}}":    vars.Binary,
-	}
-
-	for placeholder, value := range replacements {
-		result = strings.ReplaceAll(result, placeholder, value)
-	}
-
-	return result
+// ConfigInfo is the run configuration exposed to templates.
+type ConfigInfo struct {
+	Model      string // Model the agent runs with
+	Iterations int    // Max iterations (0 = unlimited)
+	AutoCommit bool   // Auto-commit after each iteration
+	Headless   bool   // Running without TUI
+	WorkDir    string // Project working directory
+}
+
+// NewData returns template data for state with tasks and notes in creation order.
+func NewData(state *session.State) *Data {
+	if state == nil {
+		state = &session.State{Tasks: make(map[string]*session.Task)}
+	}
+	tasks := make([]*session.Task, 0, len(state.Tasks))
+	for _, task := range state.Tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return &Data{
+		Session:    state.Session,
+		State:      state,
+		Tasks:      tasks,
+		Notes:      state.Notes,
+		Iterations: state.Iterations,
+		Vars:       map[string]string{},
+	}
+}
+
+// Render executes a text/template template against data.
+// name identifies the template in error messages, which carry line numbers
+// (e.g. "template: .iteratr.template:12: function \"foo\" not defined").
+// {{include "file"}} partials are resolved relative to data.Config.WorkDir.
+func Render(name, text string, data *Data) (string, error) {
+	return render(name, text, data, 0)
+}
+
+// render executes a template; depth tracks nested includes.
+func render(name, text string, data *Data, depth int) (string, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=zero").
+		Funcs(funcMap(data, depth)).
+		Parse(text)
+	if err != nil {
+		return "", unknownPlaceholder(err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// unknownPlaceholder rewrites text/template's "function \"foo\" not defined"
+// parse error as an unknown placeholder error. Placeholders the engine does
+// not know used to be left in the prompt as is; they now fail to parse.
+func unknownPlaceholder(err error) error {
+	prefix, rest, ok := strings.Cut(err.Error(), `function "`)
+	if !ok {
+		return err
+	}
+	name, _, ok := strings.Cut(rest, `" not defined`)
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%sunknown placeholder {{%s}} (see the template variables in the README)", prefix, name)
+}
+
+// Validate parses text and executes it against empty session data so syntax
+// errors, unknown functions, bad field references and missing partials are
+// reported with line numbers before a build starts.
+func Validate(name, text, workDir string) error {
+	data := NewData(nil)
+	data.Git = &git.Info{} // Lets {{.Git.Branch}} validate outside a repository
+	data.Config.WorkDir = workDir
+	_, err := Render(name, text, data)
+	return err
+}
+
+// ValidateFile loads the template at path and validates it.
+func ValidateFile(path, workDir string) error {
+	content, err := LoadFromFile(path)
+	if err != nil {
+		return err
+	}
+	return Validate(path, content, workDir)
 }
 
 // LoadFromFile loads a template from a file.
@@ -80,38 +156,30 @@ func GetTemplate(customPath string) (string, error) {
 
 // BuildConfig holds configuration for building a prompt.
 type BuildConfig struct {
-	SessionName       string         // Name of the session
-	Store             *session.Store // Session store for loading state
-	IterationNumber   int            // Current iteration number
-	SpecPath          string         // Path to spec file
-	TemplatePath      string         // Path to custom template (optional)
-	ExtraInstructions string         // Extra instructions (optional)
-	NATSPort          int            // NATS server port
+	SessionName       string            // Name of the session
+	Store             *session.Store    // Session store for loading state
+	IterationNumber   int               // Current iteration number
+	SpecPaths         []string          // Spec files, directories, or globs
+	RelevantSpec      bool              // Narrow the spec to sections relevant to the current task
+	TemplatePath      string            // Path to custom template (optional)
+	ExtraInstructions string            // Extra instructions (optional)
+	NATSPort          int               // NATS server port
+	Config            ConfigInfo        // Run configuration exposed as {{.Config}}
+	Vars              map[string]string // User-defined template variables
+	MaxTokens         int               // Prompt token budget (0 = unlimited)
+	TaskID            string            // Task assigned to the iteration (default: CurrentTask)
 }
 
 // BuildPrompt loads session state, formats it, and injects it into the template.
 // This is the main function for creating prompts with current state injection.
-func BuildPrompt(ctx context.Context, cfg BuildConfig) (string, error) {
+// With cfg.MaxTokens set, history and low-value notes are compacted to fit;
+// the returned *Compaction describes what was dropped (nil if nothing was).
+func BuildPrompt(ctx context.Context, cfg BuildConfig) (string, *Compaction, error) {
 	logger.Debug("Building prompt for session: %s, iteration: %d", cfg.SessionName, cfg.IterationNumber)
 
-	// Load session state
-	state, err := cfg.Store.LoadState(ctx, cfg.SessionName)
+	data, err := buildData(ctx, cfg)
 	if err != nil {
-		logger.Error("Failed to load session state: %v", err)
-		return "", fmt.Errorf("failed to load session state: %w", err)
-	}
-
-	// Load spec file content
-	specContent := ""
-	if cfg.SpecPath != "" {
-		logger.Debug("Loading spec file: %s", cfg.SpecPath)
-		data, err := os.ReadFile(cfg.SpecPath)
-		if err != nil {
-			logger.Error("Failed to read spec file: %v", err)
-			return "", fmt.Errorf("failed to read spec file: %w", err)
-		}
-		specContent = string(data)
-		logger.Debug("Spec file loaded: %d bytes", len(specContent))
+		return "", nil, err
 	}
 
 	// Get the full path to the running binary
@@ -121,78 +189,128 @@ func BuildPrompt(ctx context.Context, cfg BuildConfig) (string, error) {
 		binaryPath = "iteratr"
 	}
 	logger.Debug("Binary path: %s", binaryPath)
+	data.Binary = binaryPath
 
 	// Load template
+	name := "default"
 	if cfg.TemplatePath != "" {
 		logger.Debug("Using custom template: %s", cfg.TemplatePath)
+		name = cfg.TemplatePath
 	} else {
 		logger.Debug("Using default embedded template")
 	}
 	templateContent, err := GetTemplate(cfg.TemplatePath)
 	if err != nil {
 		logger.Error("Failed to get template: %v", err)
-		return "", fmt.Errorf("failed to get template: %w", err)
+		return "", nil, fmt.Errorf("failed to get template: %w", err)
 	}
 
-	// Format state data
-	vars := Variables{
-		Session:   cfg.SessionName,
-		Iteration: strconv.Itoa(cfg.IterationNumber),
-		Spec:      specContent,
-		Notes:     formatNotes(state),
-		Tasks:     formatTasks(state),
-		History:   formatIterationHistory(state),
-		Extra:     cfg.ExtraInstructions,
-		Port:      strconv.Itoa(cfg.NATSPort),
-		Binary:    binaryPath,
+	// Narrow the spec to what the current task needs
+	if cfg.RelevantSpec && data.Task != nil {
+		full := len(data.Spec)
+		data.Spec = (&spec.Spec{Files: data.SpecFiles}).Relevant(data.Task.Content)
+		logger.Debug("Selected spec sections for %s: %d of %d bytes", data.Task.ID, len(data.Spec), full)
 	}
 
 	logger.Debug("Formatted state: %d tasks, %d notes",
-		len(state.Tasks), len(state.Notes))
+		len(data.Tasks), len(data.Notes))
 
-	// Render template with variables
-	result := Render(templateContent, vars)
+	// Render template with data, compacting it to the token budget
+	result, compaction, err := compact(name, templateContent, data, cfg.MaxTokens)
+	if err != nil {
+		logger.Error("Failed to render template: %v", err)
+		return "", nil, fmt.Errorf("failed to render template: %w", err)
+	}
 	logger.Debug("Prompt rendered: %d characters", len(result))
-	return result, nil
+	return result, compaction, nil
 }
 
 // BuildIteration0Prompt builds the prompt for Iteration #0 (planning phase).
-// Uses the Iteration0Template with a minimal variable set (spec, tasks, extra).
+// Uses the Iteration0Template, which only references spec, tasks and extra.
 func BuildIteration0Prompt(ctx context.Context, cfg BuildConfig) (string, error) {
 	logger.Debug("Building Iteration #0 prompt for session: %s", cfg.SessionName)
 
+	data, err := buildData(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	// Render template with data
+	result, err := Render("iteration0", Iteration0Template, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render iteration #0 template: %w", err)
+	}
+	logger.Debug("Iteration #0 prompt rendered: %d characters", len(result))
+	return result, nil
+}
+
+// BuildReplanPrompt builds the prompt for re-planning after the spec changed
+// mid-session. Uses the ReplanTemplate with diff as {{.SpecDiff}}.
+func BuildReplanPrompt(ctx context.Context, cfg BuildConfig, diff string) (string, error) {
+	logger.Debug("Building re-planning prompt for session: %s", cfg.SessionName)
+
+	data, err := buildData(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	data.SpecDiff = diff
+
+	result, err := Render("replan", ReplanTemplate, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render re-planning template: %w", err)
+	}
+	logger.Debug("Re-planning prompt rendered: %d characters", len(result))
+	return result, nil
+}
+
+// buildData loads session state, the spec files and git status into template data.
+func buildData(ctx context.Context, cfg BuildConfig) (*Data, error) {
 	// Load session state
 	state, err := cfg.Store.LoadState(ctx, cfg.SessionName)
 	if err != nil {
 		logger.Error("Failed to load session state: %v", err)
-		return "", fmt.Errorf("failed to load session state: %w", err)
+		return nil, fmt.Errorf("failed to load session state: %w", err)
 	}
 
-	// Load spec file content
-	specContent := ""
-	if cfg.SpecPath != "" {
-		logger.Debug("Loading spec file: %s", cfg.SpecPath)
-		data, err := os.ReadFile(cfg.SpecPath)
+	// Load spec files with includes resolved
+	specs := &spec.Spec{}
+	if len(cfg.SpecPaths) > 0 {
+		logger.Debug("Loading spec files: %v", cfg.SpecPaths)
+		specs, err = spec.Load(cfg.SpecPaths)
 		if err != nil {
-			logger.Error("Failed to read spec file: %v", err)
-			return "", fmt.Errorf("failed to read spec file: %w", err)
+			logger.Error("Failed to load spec: %v", err)
+			return nil, err
 		}
-		specContent = string(data)
-		logger.Debug("Spec file loaded: %d bytes", len(specContent))
+		logger.Debug("Spec loaded: %d files", len(specs.Files))
 	}
 
-	// Format state data (minimal set for planning)
-	vars := Variables{
-		Session: cfg.SessionName,
-		Spec:    specContent,
-		Tasks:   formatTasks(state),
-		Extra:   cfg.ExtraInstructions,
+	data := NewData(state)
+	data.Session = cfg.SessionName
+	data.Iteration = cfg.IterationNumber
+	data.Spec = specs.Content()
+	data.SpecFiles = specs.Files
+	data.Task = state.CurrentTask()
+	if task, ok := state.Tasks[cfg.TaskID]; ok {
+		data.Task = task
+	}
+	data.Extra = cfg.ExtraInstructions
+	data.Port = cfg.NATSPort
+	data.Config = cfg.Config
+	if cfg.Vars != nil {
+		data.Vars = cfg.Vars
 	}
 
-	// Render template with variables
-	result := Render(Iteration0Template, vars)
-	logger.Debug("Iteration #0 prompt rendered: %d characters", len(result))
-	return result, nil
+	// Git status is optional context; failures only leave it nil
+	gitDir := cfg.Config.WorkDir
+	if gitDir == "" {
+		gitDir = "."
+	}
+	if info, err := git.GetInfo(gitDir); err != nil {
+		logger.Debug("Git info unavailable for template: %v", err)
+	} else {
+		data.Git = info
+	}
+	return data, nil
 }
 
 // formatNotes formats notes grouped by type for template injection.
@@ -271,6 +389,12 @@ func formatTasks(state *session.State) string {
 			}
 
 			sb.WriteString(fmt.Sprintf("  - %s[%s] %s%s%s\n", priorityPrefix, task.ID, task.Content, iterInfo, depInfo))
+			// Details of finished tasks are left out to keep the prompt short
+			if status != "completed" && status != "cancelled" {
+				for _, detail := range task.DetailLines() {
+					sb.WriteString("    " + detail + "\n")
+				}
+			}
 		}
 	}
 
@@ -7,70 +7,80 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mark3labs/iteratr/internal/git"
 	"github.com/mark3labs/iteratr/internal/session"
 )
 
 func TestRender(t *testing.T) {
+	state := &session.State{
+		Tasks: map[string]*session.Task{
+			"TAS-1": {ID: "TAS-1", Content: "Set up", Status: "completed", Priority: 1, CreatedAt: time.Unix(1, 0)},
+			"TAS-2": {ID: "TAS-2", Content: "Build it", Status: "remaining", Priority: 2, CreatedAt: time.Unix(2, 0)},
+		},
+		Notes: []*session.Note{{ID: "NOT-1", Content: "Use sqlite", Type: "decision", Iteration: 1}},
+	}
+	data := NewData(state)
+	data.Session = "s1"
+	data.Iteration = 3
+	data.Spec = "spec content"
+	data.Extra = "extra"
+	data.Port = 4222
+	data.Vars = map[string]string{"team": "core"}
+	data.Git = &git.Info{Branch: "main", Hash: "abc1234"}
+
 	tests := []struct {
 		name     string
 		template string
-		vars     Variables
 		want     string
 	}{
 		{
-			name:     "simple substitution",
-			template: "Session: {{session}}, Iteration: {{iteration}}",
-			vars: Variables{
-				Session:   "test-session",
-				Iteration: "42",
-			},
-			want: "Session: test-session, Iteration: 42",
+			name:     "legacy placeholders",
+			template: "Session: {{session}}, Iteration: {{iteration}}, Port: {{port}}, {{spec}}|{{extra}}",
+			want:     "Session: s1, Iteration: 3, Port: 4222, spec content|extra",
 		},
 		{
-			name:     "all variables",
-			template: "{{session}}|{{iteration}}|{{spec}}|{{notes}}|{{tasks}}|{{extra}}",
-			vars: Variables{
-				Session:   "s1",
-				Iteration: "1",
-				Spec:      "spec content",
-				Notes:     "notes",
-				Tasks:     "tasks",
-				Extra:     "extra",
-			},
-			want: "s1|1|spec content|notes|tasks|extra",
+			name:     "legacy sections",
+			template: "{{notes}}",
+			want:     "## Notes\nDecision:\n  - [#1] Use sqlite\n",
 		},
 		{
-			name:     "empty values",
-			template: "Session: {{session}}{{extra}}",
-			vars: Variables{
-				Session: "test",
-				Extra:   "",
-			},
-			want: "Session: test",
+			name:     "fields",
+			template: "{{.Session}} #{{.Iteration}} on {{.Git.Branch}}@{{.Git.Hash}}",
+			want:     "s1 #3 on main@abc1234",
 		},
 		{
-			name:     "multiline template",
-			template: "## Context\nSession: {{session}} | Iteration: #{{iteration}}\n{{notes}}",
-			vars: Variables{
-				Session:   "my-session",
-				Iteration: "3",
-				Notes:     "## Notes\n- Note 1\n",
-			},
-			want: "## Context\nSession: my-session | Iteration: #3\n## Notes\n- Note 1\n",
+			name:     "loop over tasks in creation order",
+			template: "{{range .Tasks}}{{.ID}}={{.Status}};{{end}}",
+			want:     "TAS-1=completed;TAS-2=remaining;",
 		},
 		{
-			name:     "placeholder not replaced if variable missing",
-			template: "{{session}} {{unknown}}",
-			vars: Variables{
-				Session: "test",
-			},
-			want: "test {{unknown}}",
+			name:     "conditionals on iteration number",
+			template: "{{if eq .Iteration 1}}first{{else if gt .Iteration 2}}later{{end}}",
+			want:     "later",
+		},
+		{
+			name:     "task filter",
+			template: "{{range tasksWithStatus \"remaining\"}}{{.Content}}{{end}}",
+			want:     "Build it",
+		},
+		{
+			name:     "user variables",
+			template: "{{.Vars.team}}/{{var \"team\"}}/{{.Vars.missing}}{{var \"missing\"}}",
+			want:     "core/core/",
+		},
+		{
+			name:     "helpers",
+			template: "{{upper .Session}} {{trim \"  x  \"}}",
+			want:     "S1 x",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := Render(tt.template, tt.vars)
+			got, err := Render("test", tt.template, data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
 			if got != tt.want {
 				t.Errorf("Render() = %q, want %q", got, tt.want)
 			}
@@ -78,37 +88,106 @@ func TestRender(t *testing.T) {
 	}
 }
 
-func TestRenderWithDefaultTemplate(t *testing.T) {
-	vars := Variables{
-		Session:   "iteratr",
-		Iteration: "20",
-		Spec:      "# Test Spec\nThis is a test spec.",
-		Notes:     "LEARNING:\n  - [#1] Something learned\n",
-		Tasks:     "REMAINING:\n  - [abc123] Task 1\nCOMPLETED: 5 tasks\n",
-		Extra:     "",
-		Port:      "4222",
+func TestRender_UnknownPlaceholder(t *testing.T) {
+	// Placeholders the engine doesn't know used to be left as is; they are
+	// now reported by name so typos don't silently reach the agent
+	_, err := Render("custom.template", "{{session}} {{unknown}}", NewData(nil))
+	if err == nil {
+		t.Fatal("Render() expected error for unknown placeholder")
+	}
+	want := "template: custom.template:1: unknown placeholder {{unknown}}"
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Render() error = %q, want it to start with %q", err, want)
+	}
+}
+
+func TestRender_Include(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "prompts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "prompts", "rules.md"), []byte("Rules for {{session}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "loop.md"), []byte(`{{include "loop.md"}}`), 0644); err != nil {
+		t.Fatal(err)
 	}
 
-	result := Render(DefaultTemplate, vars)
+	data := NewData(nil)
+	data.Session = "s1"
+	data.Config.WorkDir = dir
 
-	// Check that placeholders were replaced
-	if strings.Contains(result, "{{session}}") {
-		t.Error("{{session}} placeholder not replaced")
+	got, err := Render("test", `# Prompt
+{{include "prompts/rules.md"}}`, data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
 	}
-	if strings.Contains(result, "{{iteration}}") {
-		t.Error("{{iteration}} placeholder not replaced")
+	if got != "# Prompt\nRules for s1" {
+		t.Errorf("Render() = %q", got)
+	}
+
+	if _, err := Render("test", `{{include "loop.md"}}`, data); err == nil || !strings.Contains(err.Error(), "nested") {
+		t.Errorf("recursive include error = %v, want nesting error", err)
+	}
+	if _, err := Render("test", `{{include "missing.md"}}`, data); err == nil {
+		t.Error("missing partial: expected error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{"default template", DefaultTemplate, ""},
+		{"iteration 0 template", Iteration0Template, ""},
+		{"fields and git", "{{.Git.Branch}} {{range .State.Iterations}}{{.Number}}{{end}}", ""},
+		{"unknown placeholder", "line one\n{{unknown}}", "custom.template:2: unknown placeholder {{unknown}}"},
+		{"unclosed action", "{{if .Iteration}}\nno end", "custom.template:"},
+		{"unknown field", "\n\n{{.Nope}}", "custom.template:3:"},
+		{"missing partial", `{{include "nope.md"}}`, "nope.md"},
 	}
-	if strings.Contains(result, "{{spec}}") {
-		t.Error("{{spec}} placeholder not replaced")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate("custom.template", tt.text, dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
 	}
-	if strings.Contains(result, "{{tasks}}") {
-		t.Error("{{tasks}} placeholder not replaced")
+}
+
+func TestRenderWithDefaultTemplate(t *testing.T) {
+	state := &session.State{
+		Tasks: map[string]*session.Task{
+			"abc123": {ID: "abc123", Content: "Task 1", Status: "remaining"},
+		},
+		Notes: []*session.Note{{Content: "Something learned", Type: "learning", Iteration: 1}},
 	}
-	if strings.Contains(result, "{{notes}}") {
-		t.Error("{{notes}} placeholder not replaced")
+	data := NewData(state)
+	data.Session = "iteratr"
+	data.Iteration = 20
+	data.Spec = "# Test Spec\nThis is a test spec."
+	data.Port = 4222
+
+	result, err := Render("default", DefaultTemplate, data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
 	}
-	if strings.Contains(result, "{{port}}") {
-		t.Error("{{port}} placeholder not replaced")
+
+	// Check that placeholders were replaced
+	for _, placeholder := range []string{"{{session}}", "{{iteration}}", "{{spec}}", "{{tasks}}", "{{notes}}", "{{port}}"} {
+		if strings.Contains(result, placeholder) {
+			t.Errorf("%s placeholder not replaced", placeholder)
+		}
 	}
 
 	// Check that expected content is present
@@ -118,14 +197,37 @@ func TestRenderWithDefaultTemplate(t *testing.T) {
 	if !strings.Contains(result, "# Test Spec") {
 		t.Error("Spec content not included")
 	}
-	if !strings.Contains(result, "LEARNING:") {
+	if !strings.Contains(result, "Learning:") {
 		t.Error("Notes not included")
 	}
-	if !strings.Contains(result, "REMAINING:") {
+	if !strings.Contains(result, "Remaining:") {
 		t.Error("Tasks not included")
 	}
 }
 
+func TestRenderReplanTemplate(t *testing.T) {
+	state := &session.State{
+		Tasks: map[string]*session.Task{
+			"TAS-1": {ID: "TAS-1", Content: "Add cart", Status: "remaining"},
+		},
+	}
+	data := NewData(state)
+	data.Session = "shop"
+	data.Iteration = 4
+	data.Spec = "# Shop\n- Checkout\n"
+	data.SpecDiff = "@@ -1,2 +1,2 @@\n # Shop\n-- Cart\n+- Checkout\n"
+
+	result, err := Render("replan", ReplanTemplate, data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"Iteration #4 (Re-planning)", "```diff\n@@ -1,2 +1,2 @@", "+- Checkout\n```", "Add cart", "cancelled"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("replan prompt missing %q:\n%s", want, result)
+		}
+	}
+}
+
 func TestLoadFromFile(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -356,6 +458,18 @@ func TestFormatTasks(t *testing.T) {
 				"[P2] [task003def] Multi-dependent (depends on: task001abc, task002xyz)",
 			},
 		},
+		{
+			name: "tasks with details",
+			state: &session.State{
+				Tasks: map[string]*session.Task{
+					"task001": {ID: "task001abc", Content: "Add login", Status: "in_progress", Priority: 2, Description: "Email and password",
+						Criteria: []session.Criterion{{Text: "Shows errors", Done: true}, {Text: "Sets cookie"}}, Tags: []string{"auth"}, Estimate: "2h"},
+				},
+			},
+			want: []string{
+				"[P2] [task001abc] Add login\n    Email and password\n    Acceptance criteria:\n      1. [x] Shows errors\n      2. [ ] Sets cookie\n    Tags: auth\n    Estimate: 2h\n",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -25,6 +25,7 @@ type Orchestrator interface {
 	CancelPause()
 	Resume()
 	IsPaused() bool
+	RequestRollback(to int)
 }
 
 // loadUIState loads the UI state from persistent storage.
@@ -42,6 +43,10 @@ type App struct {
 	status         *StatusBar
 	sidebar        *Sidebar
 	dialog         *Dialog
+	permission     *PermissionModal
+	history        *HistoryModal
+	graph          *GraphModal
+	specChange     *SpecChangeModal
 	taskModal      *TaskModal
 	noteModal      *NoteModal
 	noteInputModal *NoteInputModal
@@ -105,6 +110,10 @@ func NewApp(ctx context.Context, store *session.Store, sessionName, workDir, dat
 		status:            statusBar,
 		sidebar:           sidebar,
 		dialog:            NewDialog(),
+		permission:        NewPermissionModal(),
+		history:           NewHistoryModal(),
+		graph:             NewGraphModal(),
+		specChange:        NewSpecChangeModal(),
 		taskModal:         NewTaskModal(),
 		noteModal:         NewNoteModal(),
 		noteInputModal:    NewNoteInputModal(),
@@ -220,6 +229,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.sidebar.SetState(msg.State)
 		a.dashboard.SetState(msg.State)
 		a.logs.SetState(msg.State)
+		a.history.SetState(msg.State)
+		a.taskModal.Refresh(msg.State)
+		a.graph.SetState(msg.State)
 		return a, a.status.Tick()
 
 	case EventMsg:
@@ -236,6 +248,35 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Reschedule health check
 		return a, a.checkConnectionHealth()
 
+	case PermissionRequestMsg:
+		// Queue the request; the orchestrator blocks until the user answers
+		a.permission.Push(msg)
+		return a, nil
+
+	case WorkerStartMsg, WorkerOutputMsg, WorkerDoneMsg:
+		// Parallel workers report to their own dashboard panes
+		if a.dashboard.UpdateWorkers(msg) {
+			a.propagateSizes()
+		}
+		return a, nil
+
+	case SpecChangeMsg:
+		// The orchestrator holds the iteration until the user continues
+		a.specChange.Show(msg)
+		return a, nil
+
+	case RequestRollbackMsg:
+		// Rollback runs in the orchestrator between iterations
+		if a.orchestrator == nil {
+			return a, nil
+		}
+		a.orchestrator.RequestRollback(msg.To)
+		text := fmt.Sprintf("Rolling back to iteration #%d", msg.To)
+		if a.dashboard != nil && a.dashboard.agentBusy {
+			text = fmt.Sprintf("Rollback to iteration #%d queued until the iteration finishes", msg.To)
+		}
+		return a, func() tea.Msg { return ShowToastMsg{Text: text} }
+
 	case SessionCompleteMsg:
 		// Stop the duration timer
 		a.status.StopDurationTick()
@@ -336,9 +377,14 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		go func() {
 			_, err := a.store.TaskAdd(a.ctx, a.sessionName, session.TaskAddParams{
-				Content:   msg.Content,
-				Priority:  msg.Priority,
-				Iteration: iteration,
+				Content:     msg.Content,
+				Priority:    msg.Priority,
+				Iteration:   iteration,
+				Description: msg.Description,
+				Criteria:    msg.Criteria,
+				Tags:        msg.Tags,
+				SpecRefs:    msg.SpecRefs,
+				Estimate:    msg.Estimate,
 			})
 			if err != nil {
 				// TODO: Add visual feedback for user
@@ -394,6 +440,73 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}()
 		return a, nil
 
+	case AddTaskDependencyMsg:
+		// Add the dependency via store; rejected cycles are shown as a toast
+		iteration := a.iteration
+		return a, func() tea.Msg {
+			err := a.store.TaskDepends(a.ctx, a.sessionName, session.TaskDependsParams{
+				ID:        msg.ID,
+				DependsOn: msg.DependsOn,
+				Iteration: iteration,
+			})
+			if err != nil {
+				logger.Warn("failed to add task dependency: %v", err)
+				return ShowToastMsg{Text: "Cannot add dependency: " + err.Error()}
+			}
+			return nil
+		}
+
+	case RemoveTaskDependencyMsg:
+		// Remove the dependency via store
+		iteration := a.iteration
+		return a, func() tea.Msg {
+			err := a.store.TaskUndepends(a.ctx, a.sessionName, session.TaskDependsParams{
+				ID:        msg.ID,
+				DependsOn: msg.DependsOn,
+				Iteration: iteration,
+			})
+			if err != nil {
+				logger.Warn("failed to remove task dependency: %v", err)
+				return ShowToastMsg{Text: "Cannot remove dependency: " + err.Error()}
+			}
+			return nil
+		}
+
+	case EditTaskMsg:
+		// Switch from the task modal to the input modal to edit the task
+		a.taskModal.Close()
+		if a.sidebar != nil {
+			a.sidebar.ClearActiveTask()
+		}
+		return a, a.taskInputModal.Edit(msg.Task)
+
+	case UpdateTaskDetailsMsg:
+		// Apply the changed fields via store, in order
+		iteration := a.iteration
+		return a, func() tea.Msg {
+			if err := a.updateTask(msg, iteration); err != nil {
+				logger.Warn("failed to update task: %v", err)
+				return ShowToastMsg{Text: "Cannot update task: " + err.Error()}
+			}
+			return nil
+		}
+
+	case ToggleTaskCriterionMsg:
+		// Mark the acceptance criterion via store
+		iteration := a.iteration
+		go func() {
+			err := a.store.TaskCriterion(a.ctx, a.sessionName, session.TaskCriterionParams{
+				ID:        msg.ID,
+				Index:     msg.Index,
+				Done:      msg.Done,
+				Iteration: iteration,
+			})
+			if err != nil {
+				logger.Warn("failed to update acceptance criterion: %v", err)
+			}
+		}()
+		return a, nil
+
 	case RequestDeleteTaskMsg:
 		// Show confirmation dialog before deleting
 		taskID := msg.ID
@@ -559,7 +672,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 // handleKeyPress processes keyboard input using hierarchical priority routing.
-// Priority: Global Keys (ctrl+c) → Dialog → Prefix Mode → Modal → View → Focus → Component
+// Priority: Global Keys (ctrl+c) → Dialog → Permission → Spec Change → Prefix Mode → Modal → View → Focus → Component
 func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	// 0. Global keys (ctrl+x, ctrl+c) - must work everywhere, even with dialog open
 	if cmd := a.handleGlobalKeys(msg); cmd != nil {
@@ -574,6 +687,16 @@ func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return a, nil // Consume all keys when dialog is visible
 	}
 
+	// 1b. Pending permission request blocks the agent - answer it first
+	if a.permission.IsVisible() {
+		return a, a.permission.Update(msg)
+	}
+
+	// 1c. Re-planned spec change holds the iteration until acknowledged
+	if a.specChange.IsVisible() {
+		return a, a.specChange.Update(msg)
+	}
+
 	// 2. Handle prefix key sequences (ctrl+x followed by another key)
 	if a.awaitingPrefixKey {
 		a.awaitingPrefixKey = false // Exit prefix mode after handling
@@ -613,6 +736,22 @@ func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		case "r":
 			// ctrl+x r -> restart completed session
 			return a, a.restartSession()
+		case "h":
+			// ctrl+x h -> iteration history (rollback)
+			if a.dialog.IsVisible() || a.taskModal.IsVisible() || a.noteModal.IsVisible() ||
+				a.noteInputModal.IsVisible() || a.taskInputModal.IsVisible() || a.logsVisible {
+				return a, nil
+			}
+			a.history.Show()
+			return a, nil
+		case "g":
+			// ctrl+x g -> task dependency graph
+			if a.dialog.IsVisible() || a.taskModal.IsVisible() || a.noteModal.IsVisible() ||
+				a.noteInputModal.IsVisible() || a.taskInputModal.IsVisible() || a.logsVisible {
+				return a, nil
+			}
+			a.graph.Show()
+			return a, nil
 		case "ctrl+c", "esc":
 			// Allow escape or ctrl+c to exit prefix mode
 			return a, nil
@@ -623,6 +762,14 @@ func (a *App) handleKeyPress(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	}
 
 	// 3. Modal gets priority when visible
+	if a.history.IsVisible() {
+		return a, a.history.Update(msg)
+	}
+
+	if a.graph.IsVisible() {
+		return a, a.graph.Update(msg)
+	}
+
 	if a.taskModal != nil && a.taskModal.IsVisible() {
 		// Forward all keys to TaskModal for interactive editing
 		cmd := a.taskModal.Update(msg)
@@ -689,7 +836,7 @@ func (a *App) handlePaste(msg tea.PasteMsg) (tea.Model, tea.Cmd) {
 	content := SanitizePaste(msg.Content)
 
 	// 1. Dialog has no text input — consume paste
-	if a.dialog.IsVisible() {
+	if a.dialog.IsVisible() || a.permission.IsVisible() || a.specChange.IsVisible() || a.history.IsVisible() || a.graph.IsVisible() {
 		return a, nil
 	}
 
@@ -740,6 +887,11 @@ func (a *App) handleMouse(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
 		return a, a.dialog.HandleClick(mouse.X, mouse.Y)
 	}
 
+	// Permission, spec change, history and graph modals are keyboard-only - consume clicks
+	if a.permission.IsVisible() || a.specChange.IsVisible() || a.history.IsVisible() || a.graph.IsVisible() {
+		return a, nil
+	}
+
 	// Subagent modal takes priority when visible - handle clicks for expand/collapse
 	if a.subagentModal != nil {
 		// Handle click within modal (for expand/collapse on messages)
@@ -908,11 +1060,45 @@ func (a *App) handleGlobalKeys(msg tea.KeyPressMsg) tea.Cmd {
 		return func() tea.Msg { return nil }
 	case "ctrl+c":
 		a.quitting = true
+		a.permission.DenyAll() // Unblock the orchestrator
+		a.specChange.Continue()
 		return tea.Quit
 	}
 	return nil
 }
 
+// updateTask applies an edited task's changed fields via the store.
+func (a *App) updateTask(msg UpdateTaskDetailsMsg, iteration int) error {
+	if msg.Content != "" {
+		err := a.store.TaskContent(a.ctx, a.sessionName, session.TaskContentParams{ID: msg.ID, Content: msg.Content, Iteration: iteration})
+		if err != nil {
+			return err
+		}
+	}
+	if msg.Priority >= 0 {
+		err := a.store.TaskPriority(a.ctx, a.sessionName, session.TaskPriorityParams{ID: msg.ID, Priority: msg.Priority, Iteration: iteration})
+		if err != nil {
+			return err
+		}
+	}
+	details := msg.Details
+	if details.Description != nil || details.Criteria != nil || details.Tags != nil || details.SpecRefs != nil || details.Estimate != nil {
+		details.ID = msg.ID
+		details.Iteration = iteration
+		if err := a.store.TaskDetails(a.ctx, a.sessionName, details); err != nil {
+			return err
+		}
+	}
+	for _, check := range msg.Checks {
+		check.ID = msg.ID
+		check.Iteration = iteration
+		if err := a.store.TaskCriterion(a.ctx, a.sessionName, check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // togglePause handles the ctrl+x p keyboard shortcut to toggle pause/resume.
 // Behavior depends on current state:
 // - If not paused: request pause (will take effect after current iteration)
@@ -1115,6 +1301,18 @@ func (a *App) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
 	if a.taskInputModal.IsVisible() {
 		a.taskInputModal.Draw(scr, area)
 	}
+	if a.history.IsVisible() {
+		a.history.Draw(scr, area)
+	}
+	if a.graph.IsVisible() {
+		a.graph.Draw(scr, area)
+	}
+	if a.specChange.IsVisible() {
+		a.specChange.Draw(scr, area)
+	}
+	if a.permission.IsVisible() {
+		a.permission.Draw(scr, area)
+	}
 	if a.dialog.IsVisible() {
 		a.dialog.Draw(scr, area)
 	}
@@ -1301,6 +1499,34 @@ type CreateTaskMsg struct {
 	Content   string
 	Priority  int
 	Iteration int
+
+	Description string
+	Criteria    []string
+	Tags        []string
+	SpecRefs    []string
+	Estimate    string
+}
+
+// UpdateTaskDetailsMsg is sent when the user saves an edited task from the
+// task input modal. Only changed fields are set.
+type UpdateTaskDetailsMsg struct {
+	ID       string
+	Content  string                        // Empty if unchanged
+	Priority int                           // -1 if unchanged
+	Details  session.TaskDetailsParams     // Nil fields are unchanged
+	Checks   []session.TaskCriterionParams // Criteria to mark met or unmet
+}
+
+// EditTaskMsg is sent when the user asks to edit a task from the task modal.
+type EditTaskMsg struct {
+	Task *session.Task
+}
+
+// ToggleTaskCriterionMsg is sent when the user ticks an acceptance criterion in the task modal.
+type ToggleTaskCriterionMsg struct {
+	ID    string
+	Index int // 1-based
+	Done  bool
 }
 
 // UpdateTaskStatusMsg is sent when the user changes a task's status in the task modal.
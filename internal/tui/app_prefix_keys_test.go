@@ -550,6 +550,7 @@ type mockOrchestrator struct {
 	pauseRequested bool
 	pauseCancelled bool
 	resumed        bool
+	rollbackTo     *int
 }
 
 func (m *mockOrchestrator) RequestPause() {
@@ -567,3 +568,7 @@ func (m *mockOrchestrator) Resume() {
 func (m *mockOrchestrator) IsPaused() bool {
 	return m.paused
 }
+
+func (m *mockOrchestrator) RequestRollback(to int) {
+	m.rollbackTo = &to
+}
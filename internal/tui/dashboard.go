@@ -33,6 +33,7 @@ type Dashboard struct {
 	focused      bool         // Whether the dashboard has focus
 	inputFocused bool         // Whether the input field is focused
 	agentBusy    bool         // Whether the agent is currently processing (used for input placeholder)
+	workers      *WorkerPanes // Per-worker panes in parallel mode
 }
 
 // NewDashboard creates a new Dashboard component.
@@ -43,6 +44,7 @@ func NewDashboard(agentOutput *AgentOutput, sidebar *Sidebar) *Dashboard {
 		agentOutput: agentOutput,
 		sidebar:     sidebar,
 		focusPane:   FocusAgent,
+		workers:     NewWorkerPanes(),
 	}
 }
 
@@ -142,6 +144,13 @@ func (d *Dashboard) Update(msg tea.Msg) tea.Cmd {
 
 // Draw renders the dashboard to a screen buffer using the Screen/Draw pattern.
 func (d *Dashboard) Draw(scr uv.Screen, area uv.Rectangle) *tea.Cursor {
+	// In parallel mode the worker panes take the top of the dashboard
+	if d.workers.Len() > 0 {
+		var workersArea uv.Rectangle
+		workersArea, area = uv.SplitVertical(area, uv.Fixed(workersHeight(area.Dy())))
+		d.workers.Draw(scr, workersArea)
+	}
+
 	// Draw title with rule line: "Agent Output ────────"
 	agentPanelFocused := d.focusPane == FocusAgent && d.focusPane != FocusInput
 	inner := DrawPanel(scr, area, "Agent Output", agentPanelFocused)
@@ -165,10 +174,25 @@ func (d *Dashboard) SetSize(width, height int) {
 	// Update agent output viewport size
 	if d.agentOutput != nil {
 		// Account for border (2 chars each side)
+		if d.workers.Len() > 0 {
+			height -= workersHeight(height)
+		}
 		d.agentOutput.UpdateSize(width-2, height-2)
 	}
 }
 
+// UpdateWorkers applies a parallel worker message to the worker panes.
+// Returns true if a pane was added, in which case sizes must be propagated
+// again to make room for it.
+func (d *Dashboard) UpdateWorkers(msg tea.Msg) bool {
+	return d.workers.Update(msg)
+}
+
+// workersHeight returns the rows given to worker panes out of height.
+func workersHeight(height int) int {
+	return height * 2 / 3
+}
+
 // SetIteration sets the current iteration number.
 func (d *Dashboard) SetIteration(n int) tea.Cmd {
 	d.iteration = n
@@ -0,0 +1,398 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/theme"
+)
+
+// graphModalMaxRows limits the number of graph rows shown at once.
+const graphModalMaxRows = 16
+
+// AddTaskDependencyMsg is sent when the user adds a dependency in the graph modal.
+type AddTaskDependencyMsg struct {
+	ID        string
+	DependsOn string
+}
+
+// RemoveTaskDependencyMsg is sent when the user removes a dependency in the graph modal.
+type RemoveTaskDependencyMsg struct {
+	ID        string
+	DependsOn string
+}
+
+// graphPick is what the user is picking a task for in the graph modal.
+type graphPick int
+
+const (
+	graphPickNone   graphPick = iota
+	graphPickAdd              // Picking a task for the source task to depend on
+	graphPickRemove           // Picking a dependency of the source task to remove
+)
+
+// graphRow is one line of the rendered dependency tree.
+type graphRow struct {
+	task   *session.Task
+	prefix string // Tree guides leading up to the task
+	repeat bool   // Task and its dependents are already shown above
+}
+
+// GraphModal renders the task dependency graph as a tree, with each task's
+// dependents below it, and lets the user add and remove dependencies. The
+// in-progress task, ready tasks and tasks on blocking chains are highlighted.
+type GraphModal struct {
+	visible  bool
+	state    *session.State
+	rows     []graphRow
+	blocking map[string]bool // Tasks on a chain held up by a blocked, cancelled, missing or cyclic task
+	selected int
+	picking  graphPick
+	source   string // Task whose dependencies are being edited
+}
+
+// NewGraphModal creates a new dependency graph modal.
+func NewGraphModal() *GraphModal {
+	return &GraphModal{}
+}
+
+// SetState rebuilds the graph, keeping the selected task if it still exists.
+func (m *GraphModal) SetState(state *session.State) {
+	m.state = state
+	m.refresh()
+}
+
+// Show opens the modal with the first task selected.
+func (m *GraphModal) Show() {
+	m.visible = true
+	m.picking = graphPickNone
+	m.selected = 0
+	m.refresh()
+}
+
+// Close hides the modal.
+func (m *GraphModal) Close() {
+	m.visible = false
+	m.picking = graphPickNone
+}
+
+// IsVisible returns whether the modal is open.
+func (m *GraphModal) IsVisible() bool {
+	return m != nil && m.visible
+}
+
+// selectedTask returns the task on the selected row, or nil if there are no tasks.
+func (m *GraphModal) selectedTask() *session.Task {
+	if m.selected < len(m.rows) {
+		return m.rows[m.selected].task
+	}
+	return nil
+}
+
+// refresh rebuilds the tree rows from the current state.
+func (m *GraphModal) refresh() {
+	selectedID := ""
+	if task := m.selectedTask(); task != nil {
+		selectedID = task.ID
+	}
+
+	m.rows = nil
+	m.blocking = make(map[string]bool)
+	if m.state != nil {
+		m.rows = graphRows(m.state)
+		for _, chain := range m.state.BlockingChains() {
+			switch chain.Reason {
+			case "blocked", "cancelled", "missing", "cycle":
+				for _, id := range chain.Path {
+					m.blocking[id] = true
+				}
+			}
+		}
+	}
+	m.selectTask(selectedID)
+}
+
+// selectTask selects the first row showing the task, or the first row if it is not shown.
+func (m *GraphModal) selectTask(id string) {
+	m.selected = 0
+	for i, row := range m.rows {
+		if row.task.ID == id {
+			m.selected = i
+			return
+		}
+	}
+}
+
+// graphRows lays the dependency graph out as a tree: tasks without
+// dependencies are roots and each task's dependents are nested below it. A
+// task with several dependencies appears under each of them, but its own
+// dependents are only expanded the first time.
+func graphRows(state *session.State) []graphRow {
+	order := state.TopoOrder()
+	dependents := make(map[string][]*session.Task)
+	for _, task := range order {
+		for _, dep := range task.DependsOn {
+			dependents[dep] = append(dependents[dep], task)
+		}
+	}
+
+	var rows []graphRow
+	shown := make(map[string]bool)
+	var walk func(task *session.Task, prefix, indent string)
+	walk = func(task *session.Task, prefix, indent string) {
+		if shown[task.ID] {
+			rows = append(rows, graphRow{task: task, prefix: prefix, repeat: true})
+			return
+		}
+		shown[task.ID] = true
+		rows = append(rows, graphRow{task: task, prefix: prefix})
+		children := dependents[task.ID]
+		for i, child := range children {
+			if i == len(children)-1 {
+				walk(child, indent+"└─ ", indent+"   ")
+			} else {
+				walk(child, indent+"├─ ", indent+"│  ")
+			}
+		}
+	}
+
+	// Roots first, then anything only reachable through a cycle
+	for _, task := range order {
+		hasDeps := false
+		for _, dep := range task.DependsOn {
+			if _, exists := state.Tasks[dep]; exists {
+				hasDeps = true
+			}
+		}
+		if !hasDeps {
+			walk(task, "", "")
+		}
+	}
+	for _, task := range order {
+		if !shown[task.ID] {
+			walk(task, "", "")
+		}
+	}
+	return rows
+}
+
+// Update handles navigation (↑/↓, j/k), a/d to add or remove a dependency of
+// the selected task, and esc to close. Adding or removing switches to picking
+// the other task, confirmed with enter.
+func (m *GraphModal) Update(msg tea.Msg) tea.Cmd {
+	if !m.IsVisible() {
+		return nil
+	}
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return nil
+	}
+
+	switch key.String() {
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+		return nil
+	case "down", "j":
+		if m.selected < len(m.rows)-1 {
+			m.selected++
+		}
+		return nil
+	}
+
+	if m.picking != graphPickNone {
+		switch key.String() {
+		case "enter":
+			return m.pick()
+		case "esc":
+			m.picking = graphPickNone
+			m.selectTask(m.source)
+		}
+		return nil
+	}
+
+	task := m.selectedTask()
+	switch key.String() {
+	case "a":
+		if task != nil {
+			m.picking = graphPickAdd
+			m.source = task.ID
+		}
+	case "d":
+		if task == nil {
+			return nil
+		}
+		if len(task.DependsOn) == 0 {
+			id := task.ID
+			return func() tea.Msg {
+				return ShowToastMsg{Text: fmt.Sprintf("%s has no dependencies", id)}
+			}
+		}
+		m.picking = graphPickRemove
+		m.source = task.ID
+	case "esc":
+		m.Close()
+	}
+	return nil
+}
+
+// pick confirms the selected task as the dependency to add or remove.
+func (m *GraphModal) pick() tea.Cmd {
+	target := m.selectedTask()
+	source := m.state.Tasks[m.source]
+	picking := m.picking
+	m.picking = graphPickNone
+	m.selectTask(m.source)
+	if target == nil || source == nil {
+		return nil
+	}
+
+	id, dependsOn := source.ID, target.ID
+	if picking == graphPickAdd {
+		return func() tea.Msg { return AddTaskDependencyMsg{ID: id, DependsOn: dependsOn} }
+	}
+	if !slices.Contains(source.DependsOn, dependsOn) {
+		return func() tea.Msg {
+			return ShowToastMsg{Text: fmt.Sprintf("%s does not depend on %s", id, dependsOn)}
+		}
+	}
+	return func() tea.Msg { return RemoveTaskDependencyMsg{ID: id, DependsOn: dependsOn} }
+}
+
+// Draw renders the dependency tree centered on screen.
+func (m *GraphModal) Draw(scr uv.Screen, area uv.Rectangle) {
+	if !m.IsVisible() {
+		return
+	}
+
+	t := theme.Current()
+	s := t.S()
+
+	contentWidth := min(area.Dx()-12, 100)
+	if contentWidth < 30 {
+		contentWidth = 30
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Primary)).
+		Bold(true).
+		Width(contentWidth).
+		Align(lipgloss.Center)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.FgMuted))
+	rowStyle := lipgloss.NewStyle().Width(contentWidth).MaxHeight(1)
+	selectedStyle := rowStyle.
+		Foreground(lipgloss.Color(t.BgBase)).
+		Background(lipgloss.Color(t.Primary))
+
+	lines := []string{titleStyle.Render("Task Dependencies"), ""}
+	if len(m.rows) == 0 {
+		lines = append(lines, mutedStyle.Render("No tasks yet"))
+	}
+
+	// Scroll so the selection stays visible
+	start := 0
+	if m.selected >= graphModalMaxRows {
+		start = m.selected - graphModalMaxRows + 1
+	}
+	end := min(start+graphModalMaxRows, len(m.rows))
+	for i := start; i < end; i++ {
+		row := m.rows[i]
+		text := row.prefix + graphMarker(m.state, row.task) + " " + row.task.ID + " " + row.task.Title()
+		if row.repeat {
+			text += " ↑"
+		}
+		if i == m.selected {
+			lines = append(lines, selectedStyle.Render(text))
+		} else {
+			lines = append(lines, rowStyle.Foreground(lipgloss.Color(m.rowColor(row.task, t))).Render(text))
+		}
+	}
+
+	lines = append(lines, "", mutedStyle.Render("▶ in progress  ● ready  ○ waiting  ■ blocked  ✓ done  ✗ cancelled  ↑ shown above"))
+	if m.state != nil {
+		if critical := m.state.CriticalPath(); len(critical) > 1 {
+			ids := make([]string, len(critical))
+			for i, task := range critical {
+				ids[i] = task.ID
+			}
+			lines = append(lines, mutedStyle.Width(contentWidth).MaxHeight(1).Render("Critical path: "+strings.Join(ids, " → ")))
+		}
+		if m.state.Stalled() {
+			errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.Error)).Width(contentWidth).MaxHeight(1)
+			for _, chain := range m.state.BlockingChains() {
+				lines = append(lines, errorStyle.Render("Blocked: "+chain.String()))
+			}
+		}
+	}
+
+	lines = append(lines, "")
+	switch m.picking {
+	case graphPickAdd:
+		lines = append(lines,
+			lipgloss.NewStyle().Foreground(lipgloss.Color(t.Warning)).Render(fmt.Sprintf("Select the task %s should depend on", m.source)),
+			RenderHintBar(KeyUpDownJK, "select", KeyEnter, "add dependency", KeyEsc, "cancel"))
+	case graphPickRemove:
+		lines = append(lines,
+			lipgloss.NewStyle().Foreground(lipgloss.Color(t.Warning)).Render(fmt.Sprintf("Select the dependency of %s to remove", m.source)),
+			RenderHintBar(KeyUpDownJK, "select", KeyEnter, "remove dependency", KeyEsc, "cancel"))
+	default:
+		lines = append(lines, RenderHintBar(KeyUpDownJK, "select", "a", "add dependency", "d", "remove dependency", KeyEsc, "close"))
+	}
+
+	content := strings.Join(lines, "\n")
+	box := s.ModalContainer.Width(contentWidth + 4).Render(content)
+
+	w := lipgloss.Width(box)
+	h := lipgloss.Height(box)
+	x := max((area.Dx()-w)/2, 0)
+	y := max((area.Dy()-h)/2, 0)
+	uv.NewStyledString(box).Draw(scr, uv.Rectangle{
+		Min: uv.Position{X: area.Min.X + x, Y: area.Min.Y + y},
+		Max: uv.Position{X: area.Min.X + x + w, Y: area.Min.Y + y + h},
+	})
+}
+
+// rowColor returns the color of an unselected task row: the in-progress task
+// stands out, ready tasks are green and tasks on blocking chains red.
+func (m *GraphModal) rowColor(task *session.Task, t *theme.Theme) string {
+	switch {
+	case task.Status == "in_progress":
+		return t.Warning
+	case m.blocking[task.ID]:
+		return t.Error
+	case task.Status == "completed" || task.Status == "cancelled":
+		return t.FgMuted
+	case graphReady(m.state, task):
+		return t.Success
+	}
+	return t.FgBase
+}
+
+// graphMarker returns the status marker shown before a task.
+func graphMarker(state *session.State, task *session.Task) string {
+	switch task.Status {
+	case "in_progress":
+		return "▶"
+	case "completed":
+		return "✓"
+	case "cancelled":
+		return "✗"
+	case "blocked":
+		return "■"
+	}
+	if graphReady(state, task) {
+		return "●"
+	}
+	return "○"
+}
+
+// graphReady reports whether a task is ready to be worked on.
+func graphReady(state *session.State, task *session.Task) bool {
+	return slices.Contains(state.ReadyTasks(), task)
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+// graphTestState returns TAS-1 (completed) ← TAS-2 (in progress) and
+// TAS-3 (blocked) ← TAS-4, with TAS-4 also depending on TAS-2.
+func graphTestState() *session.State {
+	state := &session.State{Tasks: map[string]*session.Task{}}
+	add := func(id, content, status string, deps ...string) {
+		state.Tasks[id] = &session.Task{ID: id, Content: content, Status: status, Priority: 2, DependsOn: deps}
+	}
+	add("TAS-1", "Create schema", "completed")
+	add("TAS-2", "Add models", "in_progress", "TAS-1")
+	add("TAS-3", "Pick a payment provider", "blocked")
+	add("TAS-4", "Add checkout", "remaining", "TAS-3", "TAS-2")
+	add("TAS-5", "Write docs", "remaining")
+	return state
+}
+
+func graphRowIDs(m *GraphModal) []string {
+	var ids []string
+	for _, row := range m.rows {
+		id := row.prefix + row.task.ID
+		if row.repeat {
+			id += " ↑"
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestGraphModal_Rows(t *testing.T) {
+	t.Parallel()
+
+	m := NewGraphModal()
+	m.SetState(graphTestState())
+	require.Equal(t, []string{
+		"TAS-1",
+		"└─ TAS-2",
+		"   └─ TAS-4",
+		"TAS-3",
+		"└─ TAS-4 ↑",
+		"TAS-5",
+	}, graphRowIDs(m))
+	require.True(t, m.blocking["TAS-3"] && m.blocking["TAS-4"], "tasks held up by TAS-3 are on a blocking chain")
+	require.False(t, m.blocking["TAS-2"])
+}
+
+func TestGraphModal_AddAndRemoveDependency(t *testing.T) {
+	t.Parallel()
+
+	m := NewGraphModal()
+	m.SetState(graphTestState())
+	m.Show()
+
+	// Select TAS-5 and make it depend on TAS-3
+	for range 5 {
+		m.Update(tea.KeyPressMsg{Text: "down"})
+	}
+	require.Nil(t, m.Update(tea.KeyPressMsg{Text: "a"}))
+	require.Equal(t, graphPickAdd, m.picking)
+	m.Update(tea.KeyPressMsg{Text: "k"})
+	m.Update(tea.KeyPressMsg{Text: "k"})
+	cmd := m.Update(tea.KeyPressMsg{Text: "enter"})
+	require.NotNil(t, cmd)
+	require.Equal(t, AddTaskDependencyMsg{ID: "TAS-5", DependsOn: "TAS-3"}, cmd())
+	require.Equal(t, "TAS-5", m.selectedTask().ID, "selection returns to the edited task")
+
+	// TAS-5 has no dependencies in the state yet
+	cmd = m.Update(tea.KeyPressMsg{Text: "d"})
+	require.NotNil(t, cmd)
+	require.Contains(t, cmd().(ShowToastMsg).Text, "no dependencies")
+
+	// Remove TAS-4's dependency on TAS-2
+	m.selectTask("TAS-4")
+	m.Update(tea.KeyPressMsg{Text: "d"})
+	require.Equal(t, graphPickRemove, m.picking)
+	m.selectTask("TAS-5")
+	cmd = m.Update(tea.KeyPressMsg{Text: "enter"})
+	require.Contains(t, cmd().(ShowToastMsg).Text, "TAS-4 does not depend on TAS-5")
+
+	m.Update(tea.KeyPressMsg{Text: "d"})
+	m.selectTask("TAS-2")
+	cmd = m.Update(tea.KeyPressMsg{Text: "enter"})
+	require.Equal(t, RemoveTaskDependencyMsg{ID: "TAS-4", DependsOn: "TAS-2"}, cmd())
+
+	m.Update(tea.KeyPressMsg{Text: "a"})
+	m.Update(tea.KeyPressMsg{Text: "esc"})
+	require.Equal(t, graphPickNone, m.picking, "esc cancels picking")
+	require.True(t, m.IsVisible())
+	m.Update(tea.KeyPressMsg{Text: "esc"})
+	require.False(t, m.IsVisible())
+}
+
+func TestGraphModal_Draw(t *testing.T) {
+	t.Parallel()
+
+	state := graphTestState()
+	state.Tasks["TAS-2"].Status = "completed"
+	state.Tasks["TAS-5"].Status = "completed"
+	m := NewGraphModal()
+	m.SetState(state)
+	m.Show()
+
+	scr := uv.NewScreenBuffer(testfixtures.TestTermWidth, testfixtures.TestTermHeight)
+	m.Draw(scr, uv.Rect(0, 0, testfixtures.TestTermWidth, testfixtures.TestTermHeight))
+	out := scr.Render()
+	for _, want := range []string{"Task Dependencies", "✓ TAS-1 Create schema", "■ TAS-3", "○ TAS-4 Add checkout", "Blocked: TAS-4 → TAS-3 (blocked)"} {
+		require.True(t, strings.Contains(out, want), "draw output should contain %q", want)
+	}
+}
+
+func TestApp_GraphModalOpens(t *testing.T) {
+	t.Parallel()
+
+	app := NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, &mockOrchestrator{})
+	app.Update(StateUpdateMsg{State: graphTestState()})
+
+	app.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	app.Update(tea.KeyPressMsg{Text: "g"})
+	require.True(t, app.graph.IsVisible(), "ctrl+x g should open the graph modal")
+	require.Len(t, app.graph.rows, 6)
+}
@@ -22,6 +22,8 @@ const (
 	KeyCtrlXT   = "ctrl+x t" // Create task
 	KeyCtrlXP   = "ctrl+x p" // Pause/resume
 	KeyCtrlXR   = "ctrl+x r" // Restart completed session
+	KeyCtrlXH   = "ctrl+x h" // Iteration history
+	KeyCtrlXG   = "ctrl+x g" // Task dependency graph
 	KeyPgUpDown = "pgup/pgdn"
 	KeyHomeEnd  = "home/end"
 	KeyI        = "i"
@@ -0,0 +1,225 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/theme"
+)
+
+// historyModalMaxRows limits the number of iterations listed at once.
+const historyModalMaxRows = 12
+
+// RequestRollbackMsg is sent when the user confirms a rollback from the
+// iteration history modal.
+type RequestRollbackMsg struct {
+	To int // Iteration to roll back to
+}
+
+// HistoryModal lists the session's iterations (newest first) and lets the
+// user roll the session back to one of them.
+type HistoryModal struct {
+	visible    bool
+	state      *session.State
+	iterations []*session.Iteration // Newest first
+	selected   int
+	confirming bool // Waiting for y/n on a rollback
+}
+
+// NewHistoryModal creates a new iteration history modal.
+func NewHistoryModal() *HistoryModal {
+	return &HistoryModal{}
+}
+
+// SetState updates the iterations listed, keeping the selected iteration if it still exists.
+func (m *HistoryModal) SetState(state *session.State) {
+	m.state = state
+	m.refresh()
+}
+
+// Show opens the modal with the latest iteration selected.
+func (m *HistoryModal) Show() {
+	m.visible = true
+	m.confirming = false
+	m.selected = 0
+	m.refresh()
+}
+
+// Close hides the modal.
+func (m *HistoryModal) Close() {
+	m.visible = false
+	m.confirming = false
+}
+
+// IsVisible returns whether the modal is open.
+func (m *HistoryModal) IsVisible() bool {
+	return m != nil && m.visible
+}
+
+// refresh rebuilds the iteration list from the current state.
+func (m *HistoryModal) refresh() {
+	var selectedNumber = -1
+	if m.selected < len(m.iterations) {
+		selectedNumber = m.iterations[m.selected].Number
+	}
+
+	m.iterations = nil
+	if m.state != nil {
+		m.iterations = append(m.iterations, m.state.Iterations...)
+	}
+	sort.SliceStable(m.iterations, func(i, j int) bool {
+		return m.iterations[i].Number > m.iterations[j].Number
+	})
+
+	m.selected = 0
+	for i, iter := range m.iterations {
+		if iter.Number == selectedNumber {
+			m.selected = i
+		}
+	}
+}
+
+// Update handles navigation (↑/↓, j/k), r to roll back, and esc to close.
+func (m *HistoryModal) Update(msg tea.Msg) tea.Cmd {
+	if !m.IsVisible() {
+		return nil
+	}
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return nil
+	}
+
+	if m.confirming {
+		switch key.String() {
+		case "y", "enter":
+			to := m.iterations[m.selected].Number
+			m.Close()
+			return func() tea.Msg { return RequestRollbackMsg{To: to} }
+		case "n", "esc":
+			m.confirming = false
+		}
+		return nil
+	}
+
+	switch key.String() {
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+	case "down", "j":
+		if m.selected < len(m.iterations)-1 {
+			m.selected++
+		}
+	case "r":
+		if len(m.iterations) == 0 {
+			return nil
+		}
+		if m.selected == 0 {
+			number := m.iterations[0].Number
+			return func() tea.Msg {
+				return ShowToastMsg{Text: fmt.Sprintf("Nothing to roll back: #%d is the latest iteration", number)}
+			}
+		}
+		m.confirming = true
+	case "esc":
+		m.Close()
+	}
+	return nil
+}
+
+// Draw renders the iteration list centered on screen.
+func (m *HistoryModal) Draw(scr uv.Screen, area uv.Rectangle) {
+	if !m.IsVisible() {
+		return
+	}
+
+	t := theme.Current()
+	s := t.S()
+
+	contentWidth := min(area.Dx()-12, 80)
+	if contentWidth < 30 {
+		contentWidth = 30
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Primary)).
+		Bold(true).
+		Width(contentWidth).
+		Align(lipgloss.Center)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.FgMuted))
+	rowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.FgBase)).Width(contentWidth).MaxHeight(1)
+	selectedStyle := rowStyle.
+		Foreground(lipgloss.Color(t.BgBase)).
+		Background(lipgloss.Color(t.Primary))
+
+	lines := []string{titleStyle.Render("Iteration History"), ""}
+	if len(m.iterations) == 0 {
+		lines = append(lines, mutedStyle.Render("No iterations yet"))
+	}
+
+	// Scroll so the selection stays visible
+	start := 0
+	if m.selected >= historyModalMaxRows {
+		start = m.selected - historyModalMaxRows + 1
+	}
+	end := min(start+historyModalMaxRows, len(m.iterations))
+	for i := start; i < end; i++ {
+		row := historyRow(m.iterations[i])
+		if i == m.selected {
+			lines = append(lines, selectedStyle.Render(row))
+		} else {
+			lines = append(lines, rowStyle.Render(row))
+		}
+	}
+
+	lines = append(lines, "")
+	if m.confirming {
+		warnStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(t.Warning)).
+			Width(contentWidth).
+			Align(lipgloss.Center)
+		to := m.iterations[m.selected].Number
+		lines = append(lines,
+			warnStyle.Render(fmt.Sprintf("Roll back to iteration #%d?", to)),
+			mutedStyle.Width(contentWidth).Align(lipgloss.Center).Render("Files and tasks changed after it will be restored."),
+			"",
+			lipgloss.NewStyle().Width(contentWidth).Align(lipgloss.Center).Render(RenderHintBar("y", "roll back", "n", "cancel")),
+		)
+	} else {
+		lines = append(lines, RenderHintBar(KeyUpDownJK, "select", "r", "roll back to selected", KeyEsc, "close"))
+	}
+
+	content := strings.Join(lines, "\n")
+	box := s.ModalContainer.Width(contentWidth + 4).Render(content)
+
+	w := lipgloss.Width(box)
+	h := lipgloss.Height(box)
+	x := max((area.Dx()-w)/2, 0)
+	y := max((area.Dy()-h)/2, 0)
+	uv.NewStyledString(box).Draw(scr, uv.Rectangle{
+		Min: uv.Position{X: area.Min.X + x, Y: area.Min.Y + y},
+		Max: uv.Position{X: area.Min.X + x + w, Y: area.Min.Y + y + h},
+	})
+}
+
+// historyRow formats one iteration: number, status, commit, and summary.
+func historyRow(iter *session.Iteration) string {
+	status := "…"
+	if iter.Complete {
+		status = "✓"
+	}
+	commit := "       "
+	if iter.CommitSHA != "" {
+		commit = iter.CommitSHA[:min(7, len(iter.CommitSHA))]
+	}
+	summary := iter.Summary
+	if i := strings.IndexByte(summary, '\n'); i >= 0 {
+		summary = summary[:i]
+	}
+	return fmt.Sprintf(" #%-4d %s  %s  %s", iter.Number, status, commit, summary)
+}
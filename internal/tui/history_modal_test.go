@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+func historyTestState() *session.State {
+	return &session.State{
+		Iterations: []*session.Iteration{
+			{Number: 1, Complete: true, Summary: "Added login form", CommitSHA: "abc1234def"},
+			{Number: 2, Complete: true, Summary: "Added validation\nand tests"},
+			{Number: 3, Summary: "In progress"},
+		},
+	}
+}
+
+func TestHistoryModal_SelectAndConfirm(t *testing.T) {
+	t.Parallel()
+
+	m := NewHistoryModal()
+	m.SetState(historyTestState())
+	m.Show()
+	require.True(t, m.IsVisible())
+	require.Equal(t, 3, m.iterations[m.selected].Number, "latest iteration should be selected first")
+
+	m.Update(tea.KeyPressMsg{Text: "j"})
+	m.Update(tea.KeyPressMsg{Text: "down"})
+	m.Update(tea.KeyPressMsg{Text: "down"}) // Clamped at the oldest iteration
+	require.Equal(t, 1, m.iterations[m.selected].Number)
+	m.Update(tea.KeyPressMsg{Text: "k"})
+	require.Equal(t, 2, m.iterations[m.selected].Number)
+
+	require.Nil(t, m.Update(tea.KeyPressMsg{Text: "r"}))
+	require.True(t, m.confirming, "r should ask for confirmation")
+
+	m.Update(tea.KeyPressMsg{Text: "n"})
+	require.False(t, m.confirming, "n should cancel")
+	require.True(t, m.IsVisible())
+
+	m.Update(tea.KeyPressMsg{Text: "r"})
+	cmd := m.Update(tea.KeyPressMsg{Text: "y"})
+	require.NotNil(t, cmd)
+	require.Equal(t, RequestRollbackMsg{To: 2}, cmd())
+	require.False(t, m.IsVisible(), "modal should close after confirming")
+}
+
+func TestHistoryModal_LatestIteration(t *testing.T) {
+	t.Parallel()
+
+	m := NewHistoryModal()
+	m.SetState(historyTestState())
+	m.Show()
+
+	cmd := m.Update(tea.KeyPressMsg{Text: "r"})
+	require.NotNil(t, cmd)
+	toast, ok := cmd().(ShowToastMsg)
+	require.True(t, ok, "r on the latest iteration should show a toast")
+	require.Contains(t, toast.Text, "Nothing to roll back")
+	require.False(t, m.confirming)
+
+	m.Update(tea.KeyPressMsg{Text: "esc"})
+	require.False(t, m.IsVisible())
+}
+
+func TestHistoryModal_SetStateKeepsSelection(t *testing.T) {
+	t.Parallel()
+
+	m := NewHistoryModal()
+	m.SetState(historyTestState())
+	m.Show()
+	m.Update(tea.KeyPressMsg{Text: "down"})
+
+	state := historyTestState()
+	state.Iterations = append(state.Iterations, &session.Iteration{Number: 4})
+	m.SetState(state)
+	require.Equal(t, 2, m.iterations[m.selected].Number, "selection should follow the iteration")
+}
+
+func TestHistoryModal_Draw(t *testing.T) {
+	t.Parallel()
+
+	m := NewHistoryModal()
+	m.SetState(historyTestState())
+	m.Show()
+	m.Update(tea.KeyPressMsg{Text: "down"})
+	m.Update(tea.KeyPressMsg{Text: "r"})
+
+	scr := uv.NewScreenBuffer(testfixtures.TestTermWidth, testfixtures.TestTermHeight)
+	m.Draw(scr, uv.Rect(0, 0, testfixtures.TestTermWidth, testfixtures.TestTermHeight))
+	out := scr.Render()
+	for _, want := range []string{"Iteration History", "#1", "abc1234", "Added validation", "Roll back to iteration #2?"} {
+		require.True(t, strings.Contains(out, want), "draw output should contain %q", want)
+	}
+	require.False(t, strings.Contains(out, "abc1234def"), "commit SHA should be shortened")
+}
+
+func TestApp_HistoryRollback(t *testing.T) {
+	t.Parallel()
+
+	orch := &mockOrchestrator{}
+	app := NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, orch)
+	app.width = testfixtures.TestTermWidth
+	app.height = testfixtures.TestTermHeight
+	app.Update(StateUpdateMsg{State: historyTestState()})
+
+	app.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	app.Update(tea.KeyPressMsg{Text: "h"})
+	require.True(t, app.history.IsVisible(), "ctrl+x h should open the history modal")
+
+	app.Update(tea.KeyPressMsg{Text: "down"})
+	app.Update(tea.KeyPressMsg{Text: "r"})
+	_, cmd := app.Update(tea.KeyPressMsg{Text: "y"})
+	require.NotNil(t, cmd)
+
+	app.Update(cmd())
+	require.NotNil(t, orch.rollbackTo, "confirmed rollback should reach the orchestrator")
+	require.Equal(t, 2, *orch.rollbackTo)
+}
@@ -134,6 +134,9 @@ func (l *LogViewer) renderEvent(event session.Event) string {
 	case "control":
 		typeStyle = s.LogControl
 		typeLabel = "CTRL"
+	case "permission":
+		typeStyle = s.LogControl
+		typeLabel = "PERM"
 	default:
 		typeStyle = s.LogContent
 		typeLabel = "EVENT"
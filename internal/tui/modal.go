@@ -105,6 +105,17 @@ func (m *TaskModal) SetTask(task *session.Task) {
 	m.textarea.Blur()
 }
 
+// Refresh replaces the displayed task with its latest version from state,
+// keeping the selectors and any content being edited.
+func (m *TaskModal) Refresh(state *session.State) {
+	if !m.visible || m.task == nil || state == nil {
+		return
+	}
+	if task, exists := state.Tasks[m.task.ID]; exists {
+		m.task = task
+	}
+}
+
 // Close hides the modal.
 func (m *TaskModal) Close() {
 	m.visible = false
@@ -224,6 +235,23 @@ func (m *TaskModal) Update(msg tea.Msg) tea.Cmd {
 				return RequestDeleteTaskMsg{ID: taskID}
 			}
 		}
+
+	case "e":
+		// Shortcut: 'e' edits content and details in the task input modal
+		if m.focus != taskModalFocusContent {
+			task := m.task
+			return func() tea.Msg {
+				return EditTaskMsg{Task: task}
+			}
+		}
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		// Shortcut: number keys tick acceptance criteria when NOT in textarea
+		index := int(keyMsg.String()[0] - '0')
+		if m.focus != taskModalFocusContent && index <= len(m.task.Criteria) {
+			msg := ToggleTaskCriterionMsg{ID: m.task.ID, Index: index, Done: !m.task.Criteria[index-1].Done}
+			return func() tea.Msg { return msg }
+		}
 	}
 
 	// Forward to textarea when it's focused
@@ -485,6 +513,16 @@ func (m *TaskModal) buildContent(width int) string {
 		depsContent := s.ModalValue.Render(strings.Join(m.task.DependsOn, ", "))
 		sections = append(sections, depsLabel+depsContent)
 	}
+	if m.task.Branch != "" {
+		branchLabel := s.ModalLabel.Render("Branch:   ")
+		sections = append(sections, branchLabel+s.ModalValue.Render(m.task.Branch))
+	}
+
+	// === Details Section ===
+	if details := m.renderDetails(width - 2); details != "" {
+		sections = append(sections, details)
+		sections = append(sections, "")
+	}
 
 	// === Timestamps Section ===
 	createdLine := s.ModalLabel.Render("Created:  ") + s.ModalValue.Render(m.formatTime(m.task.CreatedAt))
@@ -503,6 +541,44 @@ func (m *TaskModal) buildContent(width int) string {
 	return strings.Join(sections, "\n")
 }
 
+// renderDetails renders the task's description, acceptance criteria, tags,
+// spec sections, estimate and latest verification. Returns "" if the task
+// has none.
+func (m *TaskModal) renderDetails(width int) string {
+	s := theme.Current().S()
+	var lines []string
+	if m.task.Description != "" {
+		lines = append(lines, s.ModalValue.Render(m.wordWrap(m.task.Description, width)))
+	}
+	if len(m.task.Criteria) > 0 {
+		lines = append(lines, s.ModalLabel.Render("Acceptance criteria:"))
+		for i, c := range m.task.Criteria {
+			if c.Done {
+				lines = append(lines, s.Success.Render(fmt.Sprintf(" %d. ✓ %s", i+1, c.Text)))
+			} else {
+				lines = append(lines, s.ModalValue.Render(fmt.Sprintf(" %d. ○ %s", i+1, c.Text)))
+			}
+		}
+	}
+	if len(m.task.Tags) > 0 {
+		lines = append(lines, s.ModalLabel.Render("Tags:     ")+s.ModalValue.Render(strings.Join(m.task.Tags, ", ")))
+	}
+	if len(m.task.SpecRefs) > 0 {
+		lines = append(lines, s.ModalLabel.Render("Spec:     ")+s.ModalValue.Render(strings.Join(m.task.SpecRefs, ", ")))
+	}
+	if m.task.Estimate != "" {
+		lines = append(lines, s.ModalLabel.Render("Estimate: ")+s.ModalValue.Render(m.task.Estimate))
+	}
+	if v := m.task.Verification; v != nil {
+		if v.Passed {
+			lines = append(lines, s.ModalLabel.Render("Verified: ")+s.Success.Render("✓ "+strings.Join(v.Commands, ", ")))
+		} else {
+			lines = append(lines, s.ModalLabel.Render("Verified: ")+s.Error.Render("✗ "+v.Failed+" failed"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // renderStatusBadges renders all status badges with the active one highlighted.
 func (m *TaskModal) renderStatusBadges() string {
 	t := theme.Current()
@@ -599,10 +675,20 @@ func (m *TaskModal) renderDeleteButton() string {
 
 // renderHintBar renders the keyboard shortcut hints for the modal.
 func (m *TaskModal) renderHintBar() string {
+	if len(m.task.Criteria) > 0 {
+		return RenderHintBar(
+			KeyTab, "cycle",
+			"←→", "change",
+			"e", "edit",
+			"1-9", "tick",
+			KeyEsc, "close",
+		)
+	}
 	return RenderHintBar(
 		KeyTab, "cycle",
 		"←→", "change",
 		"ctrl+enter", "save",
+		"e", "edit",
 		KeyEsc, "close",
 	)
 }
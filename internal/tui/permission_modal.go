@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/tui/theme"
+)
+
+// PermissionRequestMsg asks the user to approve or deny an agent tool call.
+// The answer is sent on Reply (true = allow). Reply must be buffered.
+type PermissionRequestMsg struct {
+	Title   string      // Tool title (e.g., "bash")
+	Kind    string      // Tool kind (e.g., "execute")
+	Path    string      // File path the tool operates on
+	Command string      // Shell command the tool runs
+	Rule    string      // Policy rule that requested confirmation
+	Reply   chan<- bool // Receives the decision
+}
+
+// PermissionModal is a modal overlay that asks the user to approve a tool call.
+// Requests are queued and answered one at a time.
+type PermissionModal struct {
+	queue []PermissionRequestMsg
+}
+
+// NewPermissionModal creates a new permission modal.
+func NewPermissionModal() *PermissionModal {
+	return &PermissionModal{}
+}
+
+// Push queues a permission request for display.
+func (m *PermissionModal) Push(req PermissionRequestMsg) {
+	m.queue = append(m.queue, req)
+}
+
+// IsVisible returns whether a request is awaiting an answer.
+func (m *PermissionModal) IsVisible() bool {
+	return m != nil && len(m.queue) > 0
+}
+
+// answer replies to the current request and advances the queue.
+func (m *PermissionModal) answer(allow bool) {
+	if len(m.queue) == 0 {
+		return
+	}
+	req := m.queue[0]
+	m.queue = m.queue[1:]
+	if req.Reply != nil {
+		select {
+		case req.Reply <- allow:
+		default:
+		}
+	}
+}
+
+// DenyAll denies every pending request (used on shutdown).
+func (m *PermissionModal) DenyAll() {
+	for m.IsVisible() {
+		m.answer(false)
+	}
+}
+
+// Update handles key input: y/a allows, n/d/esc denies.
+func (m *PermissionModal) Update(msg tea.Msg) tea.Cmd {
+	if !m.IsVisible() {
+		return nil
+	}
+	if key, ok := msg.(tea.KeyPressMsg); ok {
+		switch key.String() {
+		case "y", "a", "enter":
+			m.answer(true)
+		case "n", "d", "esc":
+			m.answer(false)
+		}
+	}
+	return nil
+}
+
+// Draw renders the current request centered on screen.
+func (m *PermissionModal) Draw(scr uv.Screen, area uv.Rectangle) {
+	if !m.IsVisible() {
+		return
+	}
+	req := m.queue[0]
+
+	t := theme.Current()
+	s := t.S()
+
+	contentWidth := area.Dx() - 12
+	if contentWidth > 72 {
+		contentWidth = 72
+	}
+	if contentWidth < 20 {
+		contentWidth = 20
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Warning)).
+		Bold(true).
+		Width(contentWidth).
+		Align(lipgloss.Center)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.FgMuted))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.FgBase)).Width(contentWidth - 10)
+
+	row := func(label, value string) string {
+		return lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Width(10).Render(label), valueStyle.Render(value))
+	}
+
+	lines := []string{titleStyle.Render("Permission Request"), ""}
+	lines = append(lines, row("Tool", req.Title))
+	if req.Kind != "" {
+		lines = append(lines, row("Kind", req.Kind))
+	}
+	if req.Command != "" {
+		lines = append(lines, row("Command", req.Command))
+	}
+	if req.Path != "" {
+		lines = append(lines, row("Path", req.Path))
+	}
+	if req.Rule != "" {
+		lines = append(lines, row("Rule", req.Rule))
+	}
+
+	allowStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.BgBase)).
+		Background(lipgloss.Color(t.Success)).
+		Padding(0, 2)
+	denyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.BgBase)).
+		Background(lipgloss.Color(t.Error)).
+		Padding(0, 2)
+	buttons := lipgloss.JoinHorizontal(lipgloss.Center, allowStyle.Render("Allow (y)"), "  ", denyStyle.Render("Deny (n)"))
+	lines = append(lines, "", lipgloss.NewStyle().Width(contentWidth).Align(lipgloss.Center).Render(buttons))
+	if pending := len(m.queue) - 1; pending > 0 {
+		lines = append(lines, labelStyle.Width(contentWidth).Align(lipgloss.Center).Render(strings.Repeat("•", min(pending, 10))+" more pending"))
+	}
+
+	content := strings.Join(lines, "\n")
+	box := s.ModalContainer.Width(contentWidth + 4).Render(content)
+
+	w := lipgloss.Width(box)
+	h := lipgloss.Height(box)
+	x := max((area.Dx()-w)/2, 0)
+	y := max((area.Dy()-h)/2, 0)
+	uv.NewStyledString(box).Draw(scr, uv.Rectangle{
+		Min: uv.Position{X: area.Min.X + x, Y: area.Min.Y + y},
+		Max: uv.Position{X: area.Min.X + x + w, Y: area.Min.Y + y + h},
+	})
+}
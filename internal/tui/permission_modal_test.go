@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionModal_AllowDeny(t *testing.T) {
+	t.Parallel()
+
+	m := NewPermissionModal()
+	require.False(t, m.IsVisible(), "modal should not be visible initially")
+
+	first := make(chan bool, 1)
+	second := make(chan bool, 1)
+	m.Push(PermissionRequestMsg{Title: "bash", Command: "make deploy", Reply: first})
+	m.Push(PermissionRequestMsg{Title: "edit", Path: "main.go", Reply: second})
+	require.True(t, m.IsVisible(), "modal should be visible with pending requests")
+
+	// Unrelated keys are ignored
+	m.Update(tea.KeyPressMsg{Text: "x"})
+	require.Len(t, m.queue, 2, "unrelated key should not answer")
+
+	m.Update(tea.KeyPressMsg{Text: "y"})
+	require.True(t, <-first, "y should allow")
+	require.True(t, m.IsVisible(), "second request should still be pending")
+
+	m.Update(tea.KeyPressMsg{Text: "esc"})
+	require.False(t, <-second, "esc should deny")
+	require.False(t, m.IsVisible(), "modal should close when queue is empty")
+}
+
+func TestPermissionModal_DenyAll(t *testing.T) {
+	t.Parallel()
+
+	m := NewPermissionModal()
+	replies := []chan bool{make(chan bool, 1), make(chan bool, 1)}
+	for _, r := range replies {
+		m.Push(PermissionRequestMsg{Title: "bash", Reply: r})
+	}
+
+	m.DenyAll()
+	require.False(t, m.IsVisible())
+	for _, r := range replies {
+		require.False(t, <-r, "DenyAll should deny every request")
+	}
+}
+
+func TestPermissionModal_Draw(t *testing.T) {
+	t.Parallel()
+
+	m := NewPermissionModal()
+	scr := uv.NewScreenBuffer(testfixtures.TestTermWidth, testfixtures.TestTermHeight)
+	area := uv.Rectangle{
+		Min: uv.Position{X: 0, Y: 0},
+		Max: uv.Position{X: testfixtures.TestTermWidth, Y: testfixtures.TestTermHeight},
+	}
+
+	m.Draw(scr, area)
+	require.Empty(t, strings.TrimSpace(scr.String()), "screen should be empty when modal is not visible")
+
+	m.Push(PermissionRequestMsg{Title: "bash", Kind: "execute", Command: "make deploy", Rule: "default"})
+	m.Draw(scr, area)
+	output := scr.String()
+	require.Contains(t, output, "Permission Request")
+	require.Contains(t, output, "make deploy")
+	require.Contains(t, output, "Allow (y)")
+}
+
+// TestModalPriority_Permission_OverTaskModal tests that a pending permission
+// request captures keys before other modals.
+func TestModalPriority_Permission_OverTaskModal(t *testing.T) {
+	ctx := context.Background()
+	app := NewApp(ctx, nil, "test-session", "/tmp", t.TempDir(), nil, nil, nil)
+	app.width = 120
+	app.height = 40
+
+	app.taskModal.SetTask(&session.Task{ID: "task1", Content: "Test task", Status: "remaining", Priority: 1})
+
+	reply := make(chan bool, 1)
+	updatedModel, _ := app.Update(PermissionRequestMsg{Title: "bash", Reply: reply})
+	app = updatedModel.(*App)
+	require.True(t, app.permission.IsVisible())
+
+	// ESC denies the request instead of closing the task modal
+	updatedModel, _ = app.handleKeyPress(tea.KeyPressMsg{Text: "esc"})
+	app = updatedModel.(*App)
+	require.False(t, <-reply)
+	require.False(t, app.permission.IsVisible())
+	require.True(t, app.taskModal.IsVisible(), "task modal should remain open")
+}
@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/theme"
+)
+
+// specChangeMaxRows limits the number of diff lines shown at once.
+const specChangeMaxRows = 14
+
+// SpecChangeMsg reports that the spec was edited mid-session and the task
+// list was re-planned. The orchestrator waits for a value on Reply before
+// the iteration continues. Reply must be buffered.
+type SpecChangeMsg struct {
+	Iteration int             // Iteration the re-planning ran in
+	Diff      string          // Unified diff of the spec edits
+	Added     []*session.Task // Tasks added by re-planning
+	Cancelled []*session.Task // Tasks cancelled by re-planning
+	Reply     chan<- struct{} // Signalled when the user continues
+}
+
+// SpecChangeModal shows a spec diff and the resulting task changes, and
+// holds the iteration until the user continues.
+type SpecChangeModal struct {
+	change *SpecChangeMsg
+	lines  []string // Diff lines
+	offset int      // First diff line shown
+}
+
+// NewSpecChangeModal creates a new spec change modal.
+func NewSpecChangeModal() *SpecChangeModal {
+	return &SpecChangeModal{}
+}
+
+// Show displays a spec change, continuing any change already shown.
+func (m *SpecChangeModal) Show(msg SpecChangeMsg) {
+	m.Continue()
+	m.change = &msg
+	m.lines = strings.Split(strings.TrimRight(msg.Diff, "\n"), "\n")
+	m.offset = 0
+}
+
+// IsVisible returns whether a spec change is awaiting acknowledgement.
+func (m *SpecChangeModal) IsVisible() bool {
+	return m != nil && m.change != nil
+}
+
+// Continue closes the modal and lets the iteration proceed.
+func (m *SpecChangeModal) Continue() {
+	if m == nil || m.change == nil {
+		return
+	}
+	if m.change.Reply != nil {
+		select {
+		case m.change.Reply <- struct{}{}:
+		default:
+		}
+	}
+	m.change = nil
+	m.lines = nil
+}
+
+// Update handles scrolling (↑/↓, j/k, pgup/pgdn) and enter/esc to continue.
+func (m *SpecChangeModal) Update(msg tea.Msg) tea.Cmd {
+	if !m.IsVisible() {
+		return nil
+	}
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return nil
+	}
+	maxOffset := max(len(m.lines)-specChangeMaxRows, 0)
+	switch key.String() {
+	case "up", "k":
+		m.offset = max(m.offset-1, 0)
+	case "down", "j":
+		m.offset = min(m.offset+1, maxOffset)
+	case "pgup":
+		m.offset = max(m.offset-specChangeMaxRows, 0)
+	case "pgdown":
+		m.offset = min(m.offset+specChangeMaxRows, maxOffset)
+	case "enter", "esc", "c":
+		m.Continue()
+	}
+	return nil
+}
+
+// Draw renders the diff and task changes centered on screen.
+func (m *SpecChangeModal) Draw(scr uv.Screen, area uv.Rectangle) {
+	if !m.IsVisible() {
+		return
+	}
+
+	t := theme.Current()
+	s := t.S()
+
+	contentWidth := min(area.Dx()-12, 96)
+	if contentWidth < 30 {
+		contentWidth = 30
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Warning)).
+		Bold(true).
+		Width(contentWidth).
+		Align(lipgloss.Center)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.FgMuted))
+	lineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(t.FgBase)).Width(contentWidth).MaxHeight(1)
+	addStyle := lineStyle.Foreground(lipgloss.Color(t.Success))
+	delStyle := lineStyle.Foreground(lipgloss.Color(t.Error))
+	hunkStyle := lineStyle.Foreground(lipgloss.Color(t.FgMuted))
+
+	lines := []string{
+		titleStyle.Render("Spec Changed"),
+		mutedStyle.Width(contentWidth).Align(lipgloss.Center).Render(fmt.Sprintf("Tasks re-planned in iteration #%d", m.change.Iteration)),
+		"",
+	}
+
+	end := min(m.offset+specChangeMaxRows, len(m.lines))
+	for _, line := range m.lines[m.offset:end] {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+			lines = append(lines, hunkStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, addStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			lines = append(lines, delStyle.Render(line))
+		default:
+			lines = append(lines, lineStyle.Render(line))
+		}
+	}
+	if len(m.lines) > specChangeMaxRows {
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("lines %d-%d of %d", m.offset+1, end, len(m.lines))))
+	}
+
+	lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color(t.Primary)).Bold(true).Render("Task Changes"))
+	rows := specChangeTaskRows(m.change)
+	if len(rows) == 0 {
+		lines = append(lines, mutedStyle.Render("No task changes"))
+	}
+	for _, row := range rows {
+		style := addStyle
+		if strings.HasPrefix(row, "✗") {
+			style = delStyle
+		}
+		lines = append(lines, style.Render(row))
+	}
+
+	lines = append(lines, "", RenderHintBar(KeyUpDownJK, "scroll diff", KeyEnter, "continue"))
+
+	content := strings.Join(lines, "\n")
+	box := s.ModalContainer.Width(contentWidth + 4).Render(content)
+
+	w := lipgloss.Width(box)
+	h := lipgloss.Height(box)
+	x := max((area.Dx()-w)/2, 0)
+	y := max((area.Dy()-h)/2, 0)
+	uv.NewStyledString(box).Draw(scr, uv.Rectangle{
+		Min: uv.Position{X: area.Min.X + x, Y: area.Min.Y + y},
+		Max: uv.Position{X: area.Min.X + x + w, Y: area.Min.Y + y + h},
+	})
+}
+
+// specChangeTaskRows formats added tasks as "+ ID content" and cancelled
+// tasks as "✗ ID content".
+func specChangeTaskRows(change *SpecChangeMsg) []string {
+	row := func(mark string, task *session.Task) string {
+		content, _, _ := strings.Cut(task.Content, "\n")
+		return fmt.Sprintf("%s %s %s", mark, task.ID, content)
+	}
+	var rows []string
+	for _, task := range change.Added {
+		rows = append(rows, row("+", task))
+	}
+	for _, task := range change.Cancelled {
+		rows = append(rows, row("✗", task))
+	}
+	return rows
+}
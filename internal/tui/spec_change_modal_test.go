@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+func specChangeTestMsg(reply chan struct{}) SpecChangeMsg {
+	return SpecChangeMsg{
+		Iteration: 3,
+		Diff:      "--- spec (planned)\n+++ spec (now)\n@@ -1,2 +1,2 @@\n # Shop\n-- Cart\n+- Checkout\n",
+		Added:     []*session.Task{{ID: "TAS-4", Content: "Add checkout\nwith card payments"}},
+		Cancelled: []*session.Task{{ID: "TAS-2", Content: "Add cart"}},
+		Reply:     reply,
+	}
+}
+
+func TestSpecChangeModal_ScrollAndContinue(t *testing.T) {
+	t.Parallel()
+
+	reply := make(chan struct{}, 1)
+	msg := specChangeTestMsg(reply)
+	for i := 0; i < 20; i++ {
+		msg.Diff += fmt.Sprintf("+line %d\n", i)
+	}
+
+	m := NewSpecChangeModal()
+	m.Show(msg)
+	require.True(t, m.IsVisible())
+
+	m.Update(tea.KeyPressMsg{Text: "k"})
+	require.Equal(t, 0, m.offset, "scrolling up is clamped at the top")
+	m.Update(tea.KeyPressMsg{Text: "j"})
+	require.Equal(t, 1, m.offset)
+	m.Update(tea.KeyPressMsg{Text: "pgdown"})
+	require.Equal(t, len(m.lines)-specChangeMaxRows, m.offset, "scrolling down is clamped at the last page")
+
+	m.Update(tea.KeyPressMsg{Text: "enter"})
+	require.False(t, m.IsVisible())
+	select {
+	case <-reply:
+	default:
+		t.Fatal("enter should signal Reply")
+	}
+}
+
+func TestSpecChangeModal_Draw(t *testing.T) {
+	t.Parallel()
+
+	m := NewSpecChangeModal()
+	m.Show(specChangeTestMsg(make(chan struct{}, 1)))
+
+	scr := uv.NewScreenBuffer(testfixtures.TestTermWidth, testfixtures.TestTermHeight)
+	m.Draw(scr, uv.Rect(0, 0, testfixtures.TestTermWidth, testfixtures.TestTermHeight))
+	out := scr.Render()
+	for _, want := range []string{"Spec Changed", "iteration #3", "+- Checkout", "-- Cart", "+ TAS-4 Add checkout", "✗ TAS-2 Add cart"} {
+		require.True(t, strings.Contains(out, want), "draw output should contain %q", want)
+	}
+	require.False(t, strings.Contains(out, "card payments"), "only the first line of a task is shown")
+}
+
+func TestApp_SpecChangeBlocksKeysUntilContinued(t *testing.T) {
+	t.Parallel()
+
+	app := NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, &mockOrchestrator{})
+	reply := make(chan struct{}, 1)
+	app.Update(specChangeTestMsg(reply))
+	require.True(t, app.specChange.IsVisible())
+
+	app.Update(tea.KeyPressMsg{Text: "ctrl+x"})
+	app.Update(tea.KeyPressMsg{Text: "l"})
+	require.False(t, app.logsVisible, "keys should go to the spec change modal")
+
+	app.Update(tea.KeyPressMsg{Text: "esc"})
+	require.False(t, app.specChange.IsVisible())
+	require.Len(t, reply, 1)
+}
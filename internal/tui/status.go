@@ -131,6 +131,11 @@ func (s *StatusBar) buildLeft() string {
 		left += sep + theme.Current().S().HeaderInfo.Render(fileInfo)
 	}
 
+	// Add running token/cost totals once the agent has reported usage
+	if s.state != nil && !s.state.Usage.IsZero() {
+		left += sep + theme.Current().S().HeaderInfo.Render(s.state.Usage.String())
+	}
+
 	// Add spinner when working
 	if s.working {
 		left += " " + s.spinner.View()
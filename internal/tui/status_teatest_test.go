@@ -2,12 +2,14 @@ package tui
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	uv "github.com/charmbracelet/ultraviolet"
 	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/mark3labs/iteratr/internal/usage"
 )
 
 // TestStatusBar_InitialState verifies the initial state of a new StatusBar
@@ -623,6 +625,29 @@ func TestStatusBar_Render_WithModifiedFiles(t *testing.T) {
 	compareStatusBarGolden(t, canvas, "with_modified_files")
 }
 
+// TestStatusBar_Render_WithUsage tests rendering the running token and cost totals
+func TestStatusBar_Render_WithUsage(t *testing.T) {
+	t.Parallel()
+
+	sb := NewStatusBar(testfixtures.FixedSessionName)
+	sb.SetLayoutMode(LayoutDesktop)
+	sb.startedAt = testfixtures.FixedTime
+	sb.stoppedAt = testfixtures.FixedTime // Freeze duration at fixed time
+	state := testfixtures.StateWithTasks()
+	state.Usage = usage.Usage{InputTokens: 12_000, OutputTokens: 340, Cost: 0.42}
+	sb.SetState(state)
+
+	if left := sb.buildLeft(); !strings.Contains(left, "12.3k tok · $0.42") {
+		t.Errorf("status bar missing usage: %q", left)
+	}
+
+	canvas := uv.NewScreenBuffer(150, 1)
+	area := uv.Rect(0, 0, 150, 1)
+	sb.Draw(canvas, area)
+
+	compareStatusBarGolden(t, canvas, "with_usage")
+}
+
 // TestStatusBar_Render_FullState tests rendering with all features enabled
 func TestStatusBar_Render_FullState(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// criterionLineRe matches an acceptance criterion line: "- [ ] text" or "- [x] text".
+var criterionLineRe = regexp.MustCompile(`^[-*+]\s+\[([ xX])\]\s+(.+)$`)
+
+// taskDetailsPlaceholder explains the details textarea format.
+const taskDetailsPlaceholder = "Description, then optional lines:\n- [ ] acceptance criterion\ntags: backend, api\nspec: spec.md#Login\nestimate: 2h"
+
+// taskDetails are the task fields edited in the details textarea.
+type taskDetails struct {
+	Description string
+	Criteria    []session.Criterion
+	Tags        []string
+	SpecRefs    []string
+	Estimate    string
+}
+
+// parseTaskDetails parses the details textarea: "- [ ]" lines are acceptance
+// criteria, "tags:", "spec:" and "estimate:" lines set those fields, and the
+// remaining lines form the description.
+func parseTaskDetails(text string) taskDetails {
+	var d taskDetails
+	var description []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if m := criterionLineRe.FindStringSubmatch(trimmed); m != nil {
+			d.Criteria = append(d.Criteria, session.Criterion{Text: strings.TrimSpace(m[2]), Done: m[1] != " "})
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		switch strings.ToLower(key) {
+		case "tags":
+			if ok {
+				d.Tags = splitList(value)
+				continue
+			}
+		case "spec":
+			if ok {
+				d.SpecRefs = splitList(value)
+				continue
+			}
+		case "estimate":
+			if ok {
+				d.Estimate = strings.TrimSpace(value)
+				continue
+			}
+		}
+		description = append(description, line)
+	}
+	d.Description = strings.TrimSpace(strings.Join(description, "\n"))
+	return d
+}
+
+// formatTaskDetails formats a task's details for the details textarea, the
+// inverse of parseTaskDetails.
+func formatTaskDetails(task *session.Task) string {
+	var lines []string
+	if task.Description != "" {
+		lines = append(lines, task.Description)
+	}
+	for _, c := range task.Criteria {
+		check := " "
+		if c.Done {
+			check = "x"
+		}
+		lines = append(lines, "- ["+check+"] "+c.Text)
+	}
+	if len(task.Tags) > 0 {
+		lines = append(lines, "tags: "+strings.Join(task.Tags, ", "))
+	}
+	if len(task.SpecRefs) > 0 {
+		lines = append(lines, "spec: "+strings.Join(task.SpecRefs, ", "))
+	}
+	if task.Estimate != "" {
+		lines = append(lines, "estimate: "+task.Estimate)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// criteriaTexts returns the text of each criterion.
+func criteriaTexts(criteria []session.Criterion) []string {
+	texts := make([]string, len(criteria))
+	for i, c := range criteria {
+		texts[i] = c.Text
+	}
+	return texts
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+func detailsTestTask() *session.Task {
+	return &session.Task{
+		ID:          "TAS-1",
+		Content:     "Add login form",
+		Status:      "in_progress",
+		Priority:    2,
+		Description: "Email and password.\nShow errors inline.",
+		Criteria:    []session.Criterion{{Text: "Shows errors", Done: true}, {Text: "Sets cookie"}},
+		Tags:        []string{"auth", "ui"},
+		SpecRefs:    []string{"spec.md#Login"},
+		Estimate:    "2h",
+	}
+}
+
+func TestParseTaskDetails(t *testing.T) {
+	t.Parallel()
+
+	task := detailsTestTask()
+	text := formatTaskDetails(task)
+	require.Equal(t, "Email and password.\nShow errors inline.\n- [x] Shows errors\n- [ ] Sets cookie\ntags: auth, ui\nspec: spec.md#Login\nestimate: 2h", text)
+
+	d := parseTaskDetails(text)
+	require.Equal(t, task.Description, d.Description)
+	require.Equal(t, task.Criteria, d.Criteria)
+	require.Equal(t, task.Tags, d.Tags)
+	require.Equal(t, task.SpecRefs, d.SpecRefs)
+	require.Equal(t, task.Estimate, d.Estimate)
+
+	d = parseTaskDetails("Note: keys are case sensitive\n\n* [X] Done already\nTags: , api ,")
+	require.Equal(t, "Note: keys are case sensitive", d.Description, "only known keys are fields")
+	require.Equal(t, []session.Criterion{{Text: "Done already", Done: true}}, d.Criteria)
+	require.Equal(t, []string{"api"}, d.Tags)
+}
+
+func TestTaskInputModal_CreateWithDetails(t *testing.T) {
+	t.Parallel()
+
+	modal := NewTaskInputModal()
+	modal.Show()
+	modal.textarea.SetValue("Add login form")
+	modal.details.SetValue("Email and password\n- [ ] Shows errors\ntags: auth")
+
+	cmd := modal.Update(tea.KeyPressMsg{Text: "ctrl+enter"})
+	require.NotNil(t, cmd)
+	msg, ok := cmd().(CreateTaskMsg)
+	require.True(t, ok)
+	require.Equal(t, "Add login form", msg.Content)
+	require.Equal(t, "Email and password", msg.Description)
+	require.Equal(t, []string{"Shows errors"}, msg.Criteria)
+	require.Equal(t, []string{"auth"}, msg.Tags)
+}
+
+func TestTaskInputModal_EditSendsChangedFields(t *testing.T) {
+	t.Parallel()
+
+	modal := NewTaskInputModal()
+	modal.Edit(detailsTestTask())
+	require.True(t, modal.IsVisible())
+	require.Equal(t, "Add login form", modal.textarea.Value())
+
+	// Untouched edit changes nothing
+	cmd := modal.Update(tea.KeyPressMsg{Text: "ctrl+enter"})
+	require.Equal(t, UpdateTaskDetailsMsg{ID: "TAS-1", Priority: -1}, cmd())
+	require.False(t, modal.IsVisible(), "modal closes after saving")
+
+	modal.Edit(detailsTestTask())
+	modal.details.SetValue("Email and password.\nShow errors inline.\n- [ ] Shows errors\n- [x] Sets cookie\n- [ ] Remembers me\ntags: auth, ui\nspec: spec.md#Login\nestimate: 1d")
+	cmd = modal.Update(tea.KeyPressMsg{Text: "ctrl+enter"})
+	msg := cmd().(UpdateTaskDetailsMsg)
+	require.Empty(t, msg.Content)
+	require.Equal(t, -1, msg.Priority)
+	require.Nil(t, msg.Details.Description)
+	require.Nil(t, msg.Details.Tags)
+	require.Equal(t, []string{"Shows errors", "Sets cookie", "Remembers me"}, msg.Details.Criteria)
+	require.Equal(t, "1d", *msg.Details.Estimate)
+	require.Equal(t, []session.TaskCriterionParams{{Index: 1, Done: false}, {Index: 2, Done: true}}, msg.Checks)
+}
+
+func TestTaskModal_Details(t *testing.T) {
+	t.Parallel()
+
+	modal := NewTaskModal()
+	modal.SetTask(detailsTestTask())
+
+	scr := uv.NewScreenBuffer(testfixtures.TestTermWidth, testfixtures.TestTermHeight)
+	modal.Draw(scr, uv.Rect(0, 0, testfixtures.TestTermWidth, testfixtures.TestTermHeight))
+	out := scr.Render()
+	for _, want := range []string{"Show errors inline.", "1. ✓ Shows errors", "2. ○ Sets cookie", "auth, ui", "spec.md#Login", "2h"} {
+		require.True(t, strings.Contains(out, want), "draw output should contain %q", want)
+	}
+
+	task := detailsTestTask()
+	task.Verification = &session.Verification{Commands: []string{"go vet ./...", "go test ./..."}, Failed: "go test ./..."}
+	modal.SetTask(task)
+	scr = uv.NewScreenBuffer(testfixtures.TestTermWidth, testfixtures.TestTermHeight)
+	modal.Draw(scr, uv.Rect(0, 0, testfixtures.TestTermWidth, testfixtures.TestTermHeight))
+	require.Contains(t, scr.Render(), "✗ go test ./... failed")
+
+	cmd := modal.Update(tea.KeyPressMsg{Text: "2"})
+	require.Equal(t, ToggleTaskCriterionMsg{ID: "TAS-1", Index: 2, Done: true}, cmd())
+	require.Nil(t, modal.Update(tea.KeyPressMsg{Text: "3"}), "no third criterion")
+
+	cmd = modal.Update(tea.KeyPressMsg{Text: "e"})
+	edit, ok := cmd().(EditTaskMsg)
+	require.True(t, ok)
+	require.Equal(t, "TAS-1", edit.Task.ID)
+
+	updated := detailsTestTask()
+	updated.Criteria[1].Done = true
+	modal.Refresh(&session.State{Tasks: map[string]*session.Task{"TAS-1": updated}})
+	require.True(t, modal.Task().Criteria[1].Done, "refresh shows the latest task")
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
@@ -10,6 +11,7 @@ import (
 	lipgloss "charm.land/lipgloss/v2"
 	uv "github.com/charmbracelet/ultraviolet"
 
+	"github.com/mark3labs/iteratr/internal/session"
 	"github.com/mark3labs/iteratr/internal/tui/theme"
 )
 
@@ -17,6 +19,9 @@ import (
 // We reuse the focusTypeSelector value since they serve the same role (first selector in modal).
 const focusPrioritySelector = focusTypeSelector
 
+// focusDetails is the focusZone value for the details textarea in TaskInputModal.
+const focusDetails = focusSubmitButton + 1
+
 // Priority levels matching session.Task priority values.
 // The value field maps to the integer stored in the Task struct and event metadata.
 var priorities = []struct {
@@ -31,13 +36,17 @@ var priorities = []struct {
 	{4, "backlog", "○"},
 }
 
-// TaskInputModal is an interactive modal for creating new tasks.
-// It displays a textarea for content input, a priority selector, and allows the user to submit tasks.
+// TaskInputModal is an interactive modal for creating and editing tasks.
+// It displays a textarea for content input, a details textarea (description,
+// acceptance criteria, tags, spec sections and estimate), a priority selector,
+// and allows the user to submit tasks.
 type TaskInputModal struct {
 	visible       bool
 	textarea      textarea.Model // Bubbles v2 textarea
+	details       textarea.Model // Task details, parsed by parseTaskDetails
 	priorityIndex int            // Current selected priority (0-4)
 	focus         focusZone      // Which UI element currently has keyboard focus
+	editing       *session.Task  // Task being edited, nil when creating a task
 	width         int
 	height        int
 }
@@ -67,13 +76,24 @@ func NewTaskInputModal() *TaskInputModal {
 	styles.Cursor.Blink = true
 	ta.SetStyles(styles)
 
+	details := textarea.New()
+	details.Placeholder = taskDetailsPlaceholder
+	details.CharLimit = 2000
+	details.ShowLineNumbers = false
+	details.Prompt = ""
+	details.SetWidth(50)
+	details.SetHeight(5)
+	details.KeyMap.LineNext = key.NewBinding(key.WithKeys("down"))
+	details.SetStyles(styles)
+
 	return &TaskInputModal{
 		visible:       false,
 		textarea:      ta,
+		details:       details,
 		priorityIndex: 2,             // Default to medium
 		focus:         focusTextarea, // Start with textarea focused
 		width:         60,
-		height:        18, // Slightly taller than note modal to fit priority row
+		height:        25, // Taller than note modal to fit priority row and details
 	}
 }
 
@@ -89,6 +109,19 @@ func (m *TaskInputModal) Show() tea.Cmd {
 	return m.textarea.Focus()
 }
 
+// Edit opens the modal filled in with an existing task. Submitting sends an
+// UpdateTaskDetailsMsg with the fields that changed.
+func (m *TaskInputModal) Edit(task *session.Task) tea.Cmd {
+	m.reset()
+	m.editing = task
+	m.textarea.SetValue(task.Content)
+	m.details.SetValue(formatTaskDetails(task))
+	if task.Priority >= 0 && task.Priority < len(priorities) {
+		m.priorityIndex = task.Priority
+	}
+	return m.Show()
+}
+
 // Close hides the modal and resets its state.
 func (m *TaskInputModal) Close() {
 	m.visible = false
@@ -100,6 +133,8 @@ func (m *TaskInputModal) Close() {
 func (m *TaskInputModal) reset() {
 	// Clear textarea content
 	m.textarea.SetValue("")
+	m.details.SetValue("")
+	m.editing = nil
 
 	// Reset priority to default (medium)
 	m.priorityIndex = 2
@@ -107,13 +142,14 @@ func (m *TaskInputModal) reset() {
 	// Reset focus to textarea (default starting position)
 	m.focus = focusTextarea
 
-	// Blur the textarea to reset its internal state
+	// Blur the textareas to reset their internal state
 	m.textarea.Blur()
+	m.details.Blur()
 }
 
 // cycleFocusForward moves focus to the next element in the cycle:
-// priority selector → textarea → submit button → priority selector (wraps)
-// Returns a command to focus the textarea if it becomes the active element.
+// priority selector → textarea → details → submit button → priority selector (wraps)
+// Returns a command to focus a textarea if it becomes the active element.
 func (m *TaskInputModal) cycleFocusForward() tea.Cmd {
 	oldFocus := m.focus
 
@@ -121,6 +157,8 @@ func (m *TaskInputModal) cycleFocusForward() tea.Cmd {
 	case focusPrioritySelector:
 		m.focus = focusTextarea
 	case focusTextarea:
+		m.focus = focusDetails
+	case focusDetails:
 		m.focus = focusSubmitButton
 	case focusSubmitButton:
 		m.focus = focusPrioritySelector
@@ -130,8 +168,8 @@ func (m *TaskInputModal) cycleFocusForward() tea.Cmd {
 }
 
 // cycleFocusBackward moves focus to the previous element in the cycle:
-// button → textarea → priority selector → button (wraps)
-// Returns a command to focus the textarea if it becomes the active element.
+// button → details → textarea → priority selector → button (wraps)
+// Returns a command to focus a textarea if it becomes the active element.
 func (m *TaskInputModal) cycleFocusBackward() tea.Cmd {
 	oldFocus := m.focus
 
@@ -140,25 +178,33 @@ func (m *TaskInputModal) cycleFocusBackward() tea.Cmd {
 		m.focus = focusSubmitButton
 	case focusTextarea:
 		m.focus = focusPrioritySelector
-	case focusSubmitButton:
+	case focusDetails:
 		m.focus = focusTextarea
+	case focusSubmitButton:
+		m.focus = focusDetails
 	}
 
 	return m.updateTextareaFocus(oldFocus)
 }
 
-// updateTextareaFocus manages the textarea's focus/blur state based on focus changes.
-// If focus moved TO textarea, it calls Focus(). If focus moved AWAY from textarea, it calls Blur().
-// Returns the Focus() command if textarea should be focused, nil otherwise.
+// updateTextareaFocus manages the textareas' focus/blur state based on focus changes.
+// If focus moved AWAY from a textarea, it calls Blur(). If focus moved TO a textarea, it calls Focus().
+// Returns the Focus() command if a textarea should be focused, nil otherwise.
 func (m *TaskInputModal) updateTextareaFocus(oldFocus focusZone) tea.Cmd {
-	// Focus moved TO textarea
-	if m.focus == focusTextarea && oldFocus != focusTextarea {
-		return m.textarea.Focus()
+	// Focus moved AWAY from a textarea
+	switch {
+	case oldFocus == focusTextarea && m.focus != focusTextarea:
+		m.textarea.Blur()
+	case oldFocus == focusDetails && m.focus != focusDetails:
+		m.details.Blur()
 	}
 
-	// Focus moved AWAY from textarea
-	if m.focus != focusTextarea && oldFocus == focusTextarea {
-		m.textarea.Blur()
+	// Focus moved TO a textarea
+	switch {
+	case m.focus == focusTextarea && oldFocus != focusTextarea:
+		return m.textarea.Focus()
+	case m.focus == focusDetails && oldFocus != focusDetails:
+		return m.details.Focus()
 	}
 
 	return nil
@@ -189,6 +235,11 @@ func (m *TaskInputModal) Update(msg tea.Msg) tea.Cmd {
 		if m.focus == focusTextarea {
 			return m.handlePaste(pasteMsg)
 		}
+		if m.focus == focusDetails {
+			var cmd tea.Cmd
+			m.details, cmd = m.details.Update(pasteMsg)
+			return cmd
+		}
 		// Paste when textarea not focused is a no-op
 		return nil
 	}
@@ -251,21 +302,82 @@ func (m *TaskInputModal) Update(msg tea.Msg) tea.Cmd {
 		m.textarea, cmd = m.textarea.Update(msg)
 		return cmd
 	}
+	if m.focus == focusDetails {
+		var cmd tea.Cmd
+		m.details, cmd = m.details.Update(msg)
+		return cmd
+	}
 
 	return nil
 }
 
-// submit returns a command that creates a CreateTaskMsg.
+// submit returns a command that creates a CreateTaskMsg, or an
+// UpdateTaskDetailsMsg when editing a task.
 // The App will receive this message and fill in the iteration number.
 func (m *TaskInputModal) submit(content string) tea.Cmd {
 	priority := priorities[m.priorityIndex].value
+	details := parseTaskDetails(m.details.Value())
+	if m.editing != nil {
+		msg := taskUpdate(m.editing, content, priority, details)
+		m.Close()
+		return func() tea.Msg { return msg }
+	}
 	return func() tea.Msg {
 		return CreateTaskMsg{
-			Content:   content,
-			Priority:  priority,
-			Iteration: 0, // Will be filled in by App
+			Content:     content,
+			Priority:    priority,
+			Iteration:   0, // Will be filled in by App
+			Description: details.Description,
+			Criteria:    criteriaTexts(details.Criteria),
+			Tags:        details.Tags,
+			SpecRefs:    details.SpecRefs,
+			Estimate:    details.Estimate,
+		}
+	}
+}
+
+// taskUpdate builds the UpdateTaskDetailsMsg for the fields of task that
+// differ from the edited values.
+func taskUpdate(task *session.Task, content string, priority int, d taskDetails) UpdateTaskDetailsMsg {
+	msg := UpdateTaskDetailsMsg{ID: task.ID, Priority: -1}
+	if content != task.Content {
+		msg.Content = content
+	}
+	if priority != task.Priority {
+		msg.Priority = priority
+	}
+
+	texts := criteriaTexts(d.Criteria)
+	if d.Description != task.Description {
+		msg.Details.Description = &d.Description
+	}
+	if !slices.Equal(texts, criteriaTexts(task.Criteria)) {
+		msg.Details.Criteria = append([]string{}, texts...)
+	}
+	if !slices.Equal(d.Tags, task.Tags) {
+		msg.Details.Tags = append([]string{}, d.Tags...)
+	}
+	if !slices.Equal(d.SpecRefs, task.SpecRefs) {
+		msg.Details.SpecRefs = append([]string{}, d.SpecRefs...)
+	}
+	if d.Estimate != task.Estimate {
+		msg.Details.Estimate = &d.Estimate
+	}
+
+	// Criteria keep their met state when their text is unchanged, so only
+	// ticks that differ from that need marking
+	met := make(map[string]bool)
+	for _, c := range task.Criteria {
+		if c.Done {
+			met[c.Text] = true
+		}
+	}
+	for i, c := range d.Criteria {
+		if c.Done != met[c.Text] {
+			msg.Checks = append(msg.Checks, session.TaskCriterionParams{Index: i + 1, Done: c.Done})
 		}
 	}
+	return msg
 }
 
 // handlePaste processes a paste message for the textarea with char limit enforcement.
@@ -395,6 +507,9 @@ func (m *TaskInputModal) renderButton() string {
 		buttonStyle = s.BadgeMuted
 	}
 
+	if m.editing != nil {
+		return buttonStyle.Render("  Save Task  ")
+	}
 	return buttonStyle.Render("  Add Task  ")
 }
 
@@ -408,7 +523,11 @@ func (m *TaskInputModal) View() string {
 	var sections []string
 
 	// Title - width accounts for border (2) + padding (4)
-	title := renderModalTitle("New Task", m.width-6)
+	titleText := "New Task"
+	if m.editing != nil {
+		titleText = "Edit Task " + m.editing.ID
+	}
+	title := renderModalTitle(titleText, m.width-6)
 	sections = append(sections, title)
 	sections = append(sections, "")
 
@@ -421,6 +540,10 @@ func (m *TaskInputModal) View() string {
 	sections = append(sections, m.textarea.View())
 	sections = append(sections, "")
 
+	// Details textarea
+	sections = append(sections, m.details.View())
+	sections = append(sections, "")
+
 	// Submit button (right-aligned)
 	button := m.renderButton()
 	buttonLine := lipgloss.NewStyle().Width(m.width - 4).Align(lipgloss.Right).Render(button)
@@ -88,22 +88,28 @@ func TestTaskInputModal_FocusCycleForward(t *testing.T) {
 		t.Errorf("Initial focus: got %v, want focusTextarea", modal.focus)
 	}
 
-	// Tab: textarea → submit button
+	// Tab: textarea → details
+	modal.Update(tea.KeyPressMsg{Text: "tab"})
+	if modal.focus != focusDetails {
+		t.Errorf("After first tab: got %v, want focusDetails", modal.focus)
+	}
+
+	// Tab: details → submit button
 	modal.Update(tea.KeyPressMsg{Text: "tab"})
 	if modal.focus != focusSubmitButton {
-		t.Errorf("After first tab: got %v, want focusSubmitButton", modal.focus)
+		t.Errorf("After second tab: got %v, want focusSubmitButton", modal.focus)
 	}
 
 	// Tab: submit button → priority selector
 	modal.Update(tea.KeyPressMsg{Text: "tab"})
 	if modal.focus != focusPrioritySelector {
-		t.Errorf("After second tab: got %v, want focusPrioritySelector", modal.focus)
+		t.Errorf("After third tab: got %v, want focusPrioritySelector", modal.focus)
 	}
 
 	// Tab: priority selector → textarea (wraps around)
 	modal.Update(tea.KeyPressMsg{Text: "tab"})
 	if modal.focus != focusTextarea {
-		t.Errorf("After third tab: got %v, want focusTextarea (wrap)", modal.focus)
+		t.Errorf("After fourth tab: got %v, want focusTextarea (wrap)", modal.focus)
 	}
 }
 
@@ -129,10 +135,16 @@ func TestTaskInputModal_FocusCycleBackward(t *testing.T) {
 		t.Errorf("After second shift+tab: got %v, want focusSubmitButton", modal.focus)
 	}
 
-	// Shift+Tab: submit button → textarea (wraps around)
+	// Shift+Tab: submit button → details
+	modal.Update(tea.KeyPressMsg{Text: "shift+tab"})
+	if modal.focus != focusDetails {
+		t.Errorf("After third shift+tab: got %v, want focusDetails", modal.focus)
+	}
+
+	// Shift+Tab: details → textarea (wraps around)
 	modal.Update(tea.KeyPressMsg{Text: "shift+tab"})
 	if modal.focus != focusTextarea {
-		t.Errorf("After third shift+tab: got %v, want focusTextarea (wrap)", modal.focus)
+		t.Errorf("After fourth shift+tab: got %v, want focusTextarea (wrap)", modal.focus)
 	}
 }
 
@@ -252,7 +264,8 @@ func TestTaskInputModal_PriorityCycleOnlyWhenFocused(t *testing.T) {
 		t.Errorf("Priority should not change when textarea is focused: got %d, want %d", modal.priorityIndex, initialPriority)
 	}
 
-	// Move to submit button
+	// Move to submit button (past the details textarea)
+	modal.Update(tea.KeyPressMsg{Text: "tab"})
 	modal.Update(tea.KeyPressMsg{Text: "tab"})
 	if modal.focus != focusSubmitButton {
 		t.Fatalf("Focus should be on submit button, got %v", modal.focus)
@@ -325,7 +338,8 @@ func TestTaskInputModal_EnterOnButtonSubmits(t *testing.T) {
 	// Set content
 	modal.textarea.SetValue("Test task content")
 
-	// Move focus to submit button
+	// Move focus to submit button (past the details textarea)
+	modal.Update(tea.KeyPressMsg{Text: "tab"})
 	modal.Update(tea.KeyPressMsg{Text: "tab"})
 	if modal.focus != focusSubmitButton {
 		t.Fatalf("Focus should be on submit button, got %v", modal.focus)
@@ -406,7 +420,8 @@ func TestTaskInputModal_EmptyContentDoesNotSubmit(t *testing.T) {
 		t.Error("Empty content should not submit via Ctrl+Enter")
 	}
 
-	// Move to submit button
+	// Move to submit button (past the details textarea)
+	modal.Update(tea.KeyPressMsg{Text: "tab"})
 	modal.Update(tea.KeyPressMsg{Text: "tab"})
 
 	// Try Enter on button
@@ -1597,6 +1612,8 @@ func TestTaskInputModal_VeryLongContentScrolling(t *testing.T) {
 	}, "Drawing modal with multi-line long content should not panic")
 
 	// Verify the modal is still functional (can navigate)
+	modal.Update(tea.KeyPressMsg{Text: "tab"})
+	require.Equal(t, focusDetails, modal.focus, "Focus should cycle to details textarea")
 	cmd := modal.Update(tea.KeyPressMsg{Text: "tab"})
 	require.Nil(t, cmd, "Tab navigation should work with long content")
 	require.Equal(t, focusSubmitButton, modal.focus, "Focus should cycle to submit button")
@@ -321,6 +321,7 @@ type MockOrchestrator struct {
 	pauseRequested bool
 	pauseCancelled bool
 	resumed        bool
+	rollbackTo     *int
 }
 
 // NewMockOrchestrator creates a new MockOrchestrator.
@@ -356,6 +357,23 @@ func (m *MockOrchestrator) IsPaused() bool {
 	return m.paused
 }
 
+// RequestRollback records the requested rollback target.
+func (m *MockOrchestrator) RequestRollback(to int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollbackTo = &to
+}
+
+// RollbackTarget returns the last requested rollback target, if any.
+func (m *MockOrchestrator) RollbackTarget() (int, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.rollbackTo == nil {
+		return 0, false
+	}
+	return *m.rollbackTo, true
+}
+
 // SetPaused sets the paused state (for testing).
 func (m *MockOrchestrator) SetPaused(paused bool) {
 	m.mu.Lock()
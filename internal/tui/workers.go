@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/theme"
+)
+
+// workerPaneLines is the number of output lines kept per worker pane.
+const workerPaneLines = 50
+
+// WorkerStartMsg is sent when a parallel worker starts an iteration on a task.
+type WorkerStartMsg struct {
+	Worker    int           // 1-based worker slot
+	Iteration int           // Iteration the worker runs
+	Task      *session.Task // Task assigned to the worker
+	Branch    string        // Branch the worker commits to
+}
+
+// WorkerOutputMsg carries agent output from a parallel worker.
+type WorkerOutputMsg struct {
+	Worker  int
+	Content string
+}
+
+// WorkerDoneMsg is sent when a parallel worker finishes its iteration.
+type WorkerDoneMsg struct {
+	Worker int
+	Result string // e.g. "merged", "unfinished" or "conflict: go.mod"
+	OK     bool   // False if the work could not be merged
+}
+
+// workerPane holds the state of one worker's pane.
+type workerPane struct {
+	start  WorkerStartMsg
+	lines  []string // Output tail; the last line may be partial
+	result string
+	ok     bool
+	done   bool
+}
+
+// WorkerPanes shows one pane per parallel worker with its task, status and
+// latest agent output. It is empty outside parallel mode.
+type WorkerPanes struct {
+	panes map[int]*workerPane
+}
+
+// NewWorkerPanes creates an empty set of worker panes.
+func NewWorkerPanes() *WorkerPanes {
+	return &WorkerPanes{panes: make(map[int]*workerPane)}
+}
+
+// Len returns the number of worker panes.
+func (w *WorkerPanes) Len() int {
+	if w == nil {
+		return 0
+	}
+	return len(w.panes)
+}
+
+// Update applies a worker message. Returns true if a pane was added.
+func (w *WorkerPanes) Update(msg tea.Msg) bool {
+	if w == nil {
+		return false
+	}
+	switch msg := msg.(type) {
+	case WorkerStartMsg:
+		_, exists := w.panes[msg.Worker]
+		w.panes[msg.Worker] = &workerPane{start: msg}
+		return !exists
+	case WorkerOutputMsg:
+		if p := w.panes[msg.Worker]; p != nil {
+			p.append(msg.Content)
+		}
+	case WorkerDoneMsg:
+		if p := w.panes[msg.Worker]; p != nil {
+			p.done = true
+			p.result = msg.Result
+			p.ok = msg.OK
+		}
+	}
+	return false
+}
+
+// append adds output text, continuing the last line until a newline.
+func (p *workerPane) append(text string) {
+	parts := strings.Split(text, "\n")
+	if len(p.lines) == 0 {
+		p.lines = []string{""}
+	}
+	p.lines[len(p.lines)-1] += parts[0]
+	p.lines = append(p.lines, parts[1:]...)
+	if len(p.lines) > workerPaneLines {
+		p.lines = p.lines[len(p.lines)-workerPaneLines:]
+	}
+}
+
+// Draw renders the worker panes side by side in slot order.
+func (w *WorkerPanes) Draw(scr uv.Screen, area uv.Rectangle) {
+	if w.Len() == 0 || area.Dx() < 2 || area.Dy() < 2 {
+		return
+	}
+	slots := make([]int, 0, len(w.panes))
+	for slot := range w.panes {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+
+	width := area.Dx() / len(slots)
+	for i, slot := range slots {
+		paneArea := uv.Rect(area.Min.X+i*width, area.Min.Y, width-1, area.Dy())
+		if i == len(slots)-1 {
+			paneArea.Max.X = area.Max.X
+		}
+		w.panes[slot].draw(scr, paneArea)
+	}
+}
+
+// draw renders a single worker pane.
+func (p *workerPane) draw(scr uv.Screen, area uv.Rectangle) {
+	s := theme.Current().S()
+	inner := DrawPanel(scr, area, fmt.Sprintf("Worker %d · %s", p.start.Worker, p.start.Task.ID), false)
+	width := inner.Dx()
+	if width < 1 || inner.Dy() < 1 {
+		return
+	}
+	line := func(style func(...string) string, text string) string {
+		return style(truncateLine(strings.ReplaceAll(text, "\t", "  "), width))
+	}
+
+	status := line(s.Dim.Render, fmt.Sprintf("#%d on %s", p.start.Iteration, p.start.Branch))
+	if p.done {
+		if p.ok {
+			status = line(s.Success.Render, "✓ "+p.result)
+		} else {
+			status = line(s.Error.Render, "✗ "+p.result)
+		}
+	}
+	rows := []string{line(s.Bright.Render, p.start.Task.Title()), status}
+
+	// Fill the remaining height with the latest non-empty output lines
+	var output []string
+	for _, l := range p.lines {
+		if strings.TrimSpace(l) != "" {
+			output = append(output, l)
+		}
+	}
+	if room := inner.Dy() - len(rows); len(output) > room {
+		output = output[len(output)-max(room, 0):]
+	}
+	for _, l := range output {
+		rows = append(rows, line(s.Muted.Render, l))
+	}
+	uv.NewStyledString(strings.Join(rows, "\n")).Draw(scr, inner)
+}
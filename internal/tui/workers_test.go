@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/mark3labs/iteratr/internal/session"
+	"github.com/mark3labs/iteratr/internal/tui/testfixtures"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPanes_Output(t *testing.T) {
+	t.Parallel()
+
+	w := NewWorkerPanes()
+	require.True(t, w.Update(WorkerStartMsg{Worker: 1, Iteration: 3, Task: &session.Task{ID: "TAS-1", Content: "Add login"}, Branch: "iteratr/tas-1"}))
+	require.False(t, w.Update(WorkerOutputMsg{Worker: 2, Content: "ignored"}), "output for an unknown worker is dropped")
+
+	w.Update(WorkerOutputMsg{Worker: 1, Content: "Reading "})
+	w.Update(WorkerOutputMsg{Worker: 1, Content: "files\nEditing"})
+	require.Equal(t, []string{"Reading files", "Editing"}, w.panes[1].lines)
+
+	for i := 0; i < workerPaneLines+10; i++ {
+		w.Update(WorkerOutputMsg{Worker: 1, Content: fmt.Sprintf("line %d\n", i)})
+	}
+	require.Len(t, w.panes[1].lines, workerPaneLines, "output is capped")
+
+	var nilPanes *WorkerPanes
+	require.Equal(t, 0, nilPanes.Len())
+	require.False(t, nilPanes.Update(WorkerStartMsg{Worker: 1}))
+}
+
+func TestWorkerPanes_Draw(t *testing.T) {
+	t.Parallel()
+
+	w := NewWorkerPanes()
+	w.Update(WorkerStartMsg{Worker: 1, Iteration: 3, Task: &session.Task{ID: "TAS-1", Content: "Add login"}, Branch: "iteratr/tas-1"})
+	w.Update(WorkerStartMsg{Worker: 2, Iteration: 4, Task: &session.Task{ID: "TAS-2", Content: "Add signup\nwith email"}, Branch: "iteratr/tas-2"})
+	w.Update(WorkerOutputMsg{Worker: 1, Content: "Editing login.go\n"})
+	w.Update(WorkerDoneMsg{Worker: 2, Result: "conflict: forms.go"})
+
+	scr := uv.NewScreenBuffer(testfixtures.TestTermWidth, 12)
+	w.Draw(scr, uv.Rect(0, 0, testfixtures.TestTermWidth, 12))
+	out := scr.Render()
+	for _, want := range []string{"Worker 1 · TAS-1", "Worker 2 · TAS-2", "#3 on iteratr/tas-1", "Editing login.go", "✗ conflict: forms.go"} {
+		require.True(t, strings.Contains(out, want), "draw output should contain %q", want)
+	}
+	require.False(t, strings.Contains(out, "with email"), "only the first line of a task is shown")
+}
+
+func TestApp_WorkerPanesShowOnDashboard(t *testing.T) {
+	t.Parallel()
+
+	app := NewApp(context.Background(), nil, testfixtures.FixedSessionName, "/tmp", t.TempDir(), nil, nil, &mockOrchestrator{})
+	app.Update(WorkerStartMsg{Worker: 1, Iteration: 1, Task: &session.Task{ID: "TAS-1", Content: "Add login"}, Branch: "tas-1"})
+	app.Update(WorkerOutputMsg{Worker: 1, Content: "Writing tests\n"})
+	require.Equal(t, 1, app.dashboard.workers.Len())
+
+	scr := uv.NewScreenBuffer(testfixtures.TestTermWidth, testfixtures.TestTermHeight)
+	app.dashboard.Draw(scr, uv.Rect(0, 0, testfixtures.TestTermWidth, testfixtures.TestTermHeight))
+	out := scr.Render()
+	require.True(t, strings.Contains(out, "Worker 1 · TAS-1"))
+	require.True(t, strings.Contains(out, "Agent Output"), "agent output stays below the worker panes")
+}
@@ -0,0 +1,120 @@
+package usage
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Price is the cost of a model in USD per million tokens.
+// Reasoning tokens are billed at the output rate.
+type Price struct {
+	Input      float64 `yaml:"input"`
+	Output     float64 `yaml:"output"`
+	CacheRead  float64 `yaml:"cache_read,omitempty"`
+	CacheWrite float64 `yaml:"cache_write,omitempty"`
+}
+
+// Cost returns the USD cost of u at this price.
+func (p Price) Cost(u Usage) float64 {
+	return (float64(u.InputTokens)*p.Input +
+		float64(u.OutputTokens+u.ReasoningTokens)*p.Output +
+		float64(u.CacheReadTokens)*p.CacheRead +
+		float64(u.CacheWriteTokens)*p.CacheWrite) / 1_000_000
+}
+
+// Pricing maps model IDs to prices. Keys are either a full model ID
+// ("anthropic/claude-sonnet-4-5"), a bare model ID ("claude-sonnet-4-5"),
+// or a glob pattern ("anthropic/claude-opus-*").
+type Pricing map[string]Price
+
+// DefaultPricing returns the built-in pricing table.
+func DefaultPricing() Pricing {
+	return Pricing{
+		"claude-opus-4*":    {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+		"claude-sonnet-4*":  {Input: 3, Output: 15, CacheRead: 0.30, CacheWrite: 3.75},
+		"claude-haiku-4*":   {Input: 1, Output: 5, CacheRead: 0.10, CacheWrite: 1.25},
+		"claude-3-5-haiku*": {Input: 0.80, Output: 4, CacheRead: 0.08, CacheWrite: 1},
+		"gpt-5":             {Input: 1.25, Output: 10, CacheRead: 0.125},
+		"gpt-5-mini":        {Input: 0.25, Output: 2, CacheRead: 0.025},
+		"gpt-4.1":           {Input: 2, Output: 8, CacheRead: 0.50},
+		"o3":                {Input: 2, Output: 8, CacheRead: 0.50},
+	}
+}
+
+// LoadPricing reads a YAML pricing file and merges it over the built-in table.
+// An empty path returns the built-in table.
+//
+//	anthropic/claude-sonnet-4-5: {input: 3, output: 15, cache_read: 0.3}
+//	my-local-model: {input: 0, output: 0}
+func LoadPricing(file string) (Pricing, error) {
+	pricing := DefaultPricing()
+	if file == "" {
+		return pricing, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading pricing file: %w", err)
+	}
+	var custom Pricing
+	if err := yaml.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("parsing pricing file %s: %w", file, err)
+	}
+	for model, price := range custom {
+		if _, err := path.Match(model, ""); err != nil {
+			return nil, fmt.Errorf("pricing file %s: invalid model pattern %q", file, model)
+		}
+		pricing[model] = price
+	}
+	return pricing, nil
+}
+
+// Lookup returns the price for a model. Exact matches on the full or bare
+// model ID win over patterns; among patterns the longest one wins.
+func (p Pricing) Lookup(model string) (Price, bool) {
+	if model == "" {
+		return Price{}, false
+	}
+	bare := model
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		bare = model[i+1:]
+	}
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+	if price, ok := p[bare]; ok {
+		return price, true
+	}
+
+	var best string
+	for pattern := range p {
+		if !strings.ContainsAny(pattern, "*?[") || len(pattern) < len(best) ||
+			(len(pattern) == len(best) && pattern > best) {
+			continue
+		}
+		if ok, _ := path.Match(pattern, model); ok {
+			best = pattern
+		} else if ok, _ := path.Match(pattern, bare); ok {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return p[best], true
+}
+
+// Cost fills in u.Cost from the pricing table when the agent did not report one.
+// Usage is returned unchanged if a cost is already set or the model is unknown.
+func (p Pricing) Cost(model string, u Usage) Usage {
+	if u.Cost > 0 {
+		return u
+	}
+	if price, ok := p.Lookup(model); ok {
+		u.Cost = price.Cost(u)
+	}
+	return u
+}
@@ -0,0 +1,57 @@
+// Package usage tracks token consumption and cost reported by agents.
+package usage
+
+import "fmt"
+
+// Usage holds token counts and cost for one or more agent prompts.
+type Usage struct {
+	InputTokens      int64   `json:"input_tokens,omitempty"`
+	OutputTokens     int64   `json:"output_tokens,omitempty"`
+	ReasoningTokens  int64   `json:"reasoning_tokens,omitempty"`
+	CacheReadTokens  int64   `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64   `json:"cache_write_tokens,omitempty"`
+	Cost             float64 `json:"cost,omitempty"` // USD
+}
+
+// Tokens returns the total number of tokens across all categories.
+func (u Usage) Tokens() int64 {
+	return u.InputTokens + u.OutputTokens + u.ReasoningTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// IsZero reports whether no tokens or cost were recorded.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// Add returns the sum of u and other.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:      u.InputTokens + other.InputTokens,
+		OutputTokens:     u.OutputTokens + other.OutputTokens,
+		ReasoningTokens:  u.ReasoningTokens + other.ReasoningTokens,
+		CacheReadTokens:  u.CacheReadTokens + other.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + other.CacheWriteTokens,
+		Cost:             u.Cost + other.Cost,
+	}
+}
+
+// String formats the usage compactly for display, e.g. "12.3k tok · $0.42".
+// Cost is omitted when unknown.
+func (u Usage) String() string {
+	s := formatTokens(u.Tokens()) + " tok"
+	if u.Cost > 0 {
+		s += fmt.Sprintf(" · $%.2f", u.Cost)
+	}
+	return s
+}
+
+// formatTokens abbreviates a token count (950, 12.3k, 4.5M).
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,118 @@
+package usage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsageAdd(t *testing.T) {
+	a := Usage{InputTokens: 100, OutputTokens: 20, Cost: 0.5}
+	b := Usage{InputTokens: 50, ReasoningTokens: 5, CacheReadTokens: 1000, Cost: 0.25}
+	got := a.Add(b)
+	want := Usage{InputTokens: 150, OutputTokens: 20, ReasoningTokens: 5, CacheReadTokens: 1000, Cost: 0.75}
+	if got != want {
+		t.Errorf("Add() = %+v, want %+v", got, want)
+	}
+	if got.Tokens() != 1175 {
+		t.Errorf("Tokens() = %d, want 1175", got.Tokens())
+	}
+	if got.IsZero() || !(Usage{}).IsZero() {
+		t.Error("IsZero() mismatch")
+	}
+}
+
+func TestUsageString(t *testing.T) {
+	tests := []struct {
+		u    Usage
+		want string
+	}{
+		{Usage{InputTokens: 950}, "950 tok"},
+		{Usage{InputTokens: 12_000, OutputTokens: 340, Cost: 0.4234}, "12.3k tok · $0.42"},
+		{Usage{CacheReadTokens: 4_500_000, Cost: 1.5}, "4.5M tok · $1.50"},
+	}
+	for _, tt := range tests {
+		if got := tt.u.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestPricingLookup(t *testing.T) {
+	p := Pricing{
+		"claude-sonnet-4*":            {Input: 3, Output: 15},
+		"anthropic/claude-sonnet-4-5": {Input: 4, Output: 16},
+		"claude-*":                    {Input: 1, Output: 1},
+		"gpt-5":                       {Input: 1.25, Output: 10},
+	}
+	tests := []struct {
+		model string
+		want  float64 // Input price
+		ok    bool
+	}{
+		{"anthropic/claude-sonnet-4-5", 4, true},
+		{"claude-sonnet-4-5", 3, true},
+		{"bedrock/claude-sonnet-4", 3, true},
+		{"anthropic/claude-opus-4", 1, true},
+		{"openai/gpt-5", 1.25, true},
+		{"openai/gpt-5-mini", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		price, ok := p.Lookup(tt.model)
+		if ok != tt.ok || price.Input != tt.want {
+			t.Errorf("Lookup(%q) = %+v, %v; want input %v, %v", tt.model, price, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPriceCost(t *testing.T) {
+	price := Price{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	u := Usage{InputTokens: 1_000_000, OutputTokens: 100_000, ReasoningTokens: 100_000, CacheReadTokens: 1_000_000}
+	if got, want := price.Cost(u), 3+3+0.3; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost() = %v, want %v", got, want)
+	}
+
+	p := Pricing{"m": price}
+	if got := p.Cost("m", Usage{OutputTokens: 1000, Cost: 9}); got.Cost != 9 {
+		t.Errorf("reported cost overwritten: %v", got.Cost)
+	}
+	if got := p.Cost("unknown", Usage{OutputTokens: 1000}); got.Cost != 0 {
+		t.Errorf("unknown model cost = %v, want 0", got.Cost)
+	}
+}
+
+func TestLoadPricing(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "pricing.yml")
+	content := "local/llama: {input: 0.1, output: 0.2}\nclaude-sonnet-4*: {input: 2, output: 10}\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadPricing(file)
+	if err != nil {
+		t.Fatalf("LoadPricing() error = %v", err)
+	}
+	if price, _ := p.Lookup("local/llama"); price.Output != 0.2 {
+		t.Errorf("custom model price = %+v", price)
+	}
+	if price, _ := p.Lookup("claude-sonnet-4-5"); price.Input != 2 {
+		t.Errorf("override price = %+v, want input 2", price)
+	}
+	if _, ok := p.Lookup("claude-opus-4-1"); !ok {
+		t.Error("built-in prices should remain after merge")
+	}
+
+	if _, err := LoadPricing(filepath.Join(dir, "missing.yml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	bad := filepath.Join(dir, "bad.yml")
+	if err := os.WriteFile(bad, []byte("model: [1, 2]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPricing(bad); err == nil {
+		t.Error("expected error for malformed file")
+	}
+}
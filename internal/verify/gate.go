@@ -0,0 +1,38 @@
+package verify
+
+import (
+	"context"
+
+	"github.com/mark3labs/iteratr/internal/config"
+	"github.com/mark3labs/iteratr/internal/logger"
+	"github.com/mark3labs/iteratr/internal/session"
+)
+
+// Gate runs the verification commands configured for a task about to be
+// marked completed, in dir, and records the result against iteration.
+// Returns a passing Result with no commands when there is nothing to verify
+// (the task is already completed or no commands apply). Returns an error only
+// if ctx is cancelled.
+func Gate(ctx context.Context, store *session.Store, sessionName string, task *session.Task, cfg config.VerifyConfig, dir string, iteration int) (Result, error) {
+	commands := cfg.CommandsFor(task.ID, task.Tags)
+	if task.Status == "completed" || len(commands) == 0 {
+		return Result{Passed: true}, nil
+	}
+
+	result, err := Run(ctx, dir, commands, cfg.Timeout)
+	if err != nil {
+		return Result{}, err
+	}
+	err = store.TaskVerify(ctx, sessionName, session.TaskVerifyParams{
+		ID:        task.ID,
+		Passed:    result.Passed,
+		Commands:  result.Commands,
+		Failed:    result.Failed,
+		Output:    result.Output,
+		Iteration: iteration,
+	})
+	if err != nil {
+		logger.Warn("Failed to record verification of %s: %v", task.ID, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+// Package verify runs the commands that must pass before a task can be
+// marked completed.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/mark3labs/iteratr/internal/logger"
+)
+
+// DefaultTimeout is the per-command timeout used when none is configured.
+const DefaultTimeout = 10 * time.Minute
+
+// MaxOutput is the number of bytes kept from the end of a failed command's output.
+const MaxOutput = 8000
+
+// Result is the outcome of running verification commands.
+type Result struct {
+	Commands []string // Commands run, in order (stops at the first failure)
+	Passed   bool
+	Failed   string // Command that failed (empty if passed)
+	Output   string // Tail of the failed command's combined stdout and stderr
+}
+
+// Run runs commands in dir through the shell, in order, stopping at the
+// first one that fails or exceeds timeout (DefaultTimeout if <= 0). Returns
+// an error only if ctx is cancelled.
+func Run(ctx context.Context, dir string, commands []string, timeout time.Duration) (Result, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	result := Result{Passed: true}
+	for _, command := range commands {
+		result.Commands = append(result.Commands, command)
+		logger.Debug("Running verification command: %s", command)
+
+		execCtx, cancel := context.WithTimeout(ctx, timeout)
+		cmd := exec.CommandContext(execCtx, "sh", "-c", command)
+		cmd.Dir = dir
+		cmd.WaitDelay = time.Second // Don't wait on children still holding the output pipe after a kill
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		err := cmd.Run()
+		timedOut := execCtx.Err() == context.DeadlineExceeded
+		cancel()
+
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		if err == nil {
+			continue
+		}
+
+		logger.Warn("Verification command failed: %s: %v", command, err)
+		result.Passed = false
+		result.Failed = command
+		result.Output = tail(output.String(), MaxOutput)
+		if timedOut {
+			result.Output += fmt.Sprintf("\n[timed out after %s]", timeout)
+		} else {
+			result.Output += fmt.Sprintf("\n[%v]", err)
+		}
+		break
+	}
+	return result, nil
+}
+
+// tail returns the last n bytes of s, marking where it was cut.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "[... output truncated ...]\n" + s[len(s)-n:]
+}
@@ -0,0 +1,69 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "marker"), []byte("ok"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Run(context.Background(), dir, []string{"test -f marker", "true"}, 0)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.Passed || result.Failed != "" || len(result.Commands) != 2 {
+		t.Errorf("Run() = %+v, want both commands passed", result)
+	}
+
+	result, err = Run(context.Background(), dir, []string{"true", "echo boom >&2; exit 3", "touch never"}, 0)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Passed || result.Failed != "echo boom >&2; exit 3" {
+		t.Errorf("Run() = %+v, want second command to fail", result)
+	}
+	if len(result.Commands) != 2 {
+		t.Errorf("Commands = %v, want run to stop at the failure", result.Commands)
+	}
+	if !strings.Contains(result.Output, "boom") || !strings.Contains(result.Output, "exit status 3") {
+		t.Errorf("Output = %q, want stderr and exit status", result.Output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "never")); err == nil {
+		t.Error("commands after the failure should not run")
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	result, err := Run(context.Background(), t.TempDir(), []string{"sleep 5"}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Passed || !strings.Contains(result.Output, "timed out after 50ms") {
+		t.Errorf("Run() = %+v, want timeout failure", result)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, t.TempDir(), []string{"true"}, 0); err == nil {
+		t.Error("Run() should return an error when the context is cancelled")
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := tail("short", 10); got != "short" {
+		t.Errorf("tail() = %q", got)
+	}
+	if got := tail("0123456789", 4); got != "[... output truncated ...]\n6789" {
+		t.Errorf("tail() = %q", got)
+	}
+}